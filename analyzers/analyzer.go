@@ -0,0 +1,144 @@
+package analyzers
+
+import (
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+// Analyzer produces diagnostics for a document. Each run receives a snapshot
+// of the settings by value, so that all analyzers in a run see the same
+// settings, even if they change during the run.
+type Analyzer interface {
+	Analyze(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic
+	// Cost hints at how long the analyzer takes, so that the results of
+	// cheap analyzers can be published before expensive ones have finished.
+	Cost() Cost
+}
+
+// Cost of running an analyzer.
+type Cost int
+
+const (
+	// CostCheap analyzers are fast enough to run before the first publish.
+	CostCheap Cost = iota
+	// CostExpensive analyzers run after the results of cheap analyzers have
+	// been published.
+	CostExpensive
+)
+
+// AnalyzerFunc adapts a function to the Analyzer interface, with a cheap
+// cost.
+type AnalyzerFunc func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic
+
+func (f AnalyzerFunc) Analyze(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+	return f(doc, s)
+}
+
+func (f AnalyzerFunc) Cost() Cost {
+	return CostCheap
+}
+
+type costed struct {
+	Analyzer
+	cost Cost
+}
+
+func (c costed) Cost() Cost {
+	return c.cost
+}
+
+// WithCost returns the analyzer with a different cost.
+func WithCost(a Analyzer, cost Cost) Analyzer {
+	return costed{Analyzer: a, cost: cost}
+}
+
+// Analyze runs the analyzers against the document, using the same settings
+// snapshot for all of them.
+func Analyze(doc messages.TextDocumentItem, s settings.Snapshot, analyzers ...Analyzer) (diagnostics []messages.Diagnostic) {
+	diagnostics = []messages.Diagnostic{}
+	for _, a := range analyzers {
+		diagnostics = append(diagnostics, a.Analyze(doc, s)...)
+	}
+	if s.FlatDiagnosticSource() {
+		FlattenSources(diagnostics)
+	}
+	return diagnostics
+}
+
+// AnalyzeInPhases runs the cheap analyzers and publishes their diagnostics,
+// then, if there are any expensive analyzers, runs them and publishes the
+// diagnostics of both phases. All of the analyzers use the same settings
+// snapshot.
+func AnalyzeInPhases(doc messages.TextDocumentItem, s settings.Snapshot, publish func(diagnostics []messages.Diagnostic), analyzers ...Analyzer) {
+	var cheap, expensive []Analyzer
+	for _, a := range analyzers {
+		if a.Cost() == CostCheap {
+			cheap = append(cheap, a)
+			continue
+		}
+		expensive = append(expensive, a)
+	}
+	diagnostics := Analyze(doc, s, cheap...)
+	publish(diagnostics)
+	if len(expensive) == 0 {
+		return
+	}
+	all := make([]messages.Diagnostic, 0, len(diagnostics))
+	all = append(all, diagnostics...)
+	publish(append(all, Analyze(doc, s, expensive...)...))
+}
+
+// Analyzers for the checks in this package, with their declared codes.
+var (
+	DuplicateStepsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return DuplicateSteps(doc.URI, doc.Text)
+	}), CostExpensive), CodeDuplicateStep)
+	UndeclaredTimersAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return UndeclaredTimers(doc.Text)
+	}), CostExpensive), CodeUndeclaredTimer)
+	MissingUnitsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return MissingUnits(doc.Text)
+	}), CostExpensive), CodeMissingUnit)
+	IncompatibleUnitsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return IncompatibleUnits(doc.URI, doc.Text)
+	}), CostExpensive), CodeIncompatibleUnits)
+	MisspelledIngredientsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		if !s.SpellcheckEnabled() {
+			return nil
+		}
+		return MisspelledIngredients(doc.Text)
+	}), CostExpensive), CodeMisspelledIngredient)
+	FahrenheitAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return Fahrenheit(doc.Text)
+	}), CostExpensive), CodeFahrenheit)
+	EmptyRecipeAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return EmptyRecipe(doc.Text)
+	}), CostExpensive), CodeNoSteps, CodeNoIngredients)
+	NoServingsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return NoServings(doc.Text)
+	}), CostExpensive), CodeNoServings)
+	EmptyStepsAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return EmptySteps(doc.Text)
+	}), CostExpensive), CodeEmptyStep)
+	WhitespaceAnalyzer = mustDeclare(WithCost(AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		if !s.StyleEnabled() {
+			return nil
+		}
+		return Whitespace(doc.Text)
+	}), CostExpensive), CodeTrailingWhitespace, CodeTabIndentation)
+)
+
+// Defaults are the analyzers in this package, in the order the server runs
+// them.
+var Defaults = []Analyzer{
+	DuplicateStepsAnalyzer,
+	UndeclaredTimersAnalyzer,
+	MissingUnitsAnalyzer,
+	IncompatibleUnitsAnalyzer,
+	MisspelledIngredientsAnalyzer,
+	FahrenheitAnalyzer,
+	EmptyRecipeAnalyzer,
+	NoServingsAnalyzer,
+	EmptyStepsAnalyzer,
+	WhitespaceAnalyzer,
+}
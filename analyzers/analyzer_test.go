@@ -0,0 +1,190 @@
+package analyzers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+func TestAnalyzeUsesOneSnapshotPerRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, settings.FileName), nil, 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	store := settings.NewStore()
+	doc := messages.TextDocumentItem{
+		URI:  "file:///recipe.cook",
+		Text: "Boil @water. \n\nBoil @water. ",
+	}
+
+	// Flip the settings while documents are being analyzed.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			enabled := i%2 == 0
+			store.SetDefaults(settings.Settings{Style: &enabled, FlatDiagnosticSource: enabled})
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		snapshot, err := store.Snapshot(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		diagnostics := Analyze(doc, snapshot, DuplicateStepsAnalyzer, WhitespaceAnalyzer)
+		var whitespace, flat int
+		for _, d := range diagnostics {
+			if *d.Code == CodeTrailingWhitespace {
+				whitespace++
+			}
+			if *d.Source == Source {
+				flat++
+			}
+		}
+		if snapshot.StyleEnabled() != (whitespace > 0) {
+			t.Fatalf("expected whitespace diagnostics to match the snapshot, style enabled: %v, got %d", snapshot.StyleEnabled(), whitespace)
+		}
+		if snapshot.FlatDiagnosticSource() != (flat == len(diagnostics)) || (flat != 0 && flat != len(diagnostics)) {
+			t.Fatalf("expected sources to match the snapshot, flat sources: %v, got %d of %d flat", snapshot.FlatDiagnosticSource(), flat, len(diagnostics))
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestAnalyzeInPhases(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 5000; i++ {
+		sb.WriteString("Boil the @water{1%l} for ~{10%minutes}. \n\n")
+	}
+	doc := messages.TextDocumentItem{URI: "file:///large.cook", Text: sb.String()}
+	cheap := AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return []messages.Diagnostic{{Message: "parse error"}}
+	})
+
+	var published [][]messages.Diagnostic
+	publish := func(diagnostics []messages.Diagnostic) {
+		published = append(published, diagnostics)
+	}
+	AnalyzeInPhases(doc, settings.Settings{Style: ptr(true)}.Snapshot(), publish, cheap, DuplicateStepsAnalyzer, WhitespaceAnalyzer)
+
+	if len(published) != 2 {
+		t.Fatalf("expected 2 publishes, got %d", len(published))
+	}
+	if len(published[0]) != 1 || published[0][0].Message != "parse error" {
+		t.Errorf("expected the first publish to contain only the cheap diagnostics, got %d diagnostics", len(published[0]))
+	}
+	if len(published[1]) <= len(published[0]) {
+		t.Fatalf("expected the second publish to contain more diagnostics, got %d then %d", len(published[0]), len(published[1]))
+	}
+	if !reflect.DeepEqual(published[1][:len(published[0])], published[0]) {
+		t.Errorf("expected the second publish to include the diagnostics of the first")
+	}
+}
+
+func TestAnalyzeInPhasesWithoutExpensiveAnalyzers(t *testing.T) {
+	var publishes int
+	AnalyzeInPhases(messages.TextDocumentItem{Text: "Boil @water."}, settings.Settings{}.Snapshot(), func([]messages.Diagnostic) { publishes++ })
+	if publishes != 1 {
+		t.Errorf("expected 1 publish, got %d", publishes)
+	}
+}
+
+func TestPublisherSkipsUnchangedDiagnostics(t *testing.T) {
+	var sent []messages.PublishDiagnosticsParams
+	p := NewPublisher(func(params messages.PublishDiagnosticsParams) {
+		sent = append(sent, params)
+	})
+	diagnostics := Whitespace("Boil @water. ")
+	if !p.Publish(messages.PublishDiagnosticsParams{URI: "file:///a.cook", Diagnostics: diagnostics}) {
+		t.Error("expected the first diagnostics to be published")
+	}
+	if p.Publish(messages.PublishDiagnosticsParams{URI: "file:///a.cook", Diagnostics: Whitespace("Boil @water. ")}) {
+		t.Error("expected unchanged diagnostics not to be published")
+	}
+	if !p.Publish(messages.PublishDiagnosticsParams{URI: "file:///b.cook", Diagnostics: diagnostics}) {
+		t.Error("expected diagnostics for another document to be published")
+	}
+	if !p.Publish(messages.PublishDiagnosticsParams{URI: "file:///a.cook", Diagnostics: []messages.Diagnostic{}}) {
+		t.Error("expected cleared diagnostics to be published")
+	}
+	if len(sent) != 3 {
+		t.Errorf("expected 3 publishes, got %d", len(sent))
+	}
+}
+
+func TestPublisherRefreshesAfterSave(t *testing.T) {
+	var sent []messages.PublishDiagnosticsParams
+	p := NewPublisher(func(params messages.PublishDiagnosticsParams) {
+		sent = append(sent, params)
+	})
+	publish := func(version int, text string) bool {
+		return p.Publish(messages.PublishDiagnosticsParams{URI: "file:///a.cook", Version: &version, Diagnostics: Whitespace(text)})
+	}
+
+	// The user adds trailing whitespace, removes it, then undoes the removal.
+	// Some clients clear the diagnostics of the document in between, so the
+	// diagnostics of the undo are missing from the client until it's saved.
+	publish(1, "Boil @water. ")
+	publish(2, "Boil @water.")
+	if !publish(3, "Boil @water. ") {
+		t.Fatal("expected changed diagnostics to be published")
+	}
+	if publish(4, "Boil @water. ") {
+		t.Fatal("expected unchanged diagnostics not to be published")
+	}
+	p.Refresh("file:///a.cook")
+	if !publish(4, "Boil @water. ") {
+		t.Error("expected unchanged diagnostics to be published after a refresh")
+	}
+	if publish(4, "Boil @water. ") {
+		t.Error("expected the refresh to apply to one publish")
+	}
+	if len(sent) != 4 {
+		t.Errorf("expected 4 publishes, got %d", len(sent))
+	}
+}
+
+func TestPublisherRefreshIfIdle(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	p := NewPublisher(func(params messages.PublishDiagnosticsParams) {})
+	p.now = func() time.Time { return now }
+	params := messages.PublishDiagnosticsParams{URI: "file:///a.cook", Diagnostics: Whitespace("Boil @water. ")}
+
+	if p.RefreshIfIdle("file:///a.cook", time.Minute) {
+		t.Error("expected documents that have never been published not to be refreshed")
+	}
+	p.Publish(params)
+	now = now.Add(30 * time.Second)
+	if p.RefreshIfIdle("file:///a.cook", time.Minute) {
+		t.Error("expected a document published within the idle period not to be refreshed")
+	}
+	if p.Publish(params) {
+		t.Error("expected unchanged diagnostics not to be published within the idle period")
+	}
+	now = now.Add(time.Minute)
+	if p.RefreshIfIdle("file:///a.cook", 0) {
+		t.Error("expected an idle period of zero to never refresh")
+	}
+	if !p.RefreshIfIdle("file:///a.cook", time.Minute) {
+		t.Error("expected a document that hasn't been published within the idle period to be refreshed")
+	}
+	if !p.Publish(params) {
+		t.Error("expected unchanged diagnostics to be published after the idle period")
+	}
+}
@@ -0,0 +1,41 @@
+package analyzers
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+func TestAnalyzeIgnoresByteOrderMark(t *testing.T) {
+	analyze := func(name string) []messages.Diagnostic {
+		t.Helper()
+		text, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		// Documents are analyzed as they are held in the store.
+		store := documents.NewStore()
+		store.Set(messages.TextDocumentItem{URI: "file:///recipe.cook", Text: string(text)})
+		doc, _ := store.Get("file:///recipe.cook")
+		return Analyze(doc, settings.Settings{Style: ptr(true)}.Snapshot(), DuplicateStepsAnalyzer, UndeclaredTimersAnalyzer, WhitespaceAnalyzer)
+	}
+	expected := analyze("testdata/bom/without-bom.cook")
+	actual := analyze("testdata/bom/with-bom.cook")
+	if len(expected) == 0 {
+		t.Fatal("expected the fixture to produce diagnostics")
+	}
+	var line0 bool
+	for _, d := range expected {
+		line0 = line0 || d.Range.Start.Line == 0
+	}
+	if !line0 {
+		t.Error("expected the fixture to produce diagnostics on the first line")
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected identical diagnostics with and without a BOM\nwithout: %#v\nwith: %#v", expected, actual)
+	}
+}
@@ -0,0 +1,35 @@
+package analyzers
+
+import "github.com/a-h/examplelsp/messages"
+
+// ForClient returns a copy of the params without the properties that the
+// client doesn't support. Tags that aren't in the client's value set are
+// removed, as are related information, code descriptions and the document
+// version, unless the client declares support for them.
+func ForClient(params messages.PublishDiagnosticsParams, c *messages.PublishDiagnosticsClientCapabilities) messages.PublishDiagnosticsParams {
+	if c == nil {
+		c = &messages.PublishDiagnosticsClientCapabilities{}
+	}
+	if !c.VersionSupport {
+		params.Version = nil
+	}
+	diagnostics := make([]messages.Diagnostic, len(params.Diagnostics))
+	for i, d := range params.Diagnostics {
+		var tags []messages.DiagnosticTag
+		for _, tag := range d.Tags {
+			if c.SupportsTag(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		d.Tags = tags
+		if !c.RelatedInformation {
+			d.RelatedInformation = nil
+		}
+		if !c.CodeDescriptionSupport {
+			d.CodeDescription = nil
+		}
+		diagnostics[i] = d
+	}
+	params.Diagnostics = diagnostics
+	return params
+}
@@ -0,0 +1,126 @@
+package analyzers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestForClient(t *testing.T) {
+	version := 3
+	params := messages.PublishDiagnosticsParams{
+		URI:     "file:///recipe.cook",
+		Version: &version,
+		Diagnostics: []messages.Diagnostic{
+			{
+				Message: "Trailing whitespace.",
+				Tags:    []messages.DiagnosticTag{messages.DiagnosticTagUnnecessary, messages.DiagnosticTagDeprecated},
+				RelatedInformation: []messages.DiagnosticRelatedInformation{
+					{Message: "First added here."},
+				},
+				CodeDescription: &messages.CodeDescription{HREF: "https://cooklang.org/docs/spec/"},
+			},
+		},
+	}
+	t.Run("clients that don't declare support only receive the diagnostic", func(t *testing.T) {
+		actual := ForClient(params, nil)
+		if actual.Version != nil {
+			t.Errorf("expected no version, got %d", *actual.Version)
+		}
+		d := actual.Diagnostics[0]
+		if d.Tags != nil || d.RelatedInformation != nil || d.CodeDescription != nil {
+			t.Errorf("expected unsupported properties to be removed, got %#v", d)
+		}
+		if d.Message != "Trailing whitespace." {
+			t.Errorf("expected the message to be kept, got %q", d.Message)
+		}
+	})
+	t.Run("supported properties are kept", func(t *testing.T) {
+		var c messages.PublishDiagnosticsClientCapabilities
+		if err := json.Unmarshal([]byte(`{"relatedInformation":true,"versionSupport":true,"codeDescriptionSupport":true,"tagSupport":{"valueSet":[1]}}`), &c); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		actual := ForClient(params, &c)
+		if actual.Version == nil || *actual.Version != version {
+			t.Errorf("expected version %d, got %v", version, actual.Version)
+		}
+		d := actual.Diagnostics[0]
+		if len(d.Tags) != 1 || d.Tags[0] != messages.DiagnosticTagUnnecessary {
+			t.Errorf("expected only the unnecessary tag, got %v", d.Tags)
+		}
+		if len(d.RelatedInformation) != 1 || d.CodeDescription == nil {
+			t.Errorf("expected related information and the code description to be kept, got %#v", d)
+		}
+	})
+	t.Run("the original diagnostics aren't changed", func(t *testing.T) {
+		ForClient(params, nil)
+		if len(params.Diagnostics[0].Tags) != 2 {
+			t.Errorf("expected the original tags to be kept, got %v", params.Diagnostics[0].Tags)
+		}
+	})
+}
+
+func TestForClientTags(t *testing.T) {
+	params := messages.PublishDiagnosticsParams{
+		URI: "file:///recipe.cook",
+		Diagnostics: []messages.Diagnostic{
+			{Message: "Cups are a silly measurement, consider grams", Tags: []messages.DiagnosticTag{messages.DiagnosticTagDeprecated}},
+			{Message: "Step is empty", Tags: []messages.DiagnosticTag{messages.DiagnosticTagUnnecessary}},
+		},
+	}
+	tests := []struct {
+		name         string
+		capabilities string
+		expected     string
+	}{
+		{
+			name:         "clients without tag support receive no tags",
+			capabilities: `{}`,
+			expected:     `[[],[]]`,
+		},
+		{
+			name:         "clients receive the tags in their value set",
+			capabilities: `{"tagSupport":{"valueSet":[1]}}`,
+			expected:     `[[],[1]]`,
+		},
+		{
+			name:         "clients that support both tags receive both",
+			capabilities: `{"tagSupport":{"valueSet":[1,2]}}`,
+			expected:     `[[2],[1]]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var c messages.PublishDiagnosticsClientCapabilities
+			if err := json.Unmarshal([]byte(test.capabilities), &c); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			data, err := json.Marshal(ForClient(params, &c))
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			// Tags are left out of the JSON, rather than sent as null or
+			// an empty array.
+			var sent struct {
+				Diagnostics []map[string]json.RawMessage `json:"diagnostics"`
+			}
+			if err := json.Unmarshal(data, &sent); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			var tags [][]messages.DiagnosticTag
+			for _, d := range sent.Diagnostics {
+				dTags := []messages.DiagnosticTag{}
+				if raw, ok := d["tags"]; ok {
+					if err := json.Unmarshal(raw, &dTags); err != nil || len(dTags) == 0 {
+						t.Errorf("expected tags to be left out or non-empty, got %s", raw)
+					}
+				}
+				tags = append(tags, dTags)
+			}
+			if actual, _ := json.Marshal(tags); string(actual) != test.expected {
+				t.Errorf("expected tags %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
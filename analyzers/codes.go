@@ -0,0 +1,148 @@
+package analyzers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+// CodesDocumentationURL documents the diagnostic codes, with an anchor named
+// after each code.
+const CodesDocumentationURL = "https://github.com/a-h/examplelsp/blob/main/docs/diagnostics.md"
+
+var (
+	// ErrInvalidCode is returned when registering a code that isn't
+	// lowercase words separated by hyphens, or that's already registered.
+	ErrInvalidCode = errors.New("analyzers: invalid diagnostic code")
+	// ErrUnknownCode is returned when a check declares a code that isn't
+	// registered.
+	ErrUnknownCode = errors.New("analyzers: unknown diagnostic code")
+)
+
+var codeRegexp = regexp.MustCompile(`^[a-z]+(-[a-z]+)*$`)
+
+// CodeRegistry holds the diagnostic codes that checks can produce. Codes are
+// stable, so that users can filter checks in their editor, and each one is
+// documented at CodesDocumentationURL.
+type CodeRegistry struct {
+	lock  sync.RWMutex
+	codes map[string]struct{}
+}
+
+// NewCodeRegistry creates an empty registry.
+func NewCodeRegistry() *CodeRegistry {
+	return &CodeRegistry{codes: make(map[string]struct{})}
+}
+
+// Codes is the registry of the codes produced by the checks in this package,
+// and by the checks of the server.
+var Codes = mustRegister(NewCodeRegistry(),
+	CodeDuplicateStep,
+	CodeNoSteps,
+	CodeNoIngredients,
+	CodeNoServings,
+	CodeEmptyStep,
+	CodeLinkCycle,
+	CodeUndeclaredTimer,
+	CodeMissingUnit,
+	CodeUnknownUnit,
+	CodeIncompatibleUnits,
+	CodeMisspelledIngredient,
+	CodeFahrenheit,
+	CodeTrailingWhitespace,
+	CodeTabIndentation,
+)
+
+// mustRegister registers the codes of the checks in this package.
+func mustRegister(r *CodeRegistry, codes ...string) *CodeRegistry {
+	for _, code := range codes {
+		if err := r.Register(code); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register a code.
+func (r *CodeRegistry) Register(code string) error {
+	if !codeRegexp.MatchString(code) {
+		return fmt.Errorf("%w: %q", ErrInvalidCode, code)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.codes[code]; ok {
+		return fmt.Errorf("%w: %q is already registered", ErrInvalidCode, code)
+	}
+	r.codes[code] = struct{}{}
+	return nil
+}
+
+// Codes returns the registered codes in alphabetical order.
+func (r *CodeRegistry) Codes() (codes []string) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for code := range r.codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Description returns the link to the documentation of a registered code.
+func (r *CodeRegistry) Description(code string) (*messages.CodeDescription, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if _, ok := r.codes[code]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCode, code)
+	}
+	return &messages.CodeDescription{HREF: CodesDocumentationURL + "#" + code}, nil
+}
+
+// Declare the codes that an analyzer produces. The returned analyzer links
+// its diagnostics to the documentation of their code, unless they already
+// have a code description. It returns ErrUnknownCode if any of the codes
+// aren't registered.
+func (r *CodeRegistry) Declare(a Analyzer, codes ...string) (Analyzer, error) {
+	declared := make(map[string]*messages.CodeDescription, len(codes))
+	for _, code := range codes {
+		description, err := r.Description(code)
+		if err != nil {
+			return nil, err
+		}
+		declared[code] = description
+	}
+	return described{Analyzer: a, codes: declared}, nil
+}
+
+// mustDeclare declares the codes of the analyzers in this package, which are
+// registered in Codes.
+func mustDeclare(a Analyzer, codes ...string) Analyzer {
+	a, err := Codes.Declare(a, codes...)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+type described struct {
+	Analyzer
+	codes map[string]*messages.CodeDescription
+}
+
+func (d described) Analyze(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+	diagnostics := d.Analyzer.Analyze(doc, s)
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Code == nil || diagnostic.CodeDescription != nil {
+			continue
+		}
+		if description, ok := d.codes[*diagnostic.Code]; ok {
+			diagnostics[i].CodeDescription = &messages.CodeDescription{HREF: description.HREF}
+		}
+	}
+	return diagnostics
+}
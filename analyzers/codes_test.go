@@ -0,0 +1,154 @@
+package analyzers
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+func TestDefaultsEmitDocumentedCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer Analyzer
+		text     string
+		expected string
+	}{
+		{
+			name:     "duplicate steps",
+			analyzer: DuplicateStepsAnalyzer,
+			text:     "Boil @water{1%l}.\n\nBoil @water{1%l}.",
+			expected: CodeDuplicateStep,
+		},
+		{
+			name:     "undeclared timers",
+			analyzer: UndeclaredTimersAnalyzer,
+			text:     "Boil @water{1%l} until the ~kettle{} is done.",
+			expected: CodeUndeclaredTimer,
+		},
+		{
+			name:     "no steps",
+			analyzer: EmptyRecipeAnalyzer,
+			text:     "-- This recipe only has comments in it, and no steps at all.",
+			expected: CodeNoSteps,
+		},
+		{
+			name:     "no ingredients",
+			analyzer: EmptyRecipeAnalyzer,
+			text:     "Boil the water in a large pan for ten minutes.",
+			expected: CodeNoIngredients,
+		},
+		{
+			name:     "no servings",
+			analyzer: NoServingsAnalyzer,
+			text:     "Boil @water{1%l}.",
+			expected: CodeNoServings,
+		},
+		{
+			name:     "empty steps",
+			analyzer: EmptyStepsAnalyzer,
+			text:     "Boil @water{1%l}.\n\n.",
+			expected: CodeEmptyStep,
+		},
+		{
+			name:     "trailing whitespace",
+			analyzer: WhitespaceAnalyzer,
+			text:     "Boil @water{1%l}.  ",
+			expected: CodeTrailingWhitespace,
+		},
+		{
+			name:     "tab indentation",
+			analyzer: WhitespaceAnalyzer,
+			text:     "\tBoil @water{1%l}.",
+			expected: CodeTabIndentation,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := messages.TextDocumentItem{URI: "file:///recipe.cook", Text: test.text}
+			diagnostics := test.analyzer.Analyze(doc, settings.Settings{Style: ptr(true)}.Snapshot())
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected one diagnostic, got %#v", diagnostics)
+			}
+			d := diagnostics[0]
+			if d.Code == nil || *d.Code != test.expected {
+				t.Fatalf("expected code %q, got %v", test.expected, d.Code)
+			}
+			if d.CodeDescription == nil || d.CodeDescription.HREF == "" {
+				t.Errorf("expected a code description, got %v", d.CodeDescription)
+			}
+		})
+	}
+}
+
+func TestCodeDescriptionLinksToAnchor(t *testing.T) {
+	doc := messages.TextDocumentItem{Text: "Boil @water{1%l}.  "}
+	diagnostics := WhitespaceAnalyzer.Analyze(doc, settings.Settings{Style: ptr(true)}.Snapshot())
+	expected := CodesDocumentationURL + "#" + CodeTrailingWhitespace
+	if len(diagnostics) != 1 || diagnostics[0].CodeDescription == nil || diagnostics[0].CodeDescription.HREF != expected {
+		t.Errorf("expected a link to %q, got %#v", expected, diagnostics)
+	}
+}
+
+func TestCodeRegistry(t *testing.T) {
+	r := NewCodeRegistry()
+	if err := r.Register("parse-error"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, code := range []string{"parse-error", "", "Parse-Error", "parse error", "-parse", "parse-"} {
+		if err := r.Register(code); !errors.Is(err, ErrInvalidCode) {
+			t.Errorf("%q: expected ErrInvalidCode, got %v", code, err)
+		}
+	}
+	if err := r.Register("american-measurement"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"american-measurement", "parse-error"}; !reflect.DeepEqual(r.Codes(), expected) {
+		t.Errorf("expected codes %v, got %v", expected, r.Codes())
+	}
+
+	check := AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+		return []messages.Diagnostic{{Code: ptr("parse-error")}, {Code: ptr("unregistered")}, {}}
+	})
+	if _, err := r.Declare(check, "parse-error", "unregistered"); !errors.Is(err, ErrUnknownCode) {
+		t.Errorf("expected unknown codes to be rejected, got %v", err)
+	}
+	declared, err := r.Declare(check, "parse-error")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	diagnostics := declared.Analyze(messages.TextDocumentItem{}, settings.Settings{}.Snapshot())
+	if d := diagnostics[0]; d.CodeDescription == nil || d.CodeDescription.HREF != CodesDocumentationURL+"#parse-error" {
+		t.Errorf("expected a link to the documentation, got %v", d.CodeDescription)
+	}
+	if diagnostics[1].CodeDescription != nil || diagnostics[2].CodeDescription != nil {
+		t.Errorf("expected undeclared codes not to be described, got %#v", diagnostics[1:])
+	}
+	if declared.Cost() != CostCheap {
+		t.Errorf("expected the cost to be kept, got %v", declared.Cost())
+	}
+}
+
+func TestCodesAreRegistered(t *testing.T) {
+	for _, code := range []string{CodeDuplicateStep, CodeNoSteps, CodeNoIngredients, CodeEmptyStep, CodeLinkCycle, CodeUndeclaredTimer, CodeTrailingWhitespace, CodeTabIndentation} {
+		if _, err := Codes.Description(code); err != nil {
+			t.Errorf("%q: unexpected error: %v", code, err)
+		}
+	}
+}
+
+func TestCodesAreDocumented(t *testing.T) {
+	docs, err := os.ReadFile("../docs/diagnostics.md")
+	if err != nil {
+		t.Fatalf("failed to read the documentation: %v", err)
+	}
+	for _, code := range Codes.Codes() {
+		if !strings.Contains(string(docs), "\n## "+code+"\n") {
+			t.Errorf("expected %q to have a section in the documentation", code)
+		}
+	}
+}
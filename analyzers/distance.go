@@ -0,0 +1,39 @@
+package analyzers
+
+// editDistance returns the number of insertions, deletions, substitutions and
+// swaps of adjacent characters needed to turn a into b, where a character is
+// only edited once. Swaps count as one edit, because they're common typos.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	beforePrevious := make([]int, len(br)+1)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+	return previous[len(br)]
+}
+
+func min(values ...int) (m int) {
+	m = values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
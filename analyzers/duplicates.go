@@ -0,0 +1,80 @@
+package analyzers
+
+import (
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const CodeDuplicateStep = "duplicate-step"
+
+// DuplicateSteps finds steps that are identical to the step before them,
+// usually caused by a copy and paste error.
+func DuplicateSteps(uri, text string) (diagnostics []messages.Diagnostic) {
+	steps := recipe.Parse(text).Steps
+	for i := 1; i < len(steps); i++ {
+		if steps[i].Normalized != steps[i-1].Normalized {
+			continue
+		}
+		diagnostics = append(diagnostics, messages.Diagnostic{
+			Range:    steps[i].Range,
+			Severity: ptr(messages.DiagnosticSeverityWarning),
+			Code:     ptr(CodeDuplicateStep),
+			Source:   ptr(SourceDuplicates),
+			Message:  "Step is a duplicate of the previous step",
+			RelatedInformation: []messages.DiagnosticRelatedInformation{
+				{
+					Location: messages.Location{URI: uri, Range: steps[i-1].Range},
+					Message:  "Previous step",
+				},
+			},
+		})
+	}
+	return
+}
+
+// DuplicateStepFix returns the edit that removes a duplicate step reported by
+// DuplicateSteps. The text is parsed again so that the edit is only returned
+// if the step is still a duplicate.
+func DuplicateStepFix(text string, d messages.Diagnostic) (title string, edit messages.TextEdit, ok bool) {
+	if d.Code == nil || *d.Code != CodeDuplicateStep {
+		return
+	}
+	steps := recipe.Parse(text).Steps
+	for i := 1; i < len(steps); i++ {
+		if steps[i].Range != d.Range || steps[i].Normalized != steps[i-1].Normalized {
+			continue
+		}
+		return "Remove duplicate step", messages.TextEdit{Range: duplicateStepDeletionRange(text, steps[i-1], steps[i])}, true
+	}
+	return
+}
+
+// duplicateStepDeletionRange returns the range to delete to remove the
+// duplicate step, along with the blank lines that separate it from the next
+// line of content. If there's nothing after the duplicate, the blank lines
+// before it are removed instead, so that the previous step ends the document.
+func duplicateStepDeletionRange(text string, previous, duplicate recipe.Step) messages.Range {
+	lines := strings.Split(text, "\n")
+	for lineIndex := duplicate.Range.End.Line + 1; lineIndex < len(lines); lineIndex++ {
+		if strings.TrimSpace(lines[lineIndex]) != "" {
+			return messages.Range{
+				Start: messages.NewPosition(duplicate.Range.Start.Line, 0),
+				End:   messages.NewPosition(lineIndex, 0),
+			}
+		}
+	}
+	start := previous.Range.End
+	// Keep any lines between the steps that aren't blank, such as comments.
+	for lineIndex := duplicate.Range.Start.Line - 1; lineIndex > previous.Range.End.Line; lineIndex-- {
+		if line := strings.TrimSuffix(lines[lineIndex], "\r"); strings.TrimSpace(line) != "" {
+			start = messages.NewPosition(lineIndex, utf16Len(line))
+			break
+		}
+	}
+	return messages.Range{
+		Start: start,
+		End:   duplicate.Range.End,
+	}
+}
@@ -0,0 +1,133 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestDuplicateSteps(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		expectedLines []int
+	}{
+		{
+			name:          "different steps are not flagged",
+			text:          "Add the @salt.\n\nAdd the @pepper.",
+			expectedLines: nil,
+		},
+		{
+			name:          "the second of two identical steps is flagged",
+			text:          "Add the @salt.\n\nAdd the @salt{}.",
+			expectedLines: []int{2},
+		},
+		{
+			name:          "identical steps that aren't consecutive are not flagged",
+			text:          "Add the @salt.\n\nStir.\n\nAdd the @salt.",
+			expectedLines: nil,
+		},
+		{
+			name:          "each repeat is flagged",
+			text:          "Stir.\n\nStir.\n\nStir.",
+			expectedLines: []int{2, 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := DuplicateSteps("file:///pizza.cook", test.text)
+			if len(diagnostics) != len(test.expectedLines) {
+				t.Fatalf("expected %d diagnostics, got %d: %#v", len(test.expectedLines), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Range.Start.Line != test.expectedLines[i] {
+					t.Errorf("diagnostic %d: expected line %d, got %d", i, test.expectedLines[i], d.Range.Start.Line)
+				}
+				if len(d.RelatedInformation) != 1 {
+					t.Fatalf("diagnostic %d: expected related information", i)
+				}
+				if related := d.RelatedInformation[0].Location.Range.Start.Line; related != test.expectedLines[i]-2 {
+					t.Errorf("diagnostic %d: expected related information on line %d, got %d", i, test.expectedLines[i]-2, related)
+				}
+			}
+		})
+	}
+}
+
+func TestDuplicateStepFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "blank lines after the duplicate are removed",
+			text:     "Add the @salt.\n\nAdd the @salt.\n\nStir.\n",
+			expected: "Add the @salt.\n\nStir.\n",
+		},
+		{
+			name:     "multi-line duplicates are removed",
+			text:     "Add the @salt.\nStir.\n\n\nAdd the @salt.\n  Stir.\n\nServe.",
+			expected: "Add the @salt.\nStir.\n\n\nServe.",
+		},
+		{
+			name:     "blank lines before a duplicate at the end of the document are removed",
+			text:     "Add the @salt.\n\nAdd the @salt.\n",
+			expected: "Add the @salt.\n",
+		},
+		{
+			name:     "a duplicate at the end of a document without a final newline is removed",
+			text:     "Add the @salt.\n\n\nAdd the @salt.",
+			expected: "Add the @salt.",
+		},
+		{
+			name:     "comments between the steps are kept",
+			text:     "Add the @salt.\n-- Season well.\nAdd the @salt.\n",
+			expected: "Add the @salt.\n-- Season well.\n",
+		},
+		{
+			name:     "metadata after the duplicate is kept",
+			text:     "Add the @salt.\n\nAdd the @salt.\n\n>> servings: 2\n",
+			expected: "Add the @salt.\n\n>> servings: 2\n",
+		},
+		{
+			name:     "the next step is kept when it directly follows the duplicate",
+			text:     "Stir.\n\nStir.\n-- Then.\nServe.",
+			expected: "Stir.\n\n-- Then.\nServe.",
+		},
+		{
+			name:     "carriage returns are handled",
+			text:     "Add the @salt.\r\n\r\nAdd the @salt.\r\n\r\nStir.\r\n",
+			expected: "Add the @salt.\r\n\r\nStir.\r\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := DuplicateSteps("file:///pizza.cook", test.text)
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+			}
+			_, edit, ok := DuplicateStepFix(test.text, diagnostics[0])
+			if !ok {
+				t.Fatalf("expected a fix")
+			}
+			if actual := applyEdits(test.text, []messages.TextEdit{edit}); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDuplicateStepFixIsNotReturnedForStaleDiagnostics(t *testing.T) {
+	text := "Add the @salt.\n\nAdd the @salt.\n\nStir."
+	diagnostics := DuplicateSteps("file:///pizza.cook", text)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diagnostics))
+	}
+	updated := "Add the @salt.\n\nAdd the @pepper.\n\nStir."
+	if _, _, ok := DuplicateStepFix(updated, diagnostics[0]); ok {
+		t.Error("expected no fix, because the step is no longer a duplicate")
+	}
+}
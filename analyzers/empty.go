@@ -0,0 +1,130 @@
+package analyzers
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const (
+	CodeNoSteps       = "no-steps"
+	CodeNoIngredients = "no-ingredients"
+	CodeEmptyStep     = "empty-step"
+)
+
+// SyntaxDocumentationURL is the cooklang syntax reference, linked from the
+// diagnostics of EmptyRecipe.
+const SyntaxDocumentationURL = "https://cooklang.org/docs/spec/"
+
+// minEmptyRecipeLength is the number of characters, excluding whitespace,
+// that a document needs before EmptyRecipe reports it. Shorter documents
+// are usually recipes that are still being started.
+const minEmptyRecipeLength = 20
+
+// ignoreDirective is written in a comment to turn off checks for the whole
+// file, e.g. "-- examplelsp:ignore no-ingredients".
+const ignoreDirective = "examplelsp:ignore"
+
+// EmptyRecipe finds documents that contain text, but no steps or no
+// ingredients, which usually means that the author forgot the cooklang
+// markup. A document with no steps is only reported as having no steps.
+func EmptyRecipe(text string) (diagnostics []messages.Diagnostic) {
+	if len(strings.Join(strings.Fields(text), "")) < minEmptyRecipeLength {
+		return nil
+	}
+	ignored := ignoredCodes(text)
+	r := recipe.Parse(text)
+	code, message := CodeNoSteps, "No steps found, is this a cooklang recipe?"
+	if len(r.Steps) > 0 {
+		for _, step := range r.Steps {
+			if len(step.Ingredients) > 0 {
+				return nil
+			}
+		}
+		code, message = CodeNoIngredients, "No ingredients marked with @, is this a cooklang recipe?"
+	}
+	if ignored[code] {
+		return nil
+	}
+	return []messages.Diagnostic{
+		{
+			Range:           firstContentLine(text),
+			Severity:        ptr(messages.DiagnosticSeverityInformation),
+			Code:            ptr(code),
+			CodeDescription: &messages.CodeDescription{HREF: SyntaxDocumentationURL},
+			Source:          ptr(SourceStructure),
+			Message:         message,
+		},
+	}
+}
+
+// EmptySteps finds steps without any words or elements, such as a line with
+// a stray full stop. They're tagged as unnecessary, so that editors fade them
+// out.
+func EmptySteps(text string) (diagnostics []messages.Diagnostic) {
+	if ignoredCodes(text)[CodeEmptyStep] {
+		return nil
+	}
+	for _, step := range recipe.Parse(text).Steps {
+		if len(step.Ingredients) > 0 || len(step.Cookware) > 0 || len(step.Timers) > 0 {
+			continue
+		}
+		if strings.IndexFunc(step.Text, isLetterOrDigit) >= 0 {
+			continue
+		}
+		diagnostics = append(diagnostics, messages.Diagnostic{
+			Range:    step.Range,
+			Severity: ptr(messages.DiagnosticSeverityHint),
+			Code:     ptr(CodeEmptyStep),
+			Source:   ptr(SourceStructure),
+			Message:  "Step is empty",
+			Tags:     []messages.DiagnosticTag{messages.DiagnosticTagUnnecessary},
+		})
+	}
+	return
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ignoredCodes returns the codes listed in ignore directives in the text.
+func ignoredCodes(text string) (codes map[string]bool) {
+	codes = make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		_, directive, ok := strings.Cut(line, "--")
+		if !ok {
+			continue
+		}
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, ignoreDirective) {
+			continue
+		}
+		for _, code := range strings.FieldsFunc(strings.TrimPrefix(directive, ignoreDirective), isCodeSeparator) {
+			codes[code] = true
+		}
+	}
+	return codes
+}
+
+func isCodeSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\r'
+}
+
+// firstContentLine returns the range of the first line that isn't blank or
+// metadata, or the start of the document if there isn't one.
+func firstContentLine(text string) messages.Range {
+	for lineIndex, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, ">>") {
+			continue
+		}
+		return messages.Range{
+			Start: messages.NewPosition(lineIndex, 0),
+			End:   messages.NewPosition(lineIndex, utf16Len(line)),
+		}
+	}
+	return messages.Range{}
+}
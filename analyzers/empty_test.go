@@ -0,0 +1,129 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestEmptyRecipe(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+		line     int
+	}{
+		{
+			name:     "recipes with ingredients are not reported",
+			text:     ">> servings: 2\n\nBoil the @water in a #pan for ~{5%minutes}.",
+			expected: nil,
+		},
+		{
+			name:     "short documents are not reported",
+			text:     "Boil the water.",
+			expected: nil,
+		},
+		{
+			name:     "prose without markup has no ingredients",
+			text:     ">> servings: 2\n\nBoil the water in a pan for five minutes.",
+			expected: []string{CodeNoIngredients},
+			line:     2,
+		},
+		{
+			name:     "cookware alone is not an ingredient",
+			text:     "Put the kettle on, and warm the #teapot{}.",
+			expected: []string{CodeNoIngredients},
+		},
+		{
+			name:     "metadata and comments alone have no steps",
+			text:     ">> servings: 2\n>> source: my grandmother\n-- to be written",
+			expected: []string{CodeNoSteps},
+			line:     2,
+		},
+		{
+			name:     "the check can be ignored for the file",
+			text:     "-- examplelsp:ignore no-ingredients\nBoil the water in a pan for five minutes.",
+			expected: nil,
+		},
+		{
+			name:     "ignoring one code doesn't ignore others",
+			text:     "-- examplelsp:ignore no-ingredients\n>> servings: 2\n>> source: my grandmother",
+			expected: []string{CodeNoSteps},
+		},
+		{
+			name:     "several codes can be ignored",
+			text:     "-- examplelsp:ignore no-ingredients, no-steps\n>> servings: 2\n>> source: my grandmother",
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := EmptyRecipe(test.text)
+			if len(diagnostics) != len(test.expected) {
+				t.Fatalf("expected %d diagnostics, got %d: %#v", len(test.expected), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if *d.Code != test.expected[i] {
+					t.Errorf("expected code %q, got %q", test.expected[i], *d.Code)
+				}
+				if *d.Severity != messages.DiagnosticSeverityInformation {
+					t.Errorf("expected information severity, got %v", *d.Severity)
+				}
+				if d.CodeDescription == nil || d.CodeDescription.HREF != SyntaxDocumentationURL {
+					t.Errorf("expected a link to the syntax documentation, got %#v", d.CodeDescription)
+				}
+				if d.Range.Start.Line != test.line {
+					t.Errorf("expected the diagnostic on line %d, got %d", test.line, d.Range.Start.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptySteps(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		expectedLines []int
+	}{
+		{
+			name: "steps with words are not reported",
+			text: "Boil the water.\n\nServe.",
+		},
+		{
+			name: "steps with only elements are not reported",
+			text: "@salt\n\n#pan{}",
+		},
+		{
+			name:          "stray punctuation is an empty step",
+			text:          "Boil @water{1%l}.\n\n.\n\nServe.",
+			expectedLines: []int{2},
+		},
+		{
+			name:          "comments are not counted as words",
+			text:          "Boil @water{1%l}.\n\n- [- to do -]",
+			expectedLines: []int{2},
+		},
+		{
+			name: "the check can be ignored for the file",
+			text: "-- examplelsp:ignore empty-step\nBoil @water{1%l}.\n\n.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := EmptySteps(test.text)
+			if len(diagnostics) != len(test.expectedLines) {
+				t.Fatalf("expected %d diagnostics, got %#v", len(test.expectedLines), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Range.Start.Line != test.expectedLines[i] {
+					t.Errorf("diagnostic %d: expected line %d, got %d", i, test.expectedLines[i], d.Range.Start.Line)
+				}
+				if len(d.Tags) != 1 || d.Tags[0] != messages.DiagnosticTagUnnecessary {
+					t.Errorf("diagnostic %d: expected the unnecessary tag, got %v", i, d.Tags)
+				}
+			}
+		})
+	}
+}
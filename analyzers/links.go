@@ -0,0 +1,38 @@
+package analyzers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/workspace"
+)
+
+const CodeLinkCycle = "link-cycle"
+
+// LinkCycles reports the base links of the document at uri that lead back to
+// a recipe that's already in the chain, including links to the recipe itself.
+func LinkCycles(uri string, cycles []workspace.Cycle) (diagnostics []messages.Diagnostic) {
+	for _, cycle := range cycles {
+		if cycle.Link.From != uri {
+			continue
+		}
+		names := make([]string, len(cycle.Chain))
+		for i, u := range cycle.Chain {
+			names[i] = path.Base(u)
+		}
+		message := fmt.Sprintf("Recipe links form a cycle: %s", strings.Join(names, " → "))
+		if cycle.Link.From == cycle.Link.To {
+			message = "Recipe links to itself"
+		}
+		diagnostics = append(diagnostics, messages.Diagnostic{
+			Range:    cycle.Link.Range,
+			Severity: ptr(messages.DiagnosticSeverityWarning),
+			Code:     ptr(CodeLinkCycle),
+			Source:   ptr(SourceLinks),
+			Message:  message,
+		})
+	}
+	return
+}
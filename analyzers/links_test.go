@@ -0,0 +1,52 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/workspace"
+)
+
+func TestLinkCycles(t *testing.T) {
+	tests := []struct {
+		name             string
+		uri              string
+		expectedMessages []string
+	}{
+		{
+			name:             "links to the recipe itself are reported",
+			uri:              "file:///recipes/a.cook",
+			expectedMessages: []string{"Recipe links to itself"},
+		},
+		{
+			name:             "cycles are reported on the link that closes them",
+			uri:              "file:///recipes/c.cook",
+			expectedMessages: []string{"Recipe links form a cycle: b.cook → c.cook → b.cook"},
+		},
+		{
+			name:             "cycles are not reported on other recipes in the chain",
+			uri:              "file:///recipes/b.cook",
+			expectedMessages: nil,
+		},
+	}
+	cycles := workspace.NewGraph(map[string]string{
+		"file:///recipes/a.cook": ">> base: a",
+		"file:///recipes/b.cook": ">> base: c",
+		"file:///recipes/c.cook": ">> base: b",
+	}).Cycles()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := LinkCycles(test.uri, cycles)
+			if len(diagnostics) != len(test.expectedMessages) {
+				t.Fatalf("expected %d diagnostics, got %d: %#v", len(test.expectedMessages), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Message != test.expectedMessages[i] {
+					t.Errorf("expected message %q, got %q", test.expectedMessages[i], d.Message)
+				}
+				if *d.Code != CodeLinkCycle {
+					t.Errorf("expected code %q, got %q", CodeLinkCycle, *d.Code)
+				}
+			}
+		})
+	}
+}
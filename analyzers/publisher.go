@@ -0,0 +1,86 @@
+package analyzers
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// Publisher sends diagnostics to the client, skipping diagnostics that are
+// the same as the last ones sent for the document, so that publishing the
+// results of each analysis phase doesn't make the client redraw unchanged
+// diagnostics.
+//
+// Some clients clear diagnostics without telling the server, so the skipped
+// diagnostics would be missing from the client. Refresh and RefreshIfIdle
+// make the next publish for a document send the diagnostics regardless.
+type Publisher struct {
+	publish func(params messages.PublishDiagnosticsParams)
+	now     func() time.Time
+	lock    sync.Mutex
+	last    map[string]sent
+}
+
+// sent is the last set of diagnostics sent for a document.
+type sent struct {
+	diagnostics []messages.Diagnostic
+	at          time.Time
+}
+
+// NewPublisher creates a Publisher that sends diagnostics with publish.
+func NewPublisher(publish func(params messages.PublishDiagnosticsParams)) *Publisher {
+	return &Publisher{
+		publish: publish,
+		now:     time.Now,
+		last:    make(map[string]sent),
+	}
+}
+
+// Publish the diagnostics if they've changed since the last publish for the
+// document. It returns false if the diagnostics weren't sent.
+func (p *Publisher) Publish(params messages.PublishDiagnosticsParams) (published bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if last, ok := p.last[params.URI]; ok && reflect.DeepEqual(last.diagnostics, params.Diagnostics) {
+		return false
+	}
+	p.last[params.URI] = sent{diagnostics: params.Diagnostics, at: p.now()}
+	p.publish(params)
+	return true
+}
+
+// Refresh makes the next publish for the document send the diagnostics, even
+// if they haven't changed.
+func (p *Publisher) Refresh(uri string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.last, uri)
+}
+
+// Clear the diagnostics of a document by sending an empty set, e.g. when it's
+// closed, and forget the diagnostics last sent for it.
+func (p *Publisher) Clear(uri string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.last, uri)
+	p.publish(messages.PublishDiagnosticsParams{URI: uri, Diagnostics: []messages.Diagnostic{}})
+}
+
+// RefreshIfIdle refreshes the document if diagnostics haven't been sent for it
+// within the idle period. An idle period of zero never refreshes. It returns
+// true if the document was refreshed.
+func (p *Publisher) RefreshIfIdle(uri string, idle time.Duration) (refreshed bool) {
+	if idle <= 0 {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	last, ok := p.last[uri]
+	if !ok || p.now().Sub(last.at) < idle {
+		return false
+	}
+	delete(p.last, uri)
+	return true
+}
@@ -0,0 +1,53 @@
+package analyzers
+
+import (
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const CodeNoServings = "no-servings"
+
+// NoServings finds recipes without servings metadata, which can't be scaled.
+// The diagnostic is on the first metadata line, or on the first line if
+// there's no metadata. Documents that are empty, or only contain comments,
+// aren't reported.
+func NoServings(text string) (diagnostics []messages.Diagnostic) {
+	if !hasContent(text) || ignoredCodes(text)[CodeNoServings] {
+		return nil
+	}
+	metadata := recipe.ParseMetadata(text)
+	for _, m := range metadata {
+		if strings.EqualFold(m.Key, "servings") {
+			return nil
+		}
+	}
+	var rng messages.Range
+	if len(metadata) > 0 {
+		rng = metadata[0].Range
+	} else {
+		line, _, _ := strings.Cut(text, "\n")
+		rng.End = messages.NewPosition(0, utf16Len(strings.TrimSuffix(line, "\r")))
+	}
+	return []messages.Diagnostic{
+		{
+			Range:    rng,
+			Severity: ptr(messages.DiagnosticSeverityHint),
+			Code:     ptr(CodeNoServings),
+			Source:   ptr(SourceStructure),
+			Message:  `No servings, add ">> servings: 2" so that the recipe can be scaled`,
+		},
+	}
+}
+
+// hasContent returns true if the text contains anything other than
+// whitespace and comments.
+func hasContent(text string) bool {
+	for _, line := range recipe.MaskedLines(text) {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
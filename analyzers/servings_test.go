@@ -0,0 +1,63 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestNoServings(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected *messages.Range
+	}{
+		{
+			name:     "no metadata",
+			text:     "Boil @water{1%l}.\n\nAdd @salt{1%tsp}.",
+			expected: &messages.Range{End: messages.NewPosition(0, 17)},
+		},
+		{
+			name:     "other metadata",
+			text:     "Boil @water{1%l}.\n\n>> source: grandma\n>> time: 10 minutes",
+			expected: &messages.Range{Start: messages.NewPosition(2, 0), End: messages.NewPosition(2, 18)},
+		},
+		{
+			name: "servings",
+			text: ">> Servings: 4\n\nBoil @water{1%l}.",
+		},
+		{
+			name: "empty",
+			text: " \n\n",
+		},
+		{
+			name: "only comments",
+			text: "-- TODO: write up soup\n[- the one from\nthe market -]",
+		},
+		{
+			name: "ignored",
+			text: "-- examplelsp:ignore no-servings\nBoil @water{1%l}.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := NoServings(test.text)
+			if test.expected == nil {
+				if len(diagnostics) != 0 {
+					t.Fatalf("expected no diagnostics, got %#v", diagnostics)
+				}
+				return
+			}
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected one diagnostic, got %#v", diagnostics)
+			}
+			d := diagnostics[0]
+			if d.Range != *test.expected {
+				t.Errorf("expected range %v, got %v", *test.expected, d.Range)
+			}
+			if *d.Code != CodeNoServings || *d.Severity != messages.DiagnosticSeverityHint {
+				t.Errorf("unexpected diagnostic: %#v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package analyzers
+
+import "github.com/a-h/examplelsp/messages"
+
+// Source of all diagnostics when sources are flattened.
+const Source = "examplelsp"
+
+// Sources of diagnostics, one per analyzer, so that users can filter them in
+// editors. Code actions match diagnostics by code, not by source.
+const (
+	SourceWhitespace   = Source + ".whitespace"
+	SourceDuplicates   = Source + ".duplicates"
+	SourceTimers       = Source + ".timers"
+	SourceStructure    = Source + ".structure"
+	SourceLinks        = Source + ".links"
+	SourceUnits        = Source + ".units"
+	SourceTemperatures = Source + ".temperatures"
+	SourceSpelling     = Source + ".spelling"
+)
+
+// FlattenSources sets the source of each diagnostic to Source, for clients
+// that don't handle many sources well.
+func FlattenSources(diagnostics []messages.Diagnostic) {
+	for i := range diagnostics {
+		diagnostics[i].Source = ptr(Source)
+	}
+}
@@ -0,0 +1,76 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyze  func(text string) []messages.Diagnostic
+		text     string
+		expected string
+	}{
+		{
+			name:     "whitespace",
+			analyze:  Whitespace,
+			text:     "Boil @water. \n\tServe.",
+			expected: SourceWhitespace,
+		},
+		{
+			name: "duplicates",
+			analyze: func(text string) []messages.Diagnostic {
+				return DuplicateSteps("file:///recipe.cook", text)
+			},
+			text:     "Boil @water.\n\nBoil @water.",
+			expected: SourceDuplicates,
+		},
+		{
+			name:     "timers",
+			analyze:  UndeclaredTimers,
+			text:     "When the ~marinade{} is done, drain.",
+			expected: SourceTimers,
+		},
+		{
+			name:     "temperatures",
+			analyze:  Fahrenheit,
+			text:     "Preheat the oven to 350F.",
+			expected: SourceTemperatures,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := test.analyze(test.text)
+			if len(diagnostics) == 0 {
+				t.Fatal("expected diagnostics")
+			}
+			for _, d := range diagnostics {
+				if d.Source == nil || *d.Source != test.expected {
+					t.Errorf("expected source %q, got %v", test.expected, d.Source)
+				}
+			}
+			FlattenSources(diagnostics)
+			for _, d := range diagnostics {
+				if d.Source == nil || *d.Source != Source {
+					t.Errorf("expected flattened source %q, got %v", Source, d.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestFixesMatchFlattenedDiagnostics(t *testing.T) {
+	text := "Boil @water. \n\nBoil @water. "
+	whitespace := Whitespace(text)
+	duplicates := DuplicateSteps("file:///recipe.cook", text)
+	FlattenSources(whitespace)
+	FlattenSources(duplicates)
+	if _, _, ok := WhitespaceFix(whitespace[0]); !ok {
+		t.Error("expected a whitespace fix for a diagnostic with a flattened source")
+	}
+	if _, _, ok := DuplicateStepFix(text, duplicates[0]); !ok {
+		t.Error("expected a duplicate step fix for a diagnostic with a flattened source")
+	}
+}
@@ -0,0 +1,163 @@
+package analyzers
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const CodeMisspelledIngredient = "misspelled-ingredient"
+
+// ingredientWords is the dictionary of the words in ingredient names, one
+// lowercase word per line. Plurals are only listed if they're irregular.
+//
+//go:embed ingredients.txt
+var ingredientWords string
+
+var dictionary = func() map[string]bool {
+	words := strings.Fields(ingredientWords)
+	dictionary := make(map[string]bool, len(words))
+	for _, word := range words {
+		dictionary[word] = true
+	}
+	return dictionary
+}()
+
+// minSpellcheckLength is the number of letters that a word needs to be
+// spellchecked. Shorter words are usually abbreviations.
+const minSpellcheckLength = 3
+
+// maxSpellingSuggestions is the number of suggestions in the message.
+const maxSpellingSuggestions = 3
+
+// MisspelledIngredients finds the words of ingredient names that aren't in
+// the dictionary, such as @onoin{1}, and suggests the closest words in the
+// message. Words that are used more than once in the document aren't
+// reported, because they're probably spelled the way the author intended.
+func MisspelledIngredients(text string) (diagnostics []messages.Diagnostic) {
+	if ignoredCodes(text)[CodeMisspelledIngredient] {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isNotLetter) {
+		counts[word]++
+	}
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			for _, w := range nameWords(ingredient.Name) {
+				word := strings.ToLower(ingredient.Name[w.start:w.end])
+				if utf8.RuneCountInString(word) < minSpellcheckLength || inDictionary(word) || counts[word] > 1 {
+					continue
+				}
+				message := fmt.Sprintf("'%s' isn't a known ingredient", ingredient.Name[w.start:w.end])
+				if suggestions := spellingSuggestions(word); len(suggestions) > 0 {
+					message += ", did you mean " + quotedList(suggestions) + "?"
+				}
+				start := ingredient.NameRange.Start
+				diagnostics = append(diagnostics, messages.Diagnostic{
+					Range: messages.Range{
+						Start: messages.NewPosition(start.Line, start.Character+utf16Len(ingredient.Name[:w.start])),
+						End:   messages.NewPosition(start.Line, start.Character+utf16Len(ingredient.Name[:w.end])),
+					},
+					Severity: ptr(messages.DiagnosticSeverityHint),
+					Code:     ptr(CodeMisspelledIngredient),
+					Source:   ptr(SourceSpelling),
+					Message:  message,
+				})
+			}
+		}
+	}
+	return
+}
+
+type span struct {
+	start, end int
+}
+
+// nameWords returns the byte offsets of the words in an ingredient name,
+// which are runs of letters.
+func nameWords(name string) (words []span) {
+	start := -1
+	for i, r := range name {
+		if unicode.IsLetter(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, span{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, span{start: start, end: len(name)})
+	}
+	return words
+}
+
+func isNotLetter(r rune) bool {
+	return !unicode.IsLetter(r)
+}
+
+// inDictionary returns true if the lowercase word, or its singular, is in the
+// dictionary.
+func inDictionary(word string) bool {
+	if dictionary[word] {
+		return true
+	}
+	if strings.HasSuffix(word, "ies") && dictionary[strings.TrimSuffix(word, "ies")+"y"] {
+		return true
+	}
+	if strings.HasSuffix(word, "es") && dictionary[strings.TrimSuffix(word, "es")] {
+		return true
+	}
+	return strings.HasSuffix(word, "s") && dictionary[strings.TrimSuffix(word, "s")]
+}
+
+// spellingSuggestions returns the words in the dictionary that are the
+// fewest edits away from the word, closest first. Words more than 2 edits
+// away, or that would need every character changed, aren't suggested.
+func spellingSuggestions(word string) (suggestions []string) {
+	length := utf8.RuneCountInString(word)
+	distances := map[string]int{}
+	for candidate := range dictionary {
+		// The distance is at least the difference in length.
+		if diff := utf8.RuneCountInString(candidate) - length; diff > 2 || diff < -2 {
+			continue
+		}
+		if d := editDistance(word, candidate); d <= 2 && d < length {
+			distances[candidate] = d
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	if len(suggestions) > maxSpellingSuggestions {
+		suggestions = suggestions[:maxSpellingSuggestions]
+	}
+	return suggestions
+}
+
+// quotedList returns the values in quotes, separated by commas, with "or"
+// before the last one, e.g. 'a', 'b' or 'c'.
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
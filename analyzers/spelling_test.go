@@ -0,0 +1,99 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+)
+
+func TestMisspelledIngredients(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []messages.Diagnostic
+	}{
+		{
+			name: "misspelled",
+			text: "Fry the @onoin{1} in @olive oil{1%tbsp}.",
+			expected: []messages.Diagnostic{
+				{
+					Range:   messages.Range{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 14)},
+					Message: "'onoin' isn't a known ingredient, did you mean 'onion', 'loin' or 'onions'?",
+				},
+			},
+		},
+		{
+			name: "multiple words are checked word by word",
+			text: "Add the @red lentlis{200%g} and @chopped tomatoes{400%g}.",
+			expected: []messages.Diagnostic{
+				{
+					Range:   messages.Range{Start: messages.NewPosition(0, 13), End: messages.NewPosition(0, 20)},
+					Message: "'lentlis' isn't a known ingredient, did you mean 'lentils' or 'lentil'?",
+				},
+			},
+		},
+		{
+			name: "plurals",
+			text: "Add the @cherries{200%g}, @peaches{2} and @leaves{3}.",
+		},
+		{
+			name: "exotic ingredient",
+			text: "Grate the @bottarga{20%g} over the pasta.",
+			expected: []messages.Diagnostic{
+				{
+					Range:   messages.Range{Start: messages.NewPosition(0, 11), End: messages.NewPosition(0, 19)},
+					Message: "'bottarga' isn't a known ingredient",
+				},
+			},
+		},
+		{
+			name: "words used more than once",
+			text: "Grate the @bottarga{20%g} over the pasta, and serve with more bottarga.",
+		},
+		{
+			name: "ignored",
+			text: "-- examplelsp:ignore misspelled-ingredient\nFry the @onoin{1}.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := MisspelledIngredients(test.text)
+			if len(diagnostics) != len(test.expected) {
+				t.Fatalf("expected %d diagnostics, got %#v", len(test.expected), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Range != test.expected[i].Range {
+					t.Errorf("expected range %v, got %v", test.expected[i].Range, d.Range)
+				}
+				if d.Message != test.expected[i].Message {
+					t.Errorf("expected message %q, got %q", test.expected[i].Message, d.Message)
+				}
+				if *d.Code != CodeMisspelledIngredient || *d.Severity != messages.DiagnosticSeverityHint {
+					t.Errorf("unexpected diagnostic: %#v", d)
+				}
+			}
+		})
+	}
+}
+
+func TestMisspelledIngredientsSetting(t *testing.T) {
+	doc := messages.TextDocumentItem{URI: "file:///recipe.cook", Text: "Grate the @bottarga{20%g} over the pasta."}
+	if diagnostics := Analyze(doc, settings.Settings{}.Snapshot(), MisspelledIngredientsAnalyzer); len(diagnostics) != 1 {
+		t.Errorf("expected the spellcheck to be enabled by default, got %#v", diagnostics)
+	}
+	disabled := settings.Settings{Spellcheck: ptr(false)}.Snapshot()
+	if diagnostics := Analyze(doc, disabled, MisspelledIngredientsAnalyzer); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics when the spellcheck is disabled, got %#v", diagnostics)
+	}
+}
+
+func TestSpellingSuggestions(t *testing.T) {
+	if d := editDistance("onoin", "onion"); d != 1 {
+		t.Errorf("expected swapped letters to be one edit, got %d", d)
+	}
+	suggestions := spellingSuggestions("cumon")
+	if len(suggestions) == 0 || len(suggestions) > maxSpellingSuggestions || suggestions[0] != "cumin" {
+		t.Errorf("expected up to %d suggestions, starting with cumin, got %v", maxSpellingSuggestions, suggestions)
+	}
+}
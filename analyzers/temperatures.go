@@ -0,0 +1,55 @@
+package analyzers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const CodeFahrenheit = "fahrenheit"
+
+// fahrenheitRegexp matches temperatures in Fahrenheit, such as 350F, 350 °F
+// and 350 degrees fahrenheit.
+var fahrenheitRegexp = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s*(?:°\s*|degrees?\s+)?(?:fahrenheit|f)\b`)
+
+// TemperatureData is the data of the diagnostics of Fahrenheit, which a code
+// action can use to replace the temperature.
+type TemperatureData struct {
+	// Replacement is the temperature in Celsius, e.g. "175°C".
+	Replacement string `json:"replacement"`
+}
+
+// Fahrenheit finds temperatures in Fahrenheit, and reports their value in
+// Celsius, rounded to the nearest 5°C, as ovens are marked. Comments are
+// ignored.
+func Fahrenheit(text string) (diagnostics []messages.Diagnostic) {
+	for lineIndex, line := range recipe.MaskedLines(text) {
+		for _, match := range fahrenheitRegexp.FindAllStringSubmatchIndex(line, -1) {
+			fahrenheit, err := strconv.ParseFloat(line[match[2]:match[3]], 64)
+			if err != nil {
+				continue
+			}
+			celsius := math.Round((fahrenheit-32)*5/9/5) * 5
+			data := TemperatureData{Replacement: fmt.Sprintf("%s°C", strconv.FormatFloat(celsius, 'f', -1, 64))}
+			d := messages.Diagnostic{
+				Range: messages.Range{
+					Start: messages.NewPosition(lineIndex, utf16Len(line[:match[0]])),
+					End:   messages.NewPosition(lineIndex, utf16Len(line[:match[1]])),
+				},
+				Severity: ptr(messages.DiagnosticSeverityInformation),
+				Code:     ptr(CodeFahrenheit),
+				Source:   ptr(SourceTemperatures),
+				Message:  fmt.Sprintf("%s°F ≈ %s", line[match[2]:match[3]], data.Replacement),
+			}
+			if err := d.SetData(data); err != nil {
+				continue
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return
+}
@@ -0,0 +1,73 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestFahrenheit(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		expected    []messages.Range
+		messages    []string
+		replacement string
+	}{
+		{
+			name:        "number followed by F",
+			text:        "Preheat the oven to 350F.",
+			expected:    []messages.Range{{Start: messages.NewPosition(0, 20), End: messages.NewPosition(0, 24)}},
+			messages:    []string{"350°F ≈ 175°C"},
+			replacement: "175°C",
+		},
+		{
+			name:        "degree sign",
+			text:        "Bake at 425 °f for ~{20%minutes}.",
+			expected:    []messages.Range{{Start: messages.NewPosition(0, 8), End: messages.NewPosition(0, 14)}},
+			messages:    []string{"425°F ≈ 220°C"},
+			replacement: "220°C",
+		},
+		{
+			name:        "degrees fahrenheit",
+			text:        "Heat the #oil{} to\n375 Degrees Fahrenheit.",
+			expected:    []messages.Range{{Start: messages.NewPosition(1, 0), End: messages.NewPosition(1, 22)}},
+			messages:    []string{"375°F ≈ 190°C"},
+			replacement: "190°C",
+		},
+		{
+			name: "numbers that aren't temperatures",
+			text: "Repeat step 350 for the @flour{350%g}, in 3 batches of fish.",
+		},
+		{
+			name: "comments",
+			text: "Preheat the oven. -- 350F in our oven\n[- or 400 °F -]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := Fahrenheit(test.text)
+			if len(diagnostics) != len(test.expected) {
+				t.Fatalf("expected %d diagnostics, got %#v", len(test.expected), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Range != test.expected[i] {
+					t.Errorf("expected range %v, got %v", test.expected[i], d.Range)
+				}
+				if d.Message != test.messages[i] {
+					t.Errorf("expected message %q, got %q", test.messages[i], d.Message)
+				}
+				if *d.Code != CodeFahrenheit || *d.Severity != messages.DiagnosticSeverityInformation {
+					t.Errorf("unexpected diagnostic: %#v", d)
+				}
+				var data TemperatureData
+				if ok, err := d.GetData(&data); !ok || err != nil {
+					t.Fatalf("expected data, got %v, %v", ok, err)
+				}
+				if data.Replacement != test.replacement {
+					t.Errorf("expected replacement %q, got %q", test.replacement, data.Replacement)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package analyzers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const CodeUndeclaredTimer = "undeclared-timer"
+
+// maxTimerNameDistance is the maximum edit distance between an undeclared
+// timer name and a declared timer name for the declared name to be suggested.
+const maxTimerNameDistance = 2
+
+// UndeclaredTimers finds named timers without a duration that refer to a
+// timer that isn't started anywhere in the recipe, which is usually caused by
+// a typo in the name.
+func UndeclaredTimers(text string) (diagnostics []messages.Diagnostic) {
+	r := recipe.Parse(text)
+	declared := r.TimerNames()
+	for _, step := range r.Steps {
+		for _, timer := range step.Timers {
+			if timer.Name == "" || timer.Quantity != "" {
+				continue
+			}
+			if _, ok := declared[strings.ToLower(timer.Name)]; ok {
+				continue
+			}
+			message := fmt.Sprintf("Timer %q is not started in any step", timer.Name)
+			if suggestion, ok := closest(timer.Name, declared); ok {
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range:    timer.Range,
+				Severity: ptr(messages.DiagnosticSeverityHint),
+				Code:     ptr(CodeUndeclaredTimer),
+				Source:   ptr(SourceTimers),
+				Message:  message,
+			})
+		}
+	}
+	return
+}
+
+func closest(name string, declared map[string]recipe.TimerDeclaration) (suggestion string, ok bool) {
+	best := maxTimerNameDistance + 1
+	for key, d := range declared {
+		if distance := editDistance(strings.ToLower(name), key); distance < best || (distance == best && d.Timer.Name < suggestion) {
+			best, suggestion = distance, d.Timer.Name
+		}
+	}
+	return suggestion, best <= maxTimerNameDistance
+}
@@ -0,0 +1,66 @@
+package analyzers
+
+import (
+	"testing"
+)
+
+func TestUndeclaredTimers(t *testing.T) {
+	tests := []struct {
+		name             string
+		text             string
+		expectedMessages []string
+	}{
+		{
+			name:             "unnamed timers are ignored",
+			text:             "Bake for ~{10%minutes}.",
+			expectedMessages: nil,
+		},
+		{
+			name:             "declared timers are not flagged",
+			text:             "Leave to ~marinade{2%hours}.\n\nWhen the ~Marinade{} is done, drain.",
+			expectedMessages: nil,
+		},
+		{
+			name:             "misspelled references include a suggestion",
+			text:             "Leave to ~marinade{2%hours}.\n\nWhen the ~marinde{} is done, drain.",
+			expectedMessages: []string{`Timer "marinde" is not started in any step, did you mean "marinade"?`},
+		},
+		{
+			name:             "references without a similar declaration have no suggestion",
+			text:             "Leave to ~marinade{2%hours}.\n\nWhen the ~proving{} is done, bake.",
+			expectedMessages: []string{`Timer "proving" is not started in any step`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := UndeclaredTimers(test.text)
+			if len(diagnostics) != len(test.expectedMessages) {
+				t.Fatalf("expected %d diagnostics, got %d: %#v", len(test.expectedMessages), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Message != test.expectedMessages[i] {
+					t.Errorf("expected message %q, got %q", test.expectedMessages[i], d.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "marinade", b: "marinade", expected: 0},
+		{a: "marinade", b: "marinde", expected: 1},
+		{a: "kitten", b: "sitting", expected: 3},
+		{a: "crème", b: "creme", expected: 1},
+	}
+	for _, test := range tests {
+		if actual := editDistance(test.a, test.b); actual != test.expected {
+			t.Errorf("%q, %q: expected %d, got %d", test.a, test.b, test.expected, actual)
+		}
+	}
+}
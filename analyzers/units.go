@@ -0,0 +1,231 @@
+package analyzers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+const (
+	CodeMissingUnit       = "missing-unit"
+	CodeUnknownUnit       = "unknown-unit"
+	CodeIncompatibleUnits = "incompatible-units"
+)
+
+// MissingUnitSuggestions are the units offered by AddUnitFix.
+var MissingUnitSuggestions = []string{"g", "ml", "tsp"}
+
+// MissingUnits finds ingredients that have a numeric quantity, but no unit,
+// such as @salt{1}, which could mean 1 g or 1 tsp. Ingredients without a
+// quantity aren't reported.
+func MissingUnits(text string) (diagnostics []messages.Diagnostic) {
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			if !missingUnit(ingredient) {
+				continue
+			}
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range:    ingredient.Range,
+				Severity: ptr(messages.DiagnosticSeverityHint),
+				Code:     ptr(CodeMissingUnit),
+				Source:   ptr(SourceUnits),
+				Message:  fmt.Sprintf("The quantity of %q has no unit", ingredient.Name),
+			})
+		}
+	}
+	return
+}
+
+func missingUnit(ingredient recipe.Ingredient) bool {
+	if ingredient.Unit != "" {
+		return false
+	}
+	_, numeric := recipe.ParseQuantity(ingredient.Quantity)
+	return numeric
+}
+
+// AddUnitFix returns the edit that adds the unit to the quantity of an
+// ingredient reported by MissingUnits. The text is parsed again, so that the
+// edit is only returned if the ingredient is still at the range of the
+// diagnostic, and still has no unit.
+func AddUnitFix(text string, d messages.Diagnostic, unit string) (title string, edit messages.TextEdit, ok bool) {
+	if d.Code == nil || *d.Code != CodeMissingUnit {
+		return
+	}
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			if ingredient.Range != d.Range || !missingUnit(ingredient) {
+				continue
+			}
+			// The braces after the name are replaced, which also tidies up
+			// an empty unit, such as @salt{1%}.
+			edit = messages.TextEdit{
+				Range:   messages.Range{Start: ingredient.NameRange.End, End: ingredient.Range.End},
+				NewText: "{" + ingredient.Quantity + "%" + unit + "}",
+			}
+			return "Add unit: " + unit, edit, true
+		}
+	}
+	return
+}
+
+// FreeFormUnits are units that aren't in recipe.IngredientUnits, but aren't
+// reported by UnknownUnits, because they're commonly used, e.g. "a handful".
+// Their plurals are also allowed.
+var FreeFormUnits = []string{"handful", "dash", "splash", "drizzle", "sprig", "bunch", "knob", "can", "tin", "jar", "packet", "piece", "sheet", "stick", "cube", "leaf", "leaves", "large", "medium", "small", "whole"}
+
+// UnknownUnits finds the units of ingredient quantities that aren't known,
+// which are usually typos, such as @flour{100%grms}. If a known unit is
+// close, it's suggested in the message. parseErr is the error from parsing
+// the text with the cooklang parser. If it's not nil, nothing is reported, so
+// that units aren't reported while they're typed.
+func UnknownUnits(text string, parseErr error) (diagnostics []messages.Diagnostic) {
+	if parseErr != nil {
+		return nil
+	}
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			if ingredient.Unit == "" || knownUnit(ingredient.Unit) {
+				continue
+			}
+			message := fmt.Sprintf("Unknown unit '%s'", ingredient.Unit)
+			if suggestion, ok := closestUnit(ingredient.Unit); ok {
+				message += fmt.Sprintf(", did you mean '%s'?", suggestion)
+			}
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range:    ingredient.UnitRange,
+				Severity: ptr(messages.DiagnosticSeverityWarning),
+				Code:     ptr(CodeUnknownUnit),
+				Source:   ptr(SourceUnits),
+				Message:  message,
+			})
+		}
+	}
+	return
+}
+
+func knownUnit(unit string) bool {
+	if _, ok := recipe.LookupUnit(recipe.IngredientUnits, unit); ok {
+		return true
+	}
+	unit = strings.ToLower(unit)
+	for _, u := range FreeFormUnits {
+		if unit == u || unit == u+"s" || unit == u+"es" {
+			return true
+		}
+	}
+	return false
+}
+
+// closestUnit returns the name of the known unit that's the fewest edits away
+// from the unit, by its name or aliases. Units more than 2 edits away, or
+// that would need every character changed, aren't close.
+func closestUnit(unit string) (name string, ok bool) {
+	unit = strings.ToLower(unit)
+	best := 3
+	for _, u := range recipe.IngredientUnits {
+		for _, n := range u.Names() {
+			if d := editDistance(unit, n); d < best && d < len([]rune(unit)) {
+				best, name, ok = d, u.Name, true
+			}
+		}
+	}
+	return name, ok
+}
+
+// dimensionUnitless is the dimension of quantities without a unit, such as
+// @eggs{2}.
+const dimensionUnitless recipe.Dimension = "unitless"
+
+var dimensionDescriptions = map[recipe.Dimension]string{
+	recipe.DimensionMass:   "a mass",
+	recipe.DimensionVolume: "a volume",
+	recipe.DimensionCount:  "a count",
+	dimensionUnitless:      "no unit",
+}
+
+type occurrence struct {
+	ingredient recipe.Ingredient
+	dimension  recipe.Dimension
+}
+
+// IncompatibleUnits finds ingredients that are used with units of different
+// dimensions, such as @milk{200%ml} and @milk{1%lb}, which can't be added up
+// for a shopping list. Ingredients are matched by name, ignoring case. Each
+// conflicting use is reported, with the uses of other dimensions as related
+// information. Uses without a quantity, and uses with a unit that isn't
+// known, are ignored.
+func IncompatibleUnits(uri, text string) (diagnostics []messages.Diagnostic) {
+	var names []string
+	byName := map[string][]occurrence{}
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			dimension, ok := ingredientDimension(ingredient)
+			if !ok {
+				continue
+			}
+			name := strings.ToLower(ingredient.Name)
+			if _, ok := byName[name]; !ok {
+				names = append(names, name)
+			}
+			byName[name] = append(byName[name], occurrence{ingredient: ingredient, dimension: dimension})
+		}
+	}
+	for _, name := range names {
+		occurrences := byName[name]
+		dimensions := map[recipe.Dimension]bool{}
+		for _, o := range occurrences {
+			dimensions[o.dimension] = true
+		}
+		if len(dimensions) < 2 {
+			continue
+		}
+		var described []string
+		for d := range dimensions {
+			described = append(described, dimensionDescriptions[d])
+		}
+		sort.Strings(described)
+		message := fmt.Sprintf("'%s' is used with %s, which can't be added up", occurrences[0].ingredient.Name, strings.Join(described, " and "))
+		for _, o := range occurrences {
+			d := messages.Diagnostic{
+				Range:    o.ingredient.Range,
+				Severity: ptr(messages.DiagnosticSeverityWarning),
+				Code:     ptr(CodeIncompatibleUnits),
+				Source:   ptr(SourceUnits),
+				Message:  message,
+			}
+			for _, other := range occurrences {
+				if other.dimension == o.dimension {
+					continue
+				}
+				d.RelatedInformation = append(d.RelatedInformation, messages.DiagnosticRelatedInformation{
+					Location: messages.Location{URI: uri, Range: other.ingredient.Range},
+					Message:  "Used with " + dimensionDescriptions[other.dimension],
+				})
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return
+}
+
+// ingredientDimension returns the dimension of the quantity of the
+// ingredient. Free form units, such as "handful", are counts.
+func ingredientDimension(ingredient recipe.Ingredient) (d recipe.Dimension, ok bool) {
+	if ingredient.Quantity == "" {
+		return d, false
+	}
+	if ingredient.Unit == "" {
+		return dimensionUnitless, true
+	}
+	if u, ok := recipe.LookupUnit(recipe.IngredientUnits, ingredient.Unit); ok {
+		return u.Dimension, true
+	}
+	if knownUnit(ingredient.Unit) {
+		return recipe.DimensionCount, true
+	}
+	return d, false
+}
@@ -0,0 +1,216 @@
+package analyzers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestMissingUnits(t *testing.T) {
+	text := "Add @salt{1}, @sugar{1/2}, @flour{1 1/2%cups} and @eggs{some}.\nSeason with @pepper and @olive oil{2}."
+	var ranges []messages.Range
+	for _, d := range MissingUnits(text) {
+		if *d.Code != CodeMissingUnit || *d.Severity != messages.DiagnosticSeverityHint {
+			t.Errorf("unexpected diagnostic: %#v", d)
+		}
+		ranges = append(ranges, d.Range)
+	}
+	expected := []messages.Range{
+		{Start: messages.NewPosition(0, 4), End: messages.NewPosition(0, 12)},
+		{Start: messages.NewPosition(0, 14), End: messages.NewPosition(0, 25)},
+		{Start: messages.NewPosition(1, 24), End: messages.NewPosition(1, 37)},
+	}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected %v, got %v", expected, ranges)
+	}
+}
+
+func TestAddUnitFix(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		unit     string
+		expected string
+	}{
+		{
+			name:     "whole numbers",
+			text:     "Add @salt{1}.",
+			unit:     "g",
+			expected: "Add @salt{1%g}.",
+		},
+		{
+			name:     "fractions",
+			text:     "Add @sugar{1/2} and stir.",
+			unit:     "tsp",
+			expected: "Add @sugar{1/2%tsp} and stir.",
+		},
+		{
+			name:     "mixed numbers and multi-word names",
+			text:     "Stir.\nAdd 🧂 @olive oil{1 1/2}.",
+			unit:     "ml",
+			expected: "Stir.\nAdd 🧂 @olive oil{1 1/2%ml}.",
+		},
+		{
+			name:     "empty units",
+			text:     "Add @salt{1%}.",
+			unit:     "g",
+			expected: "Add @salt{1%g}.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := MissingUnits(test.text)
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %#v", diagnostics)
+			}
+			title, edit, ok := AddUnitFix(test.text, diagnostics[0], test.unit)
+			if !ok {
+				t.Fatal("expected a fix")
+			}
+			if expected := "Add unit: " + test.unit; title != expected {
+				t.Errorf("expected title %q, got %q", expected, title)
+			}
+			if actual := applyEdit(t, test.text, edit); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+			if remaining := MissingUnits(test.expected); len(remaining) != 0 {
+				t.Errorf("expected the fix to remove the diagnostic, got %#v", remaining)
+			}
+		})
+	}
+}
+
+func TestAddUnitFixOfStaleDiagnostics(t *testing.T) {
+	d := MissingUnits("Add @salt{1}.")[0]
+	for _, text := range []string{"Add @salt{1%g}.", "Add @salt.", "Add the @salt{1}."} {
+		if _, _, ok := AddUnitFix(text, d, "g"); ok {
+			t.Errorf("%q: expected no fix", text)
+		}
+	}
+	if _, _, ok := AddUnitFix("Add @salt{1}.", messages.Diagnostic{Range: d.Range}, "g"); ok {
+		t.Error("expected no fix for a diagnostic without the code")
+	}
+}
+
+func TestUnknownUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		parseErr error
+		expected []messages.Diagnostic
+	}{
+		{
+			name: "typos suggest the closest unit",
+			text: "Mix the @flour{100%grms} with the @milk{ 250 % mls }.",
+			expected: []messages.Diagnostic{
+				{
+					Range:    messages.Range{Start: messages.NewPosition(0, 19), End: messages.NewPosition(0, 23)},
+					Severity: ptr(messages.DiagnosticSeverityWarning),
+					Code:     ptr(CodeUnknownUnit),
+					Source:   ptr(SourceUnits),
+					Message:  "Unknown unit 'grms', did you mean 'g'?",
+				},
+				{
+					Range:    messages.Range{Start: messages.NewPosition(0, 47), End: messages.NewPosition(0, 50)},
+					Severity: ptr(messages.DiagnosticSeverityWarning),
+					Code:     ptr(CodeUnknownUnit),
+					Source:   ptr(SourceUnits),
+					Message:  "Unknown unit 'mls', did you mean 'ml'?",
+				},
+			},
+		},
+		{
+			name: "units that aren't close have no suggestion",
+			text: "Add the @rice{1%bowlful}.",
+			expected: []messages.Diagnostic{
+				{
+					Range:    messages.Range{Start: messages.NewPosition(0, 16), End: messages.NewPosition(0, 23)},
+					Severity: ptr(messages.DiagnosticSeverityWarning),
+					Code:     ptr(CodeUnknownUnit),
+					Source:   ptr(SourceUnits),
+					Message:  "Unknown unit 'bowlful'",
+				},
+			},
+		},
+		{
+			name: "free-form units are allowed",
+			text: "Add a @parsley{1%handful}, @thyme{2%sprigs} and @butter{1%knob}.",
+		},
+		{
+			name: "known units and aliases are allowed",
+			text: "Add the @flour{100%g}, @milk{1%Litre}, @sugar{2%tablespoons} and @salt{1}.",
+		},
+		{
+			name:     "nothing is reported if the recipe can't be parsed",
+			text:     "Mix the @flour{100%grms} with the @milk{",
+			parseErr: errors.New("unclosed brace"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := UnknownUnits(test.text, test.parseErr)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIncompatibleUnits(t *testing.T) {
+	t.Run("units of the same dimension are compatible", func(t *testing.T) {
+		text := "Warm @milk{200%ml} with @salt.\n\nAdd @Milk{1%cup}, @salt{1%tsp} and @eggs{2}.\n\nAdd @eggs{1}."
+		if diagnostics := IncompatibleUnits("file:///recipe.cook", text); len(diagnostics) != 0 {
+			t.Errorf("expected no diagnostics, got %#v", diagnostics)
+		}
+	})
+	t.Run("mass and volume conflict", func(t *testing.T) {
+		text := "Warm @milk{200%ml}.\n\nStir in @sugar{1%tbsp}.\n\nAdd @Milk{1%lb}."
+		diagnostics := IncompatibleUnits("file:///recipe.cook", text)
+		if len(diagnostics) != 2 {
+			t.Fatalf("expected 2 diagnostics, got %#v", diagnostics)
+		}
+		milk := messages.Range{Start: messages.NewPosition(0, 5), End: messages.NewPosition(0, 18)}
+		moreMilk := messages.Range{Start: messages.NewPosition(4, 4), End: messages.NewPosition(4, 15)}
+		expected := []struct {
+			rng     messages.Range
+			related messages.Range
+			message string
+		}{
+			{rng: milk, related: moreMilk, message: "Used with a mass"},
+			{rng: moreMilk, related: milk, message: "Used with a volume"},
+		}
+		for i, d := range diagnostics {
+			if *d.Code != CodeIncompatibleUnits || *d.Severity != messages.DiagnosticSeverityWarning {
+				t.Errorf("unexpected diagnostic: %#v", d)
+			}
+			if d.Message != "'milk' is used with a mass and a volume, which can't be added up" {
+				t.Errorf("unexpected message: %q", d.Message)
+			}
+			if d.Range != expected[i].rng {
+				t.Errorf("expected range %v, got %v", expected[i].rng, d.Range)
+			}
+			if len(d.RelatedInformation) != 1 {
+				t.Fatalf("expected one related location, got %#v", d.RelatedInformation)
+			}
+			related := d.RelatedInformation[0]
+			if related.Location.URI != "file:///recipe.cook" || related.Location.Range != expected[i].related {
+				t.Errorf("expected related location %v, got %v", expected[i].related, related.Location)
+			}
+			if related.Message != expected[i].message {
+				t.Errorf("expected related message %q, got %q", expected[i].message, related.Message)
+			}
+		}
+	})
+	t.Run("unitless quantities conflict with units", func(t *testing.T) {
+		text := "Whisk @eggs{2}, then @eggs{2}.\n\nAdd @eggs{100%g}."
+		diagnostics := IncompatibleUnits("file:///recipe.cook", text)
+		if len(diagnostics) != 3 {
+			t.Fatalf("expected 3 diagnostics, got %#v", diagnostics)
+		}
+		if len(diagnostics[0].RelatedInformation) != 1 || len(diagnostics[2].RelatedInformation) != 2 {
+			t.Errorf("expected related information of the other dimension, got %#v", diagnostics)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+// Package analyzers contains checks that produce diagnostics for recipes.
+package analyzers
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+const (
+	CodeTrailingWhitespace = "trailing-whitespace"
+	CodeTabIndentation     = "tab-indentation"
+)
+
+// tabWidth is the number of spaces that replace each tab used for indentation.
+const tabWidth = 4
+
+// Whitespace finds trailing whitespace, and tabs used for indentation. The
+// ranges of the diagnostics cover only the offending characters.
+func Whitespace(text string) (diagnostics []messages.Diagnostic) {
+	for lineIndex, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		content := strings.TrimRight(line, " \t")
+		if len(content) < len(line) {
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range: messages.Range{
+					Start: messages.NewPosition(lineIndex, utf16Len(content)),
+					End:   messages.NewPosition(lineIndex, utf16Len(line)),
+				},
+				Severity: ptr(messages.DiagnosticSeverityHint),
+				Code:     ptr(CodeTrailingWhitespace),
+				Source:   ptr(SourceWhitespace),
+				Message:  "Trailing whitespace",
+				Tags:     []messages.DiagnosticTag{messages.DiagnosticTagUnnecessary},
+			})
+		}
+		// Whitespace-only lines are already covered by the trailing whitespace check.
+		indent := content[:len(content)-len(strings.TrimLeft(content, " \t"))]
+		for i := 0; i < len(indent); i++ {
+			if indent[i] != '\t' {
+				continue
+			}
+			start := i
+			for i < len(indent) && indent[i] == '\t' {
+				i++
+			}
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range: messages.Range{
+					Start: messages.NewPosition(lineIndex, start),
+					End:   messages.NewPosition(lineIndex, i),
+				},
+				Severity: ptr(messages.DiagnosticSeverityHint),
+				Code:     ptr(CodeTabIndentation),
+				Source:   ptr(SourceWhitespace),
+				Message:  "Tab used for indentation",
+				Tags:     []messages.DiagnosticTag{messages.DiagnosticTagUnnecessary},
+			})
+		}
+	}
+	return
+}
+
+// WhitespaceFix returns a title for the fix, and the edit that fixes a
+// diagnostic produced by Whitespace. ok is false if the diagnostic wasn't
+// produced by Whitespace.
+func WhitespaceFix(d messages.Diagnostic) (title string, edit messages.TextEdit, ok bool) {
+	if d.Code == nil {
+		return
+	}
+	switch *d.Code {
+	case CodeTrailingWhitespace:
+		return "Remove trailing whitespace", messages.TextEdit{Range: d.Range, NewText: ""}, true
+	case CodeTabIndentation:
+		tabs := d.Range.End.Character - d.Range.Start.Character
+		return "Replace tabs with spaces", messages.TextEdit{Range: d.Range, NewText: strings.Repeat(" ", tabs*tabWidth)}, true
+	}
+	return
+}
+
+// WhitespaceFixAll returns the edits required to fix all whitespace problems
+// in the text.
+func WhitespaceFixAll(text string) (edits []messages.TextEdit) {
+	for _, d := range Whitespace(text) {
+		if _, edit, ok := WhitespaceFix(d); ok {
+			edits = append(edits, edit)
+		}
+	}
+	return
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
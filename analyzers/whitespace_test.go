@@ -0,0 +1,132 @@
+package analyzers
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestWhitespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []messages.Range
+		codes    []string
+	}{
+		{
+			name:     "clean text has no diagnostics",
+			text:     "Add the @salt.\n\n    Stir.\n",
+			expected: nil,
+		},
+		{
+			name: "trailing spaces and tabs are flagged",
+			text: "Add the @salt. \t\nStir.",
+			expected: []messages.Range{
+				{Start: messages.NewPosition(0, 14), End: messages.NewPosition(0, 16)},
+			},
+			codes: []string{CodeTrailingWhitespace},
+		},
+		{
+			name: "carriage returns are not whitespace",
+			text: "Add the @salt.  \r\nStir.\r\n",
+			expected: []messages.Range{
+				{Start: messages.NewPosition(0, 14), End: messages.NewPosition(0, 16)},
+			},
+			codes: []string{CodeTrailingWhitespace},
+		},
+		{
+			name: "ranges are measured in UTF-16 code units",
+			text: "Heat to 230°C  ",
+			expected: []messages.Range{
+				{Start: messages.NewPosition(0, 13), End: messages.NewPosition(0, 15)},
+			},
+			codes: []string{CodeTrailingWhitespace},
+		},
+		{
+			name: "whitespace only lines are trailing whitespace",
+			text: "Stir.\n\t \nServe.",
+			expected: []messages.Range{
+				{Start: messages.NewPosition(1, 0), End: messages.NewPosition(1, 2)},
+			},
+			codes: []string{CodeTrailingWhitespace},
+		},
+		{
+			name: "only the tabs in the indentation are flagged",
+			text: "\t\t  \tStir\tthe pot.",
+			expected: []messages.Range{
+				{Start: messages.NewPosition(0, 0), End: messages.NewPosition(0, 2)},
+				{Start: messages.NewPosition(0, 4), End: messages.NewPosition(0, 5)},
+			},
+			codes: []string{CodeTabIndentation, CodeTabIndentation},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := Whitespace(test.text)
+			if len(diagnostics) != len(test.expected) {
+				t.Fatalf("expected %d diagnostics, got %d: %#v", len(test.expected), len(diagnostics), diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Range != test.expected[i] {
+					t.Errorf("diagnostic %d: expected range %v, got %v", i, test.expected[i], d.Range)
+				}
+				if *d.Code != test.codes[i] {
+					t.Errorf("diagnostic %d: expected code %q, got %q", i, test.codes[i], *d.Code)
+				}
+				if len(d.Tags) != 1 || d.Tags[0] != messages.DiagnosticTagUnnecessary {
+					t.Errorf("diagnostic %d: expected the unnecessary tag, got %v", i, d.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestWhitespaceFixAll(t *testing.T) {
+	text := "\tAdd the @salt.  \r\n\r\n  \tStir°.\t\n \n"
+	expected := "    Add the @salt.\r\n\r\n      Stir°.\n\n"
+	actual := applyEdits(text, WhitespaceFixAll(text))
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if d := Whitespace(actual); len(d) != 0 {
+		t.Errorf("expected no diagnostics after fixing, got %v", d)
+	}
+}
+
+// applyEdits applies non-overlapping edits to the text, in the same way as an
+// editor would.
+func applyEdits(text string, edits []messages.TextEdit) string {
+	edits = append([]messages.TextEdit{}, edits...)
+	sort.Slice(edits, func(i, j int) bool {
+		a, b := edits[i].Range.Start, edits[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line > b.Line
+		}
+		return a.Character > b.Character
+	})
+	for _, edit := range edits {
+		start := offset(text, edit.Range.Start)
+		end := offset(text, edit.Range.End)
+		text = text[:start] + edit.NewText + text[end:]
+	}
+	return text
+}
+
+// offset converts a position into a byte offset within the text.
+func offset(text string, p messages.Position) (o int) {
+	for i := 0; i < p.Line; i++ {
+		o += strings.Index(text[o:], "\n") + 1
+	}
+	var character int
+	for i, r := range text[o:] {
+		if character >= p.Character || r == '\n' {
+			return o + i
+		}
+		character += len(utf16.Encode([]rune{r}))
+	}
+	return len(text)
+}
@@ -0,0 +1,84 @@
+package analyzers
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// CensorWordFix returns the edit that replaces all but the first letter of
+// the word in the range of the diagnostic with asterisks. ok is false if the
+// range isn't within a single line of the text.
+func CensorWordFix(text string, d messages.Diagnostic) (title string, edit messages.TextEdit, ok bool) {
+	line, start, end, ok := wordAt(text, d.Range)
+	if !ok {
+		return
+	}
+	word := line[start:end]
+	first, size := utf8.DecodeRuneInString(word)
+	censored := string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	return "Replace with asterisks", messages.TextEdit{Range: d.Range, NewText: censored}, true
+}
+
+// RemoveWordFix returns the edit that deletes the word in the range of the
+// diagnostic. The whitespace after the word is deleted too, or the whitespace
+// before it if the word ends the line or is followed by punctuation, so that
+// no doubled or trailing whitespace is left behind. ok is false if the range
+// isn't within a single line of the text.
+func RemoveWordFix(text string, d messages.Diagnostic) (title string, edit messages.TextEdit, ok bool) {
+	line, start, end, ok := wordAt(text, d.Range)
+	if !ok {
+		return
+	}
+	before := len(strings.TrimRight(line[:start], " \t"))
+	after := len(line) - len(strings.TrimLeft(line[end:], " \t"))
+	switch {
+	case after > end && (before < start || before == 0):
+		// Between spaces, or at the start of the line.
+		end = after
+	case before < start:
+		// Before punctuation, or at the end of the line.
+		start = before
+	}
+	r := messages.Range{
+		Start: messages.NewPosition(d.Range.Start.Line, utf16Len(line[:start])),
+		End:   messages.NewPosition(d.Range.Start.Line, utf16Len(line[:end])),
+	}
+	return "Remove word", messages.TextEdit{Range: r, NewText: ""}, true
+}
+
+// wordAt returns the line that contains the range, and the byte offsets of
+// the range within it.
+func wordAt(text string, r messages.Range) (line string, start, end int, ok bool) {
+	lines := strings.Split(text, "\n")
+	if r.Start.Line != r.End.Line || r.Start.Line < 0 || r.Start.Line >= len(lines) {
+		return
+	}
+	line = strings.TrimSuffix(lines[r.Start.Line], "\r")
+	if start, ok = byteOffset(line, r.Start.Character); !ok {
+		return
+	}
+	if end, ok = byteOffset(line, r.End.Character); !ok || end <= start {
+		return line, 0, 0, false
+	}
+	return line, start, end, true
+}
+
+// byteOffset returns the byte offset of the UTF-16 character offset in the
+// line. ok is false if the offset is beyond the end of the line, or within a
+// surrogate pair.
+func byteOffset(line string, character int) (offset int, ok bool) {
+	var units int
+	for i, r := range line {
+		if units == character {
+			return i, true
+		}
+		if units > character {
+			return 0, false
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line), units == character
+}
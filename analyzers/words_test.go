@@ -0,0 +1,118 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func applyEdit(t *testing.T, text string, edit messages.TextEdit) string {
+	t.Helper()
+	applied, err := documents.ApplyEdits(text, []messages.TextEdit{edit})
+	if err != nil {
+		t.Fatalf("failed to apply %#v: %v", edit, err)
+	}
+	return applied
+}
+
+func wordRange(line, start, end int) messages.Diagnostic {
+	return messages.Diagnostic{
+		Range: messages.Range{Start: messages.NewPosition(line, start), End: messages.NewPosition(line, end)},
+	}
+}
+
+func TestWordFixes(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		d               messages.Diagnostic
+		expectedCensor  string
+		expectedRemoval string
+	}{
+		{
+			name:            "between words",
+			text:            "Add the damn salt.",
+			d:               wordRange(0, 8, 12),
+			expectedCensor:  "Add the d*** salt.",
+			expectedRemoval: "Add the salt.",
+		},
+		{
+			name:            "at the start of a line",
+			text:            "Stir.\nBloody hot, so wait.",
+			d:               wordRange(1, 0, 6),
+			expectedCensor:  "Stir.\nB***** hot, so wait.",
+			expectedRemoval: "Stir.\nhot, so wait.",
+		},
+		{
+			name:            "at the end of a line",
+			text:            "Add the salt, damn\nStir.",
+			d:               wordRange(0, 14, 18),
+			expectedCensor:  "Add the salt, d***\nStir.",
+			expectedRemoval: "Add the salt,\nStir.",
+		},
+		{
+			name:            "before punctuation",
+			text:            "Serve it, you git.",
+			d:               wordRange(0, 14, 17),
+			expectedCensor:  "Serve it, you g**.",
+			expectedRemoval: "Serve it, you.",
+		},
+		{
+			name:            "the whole line",
+			text:            "damn\r\nStir.",
+			d:               wordRange(0, 0, 4),
+			expectedCensor:  "d***\r\nStir.",
+			expectedRemoval: "\r\nStir.",
+		},
+		{
+			name:            "after characters outside of the basic multilingual plane",
+			text:            "Add 🧂 bloody salt.",
+			d:               wordRange(0, 7, 13),
+			expectedCensor:  "Add 🧂 b***** salt.",
+			expectedRemoval: "Add 🧂 salt.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			title, edit, ok := CensorWordFix(test.text, test.d)
+			if !ok || title != "Replace with asterisks" {
+				t.Fatalf("expected a censor fix, got %q, %v", title, ok)
+			}
+			if actual := applyEdit(t, test.text, edit); actual != test.expectedCensor {
+				t.Errorf("censor: expected %q, got %q", test.expectedCensor, actual)
+			}
+			title, edit, ok = RemoveWordFix(test.text, test.d)
+			if !ok || title != "Remove word" {
+				t.Fatalf("expected a removal fix, got %q, %v", title, ok)
+			}
+			actual := applyEdit(t, test.text, edit)
+			if actual != test.expectedRemoval {
+				t.Errorf("remove: expected %q, got %q", test.expectedRemoval, actual)
+			}
+			for _, line := range strings.Split(actual, "\n") {
+				if strings.Contains(line, "  ") || strings.HasSuffix(strings.TrimSuffix(line, "\r"), " ") {
+					t.Errorf("remove: stray whitespace left in %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestWordFixesOutOfRange(t *testing.T) {
+	text := "Add the damn salt.\nStir."
+	for _, d := range []messages.Diagnostic{
+		wordRange(2, 0, 4),
+		wordRange(1, 0, 10),
+		wordRange(0, 8, 8),
+		{Range: messages.Range{Start: messages.NewPosition(0, 8), End: messages.NewPosition(1, 2)}},
+	} {
+		if _, _, ok := CensorWordFix(text, d); ok {
+			t.Errorf("expected no censor fix for %v", d.Range)
+		}
+		if _, _, ok := RemoveWordFix(text, d); ok {
+			t.Errorf("expected no removal fix for %v", d.Range)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboard returns a function that copies text to the system clipboard, or
+// nil if no clipboard program is installed.
+func clipboard() func(text string) error {
+	candidates := [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	}
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		return func(text string) error {
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			return cmd.Run()
+		}
+	}
+	return nil
+}
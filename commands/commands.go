@@ -0,0 +1,205 @@
+// Package commands implements the commands that clients run using
+// workspace/executeCommand.
+//
+// Command results are read by external tools, as well as by clients, so they
+// follow some conventions. Every result embeds a ResultHeader, which has the
+// SchemaVersion of the result, and the encoding of positions in it. Places in
+// documents are always a messages.Location, or a messages.Range alongside a
+// URI, and never ad-hoc line and column fields.
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/workspace"
+)
+
+const (
+	// FixAll fixes all whitespace problems in a document.
+	FixAll = "examplelsp.fixAll"
+	// RenameIngredientEverywhere renames an ingredient in every recipe in the
+	// workspace.
+	RenameIngredientEverywhere = "examplelsp.renameIngredientEverywhere"
+	// CollectLogs gathers the end of the log, the session counters, the
+	// effective settings and the version into a report, for bug reports.
+	CollectLogs = "examplelsp.collectLogs"
+	// OpenDocumentation opens a documentation page in the user's browser.
+	OpenDocumentation = "examplelsp.openDocumentation"
+	// RefreshDiagnostics sends the diagnostics of open documents again, for
+	// when the diagnostics shown by the client look stale.
+	RefreshDiagnostics = "examplelsp.refreshDiagnostics"
+)
+
+// Names of the commands, to advertise in the server capabilities.
+var Names = []string{FixAll, RenameIngredientEverywhere, CollectLogs, OpenDocumentation, RefreshDiagnostics}
+
+// ErrUnknownCommand is returned when the command isn't one of Names.
+var ErrUnknownCommand = errors.New("commands: unknown command")
+
+// EditApplier asks the client to apply an edit, using workspace/applyEdit.
+type EditApplier func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error)
+
+// MessageRequester asks the user to pick one of the actions, using
+// window/showMessageRequest. The action is nil if the user dismissed the
+// message.
+type MessageRequester func(ctx context.Context, params messages.ShowMessageRequestParams) (action *messages.MessageActionItem, err error)
+
+// DocumentShower asks the client to show a document, or to open a URL in an
+// external program, using window/showDocument.
+type DocumentShower func(ctx context.Context, params messages.ShowDocumentParams) (result messages.ShowDocumentResult, err error)
+
+// Commands executes commands against the documents in the store, and the
+// files in the workspace that aren't open.
+type Commands struct {
+	Documents *documents.Store
+	// Workspace is nil if the client didn't open a folder.
+	Workspace          *workspace.Index
+	ApplyEdit          EditApplier
+	ShowMessageRequest MessageRequester
+	ShowDocument       DocumentShower
+	// ClientCapabilities returns the capabilities sent by the client in the
+	// initialize request.
+	ClientCapabilities func() messages.ClientCapabilities
+	// PositionEncoding returns the position encoding negotiated with the
+	// client, which the ranges of edits are converted into before they're
+	// applied. If it's nil, the ranges are sent in UTF-16.
+	PositionEncoding func() messages.PositionEncodingKind
+	// Logs configures the CollectLogs command.
+	Logs LogConfig
+	// Clipboard copies text to the user's clipboard. It's nil if there's no
+	// clipboard.
+	Clipboard func(text string) error
+	// RefreshDiagnostics analyzes an open document again, and publishes its
+	// diagnostics even if they haven't changed.
+	RefreshDiagnostics func(uri string)
+}
+
+// EditArgs are accepted by all commands that edit documents.
+type EditArgs struct {
+	// DryRun returns a PreviewResult of the changes instead of applying
+	// them.
+	DryRun bool `json:"dryRun"`
+}
+
+// FixAllArgs is the argument of the FixAll command.
+type FixAllArgs struct {
+	EditArgs
+	URI string `json:"uri"`
+}
+
+// Execute runs the command. Commands that edit documents return a
+// PreviewResult if a dry run is requested, and nil otherwise.
+func (c *Commands) Execute(ctx context.Context, params messages.ExecuteCommandParams) (result any, err error) {
+	switch params.Command {
+	case FixAll:
+		var args FixAllArgs
+		if err = decodeArgs(params.Arguments, &args); err != nil {
+			return
+		}
+		return c.fixAll(ctx, args)
+	case RenameIngredientEverywhere:
+		var args RenameIngredientEverywhereArgs
+		if err = decodeArgs(params.Arguments, &args); err != nil {
+			return
+		}
+		return c.renameIngredientEverywhere(ctx, args)
+	case CollectLogs:
+		// The argument is optional.
+		var args CollectLogsArgs
+		if len(params.Arguments) > 0 {
+			if err = decodeArgs(params.Arguments, &args); err != nil {
+				return
+			}
+		}
+		return c.collectLogs(ctx, args)
+	case OpenDocumentation:
+		var args OpenDocumentationArgs
+		if err = decodeArgs(params.Arguments, &args); err != nil {
+			return
+		}
+		return c.openDocumentation(ctx, args)
+	case RefreshDiagnostics:
+		// The argument is optional.
+		var args RefreshDiagnosticsArgs
+		if len(params.Arguments) > 0 {
+			if err = decodeArgs(params.Arguments, &args); err != nil {
+				return
+			}
+		}
+		return c.refreshDiagnostics(ctx, args)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownCommand, params.Command)
+}
+
+// decodeArgs decodes the first argument into v.
+func decodeArgs(args []json.RawMessage, v any) error {
+	if len(args) == 0 {
+		return fmt.Errorf("commands: missing argument")
+	}
+	if err := json.Unmarshal(args[0], v); err != nil {
+		return fmt.Errorf("commands: invalid argument: %w", err)
+	}
+	return nil
+}
+
+func (c *Commands) fixAll(ctx context.Context, args FixAllArgs) (result any, err error) {
+	doc, ok := c.Documents.Get(args.URI)
+	if !ok {
+		return nil, fmt.Errorf("commands: %q is not open", args.URI)
+	}
+	edit := messages.WorkspaceEdit{
+		Changes: map[string][]messages.TextEdit{},
+	}
+	if edits := analyzers.WhitespaceFixAll(doc.Text); len(edits) > 0 {
+		edit.Changes[args.URI] = edits
+	}
+	return c.applyOrPreview(ctx, "Fix all whitespace problems", edit, args.EditArgs)
+}
+
+// applyOrPreview asks the client to apply the edit, or returns a preview of it
+// if a dry run is requested.
+func (c *Commands) applyOrPreview(ctx context.Context, label string, edit messages.WorkspaceEdit, args EditArgs) (result any, err error) {
+	if args.DryRun {
+		preview, err := documents.PreviewEdit(edit, c.text)
+		if err != nil {
+			return nil, err
+		}
+		return PreviewResult{ResultHeader: newResultHeader(), Preview: preview}, nil
+	}
+	if len(edit.Changes) == 0 && len(edit.DocumentChanges) == 0 {
+		return nil, nil
+	}
+	if c.PositionEncoding != nil {
+		edit = documents.EncodeWorkspaceEdit(edit, c.text, c.PositionEncoding())
+	}
+	applied, err := c.ApplyEdit(ctx, messages.ApplyWorkspaceEditParams{
+		Label: label,
+		Edit:  edit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("commands: failed to apply edit: %w", err)
+	}
+	if !applied.Applied {
+		return nil, fmt.Errorf("commands: client did not apply edit: %s", applied.FailureReason)
+	}
+	return nil, nil
+}
+
+// text returns the text of an open document, or of the file on disk if it's
+// in the workspace but not open.
+func (c *Commands) text(uri string) (text string, ok bool) {
+	if text, ok = c.Documents.Text(uri); ok {
+		return text, true
+	}
+	if c.Workspace == nil {
+		return "", false
+	}
+	f, ok := c.Workspace.Get(uri)
+	return f.Text, ok
+}
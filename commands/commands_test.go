@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/a-h/examplelsp/diff"
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+const uri = "file:///recipes/tea.cook"
+
+const text = "Boil @water{500%ml}.  \n\tAdd @tea{1%bag}.\n"
+
+// newCommands creates Commands with an open document. Applied edits are
+// written to the store, as they would be by the client.
+func newCommands(t *testing.T) (c *Commands, applied *int) {
+	t.Helper()
+	store := documents.NewStore()
+	store.Set(messages.TextDocumentItem{URI: uri, Version: 1, Text: text})
+	applied = new(int)
+	c = &Commands{
+		Documents: store,
+		ApplyEdit: func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+			*applied++
+			for uri, edits := range params.Edit.Changes {
+				doc, _ := store.Get(uri)
+				if doc.Text, err = documents.ApplyEdits(doc.Text, edits); err != nil {
+					return
+				}
+				doc.Version++
+				store.Set(doc)
+			}
+			result.Applied = true
+			return
+		},
+	}
+	return c, applied
+}
+
+func fixAllParams(t *testing.T, dryRun bool) messages.ExecuteCommandParams {
+	t.Helper()
+	args, err := json.Marshal(FixAllArgs{URI: uri, EditArgs: EditArgs{DryRun: dryRun}})
+	if err != nil {
+		t.Fatalf("failed to marshal arguments: %v", err)
+	}
+	return messages.ExecuteCommandParams{
+		Command:   FixAll,
+		Arguments: []json.RawMessage{args},
+	}
+}
+
+func TestFixAllDryRun(t *testing.T) {
+	c, applied := newCommands(t)
+	before, _ := c.Documents.Get(uri)
+
+	result, err := c.Execute(context.Background(), fixAllParams(t, true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *applied != 0 {
+		t.Errorf("expected a dry run not to apply edits, but %d were applied", *applied)
+	}
+	if after, _ := c.Documents.Get(uri); after != before {
+		t.Errorf("expected a dry run not to change the document, got %#v", after)
+	}
+	preview, ok := result.(PreviewResult)
+	if !ok {
+		t.Fatalf("expected a preview, got %T", result)
+	}
+	if len(preview.Files) != 1 || preview.Files[0].URI != uri || preview.Files[0].Edits != 2 {
+		t.Fatalf("expected 2 edits to %q, got %#v", uri, preview.Files)
+	}
+
+	// The preview matches the outcome of applying the edit.
+	patched, err := diff.Apply(before.Text, preview.Files[0].Diff)
+	if err != nil {
+		t.Fatalf("failed to apply diff: %v", err)
+	}
+	if _, err = c.Execute(context.Background(), fixAllParams(t, false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *applied != 1 {
+		t.Errorf("expected the edit to be applied once, got %d", *applied)
+	}
+	after, _ := c.Documents.Get(uri)
+	if after.Text != patched {
+		t.Errorf("expected the applied edit to produce %q, got %q", patched, after.Text)
+	}
+}
+
+func TestFixAllWithNoChanges(t *testing.T) {
+	c, applied := newCommands(t)
+	if _, err := c.Execute(context.Background(), fixAllParams(t, false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := c.Execute(context.Background(), fixAllParams(t, true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview := result.(PreviewResult); len(preview.Files) != 0 {
+		t.Errorf("expected an empty preview, got %#v", preview)
+	}
+	result, err = c.Execute(context.Background(), fixAllParams(t, false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *applied != 1 || result != nil {
+		t.Errorf("expected no further edits to be applied, got %d edits and result %v", *applied, result)
+	}
+}
+
+func TestFixAllEditNotApplied(t *testing.T) {
+	c, _ := newCommands(t)
+	c.ApplyEdit = func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+		return messages.ApplyWorkspaceEditResult{FailureReason: "document changed"}, nil
+	}
+	if _, err := c.Execute(context.Background(), fixAllParams(t, false)); err == nil {
+		t.Error("expected an error when the client doesn't apply the edit")
+	}
+}
+
+func TestFixAllUsesPositionEncoding(t *testing.T) {
+	c, _ := newCommands(t)
+	c.Documents.Set(messages.TextDocumentItem{URI: uri, Version: 1, Text: "Boil @café{1%cup}.  \n"})
+	c.PositionEncoding = func() messages.PositionEncodingKind { return messages.PositionEncodingKindUTF8 }
+	var edits []messages.TextEdit
+	c.ApplyEdit = func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+		edits = params.Edit.Changes[uri]
+		return messages.ApplyWorkspaceEditResult{Applied: true}, nil
+	}
+	if _, err := c.Execute(context.Background(), fixAllParams(t, false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The trailing whitespace is one byte further on in UTF-8, because of
+	// the é.
+	expected := messages.Range{Start: messages.NewPosition(0, 19), End: messages.NewPosition(0, 21)}
+	if len(edits) != 1 || edits[0].Range != expected {
+		t.Errorf("expected the trailing whitespace at %v to be removed, got %#v", expected, edits)
+	}
+}
+
+func TestExecuteUnknownCommand(t *testing.T) {
+	c, _ := newCommands(t)
+	_, err := c.Execute(context.Background(), messages.ExecuteCommandParams{Command: "unknown"})
+	if !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("expected ErrUnknownCommand, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// OpenDocumentationArgs is the argument of the OpenDocumentation command.
+type OpenDocumentationArgs struct {
+	URL string `json:"url"`
+}
+
+func (c *Commands) openDocumentation(ctx context.Context, args OpenDocumentationArgs) (result any, err error) {
+	u, err := url.Parse(args.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("commands: %q is not a web address", args.URL)
+	}
+	shown, err := c.ShowDocument(ctx, messages.ShowDocumentParams{URI: args.URL, External: true})
+	if err != nil {
+		return nil, fmt.Errorf("commands: failed to show documentation: %w", err)
+	}
+	if !shown.Success {
+		return nil, fmt.Errorf("commands: client did not show %q", args.URL)
+	}
+	return nil, nil
+}
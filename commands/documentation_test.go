@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestOpenDocumentation(t *testing.T) {
+	var shown []messages.ShowDocumentParams
+	c := &Commands{
+		ShowDocument: func(ctx context.Context, params messages.ShowDocumentParams) (result messages.ShowDocumentResult, err error) {
+			shown = append(shown, params)
+			result.Success = true
+			return
+		},
+	}
+	execute := func(url string) error {
+		args, err := json.Marshal(OpenDocumentationArgs{URL: url})
+		if err != nil {
+			t.Fatalf("failed to marshal arguments: %v", err)
+		}
+		_, err = c.Execute(context.Background(), messages.ExecuteCommandParams{
+			Command:   OpenDocumentation,
+			Arguments: []json.RawMessage{args},
+		})
+		return err
+	}
+
+	if err := execute("https://cooklang.org/docs/spec/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shown) != 1 || shown[0].URI != "https://cooklang.org/docs/spec/" || !shown[0].External {
+		t.Errorf("expected the page to be shown externally, got %#v", shown)
+	}
+	if err := execute("file:///etc/passwd"); err == nil {
+		t.Error("expected an error for a URL that isn't a web address")
+	}
+	if len(shown) != 1 {
+		t.Errorf("expected only web addresses to be shown, got %d", len(shown))
+	}
+}
@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// DefaultLogKB is the amount of the end of the log file included by the
+// CollectLogs command, unless the arguments say otherwise.
+const DefaultLogKB = 64
+
+// LogConfig configures the CollectLogs command.
+type LogConfig struct {
+	// Path of the log file.
+	Path string
+	// Version of the server.
+	Version string
+	// Counters returns the counters of the session.
+	Counters func() any
+	// Settings returns the effective settings.
+	Settings func() any
+	// Redactions maps text that may identify the user, such as the home
+	// directory, to the placeholder that replaces it when the report is
+	// redacted.
+	Redactions func() map[string]string
+	// Dir to write the report to. If empty, os.TempDir is used.
+	Dir string
+}
+
+// CollectLogsArgs is the optional argument of the CollectLogs command.
+type CollectLogsArgs struct {
+	// KB of the end of the log file to include.
+	KB int `json:"kb"`
+	// Redact paths from the report.
+	Redact bool `json:"redact"`
+}
+
+// CollectLogsResult is returned by the CollectLogs command.
+type CollectLogsResult struct {
+	ResultHeader
+	// Path of the file that the report was written to.
+	Path string `json:"path"`
+	Text string `json:"text"`
+}
+
+// Titles of the actions of the clipboard message.
+const (
+	actionCopy    = "Copy"
+	actionDismiss = "Dismiss"
+)
+
+func (c *Commands) collectLogs(ctx context.Context, args CollectLogsArgs) (result CollectLogsResult, err error) {
+	if args.KB <= 0 {
+		args.KB = DefaultLogKB
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "examplelsp %s (%s %s/%s)\n", c.Logs.Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	writeSection(&sb, "Counters", c.Logs.Counters)
+	writeSection(&sb, "Settings", c.Logs.Settings)
+	fmt.Fprintf(&sb, "\n== Log (last %dKB of %s) ==\n", args.KB, c.Logs.Path)
+	log, err := tail(c.Logs.Path, int64(args.KB)*1024)
+	if err != nil {
+		fmt.Fprintf(&sb, "failed to read log: %v\n", err)
+	}
+	sb.WriteString(log)
+
+	result.ResultHeader = newResultHeader()
+	result.Text = sb.String()
+	if args.Redact && c.Logs.Redactions != nil {
+		result.Text = redact(result.Text, c.Logs.Redactions())
+	}
+	f, err := os.CreateTemp(c.Logs.Dir, "examplelsp-logs-*.txt")
+	if err != nil {
+		return result, fmt.Errorf("commands: failed to create log report: %w", err)
+	}
+	defer f.Close()
+	if _, err = f.WriteString(result.Text); err != nil {
+		return result, fmt.Errorf("commands: failed to write log report: %w", err)
+	}
+	result.Path = f.Name()
+
+	if c.Clipboard == nil || !c.supportsMessageActions() {
+		return result, nil
+	}
+	action, err := c.ShowMessageRequest(ctx, messages.ShowMessageRequestParams{
+		Type:    messages.MessageTypeInfo,
+		Message: fmt.Sprintf("Logs written to %s. Copy to clipboard?", result.Path),
+		Actions: []messages.MessageActionItem{{Title: actionCopy}, {Title: actionDismiss}},
+	})
+	if err != nil {
+		return result, fmt.Errorf("commands: failed to ask to copy logs: %w", err)
+	}
+	if action != nil && action.Title == actionCopy {
+		if err = c.Clipboard(result.Text); err != nil {
+			return result, fmt.Errorf("commands: failed to copy logs: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// supportsMessageActions returns true if the client shows the actions of
+// window/showMessageRequest.
+func (c *Commands) supportsMessageActions() bool {
+	if c.ShowMessageRequest == nil || c.ClientCapabilities == nil {
+		return false
+	}
+	window := c.ClientCapabilities().Window
+	return window != nil && window.ShowMessage != nil
+}
+
+func writeSection(w io.Writer, title string, f func() any) {
+	fmt.Fprintf(w, "\n== %s ==\n", title)
+	if f == nil {
+		fmt.Fprintln(w, "unavailable")
+		return
+	}
+	data, err := json.MarshalIndent(f(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(w, "failed to encode: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", data)
+}
+
+// tail returns up to n bytes from the end of the file, starting at a line
+// boundary.
+func tail(name string, n int64) (text string, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	// Read the byte before the start, to check whether it's a line boundary.
+	offset := info.Size() - n - 1
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err = f.ReadAt(data, offset); err != nil && err != io.EOF {
+		return "", err
+	}
+	text = string(data)
+	if offset > 0 {
+		// Drop the partial line.
+		i := strings.IndexByte(text, '\n')
+		text = text[i+1:]
+	}
+	return text, nil
+}
+
+// redact replaces each key of replacements in the text with its value. Longer
+// keys are replaced first, so that a workspace inside the home directory is
+// replaced by its own placeholder.
+func redact(text string, replacements map[string]string) string {
+	keys := make([]string, 0, len(replacements))
+	for k := range replacements {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	args := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		args = append(args, k, replacements[k])
+	}
+	return strings.NewReplacer(args...).Replace(text)
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestRedact(t *testing.T) {
+	replacements := map[string]string{
+		"/home/alice":                "~",
+		"/home/alice/recipes/family": "<workspace>",
+		"":                           "<empty>",
+	}
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "home directory",
+			text:     `opened /home/alice/examplelsp.log`,
+			expected: `opened ~/examplelsp.log`,
+		},
+		{
+			name:     "the longest match is replaced",
+			text:     `"uri":"file:///home/alice/recipes/family/pasta.cook"`,
+			expected: `"uri":"file://<workspace>/pasta.cook"`,
+		},
+		{
+			name:     "every occurrence is replaced",
+			text:     "/home/alice/a\n/home/alice/b\n",
+			expected: "~/a\n~/b\n",
+		},
+		{
+			name:     "other text is unchanged",
+			text:     "/home/bob/recipes",
+			expected: "/home/bob/recipes",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := redact(test.text, replacements); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "examplelsp.log")
+	if err := os.WriteFile(name, []byte("first line\nsecond line\nthird line\n"), 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{n: 1024, expected: "first line\nsecond line\nthird line\n"},
+		{n: 15, expected: "third line\n"},
+		{n: 11, expected: "third line\n"},
+	}
+	for _, test := range tests {
+		actual, err := tail(name, test.n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual != test.expected {
+			t.Errorf("%d bytes: expected %q, got %q", test.n, test.expected, actual)
+		}
+	}
+}
+
+func TestCollectLogs(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "examplelsp.log")
+	if err := os.WriteFile(logPath, []byte(`{"msg":"opened file:///home/alice/pasta.cook"}`+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	c, _ := newCommands(t)
+	c.Logs = LogConfig{
+		Path:       logPath,
+		Version:    "v1.2.3",
+		Counters:   func() any { return map[string]int{"requests": 3} },
+		Settings:   func() any { return map[string]bool{"style": true} },
+		Redactions: func() map[string]string { return map[string]string{"/home/alice": "~", dir: "<logs>"} },
+		Dir:        dir,
+	}
+	var asked []messages.ShowMessageRequestParams
+	var copied string
+	c.ShowMessageRequest = func(ctx context.Context, params messages.ShowMessageRequestParams) (*messages.MessageActionItem, error) {
+		asked = append(asked, params)
+		return &params.Actions[0], nil
+	}
+	c.Clipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	args, _ := json.Marshal(CollectLogsArgs{Redact: true})
+	execute := func() CollectLogsResult {
+		t.Helper()
+		result, err := c.Execute(context.Background(), messages.ExecuteCommandParams{
+			Command:   CollectLogs,
+			Arguments: []json.RawMessage{args},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.(CollectLogsResult)
+	}
+
+	t.Run("the report is returned and written to a file", func(t *testing.T) {
+		result := execute()
+		for _, expected := range []string{"examplelsp v1.2.3", `"requests": 3`, `"style": true`, "file://~/pasta.cook", "<logs>/examplelsp.log"} {
+			if !strings.Contains(result.Text, expected) {
+				t.Errorf("expected the report to contain %q, got:\n%s", expected, result.Text)
+			}
+		}
+		if strings.Contains(result.Text, "/home/alice") {
+			t.Errorf("expected the home directory to be redacted, got:\n%s", result.Text)
+		}
+		written, err := os.ReadFile(result.Path)
+		if err != nil {
+			t.Fatalf("failed to read report: %v", err)
+		}
+		if string(written) != result.Text {
+			t.Errorf("expected the file to contain the report")
+		}
+		if len(asked) != 0 || copied != "" {
+			t.Errorf("expected no clipboard prompt when the client doesn't support message actions")
+		}
+	})
+	t.Run("the report can be copied to the clipboard", func(t *testing.T) {
+		c.ClientCapabilities = func() messages.ClientCapabilities {
+			return messages.ClientCapabilities{
+				Window: &messages.WindowClientCapabilities{ShowMessage: &messages.ShowMessageRequestClientCapabilities{}},
+			}
+		}
+		result := execute()
+		if len(asked) != 1 || !strings.Contains(asked[0].Message, result.Path) {
+			t.Fatalf("expected a prompt including the path of the report, got %#v", asked)
+		}
+		if copied != result.Text {
+			t.Errorf("expected the report to be copied")
+		}
+	})
+}
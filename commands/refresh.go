@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+)
+
+// RefreshDiagnosticsArgs is the optional argument of the RefreshDiagnostics
+// command.
+type RefreshDiagnosticsArgs struct {
+	// URI of the document to refresh. If it's empty, all open documents are
+	// refreshed.
+	URI string `json:"uri"`
+}
+
+func (c *Commands) refreshDiagnostics(ctx context.Context, args RefreshDiagnosticsArgs) (result any, err error) {
+	if args.URI == "" {
+		for _, uri := range c.Documents.URIs() {
+			c.RefreshDiagnostics(uri)
+		}
+		return nil, nil
+	}
+	if _, ok := c.Documents.Get(args.URI); !ok {
+		return nil, fmt.Errorf("commands: %q is not open", args.URI)
+	}
+	c.RefreshDiagnostics(args.URI)
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestRefreshDiagnostics(t *testing.T) {
+	store := documents.NewStore()
+	store.Set(messages.TextDocumentItem{URI: "file:///a.cook", Text: "Boil @water."})
+	store.Set(messages.TextDocumentItem{URI: "file:///b.cook", Text: "Boil @milk."})
+	var refreshed []string
+	c := &Commands{
+		Documents: store,
+		RefreshDiagnostics: func(uri string) {
+			refreshed = append(refreshed, uri)
+		},
+	}
+	execute := func(args ...json.RawMessage) error {
+		_, err := c.Execute(context.Background(), messages.ExecuteCommandParams{
+			Command:   RefreshDiagnostics,
+			Arguments: args,
+		})
+		return err
+	}
+
+	if err := execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(refreshed)
+	if expected := []string{"file:///a.cook", "file:///b.cook"}; !reflect.DeepEqual(refreshed, expected) {
+		t.Errorf("expected all open documents to be refreshed, got %v", refreshed)
+	}
+
+	refreshed = nil
+	if err := execute(json.RawMessage(`{"uri":"file:///b.cook"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"file:///b.cook"}; !reflect.DeepEqual(refreshed, expected) {
+		t.Errorf("expected the document to be refreshed, got %v", refreshed)
+	}
+
+	refreshed = nil
+	if err := execute(json.RawMessage(`{"uri":"file:///c.cook"}`)); err == nil {
+		t.Error("expected an error for a document that isn't open")
+	}
+	if len(refreshed) != 0 {
+		t.Errorf("expected no documents to be refreshed, got %v", refreshed)
+	}
+}
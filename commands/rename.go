@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// RenameIngredientEverywhereArgs is the argument of the
+// RenameIngredientEverywhere command.
+type RenameIngredientEverywhereArgs struct {
+	EditArgs
+	// URI and Position of the ingredient to rename. The position is in
+	// UTF-16, like the positions in command results.
+	URI      string            `json:"uri"`
+	Position messages.Position `json:"position"`
+	// NewName of the ingredient. There's no way for the server to prompt for
+	// text, so clients must ask the user for it.
+	NewName string `json:"newName"`
+}
+
+// Titles of the actions of the confirmation message.
+const (
+	actionRename = "Rename"
+	actionCancel = "Cancel"
+)
+
+func (c *Commands) renameIngredientEverywhere(ctx context.Context, args RenameIngredientEverywhereArgs) (result any, err error) {
+	if strings.TrimSpace(args.NewName) == "" {
+		return nil, errors.New("commands: missing newName argument")
+	}
+	// Re-read any files that have changed on disk, so that the edits are
+	// computed against the current text.
+	var files []string
+	if c.Workspace != nil {
+		indexed, err := c.Workspace.Refresh()
+		if err != nil {
+			return nil, fmt.Errorf("commands: %w", err)
+		}
+		for _, f := range indexed {
+			files = append(files, f.URI)
+		}
+	}
+	text, ok := c.text(args.URI)
+	if !ok {
+		return nil, fmt.Errorf("commands: %q is not open or in the workspace", args.URI)
+	}
+	ingredient, ok := recipe.Parse(text).IngredientAt(args.Position)
+	if !ok {
+		return nil, fmt.Errorf("commands: there is no ingredient at %d:%d", args.Position.Line, args.Position.Character)
+	}
+
+	var edit messages.WorkspaceEdit
+	for _, uri := range union(files, c.Documents.URIs()) {
+		// Open documents take precedence over the text on disk, and are
+		// versioned so that the client can reject the edit if they change.
+		document := messages.OptionalVersionedTextDocumentIdentifier{URI: uri}
+		if doc, ok := c.Documents.Get(uri); ok {
+			document.Version = &doc.Version
+		}
+		text, _ := c.text(uri)
+		edits, ok := recipe.RenameIngredient(text, ingredient.Name, args.NewName)
+		if !ok {
+			return nil, fmt.Errorf("commands: %q is not a valid ingredient name", args.NewName)
+		}
+		if len(edits) > 0 {
+			edit.DocumentChanges = append(edit.DocumentChanges, messages.DocumentChange{
+				TextDocumentEdit: &messages.TextDocumentEdit{
+					TextDocument: document,
+					Edits:        messages.AnnotatedTextEdits(edits),
+				},
+			})
+		}
+	}
+
+	label := fmt.Sprintf("Rename ingredient %q to %q", ingredient.Name, strings.TrimSpace(args.NewName))
+	if !args.DryRun && len(edit.DocumentChanges) > 0 {
+		confirmed, err := c.confirmRename(ctx, fmt.Sprintf("%s in %s?", label, plural(len(edit.DocumentChanges), "file")))
+		if err != nil || !confirmed {
+			return nil, err
+		}
+	}
+	return c.applyOrPreview(ctx, label, edit, args.EditArgs)
+}
+
+// confirmRename asks the user whether to continue with the rename.
+func (c *Commands) confirmRename(ctx context.Context, message string) (ok bool, err error) {
+	action, err := c.ShowMessageRequest(ctx, messages.ShowMessageRequestParams{
+		Type:    messages.MessageTypeInfo,
+		Message: message,
+		Actions: []messages.MessageActionItem{{Title: actionRename}, {Title: actionCancel}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("commands: failed to confirm: %w", err)
+	}
+	return action != nil && action.Title == actionRename, nil
+}
+
+// union returns the sorted, distinct values of a and b.
+func union(a, b []string) (values []string) {
+	seen := map[string]bool{}
+	for _, v := range append(append([]string{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/workspace"
+)
+
+// newWorkspaceCommands creates Commands for a workspace containing an open
+// recipe, a recipe that isn't open, and a recipe that doesn't use the
+// ingredient.
+func newWorkspaceCommands(t *testing.T) (c *Commands, uris map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	uris = map[string]string{}
+	for name, text := range map[string]string{
+		"tea.cook":   "Boil @water{500%ml}.\nAdd @Water to the @teapot.\n",
+		"pasta.cook": "Boil @water.\nAdd the @pasta.\n",
+		"toast.cook": "Toast the @bread.\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		uri, err := workspace.URIFromPath(path)
+		if err != nil {
+			t.Fatalf("failed to get URI: %v", err)
+		}
+		uris[name] = uri
+	}
+	store := documents.NewStore()
+	// The open document has unsaved changes.
+	store.Set(messages.TextDocumentItem{URI: uris["tea.cook"], Version: 4, Text: "Boil @water{1%l}.\n"})
+	c = &Commands{
+		Documents: store,
+		Workspace: workspace.NewIndex(root),
+		ShowMessageRequest: func(ctx context.Context, params messages.ShowMessageRequestParams) (action *messages.MessageActionItem, err error) {
+			return &params.Actions[0], nil
+		},
+	}
+	return c, uris
+}
+
+func renameParams(t *testing.T, args RenameIngredientEverywhereArgs) messages.ExecuteCommandParams {
+	t.Helper()
+	arg, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("failed to marshal arguments: %v", err)
+	}
+	return messages.ExecuteCommandParams{
+		Command:   RenameIngredientEverywhere,
+		Arguments: []json.RawMessage{arg},
+	}
+}
+
+func TestRenameIngredientEverywhere(t *testing.T) {
+	c, uris := newWorkspaceCommands(t)
+	if _, err := c.Workspace.Refresh(); err != nil {
+		t.Fatalf("failed to index workspace: %v", err)
+	}
+	// Change a file on disk after it has been indexed.
+	pasta, _ := c.Workspace.Get(uris["pasta.cook"])
+	if err := os.WriteFile(pasta.Path, []byte("Add the @pasta to the @water.\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(pasta.Path, later, later); err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+
+	var confirmations []messages.ShowMessageRequestParams
+	c.ShowMessageRequest = func(ctx context.Context, params messages.ShowMessageRequestParams) (action *messages.MessageActionItem, err error) {
+		confirmations = append(confirmations, params)
+		return &messages.MessageActionItem{Title: "Rename"}, nil
+	}
+	var applied []messages.ApplyWorkspaceEditParams
+	c.ApplyEdit = func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+		applied = append(applied, params)
+		result.Applied = true
+		return
+	}
+
+	_, err := c.Execute(context.Background(), renameParams(t, RenameIngredientEverywhereArgs{
+		URI:      uris["tea.cook"],
+		Position: messages.NewPosition(0, 7),
+		NewName:  "sparkling water",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(confirmations) != 1 {
+		t.Fatalf("expected 1 confirmation, got %d", len(confirmations))
+	}
+	if !strings.Contains(confirmations[0].Message, "in 2 files") {
+		t.Errorf("expected the confirmation to list the number of files, got %q", confirmations[0].Message)
+	}
+	if len(applied) != 1 {
+		t.Fatalf("expected 1 edit to be applied, got %d", len(applied))
+	}
+	changes := applied[0].Edit.DocumentChanges
+	if len(changes) != 2 {
+		t.Fatalf("expected changes to 2 files, got %#v", changes)
+	}
+	// Changes are sorted by URI, so pasta comes before tea.
+	pastaChange, teaChange := changes[0].TextDocumentEdit, changes[1].TextDocumentEdit
+	if pastaChange == nil || teaChange == nil {
+		t.Fatalf("expected text document edits, got %#v", changes)
+	}
+	if pastaChange.TextDocument.URI != uris["pasta.cook"] || pastaChange.TextDocument.Version != nil {
+		t.Errorf("expected an unversioned change to pasta, got %#v", pastaChange.TextDocument)
+	}
+	expected := []messages.TextEdit{{
+		Range:   messages.Range{Start: messages.NewPosition(0, 23), End: messages.NewPosition(0, 28)},
+		NewText: "sparkling water{}",
+	}}
+	if !equalEdits(pastaChange.TextEdits(), expected) {
+		t.Errorf("expected the edits to be computed against the text on disk, got %#v", pastaChange.Edits)
+	}
+	if teaChange.TextDocument.Version == nil || *teaChange.TextDocument.Version != 4 {
+		t.Errorf("expected a change to version 4 of tea, got %#v", teaChange.TextDocument)
+	}
+	expected = []messages.TextEdit{{
+		Range:   messages.Range{Start: messages.NewPosition(0, 6), End: messages.NewPosition(0, 11)},
+		NewText: "sparkling water",
+	}}
+	if !equalEdits(teaChange.TextEdits(), expected) {
+		t.Errorf("expected the edits to be computed against the open document, got %#v", teaChange.Edits)
+	}
+}
+
+func equalEdits(a, b []messages.TextEdit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRenameIngredientEverywhereDryRun(t *testing.T) {
+	c, uris := newWorkspaceCommands(t)
+	c.ShowMessageRequest = func(ctx context.Context, params messages.ShowMessageRequestParams) (action *messages.MessageActionItem, err error) {
+		t.Error("expected a dry run not to ask for confirmation")
+		return nil, nil
+	}
+	result, err := c.Execute(context.Background(), renameParams(t, RenameIngredientEverywhereArgs{
+		EditArgs: EditArgs{DryRun: true},
+		URI:      uris["pasta.cook"],
+		Position: messages.NewPosition(0, 6),
+		NewName:  "stock",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview, ok := result.(PreviewResult)
+	if !ok {
+		t.Fatalf("expected a preview, got %T", result)
+	}
+	if len(preview.Files) != 2 {
+		t.Fatalf("expected 2 files in the preview, got %#v", preview.Files)
+	}
+	if !strings.Contains(preview.Files[0].Diff, "+Boil @stock.") {
+		t.Errorf("expected the closed file to be previewed, got:\n%s", preview.Files[0].Diff)
+	}
+	if !strings.Contains(preview.Files[1].Diff, "+Boil @stock{1%l}.") {
+		t.Errorf("expected the open document to be previewed, got:\n%s", preview.Files[1].Diff)
+	}
+}
+
+func TestRenameIngredientEverywhereCancelled(t *testing.T) {
+	for _, action := range []*messages.MessageActionItem{nil, {Title: "Cancel"}} {
+		c, uris := newWorkspaceCommands(t)
+		c.ShowMessageRequest = func(ctx context.Context, params messages.ShowMessageRequestParams) (*messages.MessageActionItem, error) {
+			return action, nil
+		}
+		c.ApplyEdit = func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+			t.Error("expected the edit not to be applied")
+			return
+		}
+		_, err := c.Execute(context.Background(), renameParams(t, RenameIngredientEverywhereArgs{
+			URI:      uris["pasta.cook"],
+			Position: messages.NewPosition(0, 6),
+			NewName:  "stock",
+		}))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRenameIngredientEverywhereInvalidArguments(t *testing.T) {
+	c, uris := newWorkspaceCommands(t)
+	tests := []struct {
+		name string
+		args RenameIngredientEverywhereArgs
+	}{
+		{
+			name: "missing new name",
+			args: RenameIngredientEverywhereArgs{URI: uris["pasta.cook"], Position: messages.NewPosition(0, 6)},
+		},
+		{
+			name: "no ingredient at the position",
+			args: RenameIngredientEverywhereArgs{URI: uris["pasta.cook"], Position: messages.NewPosition(0, 1), NewName: "stock"},
+		},
+		{
+			name: "invalid new name",
+			args: RenameIngredientEverywhereArgs{URI: uris["pasta.cook"], Position: messages.NewPosition(0, 6), NewName: "stock{1%l}"},
+		},
+		{
+			name: "unknown document",
+			args: RenameIngredientEverywhereArgs{URI: "file:///unknown.cook", NewName: "stock"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := c.Execute(context.Background(), renameParams(t, test.args)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+// SchemaVersion of the command results. It's incremented when a result
+// changes in a way that could break external consumers.
+const SchemaVersion = 1
+
+// ResultHeader is embedded in every command result.
+type ResultHeader struct {
+	SchemaVersion int `json:"schemaVersion"`
+	// PositionEncoding of the positions in the result. Results are read by
+	// external tools as well as by clients, so it's always the LSP default,
+	// UTF-16, whatever encoding was negotiated with the client.
+	PositionEncoding messages.PositionEncodingKind `json:"positionEncoding"`
+}
+
+func newResultHeader() ResultHeader {
+	return ResultHeader{
+		SchemaVersion:    SchemaVersion,
+		PositionEncoding: messages.PositionEncodingKindUTF16,
+	}
+}
+
+// PreviewResult is returned by commands that edit documents, when a dry run
+// is requested.
+type PreviewResult struct {
+	ResultHeader
+	documents.Preview
+}
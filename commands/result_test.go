@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// resultTypes are the types returned by Execute.
+var resultTypes = []any{
+	PreviewResult{},
+	CollectLogsResult{},
+}
+
+func TestResultsHaveHeaders(t *testing.T) {
+	for _, result := range resultTypes {
+		rt := reflect.TypeOf(result)
+		t.Run(rt.Name(), func(t *testing.T) {
+			field, ok := rt.FieldByName("ResultHeader")
+			if !ok || !field.Anonymous {
+				t.Fatalf("expected %s to embed ResultHeader", rt.Name())
+			}
+			v := reflect.New(rt).Elem()
+			v.FieldByName("ResultHeader").Set(reflect.ValueOf(newResultHeader()))
+			data, err := json.Marshal(v.Interface())
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if fields["schemaVersion"] != float64(SchemaVersion) || fields["positionEncoding"] != "utf-16" {
+				t.Errorf("expected the header fields at the top level, got %s", data)
+			}
+		})
+	}
+}
+
+// TestResultsUseLocations checks that results don't contain ad-hoc position
+// fields, which should be messages.Location or messages.Range instead.
+func TestResultsUseLocations(t *testing.T) {
+	adHoc := map[string]bool{"line": true, "col": true, "column": true, "character": true, "offset": true}
+	var check func(path string, rt reflect.Type, seen map[reflect.Type]bool)
+	check = func(path string, rt reflect.Type, seen map[reflect.Type]bool) {
+		for rt.Kind() == reflect.Pointer || rt.Kind() == reflect.Slice || rt.Kind() == reflect.Map {
+			rt = rt.Elem()
+		}
+		// The messages package defines the standard position types.
+		if rt.Kind() != reflect.Struct || seen[rt] || strings.HasSuffix(rt.PkgPath(), "/messages") {
+			return
+		}
+		seen[rt] = true
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if adHoc[strings.ToLower(field.Name)] {
+				t.Errorf("%s.%s: use messages.Location instead of ad-hoc positions", path, field.Name)
+			}
+			check(path+"."+field.Name, field.Type, seen)
+		}
+	}
+	for _, result := range resultTypes {
+		rt := reflect.TypeOf(result)
+		check(rt.Name(), rt, map[reflect.Type]bool{})
+	}
+}
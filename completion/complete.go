@@ -0,0 +1,103 @@
+package completion
+
+import (
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Options of Complete, which depend on the client and the document.
+type Options struct {
+	// Parser parses the text to find the ingredients and cookware that are
+	// already used.
+	Parser parser.Parser
+	// Snippets offered at the start of lines, keyed by prefix.
+	Snippets map[string]string
+	// SnippetSupport is true if the client supports snippets.
+	SnippetSupport bool
+}
+
+// provider returns the completions at the position. ok is false if the
+// position isn't where the provider offers completions.
+type provider func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool)
+
+// Names are filtered by what's been typed, so the client has to ask again as
+// the user types.
+var (
+	timerNamesProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := TimerNames(text, p)
+		return messages.CompletionList{IsIncomplete: true, Items: items}, ok
+	}
+	ingredientNamesProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := IngredientNames(text, p, o.Parser)
+		return messages.CompletionList{IsIncomplete: true, Items: items}, ok
+	}
+	cookwareNamesProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := CookwareNames(text, p, o.Parser)
+		return messages.CompletionList{IsIncomplete: true, Items: items}, ok
+	}
+	metadataKeysProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := MetadataKeys(text, p, o.SnippetSupport)
+		return messages.CompletionList{Items: items}, ok
+	}
+	snippetsProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := Snippets(text, p, o.Snippets, o.SnippetSupport)
+		return messages.CompletionList{Items: items}, ok
+	}
+	unitsProvider provider = func(text string, p messages.Position, o Options) (list messages.CompletionList, ok bool) {
+		items, ok := UnitsAt(text, p)
+		return messages.CompletionList{IsIncomplete: true, Items: items}, ok
+	}
+)
+
+// providersByTrigger are the providers for each of the TriggerCharacters.
+var providersByTrigger = map[string]provider{
+	"~": timerNamesProvider,
+	"@": ingredientNamesProvider,
+	"#": cookwareNamesProvider,
+	">": metadataKeysProvider,
+	"%": unitsProvider,
+}
+
+// providers are tried in order when completion isn't triggered by a
+// character, e.g. while typing a name after its trigger character.
+var providers = []provider{
+	timerNamesProvider,
+	ingredientNamesProvider,
+	cookwareNamesProvider,
+	metadataKeysProvider,
+	snippetsProvider,
+	unitsProvider,
+}
+
+// Complete returns the completions at the position of the request. If the
+// request was triggered by a character, or the character before the position
+// is a trigger character, only the provider for that character is used.
+// Otherwise, the first provider that offers completions at the position is
+// used. If none do, the list is empty.
+func Complete(text string, params messages.CompletionParams, o Options) (list messages.CompletionList) {
+	candidates := providers
+	if triggered, ok := providersByTrigger[triggerCharacter(text, params)]; ok {
+		candidates = []provider{triggered}
+	}
+	for _, candidate := range candidates {
+		if list, ok := candidate(text, params.Position, o); ok {
+			return list
+		}
+	}
+	return messages.CompletionList{Items: []messages.CompletionItem{}}
+}
+
+// triggerCharacter returns the character that triggered completion. Clients
+// only send it for completion that's triggered by typing the character, so
+// for invoked completion, the character before the position is used.
+func triggerCharacter(text string, params messages.CompletionParams) string {
+	if c := params.Context; c != nil && c.TriggerKind == messages.TriggerKindTriggerCharacter {
+		return c.TriggerCharacter
+	}
+	before := []rune(recipe.LineBefore(text, params.Position))
+	if len(before) == 0 {
+		return ""
+	}
+	return string(before[len(before)-1])
+}
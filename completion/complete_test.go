@@ -0,0 +1,141 @@
+package completion
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/aquilax/cooklang-go"
+	"golang.org/x/exp/slog"
+)
+
+func TestComplete(t *testing.T) {
+	documents := map[string]string{
+		"file:///timer.cook":      "Leave to ~prove{1%hour}.\n\nWhen the ~",
+		"file:///ingredient.cook": "Add the @salt.\n\nAdd more @",
+		"file:///cookware.cook":   "Heat the #wok.\n\nServe from the #",
+		"file:///unit.cook":       "Add the @flour{500%",
+		"file:///metadata.cook":   ">> ",
+		"file:///text.cook":       "Add the @salt.",
+	}
+	end := func(text string) messages.Position {
+		lines := strings.Split(text, "\n")
+		return messages.NewPosition(len(lines)-1, len(lines[len(lines)-1]))
+	}
+	failing := parser.ParserFunc(func(text string) (*cooklang.Recipe, error) {
+		return nil, &cooklang.Error{Message: "incomplete"}
+	})
+
+	r, w, client := lsptest.New()
+	t.Cleanup(func() { client.Close() })
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		return lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{
+			CompletionProvider: &messages.CompletionOptions{TriggerCharacters: TriggerCharacters},
+		}), nil
+	})
+	m.HandleMethod(messages.CompletionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var params messages.CompletionParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		return Complete(documents[params.TextDocument.URI], params, Options{Parser: failing}), nil
+	})
+	go m.Process()
+	var initialized messages.InitializeResult
+	if err := client.Call("initialize", messages.InitializeParams{}, &initialized); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if p := initialized.Capabilities.CompletionProvider; p == nil || strings.Join(p.TriggerCharacters, "") != "%~@#>" {
+		t.Fatalf("expected the trigger characters to be registered, got %#v", p)
+	}
+	if err := client.Notify("initialized", nil); err != nil {
+		t.Fatalf("failed to send initialized: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		context  *messages.CompletionContext
+		expected messages.CompletionItem
+	}{
+		{
+			name:     "tilde completes timer names",
+			uri:      "file:///timer.cook",
+			context:  &messages.CompletionContext{TriggerKind: messages.TriggerKindTriggerCharacter, TriggerCharacter: "~"},
+			expected: messages.CompletionItem{Label: "prove", Kind: messages.CompletionItemKindEvent},
+		},
+		{
+			name:     "at sign completes ingredient names",
+			uri:      "file:///ingredient.cook",
+			context:  &messages.CompletionContext{TriggerKind: messages.TriggerKindTriggerCharacter, TriggerCharacter: "@"},
+			expected: messages.CompletionItem{Label: "salt", Kind: messages.CompletionItemKindValue},
+		},
+		{
+			name:     "hash completes cookware names",
+			uri:      "file:///cookware.cook",
+			context:  &messages.CompletionContext{TriggerKind: messages.TriggerKindTriggerCharacter, TriggerCharacter: "#"},
+			expected: messages.CompletionItem{Label: "wok", Kind: messages.CompletionItemKindProperty},
+		},
+		{
+			name:     "percent completes units",
+			uri:      "file:///unit.cook",
+			context:  &messages.CompletionContext{TriggerKind: messages.TriggerKindTriggerCharacter, TriggerCharacter: "%"},
+			expected: messages.CompletionItem{Label: "g", Kind: messages.CompletionItemKindUnit},
+		},
+		{
+			name:     "invoked completion uses the character before the position",
+			uri:      "file:///ingredient.cook",
+			context:  &messages.CompletionContext{TriggerKind: messages.TriggerKindInvoked},
+			expected: messages.CompletionItem{Label: "salt", Kind: messages.CompletionItemKindValue},
+		},
+		{
+			name:     "completion without a context uses the character before the position",
+			uri:      "file:///metadata.cook",
+			expected: messages.CompletionItem{Label: "title", Kind: messages.CompletionItemKindProperty},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var list messages.CompletionList
+			err := client.Call(messages.CompletionRequestMethod, messages.CompletionParams{
+				TextDocumentPositionParams: messages.TextDocumentPositionParams{
+					TextDocument: messages.TextDocumentIdentifier{URI: test.uri},
+					Position:     end(documents[test.uri]),
+				},
+				Context: test.context,
+			}, &list)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(list.Items) == 0 {
+				t.Fatal("expected items")
+			}
+			if actual := list.Items[0]; actual.Label != test.expected.Label || actual.Kind != test.expected.Kind {
+				t.Errorf("expected %q with kind %d first, got %q with kind %d", test.expected.Label, test.expected.Kind, actual.Label, actual.Kind)
+			}
+		})
+	}
+
+	t.Run("triggers outside an element return an empty list", func(t *testing.T) {
+		var list json.RawMessage
+		err := client.Call(messages.CompletionRequestMethod, messages.CompletionParams{
+			TextDocumentPositionParams: messages.TextDocumentPositionParams{
+				TextDocument: messages.TextDocumentIdentifier{URI: "file:///text.cook"},
+				Position:     messages.NewPosition(0, 3),
+			},
+			Context: &messages.CompletionContext{TriggerKind: messages.TriggerKindTriggerCharacter, TriggerCharacter: "%"},
+		}, &list)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected := `{"isIncomplete":false,"items":[]}`; string(list) != expected {
+			t.Errorf("expected %s, got %s", expected, list)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package completion
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/aquilax/cooklang-go"
+)
+
+// CommonCookware is suggested after the cookware used in the recipe.
+var CommonCookware = []string{"pan", "pot", "oven tray", "whisk", "bowl"}
+
+var cookware = element{
+	typed: regexp.MustCompile(`#([\p{L}\p{N}_-]*)$`),
+	used:  regexp.MustCompile(`#([^@#~{}\r\n]+)\{|#([\p{L}\p{N}_-]+)`),
+	parsed: func(step cooklang.Step) (names []string) {
+		for _, cookware := range step.Cookware {
+			names = append(names, cookware.Name)
+		}
+		return names
+	},
+}
+
+// CookwareNames returns the names of the cookware used elsewhere in the text,
+// followed by the common cookware that isn't used, filtered by what's been
+// typed. ok is false if the position isn't within the name of cookware.
+func CookwareNames(text string, pos messages.Position, p parser.Parser) (items []messages.CompletionItem, ok bool) {
+	names, prefix, ok := cookware.names(text, pos, p)
+	if !ok {
+		return nil, false
+	}
+	items = []messages.CompletionItem{}
+	used := map[string]bool{}
+	for _, name := range names {
+		used[strings.ToLower(name)] = true
+		item := nameItem(name, messages.CompletionItemKindProperty)
+		item.SortText = "0 " + strings.ToLower(name)
+		items = append(items, item)
+	}
+	for _, name := range CommonCookware {
+		if used[name] || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		item := nameItem(name, messages.CompletionItemKindProperty)
+		item.SortText = "1 " + name
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].SortText < items[j].SortText })
+	return items, true
+}
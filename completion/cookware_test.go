@@ -0,0 +1,105 @@
+package completion
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/aquilax/cooklang-go"
+)
+
+func TestTriggerCharacters(t *testing.T) {
+	for _, expected := range []string{"%", "~", "@", "#", ">"} {
+		var found bool
+		for _, c := range TriggerCharacters {
+			found = found || c == expected
+		}
+		if !found {
+			t.Errorf("expected %q to be a trigger character, got %q", expected, TriggerCharacters)
+		}
+	}
+}
+
+func TestCookwareNames(t *testing.T) {
+	parsed := parser.ParserFunc(func(text string) (*cooklang.Recipe, error) {
+		return &cooklang.Recipe{Steps: []cooklang.Step{
+			{Cookware: []cooklang.Cookware{{Name: "wok"}, {Name: "Pan"}}},
+			{Cookware: []cooklang.Cookware{{Name: "frying basket"}}},
+		}}, nil
+	})
+	failing := parser.ParserFunc(func(text string) (*cooklang.Recipe, error) {
+		return nil, errors.New("unexpected end of input")
+	})
+	tests := []struct {
+		name          string
+		text          string
+		position      messages.Position
+		parser        parser.Parser
+		expectedOK    bool
+		expectedItems []messages.CompletionItem
+	}{
+		{
+			name:       "cookware from the recipe sorts above the common cookware",
+			text:       "Heat the #wok.\n\nPut it in the #",
+			position:   messages.NewPosition(2, 15),
+			parser:     parsed,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "frying basket", Kind: messages.CompletionItemKindProperty, InsertText: "frying basket{}", SortText: "0 frying basket"},
+				{Label: "Pan", Kind: messages.CompletionItemKindProperty, SortText: "0 pan"},
+				{Label: "wok", Kind: messages.CompletionItemKindProperty, SortText: "0 wok"},
+				{Label: "bowl", Kind: messages.CompletionItemKindProperty, SortText: "1 bowl"},
+				{Label: "oven tray", Kind: messages.CompletionItemKindProperty, InsertText: "oven tray{}", SortText: "1 oven tray"},
+				{Label: "pot", Kind: messages.CompletionItemKindProperty, SortText: "1 pot"},
+				{Label: "whisk", Kind: messages.CompletionItemKindProperty, SortText: "1 whisk"},
+			},
+		},
+		{
+			name:       "names are filtered by the typed prefix",
+			text:       "Heat the #wok.\n\nPut it in the #P",
+			position:   messages.NewPosition(2, 16),
+			parser:     parsed,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "Pan", Kind: messages.CompletionItemKindProperty, SortText: "0 pan"},
+				{Label: "pot", Kind: messages.CompletionItemKindProperty, SortText: "1 pot"},
+			},
+		},
+		{
+			name:       "cookware is scanned from the text if it can't be parsed",
+			text:       "Heat the #large wok{ and a #whisk.\n\nPut it in the #",
+			position:   messages.NewPosition(2, 15),
+			parser:     failing,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "large wok", Kind: messages.CompletionItemKindProperty, InsertText: "large wok{}", SortText: "0 large wok"},
+				{Label: "whisk", Kind: messages.CompletionItemKindProperty, SortText: "0 whisk"},
+				{Label: "bowl", Kind: messages.CompletionItemKindProperty, SortText: "1 bowl"},
+				{Label: "oven tray", Kind: messages.CompletionItemKindProperty, InsertText: "oven tray{}", SortText: "1 oven tray"},
+				{Label: "pan", Kind: messages.CompletionItemKindProperty, SortText: "1 pan"},
+				{Label: "pot", Kind: messages.CompletionItemKindProperty, SortText: "1 pot"},
+			},
+		},
+		{
+			name:       "other positions are not cookware names",
+			text:       "Add the @salt.",
+			position:   messages.NewPosition(0, 14),
+			parser:     parsed,
+			expectedOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := CookwareNames(test.text, test.position, test.parser)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			if !reflect.DeepEqual(items, test.expectedItems) {
+				t.Errorf("expected %#v, got %#v", test.expectedItems, items)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package completion
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/a-h/examplelsp/recipe"
+	"github.com/aquilax/cooklang-go"
+)
+
+// TriggerCharacters are the characters that start an element or metadata,
+// and ask the client for completions.
+var TriggerCharacters = []string{"%", "~", "@", "#", ">"}
+
+var ingredients = element{
+	typed: regexp.MustCompile(`@([\p{L}\p{N}_-]*)$`),
+	used:  regexp.MustCompile(`@([^@#~{}\r\n]+)\{|@([\p{L}\p{N}_-]+)`),
+	parsed: func(step cooklang.Step) (names []string) {
+		for _, ingredient := range step.Ingredients {
+			names = append(names, ingredient.Name)
+		}
+		return names
+	},
+}
+
+// IngredientNames returns the names of the ingredients used elsewhere in the
+// text, filtered by what's been typed. Names with more than one word insert
+// the braces they need. ok is false if the position isn't within the name of
+// an ingredient.
+//
+// The text is parsed with p. While an ingredient is being typed, the recipe
+// often can't be parsed, so the names are found by scanning the text instead.
+func IngredientNames(text string, pos messages.Position, p parser.Parser) (items []messages.CompletionItem, ok bool) {
+	names, _, ok := ingredients.names(text, pos, p)
+	if !ok {
+		return nil, false
+	}
+	items = []messages.CompletionItem{}
+	for _, name := range names {
+		items = append(items, nameItem(name, messages.CompletionItemKindValue))
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items, true
+}
+
+// element is a kind of named element, such as ingredients.
+type element struct {
+	// typed matches the name of the element being typed before the cursor.
+	typed *regexp.Regexp
+	// used matches the elements in the text. Names with braces are in the
+	// first group, and single words are in the second.
+	used *regexp.Regexp
+	// parsed returns the names of the elements in a parsed step.
+	parsed func(step cooklang.Step) []string
+}
+
+// names returns the distinct names of the elements used in the text, except
+// the one being typed, that start with what's been typed. Names are compared
+// case insensitively. ok is false if the position isn't within the name of
+// the element.
+func (e element) names(text string, pos messages.Position, p parser.Parser) (names []string, prefix string, ok bool) {
+	before := recipe.LineBefore(text, pos)
+	match := e.typed.FindStringSubmatch(before)
+	if match == nil {
+		return nil, "", false
+	}
+	prefix = strings.ToLower(match[1])
+
+	// Leave out the element that's being typed.
+	lines := strings.Split(text, "\n")
+	lines[pos.Line] = before[:len(before)-len(match[0])] + lines[pos.Line][len(before):]
+	text = strings.Join(lines, "\n")
+
+	seen := map[string]bool{}
+	for _, name := range e.all(text, p) {
+		key := strings.ToLower(name)
+		if seen[key] || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names, prefix, true
+}
+
+// all returns the names of the elements in the text, in the order they're
+// used. The text is scanned if it can't be parsed.
+func (e element) all(text string, p parser.Parser) (names []string) {
+	if r, err := p.Parse(text); err == nil && r != nil {
+		for _, step := range r.Steps {
+			for _, name := range e.parsed(step) {
+				names = append(names, strings.TrimSpace(name))
+			}
+		}
+		return names
+	}
+	for _, match := range e.used.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimSpace(match[1] + match[2])
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// nameItem returns a completion item for the name. Names with more than one
+// word insert the braces they need.
+func nameItem(name string, kind messages.CompletionItemKind) messages.CompletionItem {
+	item := messages.CompletionItem{
+		Label: name,
+		Kind:  kind,
+	}
+	if strings.ContainsAny(name, " \t") {
+		item.InsertText = name + "{}"
+	}
+	return item
+}
@@ -0,0 +1,103 @@
+package completion
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/aquilax/cooklang-go"
+)
+
+func TestIngredientNames(t *testing.T) {
+	parsed := parser.ParserFunc(func(text string) (*cooklang.Recipe, error) {
+		return &cooklang.Recipe{Steps: []cooklang.Step{
+			{Ingredients: []cooklang.Ingredient{{Name: "worcestershire sauce"}, {Name: "eggs"}}},
+			{Ingredients: []cooklang.Ingredient{{Name: "Eggs"}, {Name: "salt"}}},
+		}}, nil
+	})
+	failing := parser.ParserFunc(func(text string) (*cooklang.Recipe, error) {
+		return nil, errors.New("unexpected end of input")
+	})
+	tests := []struct {
+		name          string
+		text          string
+		position      messages.Position
+		parser        parser.Parser
+		expectedOK    bool
+		expectedItems []messages.CompletionItem
+	}{
+		{
+			name:       "names from the parsed recipe are returned after an at sign",
+			text:       "Whisk the @eggs{2}.\n\nAdd @",
+			position:   messages.NewPosition(2, 5),
+			parser:     parsed,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "eggs", Kind: messages.CompletionItemKindValue},
+				{Label: "salt", Kind: messages.CompletionItemKindValue},
+				{Label: "worcestershire sauce", Kind: messages.CompletionItemKindValue, InsertText: "worcestershire sauce{}"},
+			},
+		},
+		{
+			name:       "names are filtered by the typed prefix",
+			text:       "Whisk the @eggs{2}.\n\nAdd @Wor",
+			position:   messages.NewPosition(2, 8),
+			parser:     parsed,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "worcestershire sauce", Kind: messages.CompletionItemKindValue, InsertText: "worcestershire sauce{}"},
+			},
+		},
+		{
+			name:       "names are scanned from the text if it can't be parsed",
+			text:       "Add the @worcestershire sauce{1%tsp} and @salt.\n\nWhisk the @eggs{2 and @Salt.\n\nAdd @",
+			position:   messages.NewPosition(4, 5),
+			parser:     failing,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "eggs", Kind: messages.CompletionItemKindValue},
+				{Label: "salt", Kind: messages.CompletionItemKindValue},
+				{Label: "worcestershire sauce", Kind: messages.CompletionItemKindValue, InsertText: "worcestershire sauce{}"},
+			},
+		},
+		{
+			name:       "the ingredient being typed isn't suggested",
+			text:       "Add the @salt.\n\nAdd @sal",
+			position:   messages.NewPosition(2, 8),
+			parser:     failing,
+			expectedOK: true,
+			expectedItems: []messages.CompletionItem{
+				{Label: "salt", Kind: messages.CompletionItemKindValue},
+			},
+		},
+		{
+			name:          "no ingredients match",
+			text:          "Add the @salt.\n\nAdd @x",
+			position:      messages.NewPosition(2, 6),
+			parser:        failing,
+			expectedOK:    true,
+			expectedItems: []messages.CompletionItem{},
+		},
+		{
+			name:       "other positions are not ingredient names",
+			text:       "Add the @salt.",
+			position:   messages.NewPosition(0, 3),
+			parser:     parsed,
+			expectedOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := IngredientNames(test.text, test.position, test.parser)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			if !reflect.DeepEqual(items, test.expectedItems) {
+				t.Errorf("expected %#v, got %#v", test.expectedItems, items)
+			}
+		})
+	}
+}
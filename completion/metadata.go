@@ -0,0 +1,62 @@
+package completion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// metadataKeys are the well-known cooklang metadata keys, and an example of
+// their value, which is used as the placeholder of the snippet.
+var metadataKeys = []struct {
+	key, description, example string
+}{
+	{key: "title", description: "The title of the recipe, if it's different to the file name.", example: "Sourdough loaf"},
+	{key: "source", description: "Where the recipe came from, such as a URL or the name of a book.", example: "https://cooklang.org"},
+	{key: "servings", description: "The number of people the recipe serves, e.g. 4, or 2|4|8 for a recipe that can be scaled.", example: "4"},
+	{key: "time", description: "How long the recipe takes to make, e.g. 1 hour 30 minutes.", example: "30 minutes"},
+	{key: "course", description: "The course the recipe is for, e.g. breakfast, starter, main or dessert.", example: "main"},
+	{key: "cuisine", description: "The cuisine of the recipe, e.g. Italian.", example: "Italian"},
+	{key: "tags", description: "A comma separated list of tags, e.g. vegetarian, quick.", example: "vegetarian"},
+}
+
+// metadataKeyRegexp matches the text before the cursor when it's in the key
+// of a metadata line.
+var metadataKeyRegexp = regexp.MustCompile(`^>>\s*([^:]*)$`)
+
+// MetadataKeys returns the well-known metadata keys that aren't already in the
+// text. The items insert the key and separator, and a placeholder value if the
+// client supports snippets. ok is false if the position isn't in the key of a
+// metadata line.
+func MetadataKeys(text string, p messages.Position, snippetSupport bool) (items []messages.CompletionItem, ok bool) {
+	if !metadataKeyRegexp.MatchString(recipe.LineBefore(text, p)) {
+		return nil, false
+	}
+	used := map[string]bool{}
+	for _, m := range recipe.ParseMetadata(text) {
+		used[strings.ToLower(m.Key)] = true
+	}
+	items = []messages.CompletionItem{}
+	for i, m := range metadataKeys {
+		if used[m.key] {
+			continue
+		}
+		description := m.description
+		item := messages.CompletionItem{
+			Label:            m.key,
+			Kind:             messages.CompletionItemKindProperty,
+			Documentation:    &messages.StringOrMarkupContent{String: &description},
+			SortText:         fmt.Sprintf("%02d", i),
+			InsertText:       fmt.Sprintf("%s: ${1:%s}", m.key, m.example),
+			InsertTextFormat: messages.InsertTextFormatSnippet,
+		}
+		if !snippetSupport {
+			item.InsertText, item.InsertTextFormat = m.key+": ", messages.InsertTextFormatPlainText
+		}
+		items = append(items, item)
+	}
+	return items, true
+}
@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestMetadataKeys(t *testing.T) {
+	text := ">> servings: 4\n>> Source: https://cooklang.org\n>> \n\nMix the @flour{500%g}."
+	tests := []struct {
+		name       string
+		text       string
+		position   messages.Position
+		expectedOK bool
+		expected   []string
+	}{
+		{
+			name:       "keys that aren't in the document are returned",
+			text:       text,
+			position:   messages.NewPosition(2, 3),
+			expectedOK: true,
+			expected:   []string{"title", "time", "course", "cuisine", "tags"},
+		},
+		{
+			name:       "keys are returned while the key is being typed",
+			text:       ">> cu",
+			position:   messages.NewPosition(0, 5),
+			expectedOK: true,
+			expected:   []string{"title", "source", "servings", "time", "course", "cuisine", "tags"},
+		},
+		{
+			name:       "values are not keys",
+			text:       text,
+			position:   messages.NewPosition(0, 13),
+			expectedOK: false,
+		},
+		{
+			name:       "steps are not metadata",
+			text:       text,
+			position:   messages.NewPosition(4, 3),
+			expectedOK: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := MetadataKeys(test.text, test.position, true)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			var labels []string
+			for i, item := range items {
+				labels = append(labels, item.Label)
+				if i > 0 && items[i-1].SortText >= item.SortText {
+					t.Errorf("expected %q to sort after %q", item.Label, items[i-1].Label)
+				}
+			}
+			if !reflect.DeepEqual(labels, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, labels)
+			}
+		})
+	}
+}
+
+func TestMetadataKeysInsertText(t *testing.T) {
+	t.Run("snippets", func(t *testing.T) {
+		items, _ := MetadataKeys(">> ", messages.NewPosition(0, 3), true)
+		if items[2].InsertText != "servings: ${1:4}" || items[2].InsertTextFormat != messages.InsertTextFormatSnippet {
+			t.Errorf("unexpected insert text: %#v", items[2])
+		}
+		if d := items[2].Documentation; d == nil || d.String == nil || *d.String == "" {
+			t.Errorf("expected documentation, got %#v", d)
+		}
+	})
+	t.Run("plain text", func(t *testing.T) {
+		items, _ := MetadataKeys(">> ", messages.NewPosition(0, 3), false)
+		if items[2].InsertText != "servings: " || items[2].InsertTextFormat != messages.InsertTextFormatPlainText {
+			t.Errorf("unexpected insert text: %#v", items[2])
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package completion
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+	"github.com/a-h/examplelsp/snippet"
+)
+
+// lineStartRegexp matches the text before the cursor when it's at the start
+// of a line, or in a word that starts the line.
+var lineStartRegexp = regexp.MustCompile(`^\s*[\p{L}\p{N}_-]*$`)
+
+// Snippets returns completion items for the snippets, keyed by prefix. ok is
+// false if the position isn't at the start of a line, outside any element,
+// comment or metadata.
+//
+// If the client doesn't support snippets, the items insert the plain text of
+// the snippet instead.
+func Snippets(text string, p messages.Position, snippets map[string]string, snippetSupport bool) (items []messages.CompletionItem, ok bool) {
+	if len(snippets) == 0 || !lineStartRegexp.MatchString(recipe.LineBefore(text, p)) || recipe.InBlockComment(text, p.Line) {
+		return nil, false
+	}
+	items = []messages.CompletionItem{}
+	for prefix, body := range snippets {
+		plain, err := snippet.PlainText(body)
+		if err != nil {
+			continue
+		}
+		item := messages.CompletionItem{
+			Label:            prefix,
+			Kind:             messages.CompletionItemKindSnippet,
+			Detail:           plain,
+			InsertText:       body,
+			InsertTextFormat: messages.InsertTextFormatSnippet,
+		}
+		if !snippetSupport {
+			item.InsertText, item.InsertTextFormat = plain, messages.InsertTextFormatPlainText
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items, true
+}
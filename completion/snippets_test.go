@@ -0,0 +1,100 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestSnippets(t *testing.T) {
+	snippets := map[string]string{
+		"preheat": "Preheat the #oven{} to ${1:200}°C.",
+		"rest":    "Rest for ~{$1%minutes}.",
+	}
+	text := "Boil the @water.\n\npre\n  \nAdd the @salt.\n[- a comment\n\n-]\n>> servings: 2"
+	tests := []struct {
+		name          string
+		position      messages.Position
+		expectedOK    bool
+		expectedItems []string
+	}{
+		{
+			name:          "snippets are offered while typing at the start of a line",
+			position:      messages.NewPosition(2, 3),
+			expectedOK:    true,
+			expectedItems: []string{"preheat", "rest"},
+		},
+		{
+			name:          "snippets are offered on indented blank lines",
+			position:      messages.NewPosition(3, 2),
+			expectedOK:    true,
+			expectedItems: []string{"preheat", "rest"},
+		},
+		{
+			name:       "snippets are not offered after other text",
+			position:   messages.NewPosition(4, 8),
+			expectedOK: false,
+		},
+		{
+			name:       "snippets are not offered in block comments",
+			position:   messages.NewPosition(6, 0),
+			expectedOK: false,
+		},
+		{
+			name:       "snippets are not offered in metadata",
+			position:   messages.NewPosition(8, 2),
+			expectedOK: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := Snippets(text, test.position, snippets, true)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			var labels []string
+			for _, item := range items {
+				labels = append(labels, item.Label)
+			}
+			if !reflect.DeepEqual(labels, test.expectedItems) {
+				t.Errorf("expected %v, got %v", test.expectedItems, labels)
+			}
+		})
+	}
+}
+
+func TestSnippetsPlainTextFallback(t *testing.T) {
+	snippets := map[string]string{"preheat": "Preheat the #oven{} to ${1:200}°C."}
+	tests := []struct {
+		snippetSupport bool
+		expected       messages.CompletionItem
+	}{
+		{
+			snippetSupport: true,
+			expected: messages.CompletionItem{
+				Label:            "preheat",
+				Kind:             messages.CompletionItemKindSnippet,
+				Detail:           "Preheat the #oven{} to 200°C.",
+				InsertText:       "Preheat the #oven{} to ${1:200}°C.",
+				InsertTextFormat: messages.InsertTextFormatSnippet,
+			},
+		},
+		{
+			snippetSupport: false,
+			expected: messages.CompletionItem{
+				Label:            "preheat",
+				Kind:             messages.CompletionItemKindSnippet,
+				Detail:           "Preheat the #oven{} to 200°C.",
+				InsertText:       "Preheat the #oven{} to 200°C.",
+				InsertTextFormat: messages.InsertTextFormatPlainText,
+			},
+		},
+	}
+	for _, test := range tests {
+		items, _ := Snippets("", messages.NewPosition(0, 0), snippets, test.snippetSupport)
+		if len(items) != 1 || !reflect.DeepEqual(items[0], test.expected) {
+			t.Errorf("snippet support %v: expected %#v, got %#v", test.snippetSupport, test.expected, items)
+		}
+	}
+}
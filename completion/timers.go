@@ -0,0 +1,39 @@
+// Package completion provides completion items for recipes.
+package completion
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+var timerNameRegexp = regexp.MustCompile(`~([\p{L}\p{N}_-]*)$`)
+
+// TimerNames returns the names of the timers declared in the text. ok is false
+// if the position isn't within the name of a timer.
+func TimerNames(text string, p messages.Position) (items []messages.CompletionItem, ok bool) {
+	match := timerNameRegexp.FindStringSubmatch(recipe.LineBefore(text, p))
+	if match == nil {
+		return nil, false
+	}
+	prefix := strings.ToLower(match[1])
+	items = []messages.CompletionItem{}
+	for key, d := range recipe.Parse(text).TimerNames() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		documentation := fmt.Sprintf("Started in step %d.", d.StepIndex+1)
+		items = append(items, messages.CompletionItem{
+			Label:         d.Timer.Name,
+			Kind:          messages.CompletionItemKindEvent,
+			Detail:        strings.TrimSpace(d.Timer.Quantity + " " + d.Timer.Unit),
+			Documentation: &messages.StringOrMarkupContent{String: &documentation},
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return items, true
+}
@@ -0,0 +1,66 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestTimerNames(t *testing.T) {
+	text := "Leave to ~marinade{2%hours}.\n\nPut in the #oven{} for ~bake{20%minutes}.\n\nWhen the ~"
+	tests := []struct {
+		name          string
+		text          string
+		position      messages.Position
+		expectedOK    bool
+		expectedNames []string
+	}{
+		{
+			name:          "all timer names are returned after a tilde",
+			text:          text,
+			position:      messages.NewPosition(4, 10),
+			expectedOK:    true,
+			expectedNames: []string{"bake", "marinade"},
+		},
+		{
+			name:          "names are filtered by the typed prefix",
+			text:          text + "Ma",
+			position:      messages.NewPosition(4, 12),
+			expectedOK:    true,
+			expectedNames: []string{"marinade"},
+		},
+		{
+			name:          "no timers match",
+			text:          text + "x",
+			position:      messages.NewPosition(4, 11),
+			expectedOK:    true,
+			expectedNames: []string{},
+		},
+		{
+			name:       "other positions are not timer names",
+			text:       text,
+			position:   messages.NewPosition(2, 10),
+			expectedOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := TimerNames(test.text, test.position)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			if !ok {
+				return
+			}
+			names := []string{}
+			for _, item := range items {
+				names = append(names, item.Label)
+			}
+			if !reflect.DeepEqual(names, test.expectedNames) {
+				t.Errorf("expected %v, got %v", test.expectedNames, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// unitDocumentation of the units offered as completions, keyed by the name of
+// the unit in recipe.IngredientUnits or recipe.TimerUnits. The detail of time
+// units lists the forms that cooklang accepts.
+var unitDocumentation = map[string]struct {
+	detail, description, example string
+}{
+	"g":       {detail: "grams", description: "Grams are a unit of mass", example: "@flour{500%g}"},
+	"kg":      {detail: "kilograms", description: "Kilograms are a unit of mass", example: "@potatoes{1%kg}"},
+	"mg":      {detail: "milligrams", description: "Milligrams are a unit of mass", example: "@saffron{50%mg}"},
+	"oz":      {detail: "ounces", description: "Ounces are an imperial unit of mass, and grams are preferred", example: "@butter{4%oz}"},
+	"lb":      {detail: "pounds", description: "Pounds are an imperial unit of mass", example: "@beef mince{1%lb}"},
+	"ml":      {detail: "milliliters", description: "Milliliters are a unit of volume", example: "@milk{250%ml}"},
+	"cl":      {detail: "centiliters", description: "Centiliters are a unit of volume, equal to 10 ml", example: "@wine{15%cl}"},
+	"dl":      {detail: "deciliters", description: "Deciliters are a unit of volume, equal to 100 ml", example: "@cream{2%dl}"},
+	"l":       {detail: "liters", description: "Liters are a unit of volume", example: "@stock{1.5%l}"},
+	"tsp":     {detail: "teaspoons", description: "Teaspoons are a unit of volume, equal to 5 ml", example: "@salt{1%tsp}"},
+	"tbsp":    {detail: "tablespoons", description: "Tablespoons are a unit of volume, equal to 15 ml", example: "@olive oil{2%tbsp}"},
+	"cup":     {detail: "cups", description: "Cups are a unit of volume that varies between countries, and milliliters are preferred", example: "@rice{1%cup}"},
+	"fl oz":   {detail: "fluid ounces", description: "Fluid ounces are an imperial unit of volume", example: "@cream{4%fl oz}"},
+	"pinch":   {detail: "pinches", description: "A pinch is as much of a dry ingredient as can be held between a finger and thumb", example: "@salt{1%pinch}"},
+	"clove":   {detail: "cloves", description: "Cloves are the segments of a bulb", example: "@garlic{2%cloves}"},
+	"slice":   {detail: "slices", description: "Slices are pieces cut from a larger item", example: "@bread{2%slices}"},
+	"seconds": {detail: "second, seconds", description: "Seconds are a unit of time", example: "~{30%seconds}"},
+	"minutes": {detail: "minute, minutes", description: "Minutes are a unit of time", example: "~bake{25%minutes}"},
+	"hours":   {detail: "hour, hours", description: "Hours are a unit of time", example: "~prove{1%hour}"},
+	"days":    {detail: "day, days", description: "Days are a unit of time", example: "~ferment{3%days}"},
+}
+
+// unitMatches returns true if the name or an alias of the unit starts with
+// the prefix, ignoring case.
+func unitMatches(u recipe.Unit, prefix string) bool {
+	prefix = strings.ToLower(prefix)
+	for _, name := range u.Names() {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// timerUnitRegexp and ingredientUnitRegexp match the text before the cursor
+// when it's after the % in the braces of a timer or ingredient, which may not
+// be closed yet. The unit typed so far is captured.
+var (
+	timerUnitRegexp      = regexp.MustCompile(`~[^@#~{}]*\{[^{}%]*%([^{}]*)$`)
+	ingredientUnitRegexp = regexp.MustCompile(`@[^@#~{}]*\{[^{}%]*%([^{}]*)$`)
+)
+
+// unitData is the data of a unit completion item, used to resolve it.
+type unitData struct {
+	Unit string `json:"unit"`
+}
+
+// UnitsAt returns the completions for the units at the position. Time units
+// are returned after the % of a timer, and the units of ingredient quantities
+// within an ingredient, or at its end. After a %, only the units that start
+// with what's been typed since it are returned. ok is false if the position
+// isn't within a timer's unit or an ingredient.
+func UnitsAt(text string, p messages.Position) (items []messages.CompletionItem, ok bool) {
+	before := recipe.LineBefore(text, p)
+	if match := timerUnitRegexp.FindStringSubmatch(before); match != nil {
+		return typedUnitItems(recipe.TimerUnits, match[1], p), true
+	}
+	if match := ingredientUnitRegexp.FindStringSubmatch(before); match != nil {
+		return typedUnitItems(recipe.IngredientUnits, match[1], p), true
+	}
+	if _, ok := recipe.Parse(text).IngredientAt(p); ok {
+		return Units(), true
+	}
+	return nil, false
+}
+
+// Units returns the completions for the units of ingredient quantities, sorted
+// in the order of recipe.IngredientUnits rather than by label. The items don't have
+// documentation until they're resolved with Resolve.
+func Units() (items []messages.CompletionItem) {
+	return unitItems(recipe.IngredientUnits, "")
+}
+
+// TimerUnits returns the completions for the units of timer durations, in the
+// same way as Units.
+func TimerUnits() (items []messages.CompletionItem) {
+	return unitItems(recipe.TimerUnits, "")
+}
+
+// typedUnitItems returns the items for the units that match what's been typed
+// before the position. The items replace the typed text, which can have more
+// than one word, e.g. "fl o".
+func typedUnitItems(units []recipe.Unit, typed string, p messages.Position) (items []messages.CompletionItem) {
+	typed = strings.TrimLeft(typed, " ")
+	start := messages.NewPosition(p.Line, p.Character-len(utf16.Encode([]rune(typed))))
+	items = unitItems(units, typed)
+	for i := range items {
+		items[i].TextEdit = &messages.TextEditOrInsertReplaceEdit{
+			TextEdit: &messages.TextEdit{Range: messages.Range{Start: start, End: p}, NewText: items[i].Label},
+		}
+	}
+	return items
+}
+
+// unitItems returns the items for the units that match the prefix. Deprecated
+// units are tagged, so that clients can show them struck through.
+func unitItems(units []recipe.Unit, prefix string) (items []messages.CompletionItem) {
+	items = []messages.CompletionItem{}
+	for i, u := range units {
+		if !unitMatches(u, prefix) {
+			continue
+		}
+		data, err := json.Marshal(unitData{Unit: u.Name})
+		if err != nil {
+			continue
+		}
+		items = append(items, messages.CompletionItem{
+			Label:    u.Name,
+			Kind:     messages.CompletionItemKindUnit,
+			Detail:   unitDocumentation[u.Name].detail,
+			SortText: fmt.Sprintf("%02d", i),
+			Data:     data,
+		})
+		if u.Deprecated {
+			items[len(items)-1].Tags = []messages.CompletionItemTag{messages.CompletionItemTagDeprecated}
+		}
+	}
+	return items
+}
+
+// Resolve fills in the documentation of an item returned by Units or
+// TimerUnits, in Markdown if markdown is true, and in plain text otherwise.
+// Other items are returned unchanged.
+func Resolve(item messages.CompletionItem, markdown bool) (resolved messages.CompletionItem) {
+	var data unitData
+	if len(item.Data) == 0 || json.Unmarshal(item.Data, &data) != nil {
+		return item
+	}
+	u, ok := unitDocumentation[data.Unit]
+	if !ok {
+		return item
+	}
+	if markdown {
+		item.Documentation = &messages.StringOrMarkupContent{
+			MarkupContent: &messages.MarkupContent{
+				Kind:  messages.MarkupKindMarkdown,
+				Value: fmt.Sprintf("%s, e.g.\n\n```cooklang\n%s\n```", u.description, u.example),
+			},
+		}
+		return item
+	}
+	text := fmt.Sprintf("%s, e.g. %s", u.description, u.example)
+	item.Documentation = &messages.StringOrMarkupContent{String: &text}
+	return item
+}
@@ -0,0 +1,193 @@
+package completion
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+func TestUnits(t *testing.T) {
+	items := Units()
+	if len(items) != len(recipe.IngredientUnits) {
+		t.Fatalf("expected %d items, got %d", len(recipe.IngredientUnits), len(items))
+	}
+	for i, item := range items {
+		if item.Documentation != nil {
+			t.Errorf("expected the documentation of %q to be left for resolve", item.Label)
+		}
+		if item.Detail == "" || Resolve(item, false).Documentation == nil {
+			t.Errorf("expected %q to be documented", item.Label)
+		}
+		if i > 0 && items[i-1].SortText >= item.SortText {
+			t.Errorf("expected %q to sort after %q", item.Label, items[i-1].Label)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	// The client sends the item back as it was received.
+	data, err := json.Marshal(Units()[1])
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var item messages.CompletionItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		resolved := Resolve(item, true)
+		if resolved.Label != "kg" || resolved.Detail != "kilograms" || resolved.SortText != item.SortText || string(resolved.Data) != string(item.Data) {
+			t.Errorf("expected the other fields to be kept, got %#v", resolved)
+		}
+		if d := resolved.Documentation; d == nil || d.MarkupContent == nil || d.MarkupContent.Kind != messages.MarkupKindMarkdown {
+			t.Fatalf("expected Markdown documentation, got %#v", d)
+		}
+		if expected := "Kilograms are a unit of mass, e.g.\n\n```cooklang\n@potatoes{1%kg}\n```"; resolved.Documentation.MarkupContent.Value != expected {
+			t.Errorf("expected %q, got %q", expected, resolved.Documentation.MarkupContent.Value)
+		}
+	})
+	t.Run("plain text", func(t *testing.T) {
+		resolved := Resolve(item, false)
+		if d := resolved.Documentation; d == nil || d.String == nil || *d.String != "Kilograms are a unit of mass, e.g. @potatoes{1%kg}" {
+			t.Errorf("expected plain text documentation, got %#v", d)
+		}
+	})
+	t.Run("other items are unchanged", func(t *testing.T) {
+		other := messages.CompletionItem{Label: "bake", Data: json.RawMessage(`{"unit":"cups"}`)}
+		if resolved := Resolve(other, true); resolved.Documentation != nil {
+			t.Errorf("expected no documentation, got %#v", resolved.Documentation)
+		}
+		if resolved := Resolve(messages.CompletionItem{Label: "bake"}, true); resolved.Documentation != nil {
+			t.Errorf("expected no documentation, got %#v", resolved.Documentation)
+		}
+	})
+}
+
+func TestUnitsAt(t *testing.T) {
+	labels := func(items []messages.CompletionItem) (labels []string) {
+		for _, item := range items {
+			labels = append(labels, item.Label)
+		}
+		return labels
+	}
+	timer, ingredient := labels(TimerUnits()), labels(Units())
+	tests := []struct {
+		name       string
+		text       string
+		position   messages.Position
+		expectedOK bool
+		expected   []string
+	}{
+		{
+			name:       "time units are returned after the % of a timer",
+			text:       "Bake for ~{10%",
+			position:   messages.NewPosition(0, 14),
+			expectedOK: true,
+			expected:   timer,
+		},
+		{
+			name:       "time units are filtered while typing the unit of a named timer",
+			text:       "Add the @flour{500%g}, then ~prove{1%h}.",
+			position:   messages.NewPosition(0, 38),
+			expectedOK: true,
+			expected:   []string{"hours"},
+		},
+		{
+			name:       "ingredient units are returned after the % of an ingredient",
+			text:       "Bake for ~{10%minutes} with @flour{500%",
+			position:   messages.NewPosition(0, 39),
+			expectedOK: true,
+			expected:   ingredient,
+		},
+		{
+			name:       "ingredient units are returned at the end of an ingredient",
+			text:       "Add the @flour{500%g}, then ~prove{1%hour}.",
+			position:   messages.NewPosition(0, 21),
+			expectedOK: true,
+			expected:   ingredient,
+		},
+		{
+			name:       "text has no units",
+			text:       "Add the @flour{500%g}, then ~prove{1%hour}.",
+			position:   messages.NewPosition(0, 25),
+			expectedOK: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, ok := UnitsAt(test.text, test.position)
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok %v, got %v", test.expectedOK, ok)
+			}
+			if actual := labels(items); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestResolveTimerUnits(t *testing.T) {
+	resolved := Resolve(TimerUnits()[1], false)
+	if resolved.Detail != "minute, minutes" {
+		t.Errorf("expected the forms of the unit in the detail, got %q", resolved.Detail)
+	}
+	if d := resolved.Documentation; d == nil || d.String == nil || *d.String != "Minutes are a unit of time, e.g. ~bake{25%minutes}" {
+		t.Errorf("expected plain text documentation, got %#v", d)
+	}
+}
+
+func TestUnitsAtPrefix(t *testing.T) {
+	tests := []struct {
+		typed    string
+		expected []string
+	}{
+		{typed: "", expected: []string{"g", "kg", "mg", "oz", "lb", "ml", "cl", "dl", "l", "tsp", "tbsp", "cup", "fl oz", "pinch", "clove", "slice"}},
+		{typed: "ts", expected: []string{"tsp"}},
+		{typed: "T", expected: []string{"tsp", "tbsp"}},
+		{typed: "gram", expected: []string{"g"}},
+		{typed: "l", expected: []string{"lb", "l"}},
+		{typed: "litres", expected: []string{"l"}},
+		{typed: "fl o", expected: []string{"fl oz"}},
+		{typed: " c", expected: []string{"cl", "cup", "clove"}},
+		{typed: "x", expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.typed, func(t *testing.T) {
+			text := "Add the @olive oil{2%" + test.typed
+			position := messages.NewPosition(0, len(text))
+			items, ok := UnitsAt(text, position)
+			if !ok {
+				t.Fatal("expected units")
+			}
+			var labels []string
+			for _, item := range items {
+				labels = append(labels, item.Label)
+				if item.TextEdit == nil || item.TextEdit.TextEdit == nil {
+					t.Fatalf("expected %q to have an edit", item.Label)
+				}
+				edit := item.TextEdit.TextEdit
+				start := messages.NewPosition(0, len(text)-len(strings.TrimLeft(test.typed, " ")))
+				if edit.Range != (messages.Range{Start: start, End: position}) || edit.NewText != item.Label {
+					t.Errorf("expected %q to replace the typed text, got %#v", item.Label, edit)
+				}
+			}
+			if !reflect.DeepEqual(labels, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, labels)
+			}
+		})
+	}
+}
+
+func TestUnitsDeprecated(t *testing.T) {
+	for _, item := range Units() {
+		deprecated := len(item.Tags) == 1 && item.Tags[0] == messages.CompletionItemTagDeprecated
+		if expected := item.Label == "cup" || item.Label == "oz"; deprecated != expected {
+			t.Errorf("expected %q deprecated to be %v, got tags %v", item.Label, expected, item.Tags)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package definition finds the definitions of elements in recipes.
+package definition
+
+import (
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Timer returns the declaration of the named timer at the position, which is
+// the first use of the timer that has a duration. If linkSupport is true, a
+// LocationLink is returned, which targets the step that starts the timer, and
+// selects the name of the timer.
+func Timer(uri, text string, p messages.Position, linkSupport bool) (result messages.DefinitionResult) {
+	r := recipe.Parse(text)
+	for _, step := range r.Steps {
+		for _, timer := range step.Timers {
+			if timer.Name == "" || !timer.Range.Contains(p) {
+				continue
+			}
+			d, ok := r.TimerNames()[strings.ToLower(timer.Name)]
+			if !ok {
+				return result
+			}
+			if !linkSupport {
+				result.Location = &messages.Location{URI: uri, Range: d.Timer.Range}
+				return result
+			}
+			result.LocationLinks = []messages.LocationLink{{
+				OriginSelectionRange: &timer.Range,
+				TargetURI:            uri,
+				TargetRange:          r.Steps[d.StepIndex].Range,
+				TargetSelectionRange: d.Timer.NameRange,
+			}}
+			return result
+		}
+	}
+	return result
+}
+
+// Ingredient returns the first use of the ingredient at the position, which is
+// usually where its quantity is given. The first use is its own definition.
+func Ingredient(uri, text string, p messages.Position, linkSupport bool) (result messages.DefinitionResult) {
+	return firstUse(uri, recipe.Parse(text), p, linkSupport, func(step recipe.Step) (uses []use) {
+		for _, ingredient := range step.Ingredients {
+			uses = append(uses, use{name: ingredient.Name, rng: ingredient.Range, nameRange: ingredient.NameRange})
+		}
+		return uses
+	})
+}
+
+// Cookware returns the first use of the cookware at the position.
+func Cookware(uri, text string, p messages.Position, linkSupport bool) (result messages.DefinitionResult) {
+	return firstUse(uri, recipe.Parse(text), p, linkSupport, func(step recipe.Step) (uses []use) {
+		for _, cookware := range step.Cookware {
+			uses = append(uses, use{name: cookware.Name, rng: cookware.Range, nameRange: cookware.NameRange})
+		}
+		return uses
+	})
+}
+
+// use of a named element in a step.
+type use struct {
+	name      string
+	rng       messages.Range
+	nameRange messages.Range
+}
+
+// firstUse returns the first use of the element at the position, matching
+// names case insensitively. If linkSupport is true, a LocationLink is
+// returned, which targets the step of the first use, and selects its name.
+func firstUse(uri string, r recipe.Recipe, p messages.Position, linkSupport bool, uses func(step recipe.Step) []use) (result messages.DefinitionResult) {
+	var origin *use
+	for _, step := range r.Steps {
+		for _, u := range uses(step) {
+			if u.rng.Contains(p) {
+				u := u
+				origin = &u
+			}
+		}
+	}
+	if origin == nil {
+		return result
+	}
+	for _, step := range r.Steps {
+		for _, u := range uses(step) {
+			if !strings.EqualFold(u.name, origin.name) {
+				continue
+			}
+			if !linkSupport {
+				result.Location = &messages.Location{URI: uri, Range: u.rng}
+				return result
+			}
+			result.LocationLinks = []messages.LocationLink{{
+				OriginSelectionRange: &origin.rng,
+				TargetURI:            uri,
+				TargetRange:          step.Range,
+				TargetSelectionRange: u.nameRange,
+			}}
+			return result
+		}
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package definition
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestTimer(t *testing.T) {
+	uri := "file:///recipes/bread.cook"
+	text := "Knead the dough.\nLeave to ~prove{1%hour}.\n\nWhen the ~Prove{} is done, bake. Rest for ~{5%minutes}.\n\nWhen the ~cool{} is done, slice."
+	rng := func(startLine, startChar, endLine, endChar int) messages.Range {
+		return messages.Range{Start: messages.NewPosition(startLine, startChar), End: messages.NewPosition(endLine, endChar)}
+	}
+	tests := []struct {
+		name        string
+		position    messages.Position
+		linkSupport bool
+		expected    messages.DefinitionResult
+	}{
+		{
+			name:     "references return the location of the declaration",
+			position: messages.NewPosition(3, 10),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(1, 9, 1, 23)},
+			},
+		},
+		{
+			name:        "links target the step, and select the name",
+			position:    messages.NewPosition(3, 10),
+			linkSupport: true,
+			expected: messages.DefinitionResult{
+				LocationLinks: []messages.LocationLink{{
+					OriginSelectionRange: &messages.Range{Start: messages.NewPosition(3, 9), End: messages.NewPosition(3, 17)},
+					TargetURI:            uri,
+					TargetRange:          rng(0, 0, 1, 24),
+					TargetSelectionRange: rng(1, 10, 1, 15),
+				}},
+			},
+		},
+		{
+			name:     "the declaration is its own definition",
+			position: messages.NewPosition(1, 12),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(1, 9, 1, 23)},
+			},
+		},
+		{
+			name:     "undeclared timers have no definition",
+			position: messages.NewPosition(5, 10),
+		},
+		{
+			name:     "unnamed timers have no definition",
+			position: messages.NewPosition(3, 44),
+		},
+		{
+			name:     "text has no definition",
+			position: messages.NewPosition(0, 2),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Timer(uri, text, test.position, test.linkSupport)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIngredientAndCookware(t *testing.T) {
+	uri := "file:///recipes/stir-fry.cook"
+	text := "Heat the @oil{1%tbsp} in a #wok{}.\n\nAdd the @soy sauce{2%tbsp} and more @Oil.\n\nServe from the #Wok with @soy sauce{}."
+	rng := func(startLine, startChar, endLine, endChar int) messages.Range {
+		return messages.Range{Start: messages.NewPosition(startLine, startChar), End: messages.NewPosition(endLine, endChar)}
+	}
+	tests := []struct {
+		name        string
+		definition  func(uri, text string, p messages.Position, linkSupport bool) messages.DefinitionResult
+		position    messages.Position
+		linkSupport bool
+		expected    messages.DefinitionResult
+	}{
+		{
+			name:       "ingredients return their first use",
+			definition: Ingredient,
+			position:   messages.NewPosition(2, 38),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(0, 9, 0, 21)},
+			},
+		},
+		{
+			name:       "the first use of an ingredient is its own definition",
+			definition: Ingredient,
+			position:   messages.NewPosition(2, 12),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(2, 8, 2, 26)},
+			},
+		},
+		{
+			name:        "ingredient links target the step, and select the name",
+			definition:  Ingredient,
+			position:    messages.NewPosition(4, 30),
+			linkSupport: true,
+			expected: messages.DefinitionResult{
+				LocationLinks: []messages.LocationLink{{
+					OriginSelectionRange: &messages.Range{Start: messages.NewPosition(4, 25), End: messages.NewPosition(4, 37)},
+					TargetURI:            uri,
+					TargetRange:          rng(2, 0, 2, 41),
+					TargetSelectionRange: rng(2, 9, 2, 18),
+				}},
+			},
+		},
+		{
+			name:       "cookware returns its first use",
+			definition: Cookware,
+			position:   messages.NewPosition(4, 17),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(0, 27, 0, 33)},
+			},
+		},
+		{
+			name:       "the first use of cookware is its own definition",
+			definition: Cookware,
+			position:   messages.NewPosition(0, 29),
+			expected: messages.DefinitionResult{
+				Location: &messages.Location{URI: uri, Range: rng(0, 27, 0, 33)},
+			},
+		},
+		{
+			name:       "text is not an ingredient",
+			definition: Ingredient,
+			position:   messages.NewPosition(0, 2),
+		},
+		{
+			name:       "cookware is not an ingredient",
+			definition: Ingredient,
+			position:   messages.NewPosition(0, 29),
+		},
+		{
+			name:       "ingredients are not cookware",
+			definition: Cookware,
+			position:   messages.NewPosition(0, 12),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.definition(uri, text, test.position, test.linkSupport)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPatchMismatch is returned by Apply when the diff does not match the text.
+var ErrPatchMismatch = errors.New("diff: patch does not match text")
+
+// Apply applies a unified diff created by Unified to text.
+func Apply(text, unified string) (string, error) {
+	if unified == "" {
+		return text, nil
+	}
+	lines := splitLines(text)
+	patch := splitLines(unified)
+	var out []string
+	// next is the index of the next line of text to copy.
+	var next int
+	for i := 0; i < len(patch); i++ {
+		if strings.HasPrefix(patch[i], "---") || strings.HasPrefix(patch[i], "+++") {
+			continue
+		}
+		if !strings.HasPrefix(patch[i], "@@ ") {
+			return "", fmt.Errorf("diff: unexpected line %q", patch[i])
+		}
+		start, err := parseHunkStart(patch[i])
+		if err != nil {
+			return "", err
+		}
+		if start < next || start > len(lines) {
+			return "", ErrPatchMismatch
+		}
+		out = append(out, lines[next:start]...)
+		next = start
+		for i+1 < len(patch) && !strings.HasPrefix(patch[i+1], "@@ ") {
+			i++
+			line := patch[i]
+			if i+1 < len(patch) && strings.HasPrefix(patch[i+1], `\`) {
+				line = strings.TrimSuffix(line, "\n")
+				i++
+			}
+			content := line[1:]
+			switch opKind(line[0]) {
+			case opEqual, opDelete:
+				if next >= len(lines) || lines[next] != content {
+					return "", ErrPatchMismatch
+				}
+				if opKind(line[0]) == opEqual {
+					out = append(out, content)
+				}
+				next++
+			case opInsert:
+				out = append(out, content)
+			default:
+				return "", fmt.Errorf("diff: unexpected line %q", line)
+			}
+		}
+	}
+	out = append(out, lines[next:]...)
+	return strings.Join(out, ""), nil
+}
+
+// parseHunkStart returns the zero-based index of the first line of the
+// original text that the hunk header refers to.
+func parseHunkStart(header string) (start int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("diff: invalid hunk header %q", header)
+	}
+	r, count, hasCount := strings.Cut(fields[1][1:], ",")
+	if start, err = strconv.Atoi(r); err != nil {
+		return 0, fmt.Errorf("diff: invalid hunk header %q: %w", header, err)
+	}
+	// Empty ranges refer to the line before the hunk.
+	if hasCount && count == "0" {
+		return start, nil
+	}
+	return start - 1, nil
+}
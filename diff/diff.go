@@ -0,0 +1,164 @@
+// Package diff creates unified diffs between two versions of a text.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// a and b are the zero-based indices of the line in the before and after
+	// texts, or the index the line would be at if it is not present.
+	a, b int
+}
+
+// Unified returns a unified diff between before and after, using name as the
+// file name in the header. If the texts are equal, an empty string is
+// returned.
+func Unified(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	name = strings.TrimPrefix(name, "/")
+	ops := diffLines(splitLines(before), splitLines(after))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+	for _, h := range hunks(ops) {
+		writeHunk(&sb, h)
+	}
+	return sb.String()
+}
+
+// splitLines splits the text into lines, keeping the line endings, so that a
+// missing newline at the end of the text is a difference.
+func splitLines(s string) (lines []string) {
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return append(lines, s)
+		}
+		lines = append(lines, s[:i+1])
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// diffLines returns the edit script that turns a into b, using the longest
+// common subsequence of lines. Recipes are small, so the quadratic table is
+// not a concern.
+func diffLines(a, b []string) (ops []op) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+				continue
+			}
+			lcs[i][j] = lcs[i+1][j]
+			if lcs[i][j+1] > lcs[i][j] {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var i, j int
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], a: i, b: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
+
+// hunks groups the changes in ops, along with their surrounding context.
+func hunks(ops []op) (groups [][]op) {
+	var current []op
+	var lastChange int
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		// Merge changes whose context would overlap or touch.
+		if current != nil && i-lastChange-1 <= contextLines*2 {
+			current = append(current, ops[lastChange+1:i+1]...)
+		} else {
+			if current != nil {
+				groups = append(groups, append(current, trailingContext(ops, lastChange)...))
+			}
+			current = append([]op{}, ops[start:i+1]...)
+		}
+		lastChange = i
+	}
+	if current != nil {
+		groups = append(groups, append(current, trailingContext(ops, lastChange)...))
+	}
+	return groups
+}
+
+func trailingContext(ops []op, lastChange int) []op {
+	end := lastChange + 1 + contextLines
+	if end > len(ops) {
+		end = len(ops)
+	}
+	return ops[lastChange+1 : end]
+}
+
+func writeHunk(sb *strings.Builder, h []op) {
+	var aCount, bCount int
+	for _, o := range h {
+		if o.kind != opInsert {
+			aCount++
+		}
+		if o.kind != opDelete {
+			bCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(h[0].a, aCount), hunkRange(h[0].b, bCount))
+	for _, o := range h {
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the one-based start line and line count of a hunk. An
+// empty range refers to the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
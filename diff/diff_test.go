@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		expected string
+	}{
+		{
+			name:     "equal texts produce no diff",
+			before:   "a\nb\n",
+			after:    "a\nb\n",
+			expected: "",
+		},
+		{
+			name:   "a changed line is shown with context",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: `--- a/recipe.cook
++++ b/recipe.cook
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:   "distant changes are split into hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			after:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: `--- a/recipe.cook
++++ b/recipe.cook
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+		{
+			name:   "a missing newline at the end of the text is marked",
+			before: "a\nb",
+			after:  "a\nb\n",
+			expected: `--- a/recipe.cook
++++ b/recipe.cook
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+		{
+			name:   "insertions into empty texts",
+			before: "",
+			after:  "a\n",
+			expected: `--- a/recipe.cook
++++ b/recipe.cook
+@@ -0,0 +1 @@
++a
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Unified("recipe.cook", test.before, test.after)
+			if actual != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{
+			name:   "no changes",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+		},
+		{
+			name:   "trailing whitespace removed",
+			before: "Boil @water{1%l}.  \nAdd @salt.\t\n\nServe.\n",
+			after:  "Boil @water{1%l}.\nAdd @salt.\n\nServe.\n",
+		},
+		{
+			name:   "lines removed from the start and end",
+			before: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n",
+			after:  "b\nc\nd\ne\nf\ng\nh\ni\nj\n",
+		},
+		{
+			name:   "lines inserted into an empty text",
+			before: "",
+			after:  "a\nb\n",
+		},
+		{
+			name:   "all lines removed",
+			before: "a\nb",
+			after:  "",
+		},
+		{
+			name:   "newline added at the end",
+			before: "a\nb",
+			after:  "a\nb\n",
+		},
+		{
+			name:   "many changes",
+			before: strings.Repeat("step\nother\n\n", 10),
+			after:  strings.Repeat("step\n\nadded\n", 10),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Apply(test.before, Unified("recipe.cook", test.before, test.after))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.after {
+				t.Errorf("expected %q, got %q", test.after, actual)
+			}
+		})
+	}
+	t.Run("mismatched text", func(t *testing.T) {
+		_, err := Apply("x\ny\n", Unified("recipe.cook", "a\nb\n", "a\nc\n"))
+		if err != ErrPatchMismatch {
+			t.Errorf("expected ErrPatchMismatch, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+package documents
+
+import "strings"
+
+// BOM is the UTF-8 encoding of the byte order mark. Editors don't display it,
+// and clients don't count it when computing positions on the first line.
+const BOM = "\uFEFF"
+
+// TrimBOM removes the byte order mark from the start of the text, if present.
+func TrimBOM(text string) (trimmed string, hadBOM bool) {
+	trimmed = strings.TrimPrefix(text, BOM)
+	return trimmed, len(trimmed) != len(text)
+}
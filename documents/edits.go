@@ -0,0 +1,98 @@
+package documents
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+var (
+	// ErrOverlappingEdits is returned when edits to a document overlap.
+	ErrOverlappingEdits = errors.New("documents: edits overlap")
+	// ErrInvalidRange is returned when an edit refers to a line that isn't in
+	// the document.
+	ErrInvalidRange = errors.New("documents: edit range is outside of the document")
+)
+
+// ApplyEdits applies the edits to the text, in the same way as a client
+// applying a WorkspaceEdit. All positions refer to the original text. A byte
+// order mark at the start of the text isn't counted in positions, and is kept.
+func ApplyEdits(text string, edits []messages.TextEdit) (string, error) {
+	if trimmed, hadBOM := TrimBOM(text); hadBOM {
+		applied, err := ApplyEdits(trimmed, edits)
+		if err != nil {
+			return text, err
+		}
+		return BOM + applied, nil
+	}
+	type replacement struct {
+		start, end int
+		newText    string
+	}
+	replacements := make([]replacement, len(edits))
+	for i, edit := range edits {
+		start, err := offset(text, edit.Range.Start)
+		if err != nil {
+			return text, err
+		}
+		end, err := offset(text, edit.Range.End)
+		if err != nil {
+			return text, err
+		}
+		if end < start {
+			return text, ErrInvalidRange
+		}
+		replacements[i] = replacement{start: start, end: end, newText: edit.NewText}
+	}
+	// Keep insertions at the same position in the order they were given.
+	sort.SliceStable(replacements, func(i, j int) bool {
+		return replacements[i].start < replacements[j].start
+	})
+	var sb strings.Builder
+	var last int
+	for _, r := range replacements {
+		if r.start < last {
+			return text, ErrOverlappingEdits
+		}
+		sb.WriteString(text[last:r.start])
+		sb.WriteString(r.newText)
+		last = r.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), nil
+}
+
+// offset converts a position into a byte offset within the text. Characters
+// past the end of a line refer to the end of the line.
+func offset(text string, p messages.Position) (o int, err error) {
+	for i := 0; i < p.Line; i++ {
+		next := strings.IndexByte(text[o:], '\n')
+		if next < 0 {
+			return 0, ErrInvalidRange
+		}
+		o += next + 1
+	}
+	var character int
+	for i, r := range text[o:] {
+		if character >= p.Character || r == '\n' {
+			return o + i, nil
+		}
+		character += len(utf16.Encode([]rune{r}))
+	}
+	return len(text), nil
+}
+
+// PositionAt converts a byte offset within the text into a position. Offsets
+// past the end of the text refer to the end of the text.
+func PositionAt(text string, o int) (p messages.Position) {
+	if o > len(text) {
+		o = len(text)
+	}
+	lineStart := strings.LastIndexByte(text[:o], '\n') + 1
+	p.Line = strings.Count(text[:lineStart], "\n")
+	p.Character = len(utf16.Encode([]rune(text[lineStart:o])))
+	return p
+}
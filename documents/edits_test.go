@@ -0,0 +1,126 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestApplyEdits(t *testing.T) {
+	edit := func(startLine, startChar, endLine, endChar int, newText string) messages.TextEdit {
+		return messages.TextEdit{
+			Range: messages.Range{
+				Start: messages.NewPosition(startLine, startChar),
+				End:   messages.NewPosition(endLine, endChar),
+			},
+			NewText: newText,
+		}
+	}
+	tests := []struct {
+		name          string
+		text          string
+		edits         []messages.TextEdit
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "no edits",
+			text:     "Boil @water.",
+			expected: "Boil @water.",
+		},
+		{
+			name: "byte order marks are not counted, and are kept",
+			text: "\uFEFFBoil @water.  \nAdd @salt.\n",
+			edits: []messages.TextEdit{
+				edit(0, 12, 0, 14, ""),
+			},
+			expected: "\uFEFFBoil @water.\nAdd @salt.\n",
+		},
+		{
+			name: "edits are applied relative to the original text",
+			text: "Boil @water.  \nAdd @salt.\t\n",
+			edits: []messages.TextEdit{
+				edit(1, 10, 1, 11, ""),
+				edit(0, 12, 0, 14, ""),
+			},
+			expected: "Boil @water.\nAdd @salt.\n",
+		},
+		{
+			name: "positions are in UTF-16 code units",
+			text: "Add 🧂 @salt.",
+			edits: []messages.TextEdit{
+				edit(0, 4, 0, 7, ""),
+			},
+			expected: "Add @salt.",
+		},
+		{
+			name: "characters past the end of the line refer to the end of the line",
+			text: "Boil.\nServe.",
+			edits: []messages.TextEdit{
+				edit(0, 100, 0, 100, " Stir."),
+			},
+			expected: "Boil. Stir.\nServe.",
+		},
+		{
+			name: "insertions at the same position keep their order",
+			text: "Serve.",
+			edits: []messages.TextEdit{
+				edit(0, 0, 0, 0, "Boil. "),
+				edit(0, 0, 0, 0, "Stir. "),
+			},
+			expected: "Boil. Stir. Serve.",
+		},
+		{
+			name: "overlapping edits are rejected",
+			text: "Boil @water.",
+			edits: []messages.TextEdit{
+				edit(0, 0, 0, 6, ""),
+				edit(0, 5, 0, 8, ""),
+			},
+			expected:      "Boil @water.",
+			expectedError: ErrOverlappingEdits,
+		},
+		{
+			name: "lines outside the document are rejected",
+			text: "Boil @water.",
+			edits: []messages.TextEdit{
+				edit(2, 0, 2, 1, ""),
+			},
+			expected:      "Boil @water.",
+			expectedError: ErrInvalidRange,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ApplyEdits(test.text, test.edits)
+			if err != test.expectedError {
+				t.Fatalf("expected error %v, got %v", test.expectedError, err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	text := "Heat to 230°C.\n🧂 @salt\n"
+	for _, p := range []messages.Position{
+		messages.NewPosition(0, 0),
+		messages.NewPosition(0, 14),
+		messages.NewPosition(1, 0),
+		messages.NewPosition(1, 3),
+		messages.NewPosition(2, 0),
+	} {
+		o, err := offset(text, p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := PositionAt(text, o); actual != p {
+			t.Errorf("expected %v, got %v", p, actual)
+		}
+	}
+	if actual := PositionAt(text, len(text)+10); actual != messages.NewPosition(2, 0) {
+		t.Errorf("expected offsets past the end to refer to the end, got %v", actual)
+	}
+}
@@ -0,0 +1,123 @@
+package documents
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// Positions are computed in UTF-16 code units throughout examplelsp. The
+// functions below convert them to and from the position encoding negotiated
+// with the client.
+
+// EncodePosition converts a position in UTF-16 code units into the encoding.
+func EncodePosition(text string, p messages.Position, encoding messages.PositionEncodingKind) messages.Position {
+	return convertPosition(text, p, utf16Units, units(encoding))
+}
+
+// EncodeRange converts a range in UTF-16 code units into the encoding.
+func EncodeRange(text string, r messages.Range, encoding messages.PositionEncodingKind) messages.Range {
+	return messages.Range{
+		Start: EncodePosition(text, r.Start, encoding),
+		End:   EncodePosition(text, r.End, encoding),
+	}
+}
+
+// DecodePosition converts a position in the encoding into UTF-16 code units.
+func DecodePosition(text string, p messages.Position, encoding messages.PositionEncodingKind) messages.Position {
+	return convertPosition(text, p, units(encoding), utf16Units)
+}
+
+// DecodeRange converts a range in the encoding into UTF-16 code units.
+func DecodeRange(text string, r messages.Range, encoding messages.PositionEncodingKind) messages.Range {
+	return messages.Range{
+		Start: DecodePosition(text, r.Start, encoding),
+		End:   DecodePosition(text, r.End, encoding),
+	}
+}
+
+// EncodeWorkspaceEdit returns a copy of the edit, with the ranges of its text
+// edits converted from UTF-16 into the encoding, using the text of each
+// document. File operations are copied unchanged.
+func EncodeWorkspaceEdit(edit messages.WorkspaceEdit, text TextFunc, encoding messages.PositionEncodingKind) messages.WorkspaceEdit {
+	if encoding == messages.PositionEncodingKindUTF16 {
+		return edit
+	}
+	encode := func(uri string, edits []messages.TextEdit) []messages.TextEdit {
+		t, _ := text(uri)
+		encoded := make([]messages.TextEdit, len(edits))
+		for i, e := range edits {
+			e.Range = EncodeRange(t, e.Range, encoding)
+			encoded[i] = e
+		}
+		return encoded
+	}
+	encoded := messages.WorkspaceEdit{ChangeAnnotations: edit.ChangeAnnotations}
+	if edit.Changes != nil {
+		encoded.Changes = make(map[string][]messages.TextEdit, len(edit.Changes))
+		for uri, edits := range edit.Changes {
+			encoded.Changes[uri] = encode(uri, edits)
+		}
+	}
+	if edit.DocumentChanges != nil {
+		encoded.DocumentChanges = make([]messages.DocumentChange, len(edit.DocumentChanges))
+		for i, change := range edit.DocumentChanges {
+			if e := change.TextDocumentEdit; e != nil {
+				t, _ := text(e.TextDocument.URI)
+				documentEdit := *e
+				documentEdit.Edits = make([]messages.AnnotatedTextEdit, len(e.Edits))
+				for j, annotated := range e.Edits {
+					annotated.Range = EncodeRange(t, annotated.Range, encoding)
+					documentEdit.Edits[j] = annotated
+				}
+				change.TextDocumentEdit = &documentEdit
+			}
+			encoded.DocumentChanges[i] = change
+		}
+	}
+	return encoded
+}
+
+// units returns the number of code units that a rune takes in the encoding.
+// Unknown encodings are treated as UTF-16, the default.
+func units(encoding messages.PositionEncodingKind) func(r rune) int {
+	switch encoding {
+	case messages.PositionEncodingKindUTF8:
+		return utf8.RuneLen
+	case messages.PositionEncodingKindUTF32:
+		return func(r rune) int { return 1 }
+	}
+	return utf16Units
+}
+
+func utf16Units(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// convertPosition converts the character of the position from one unit to
+// another, using the line of the text that the position is on. Characters
+// past the end of the line are kept past the end of the line.
+func convertPosition(text string, p messages.Position, from, to func(r rune) int) messages.Position {
+	text, _ = TrimBOM(text)
+	lines := strings.Split(text, "\n")
+	if p.Line < 0 || p.Line >= len(lines) {
+		return p
+	}
+	line := strings.TrimSuffix(lines[p.Line], "\r")
+	var fromCharacter, toCharacter int
+	for _, r := range line {
+		if fromCharacter >= p.Character {
+			break
+		}
+		fromCharacter += from(r)
+		toCharacter += to(r)
+	}
+	if p.Character > fromCharacter {
+		toCharacter += p.Character - fromCharacter
+	}
+	return messages.NewPosition(p.Line, toCharacter)
+}
@@ -0,0 +1,132 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestEncodePosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		position messages.Position
+		encoding messages.PositionEncodingKind
+		expected messages.Position
+	}{
+		{
+			name:     "ASCII is the same in UTF-8",
+			text:     "Add @salt{}.",
+			position: messages.NewPosition(0, 5),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(0, 5),
+		},
+		{
+			name:     "characters after é are one byte further on in UTF-8",
+			text:     "Add @café{}.",
+			position: messages.NewPosition(0, 10),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(0, 11),
+		},
+		{
+			name:     "only the line of the position is used",
+			text:     "Add @café{}.\nStir.",
+			position: messages.NewPosition(1, 4),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(1, 4),
+		},
+		{
+			name:     "emoji are four bytes in UTF-8",
+			text:     "Add 🧂 to taste.",
+			position: messages.NewPosition(0, 7),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(0, 9),
+		},
+		{
+			name:     "emoji are a single code point in UTF-32",
+			text:     "Add 🧂 to taste.",
+			position: messages.NewPosition(0, 7),
+			encoding: messages.PositionEncodingKindUTF32,
+			expected: messages.NewPosition(0, 6),
+		},
+		{
+			name:     "UTF-16 is unchanged",
+			text:     "Add 🧂 to taste.",
+			position: messages.NewPosition(0, 7),
+			encoding: messages.PositionEncodingKindUTF16,
+			expected: messages.NewPosition(0, 7),
+		},
+		{
+			name:     "byte order marks are not counted",
+			text:     BOM + "Add @café{}.",
+			position: messages.NewPosition(0, 10),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(0, 11),
+		},
+		{
+			name:     "positions past the end of the line are kept past the end",
+			text:     "é\nStir.",
+			position: messages.NewPosition(0, 3),
+			encoding: messages.PositionEncodingKindUTF8,
+			expected: messages.NewPosition(0, 4),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := EncodePosition(test.text, test.position, test.encoding)
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+			if decoded := DecodePosition(test.text, actual, test.encoding); decoded != test.position {
+				t.Errorf("expected decoding to return %v, got %v", test.position, decoded)
+			}
+		})
+	}
+}
+
+func TestEncodeWorkspaceEdit(t *testing.T) {
+	rng := func(startLine, startCharacter, endLine, endCharacter int) messages.Range {
+		return messages.Range{
+			Start: messages.NewPosition(startLine, startCharacter),
+			End:   messages.NewPosition(endLine, endCharacter),
+		}
+	}
+	texts := map[string]string{
+		"file:///a.cook": "Add @café{}.",
+		"file:///b.cook": "Add 🧂 to taste.",
+	}
+	text := func(uri string) (string, bool) {
+		t, ok := texts[uri]
+		return t, ok
+	}
+	edit := messages.WorkspaceEdit{
+		Changes: map[string][]messages.TextEdit{
+			"file:///a.cook": {{Range: rng(0, 10, 0, 11), NewText: "!"}},
+		},
+		DocumentChanges: []messages.DocumentChange{
+			{TextDocumentEdit: &messages.TextDocumentEdit{
+				TextDocument: messages.OptionalVersionedTextDocumentIdentifier{URI: "file:///b.cook"},
+				Edits:        []messages.AnnotatedTextEdit{{TextEdit: messages.TextEdit{Range: rng(0, 7, 0, 9), NewText: "in"}}},
+			}},
+			{DeleteFile: &messages.DeleteFile{URI: "file:///c.cook"}},
+		},
+	}
+
+	encoded := EncodeWorkspaceEdit(edit, text, messages.PositionEncodingKindUTF8)
+
+	if r := encoded.Changes["file:///a.cook"][0].Range; r != rng(0, 11, 0, 12) {
+		t.Errorf("expected the change to be encoded, got %v", r)
+	}
+	if r := encoded.DocumentChanges[0].TextDocumentEdit.Edits[0].Range; r != rng(0, 9, 0, 11) {
+		t.Errorf("expected the document change to be encoded, got %v", r)
+	}
+	if encoded.DocumentChanges[1].DeleteFile == nil {
+		t.Errorf("expected the file operation to be kept, got %#v", encoded.DocumentChanges[1])
+	}
+	if r := edit.Changes["file:///a.cook"][0].Range; r != rng(0, 10, 0, 11) {
+		t.Errorf("expected the original edit not to change, got %v", r)
+	}
+	if r := edit.DocumentChanges[0].TextDocumentEdit.Edits[0].Range; r != rng(0, 7, 0, 9) {
+		t.Errorf("expected the original document change not to change, got %v", r)
+	}
+}
@@ -0,0 +1,88 @@
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/a-h/examplelsp/diff"
+	"github.com/a-h/examplelsp/messages"
+)
+
+// Preview of the changes that a WorkspaceEdit would make.
+type Preview struct {
+	Files []FilePreview `json:"files"`
+}
+
+// FilePreview describes the changes to a single document.
+type FilePreview struct {
+	URI string `json:"uri"`
+	// Edits is the number of text edits made to the document.
+	Edits int `json:"edits"`
+	// Diff is a unified diff of the changes.
+	Diff string `json:"diff"`
+}
+
+// TextFunc returns the current text of a document.
+type TextFunc func(uri string) (text string, ok bool)
+
+// Text returns the text of an open document.
+func (s *Store) Text(uri string) (text string, ok bool) {
+	doc, ok := s.Get(uri)
+	return doc.Text, ok
+}
+
+// Preview the changes that the edit would make to the documents in the store,
+// without changing them.
+func (s *Store) Preview(edit messages.WorkspaceEdit) (p Preview, err error) {
+	return PreviewEdit(edit, s.Text)
+}
+
+// PreviewEdit previews the changes that the edit would make to the documents
+// returned by text, including both its Changes and DocumentChanges.
+func PreviewEdit(edit messages.WorkspaceEdit, text TextFunc) (p Preview, err error) {
+	p.Files = []FilePreview{}
+	add := func(uri string, edits []messages.TextEdit) error {
+		before, ok := text(uri)
+		if !ok {
+			return fmt.Errorf("documents: %q is not open", uri)
+		}
+		after, err := ApplyEdits(before, edits)
+		if err != nil {
+			return fmt.Errorf("documents: failed to preview edits to %q: %w", uri, err)
+		}
+		p.Files = append(p.Files, FilePreview{
+			URI:   uri,
+			Edits: len(edits),
+			Diff:  diff.Unified(diffName(uri), before, after),
+		})
+		return nil
+	}
+	for uri, edits := range edit.Changes {
+		if err = add(uri, edits); err != nil {
+			return p, err
+		}
+	}
+	for _, change := range edit.DocumentChanges {
+		if change.TextDocumentEdit == nil {
+			return p, errors.New("documents: file operations can't be previewed")
+		}
+		if err = add(change.TextDocumentEdit.TextDocument.URI, change.TextDocumentEdit.TextEdits()); err != nil {
+			return p, err
+		}
+	}
+	sort.Slice(p.Files, func(i, j int) bool {
+		return p.Files[i].URI < p.Files[j].URI
+	})
+	return p, nil
+}
+
+// diffName returns the name of the document to use in diff headers.
+func diffName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Path == "" {
+		return uri
+	}
+	return u.Path
+}
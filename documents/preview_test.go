@@ -0,0 +1,132 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/diff"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestPreview(t *testing.T) {
+	s := NewStore()
+	original := messages.TextDocumentItem{
+		URI:     "file:///recipes/tea.cook",
+		Version: 3,
+		Text:    "Boil @water{500%ml}.  \nAdd @tea{1%bag}.\n",
+	}
+	s.Set(original)
+	s.Set(messages.TextDocumentItem{URI: "file:///recipes/toast.cook", Text: "Toast @bread.\n"})
+	edit := messages.WorkspaceEdit{
+		Changes: map[string][]messages.TextEdit{
+			"file:///recipes/toast.cook": {},
+			"file:///recipes/tea.cook": {
+				{
+					Range: messages.Range{
+						Start: messages.NewPosition(0, 20),
+						End:   messages.NewPosition(0, 22),
+					},
+				},
+			},
+		},
+	}
+
+	p, err := s.Preview(edit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if actual, _ := s.Get(original.URI); actual != original {
+		t.Errorf("expected the preview not to change the document, got %#v", actual)
+	}
+	if len(p.Files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(p.Files))
+	}
+	tea, toast := p.Files[0], p.Files[1]
+	if tea.URI != original.URI || tea.Edits != 1 {
+		t.Errorf("expected 1 edit to %q, got %d edits to %q", original.URI, tea.Edits, tea.URI)
+	}
+	if toast.Edits != 0 || toast.Diff != "" {
+		t.Errorf("expected no changes to toast, got %#v", toast)
+	}
+	expected := `--- a/recipes/tea.cook
++++ b/recipes/tea.cook
+@@ -1,2 +1,2 @@
+-Boil @water{500%ml}.  
++Boil @water{500%ml}.
+ Add @tea{1%bag}.
+`
+	if tea.Diff != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, tea.Diff)
+	}
+
+	// Applying the diff has the same outcome as applying the edit.
+	edited, err := ApplyEdits(original.Text, edit.Changes[original.URI])
+	if err != nil {
+		t.Fatalf("failed to apply edits: %v", err)
+	}
+	patched, err := diff.Apply(original.Text, tea.Diff)
+	if err != nil {
+		t.Fatalf("failed to apply diff: %v", err)
+	}
+	if patched != edited {
+		t.Errorf("expected the diff to produce %q, got %q", edited, patched)
+	}
+}
+
+func TestPreviewOfDocumentThatIsNotOpen(t *testing.T) {
+	_, err := NewStore().Preview(messages.WorkspaceEdit{
+		Changes: map[string][]messages.TextEdit{
+			"file:///recipes/tea.cook": {},
+		},
+	})
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestPreviewEditDocumentChanges(t *testing.T) {
+	texts := map[string]string{
+		"file:///recipes/toast.cook": "Toast @bread.\n",
+	}
+	edit := messages.NewVersionedWorkspaceEdit(
+		messages.OptionalVersionedTextDocumentIdentifier{URI: "file:///recipes/toast.cook"},
+		messages.TextEdit{
+			Range: messages.Range{
+				Start: messages.NewPosition(0, 7),
+				End:   messages.NewPosition(0, 12),
+			},
+			NewText: "sourdough",
+		},
+	)
+	p, err := PreviewEdit(edit, func(uri string) (text string, ok bool) {
+		text, ok = texts[uri]
+		return
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Files) != 1 || p.Files[0].Edits != 1 {
+		t.Fatalf("expected 1 edit to 1 file, got %#v", p.Files)
+	}
+	expected := `--- a/recipes/toast.cook
++++ b/recipes/toast.cook
+@@ -1 +1 @@
+-Toast @bread.
++Toast @sourdough.
+`
+	if p.Files[0].Diff != expected {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", expected, p.Files[0].Diff)
+	}
+}
+
+func TestPreviewEditFileOperations(t *testing.T) {
+	edit := messages.WorkspaceEdit{
+		DocumentChanges: []messages.DocumentChange{
+			{CreateFile: &messages.CreateFile{URI: "file:///recipes/new.cook"}},
+		},
+	}
+	_, err := PreviewEdit(edit, func(uri string) (text string, ok bool) { return "", true })
+	if err == nil {
+		t.Error("expected file operations not to be previewed")
+	}
+}
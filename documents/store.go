@@ -0,0 +1,60 @@
+// Package documents keeps track of the text of the documents open in the
+// client, and applies edits to them.
+package documents
+
+import (
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// Store holds the latest version of each open document. It's safe for
+// concurrent use.
+//
+// Documents are keyed by their normalized URI, so that a document can be found
+// with any encoding of its URI.
+type Store struct {
+	lock      sync.Mutex
+	documents map[messages.DocumentURI]messages.TextDocumentItem
+}
+
+func NewStore() *Store {
+	return &Store{
+		documents: map[messages.DocumentURI]messages.TextDocumentItem{},
+	}
+}
+
+// Set the content of a document. A byte order mark at the start of the text is
+// removed, so that parsing, analysis and positions all match the client's view
+// of the document.
+func (s *Store) Set(doc messages.TextDocumentItem) {
+	doc.Text, _ = TrimBOM(doc.Text)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.documents[messages.DocumentURI(doc.URI).Normalize()] = doc
+}
+
+// Get the content of a document.
+func (s *Store) Get(uri string) (doc messages.TextDocumentItem, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	doc, ok = s.documents[messages.DocumentURI(uri).Normalize()]
+	return
+}
+
+// Delete a document, e.g. when it's closed in the client.
+func (s *Store) Delete(uri string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.documents, messages.DocumentURI(uri).Normalize())
+}
+
+// URIs of all documents in the store, as they were sent by the client.
+func (s *Store) URIs() (uris []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, doc := range s.documents {
+		uris = append(uris, doc.URI)
+	}
+	return uris
+}
@@ -0,0 +1,51 @@
+package documents
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestStoreNormalizesURIs(t *testing.T) {
+	tests := []struct {
+		name   string
+		set    string
+		lookup string
+	}{
+		{
+			name:   "escaped spaces",
+			set:    "file:///home/alice/my%20recipes/pizza.cook",
+			lookup: "file:///home/alice/my recipes/pizza.cook",
+		},
+		{
+			name:   "non-ASCII paths",
+			set:    "file:///home/alice/recettes/cr%C3%A8me%20br%C3%BBl%C3%A9e.cook",
+			lookup: "file:///home/alice/recettes/cr%c3%a8me br%c3%bbl%c3%a9e.cook",
+		},
+		{
+			name:   "Windows drive letters",
+			set:    "file:///c%3A/Users/alice/pizza.cook",
+			lookup: "file:///C:/Users/alice/pizza.cook",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewStore()
+			s.Set(messages.TextDocumentItem{URI: test.set, Text: "Boil @water."})
+			doc, ok := s.Get(test.lookup)
+			if !ok {
+				t.Fatalf("expected to find %q", test.lookup)
+			}
+			if doc.URI != test.set {
+				t.Errorf("expected the URI sent by the client to be kept, got %q", doc.URI)
+			}
+			if uris := s.URIs(); len(uris) != 1 || uris[0] != test.set {
+				t.Errorf("expected the URIs to be %q, got %v", test.set, uris)
+			}
+			s.Delete(test.lookup)
+			if _, ok := s.Get(test.set); ok {
+				t.Error("expected the document to be deleted")
+			}
+		})
+	}
+}
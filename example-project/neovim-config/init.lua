@@ -8,3 +8,20 @@ vim.api.nvim_create_autocmd('FileType', {
     })
   end,
 })
+
+-- The server can't prompt for the new name of an ingredient, so ask for it
+-- before running the command.
+vim.lsp.commands["examplelsp.renameIngredientEverywhere"] = function(command, ctx)
+  vim.ui.input({ prompt = "New name: " }, function(name)
+    if name == nil or name == "" then
+      return
+    end
+    local args = vim.deepcopy(command.arguments[1])
+    args.newName = name
+    local client = vim.lsp.get_client_by_id(ctx.client_id)
+    client.request("workspace/executeCommand", {
+      command = command.command,
+      arguments = { args },
+    }, nil, ctx.bufnr)
+  end)
+end
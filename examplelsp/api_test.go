@@ -0,0 +1,188 @@
+package examplelsp
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the API golden files")
+
+// embeddedPackages are the packages that programs embedding examplelsp can
+// use. Changes to their exported API must be deliberate.
+var embeddedPackages = []string{
+	"analyzers",
+	"documents",
+	"examplelsp",
+	"recipe",
+	"render",
+	"settings",
+}
+
+// TestAPI compares the exported declarations of each embedded package with
+// a golden file in testdata/api. Run "go test ./examplelsp -update" to
+// accept changes.
+func TestAPI(t *testing.T) {
+	for _, name := range embeddedPackages {
+		t.Run(name, func(t *testing.T) {
+			actual := api(t, filepath.Join("..", name))
+			goldenPath := filepath.Join("testdata", "api", name+".txt")
+			if *update {
+				if err := os.WriteFile(goldenPath, []byte(actual), 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if actual != string(expected) {
+				t.Errorf("the exported API of %s has changed, if this is intended, run go test ./examplelsp -update\nexpected:\n%s\ngot:\n%s", name, expected, actual)
+			}
+		})
+	}
+}
+
+// TestDependencies checks that the embedded packages don't depend on the
+// server.
+func TestDependencies(t *testing.T) {
+	forbidden := []string{
+		"github.com/a-h/examplelsp/lsp",
+		"github.com/aquilax/cooklang-go",
+		"golang.org/x/exp/slog",
+	}
+	for _, name := range embeddedPackages {
+		for _, imported := range imports(t, name, map[string]bool{}) {
+			for _, f := range forbidden {
+				if imported == f {
+					t.Errorf("%s must not depend on %s", name, f)
+				}
+			}
+		}
+	}
+}
+
+// imports returns the packages imported by the package in the module, and
+// by the module packages it imports.
+func imports(t *testing.T, dir string, seen map[string]bool) (paths []string) {
+	t.Helper()
+	const module = "github.com/a-h/examplelsp/"
+	pkgs, err := parser.ParseDir(token.NewFileSet(), filepath.Join("..", dir), isSource, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", dir, err)
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, spec := range f.Imports {
+				path, _ := strconv.Unquote(spec.Path.Value)
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+				paths = append(paths, path)
+				if strings.HasPrefix(path, module) {
+					paths = append(paths, imports(t, strings.TrimPrefix(path, module), seen)...)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+func isSource(info os.FileInfo) bool {
+	return !strings.HasSuffix(info.Name(), "_test.go")
+}
+
+// api returns the exported declarations of the package in dir, without doc
+// comments or function bodies.
+func api(t *testing.T, dir string) string {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, isSource, 0)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", dir, err)
+	}
+	var lines []string
+	for _, pkg := range pkgs {
+		p := doc.New(pkg, dir, 0)
+		for _, v := range p.Consts {
+			lines = append(lines, declaration(t, fset, v.Decl)...)
+		}
+		for _, v := range p.Vars {
+			lines = append(lines, declaration(t, fset, v.Decl)...)
+		}
+		for _, f := range p.Funcs {
+			lines = append(lines, declaration(t, fset, f.Decl)...)
+		}
+		for _, typ := range p.Types {
+			lines = append(lines, declaration(t, fset, typ.Decl)...)
+			for _, v := range typ.Consts {
+				lines = append(lines, declaration(t, fset, v.Decl)...)
+			}
+			for _, v := range typ.Vars {
+				lines = append(lines, declaration(t, fset, v.Decl)...)
+			}
+			for _, f := range typ.Funcs {
+				lines = append(lines, declaration(t, fset, f.Decl)...)
+			}
+			for _, f := range typ.Methods {
+				lines = append(lines, declaration(t, fset, f.Decl)...)
+			}
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// declaration returns a line for each exported name in the declaration.
+func declaration(t *testing.T, fset *token.FileSet, decl ast.Decl) (lines []string) {
+	t.Helper()
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		d.Body, d.Doc = nil, nil
+		return []string{format(t, fset, d)}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.ValueSpec:
+				for i, name := range s.Names {
+					if !name.IsExported() {
+						continue
+					}
+					line := d.Tok.String() + " " + name.Name
+					if s.Type != nil {
+						line += " " + format(t, fset, s.Type)
+					}
+					if d.Tok == token.CONST && i < len(s.Values) {
+						line += " = " + format(t, fset, s.Values[i])
+					}
+					lines = append(lines, line)
+				}
+			case *ast.TypeSpec:
+				s.Doc, s.Comment = nil, nil
+				lines = append(lines, "type "+strings.Join(strings.Fields(format(t, fset, s)), " "))
+			}
+		}
+	}
+	return lines
+}
+
+func format(t *testing.T, fset *token.FileSet, node any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		t.Fatalf("failed to print declaration: %v", err)
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
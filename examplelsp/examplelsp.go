@@ -0,0 +1,55 @@
+// Package examplelsp runs the features of the language server without a
+// client, for programs that embed them, such as static site generators.
+//
+// The analyzers, documents, recipe, render and settings packages can also be
+// used directly. None of them depend on the server.
+package examplelsp
+
+import (
+	"os"
+	"strings"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+	"github.com/a-h/examplelsp/render"
+	"github.com/a-h/examplelsp/settings"
+	"github.com/a-h/examplelsp/workspace"
+)
+
+// Diagnostic is a problem found in a recipe. Positions are zero-based, and
+// characters are counted in UTF-16 code units, as in the language server
+// protocol.
+type Diagnostic = messages.Diagnostic
+
+// Settings configure the analyzers, see settings.Load to read them from a
+// project.
+type Settings = settings.Settings
+
+// AnalyzeFile runs the analyzers in analyzers.Defaults against the recipe
+// file at path. Checks that need the cooklang parser aren't run.
+func AnalyzeFile(path string, s Settings) (diagnostics []Diagnostic, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text, _ := documents.TrimBOM(string(data))
+	uri, err := workspace.URIFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := messages.TextDocumentItem{URI: uri, Text: text}
+	return analyzers.Analyze(doc, s.Snapshot(), analyzers.Defaults...), nil
+}
+
+// ExportMarkdown converts the text of a recipe to Markdown, see
+// render.Markdown.
+func ExportMarkdown(text string) (markdown string, err error) {
+	text, _ = documents.TrimBOM(text)
+	var sb strings.Builder
+	if err = render.Markdown(&sb, recipe.Parse(text)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
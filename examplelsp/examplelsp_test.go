@@ -0,0 +1,48 @@
+package examplelsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/documents"
+)
+
+func TestAnalyzeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tea.cook")
+	if err := os.WriteFile(path, []byte(documents.BOM+"Boil @water. \n\nBoil @water.\n"), 0644); err != nil {
+		t.Fatalf("failed to write recipe: %v", err)
+	}
+	style := true
+	diagnostics, err := AnalyzeFile(path, Settings{Style: &style})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := map[string]bool{}
+	for _, d := range diagnostics {
+		codes[*d.Code] = true
+	}
+	if !codes[analyzers.CodeDuplicateStep] || !codes[analyzers.CodeTrailingWhitespace] {
+		t.Errorf("expected duplicate step and trailing whitespace diagnostics, got %v", codes)
+	}
+	if d := diagnostics[0]; !strings.HasPrefix(d.RelatedInformation[0].Location.URI, "file:///") {
+		t.Errorf("expected related information to refer to the file, got %q", d.RelatedInformation[0].Location.URI)
+	}
+
+	if _, err := AnalyzeFile(filepath.Join(t.TempDir(), "missing.cook"), Settings{}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	markdown, err := ExportMarkdown(documents.BOM + ">> title: Tea\n\nBoil @water{500%ml}.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "# Tea\n\n## Ingredients\n\n- water (500 ml)\n\n## Steps\n\n1. Boil water.\n"
+	if markdown != expected {
+		t.Errorf("expected %q, got %q", expected, markdown)
+	}
+}
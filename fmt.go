@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/diff"
+	"github.com/a-h/examplelsp/documents"
+)
+
+// runFmt fixes the whitespace problems in the named recipe files, making the
+// same changes as the source.fixAll code action. With -diff, the changes are
+// printed as a unified diff instead of being written to the files.
+func runFmt(args []string, stdout, stderr io.Writer) (exitCode int) {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: examplelsp fmt [-diff] <file>...")
+		flags.PrintDefaults()
+	}
+	showDiff := flags.Bool("diff", false, "print a unified diff of the changes instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	for _, name := range flags.Args() {
+		if err := fmtFile(name, *showDiff, stdout); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+func fmtFile(name string, showDiff bool, stdout io.Writer) (err error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+	before, err := os.ReadFile(name)
+	if err != nil {
+		return
+	}
+	// Positions don't include the byte order mark, and ApplyEdits keeps it.
+	text, _ := documents.TrimBOM(string(before))
+	after, err := documents.ApplyEdits(string(before), analyzers.WhitespaceFixAll(text))
+	if err != nil {
+		return
+	}
+	if showDiff {
+		_, err = io.WriteString(stdout, diff.Unified(name, string(before), after))
+		return
+	}
+	if after == string(before) {
+		return nil
+	}
+	return os.WriteFile(name, []byte(after), info.Mode().Perm())
+}
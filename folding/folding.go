@@ -0,0 +1,45 @@
+// Package folding provides the folding ranges of recipes.
+package folding
+
+import (
+	"sort"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Ranges returns the folding ranges of the recipe: each step that spans more
+// than one line, the metadata block at the start of the recipe, and block
+// comments that span more than one line. The ranges are sorted by their start
+// line.
+func Ranges(text string) (ranges []messages.FoldingRange) {
+	ranges = []messages.FoldingRange{}
+	add := func(start, end int, kind messages.FoldingRangeKind) {
+		if end > start {
+			ranges = append(ranges, messages.FoldingRange{StartLine: start, EndLine: end, Kind: kind})
+		}
+	}
+	r := recipe.Parse(text)
+	if len(r.Metadata) > 0 {
+		// The block ends at the first line that isn't metadata.
+		start := r.Metadata[0].Range.Start.Line
+		end := start
+		for _, m := range r.Metadata[1:] {
+			if m.Range.Start.Line != end+1 {
+				break
+			}
+			end = m.Range.Start.Line
+		}
+		add(start, end, messages.FoldingRangeKindRegion)
+	}
+	for _, step := range r.Steps {
+		add(step.Range.Start.Line, step.Range.End.Line, messages.FoldingRangeKindRegion)
+	}
+	for _, comment := range recipe.BlockComments(text) {
+		add(comment.Start.Line, comment.End.Line, messages.FoldingRangeKindComment)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].StartLine < ranges[j].StartLine
+	})
+	return ranges
+}
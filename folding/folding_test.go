@@ -0,0 +1,42 @@
+package folding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestRanges(t *testing.T) {
+	text := `>> servings: 2
+>> time: 30 minutes
+>> course: main
+
+Boil @water{2%l} in a #pot.
+Add @salt{1%tsp}.
+
+[- Use fresh pasta if you
+have it, or dried pasta
+from the cupboard. -]
+Add the @pasta{500%g}.
+Stir.
+
+Drain, and serve.
+`
+	expected := []messages.FoldingRange{
+		{StartLine: 0, EndLine: 2, Kind: messages.FoldingRangeKindRegion},
+		{StartLine: 4, EndLine: 5, Kind: messages.FoldingRangeKindRegion},
+		{StartLine: 7, EndLine: 9, Kind: messages.FoldingRangeKindComment},
+		{StartLine: 10, EndLine: 11, Kind: messages.FoldingRangeKindRegion},
+	}
+	if actual := Ranges(text); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v\ngot %#v", expected, actual)
+	}
+}
+
+func TestRangesOfSingleLines(t *testing.T) {
+	text := ">> servings: 2\n\nBoil @water. [- A comment -]\n\nDrain."
+	if actual := Ranges(text); actual == nil || len(actual) != 0 {
+		t.Errorf("expected no ranges, got %#v", actual)
+	}
+}
@@ -0,0 +1,64 @@
+package hover
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Cookware returns hover information for an item of cookware at the position,
+// or nil if there isn't any cookware at the position. It lists the steps that
+// use the same cookware, linked to the first line of each step if the contents
+// are markdown.
+func Cookware(uri, text string, p messages.Position, markdown bool) *messages.Hover {
+	r := recipe.Parse(text)
+	for _, step := range r.Steps {
+		for _, cookware := range step.Cookware {
+			if !cookware.Range.Contains(p) {
+				continue
+			}
+			kind, name := messages.MarkupKindPlainText, cookware.Name
+			if markdown {
+				kind, name = messages.MarkupKindMarkdown, "**"+cookware.Name+"**"
+			}
+			if quantity := strings.TrimSpace(cookware.Quantity); quantity != "" {
+				name += " (" + quantity + ")"
+			}
+			return &messages.Hover{
+				Contents: messages.HoverContents{
+					MarkupContent: &messages.MarkupContent{
+						Kind:  kind,
+						Value: name + "\n\n" + cookwareSteps(uri, r, cookware.Name, markdown),
+					},
+				},
+				Range: &cookware.Range,
+			}
+		}
+	}
+	return nil
+}
+
+// cookwareSteps lists the steps that use the cookware, ignoring case.
+func cookwareSteps(uri string, r recipe.Recipe, name string, markdown bool) string {
+	var items []string
+	for i, step := range r.Steps {
+		for _, cookware := range step.Cookware {
+			if !strings.EqualFold(cookware.Name, name) {
+				continue
+			}
+			line := step.Range.Start.Line + 1
+			item := fmt.Sprintf("- Step %d (line %d)", i+1, line)
+			if markdown {
+				item = fmt.Sprintf("- [Step %d](%s#L%d)", i+1, uri, line)
+			}
+			items = append(items, item)
+			break
+		}
+	}
+	if len(items) < 2 {
+		return "Used in this step only."
+	}
+	return "Used in steps:\n\n" + strings.Join(items, "\n")
+}
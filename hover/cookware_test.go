@@ -0,0 +1,84 @@
+package hover
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestCookware(t *testing.T) {
+	uri := "file:///recipes/pasta.cook"
+	text := "Heat the #frying pan{2} and boil water in a #pot.\n\n" +
+		"Fry the @garlic in the #Frying Pan{}.\n\n" +
+		"Drain the pasta with a #colander, and return it to the #frying pan{}."
+	tests := []struct {
+		name          string
+		position      messages.Position
+		expected      string
+		expectedRange messages.Range
+	}{
+		{
+			name:          "multi-word cookware lists every step that uses it",
+			position:      messages.NewPosition(0, 12),
+			expected:      "**frying pan** (2)\n\nUsed in steps:\n\n- [Step 1](file:///recipes/pasta.cook#L1)\n- [Step 2](file:///recipes/pasta.cook#L3)\n- [Step 3](file:///recipes/pasta.cook#L5)",
+			expectedRange: messages.Range{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 23)},
+		},
+		{
+			name:          "names are matched ignoring case",
+			position:      messages.NewPosition(2, 25),
+			expected:      "**Frying Pan**\n\nUsed in steps:\n\n- [Step 1](file:///recipes/pasta.cook#L1)\n- [Step 2](file:///recipes/pasta.cook#L3)\n- [Step 3](file:///recipes/pasta.cook#L5)",
+			expectedRange: messages.Range{Start: messages.NewPosition(2, 23), End: messages.NewPosition(2, 36)},
+		},
+		{
+			name:          "cookware used once",
+			position:      messages.NewPosition(0, 45),
+			expected:      "**pot**\n\nUsed in this step only.",
+			expectedRange: messages.Range{Start: messages.NewPosition(0, 44), End: messages.NewPosition(0, 48)},
+		},
+		{
+			name:     "text outside of cookware has no hover",
+			position: messages.NewPosition(2, 0),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := Cookware(uri, text, test.position, true)
+			if test.expected == "" {
+				if h != nil {
+					t.Fatalf("expected no hover, got %#v", h)
+				}
+				return
+			}
+			if h == nil {
+				t.Fatal("expected a hover, got nil")
+			}
+			if h.Contents.MarkupContent.Value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, h.Contents.MarkupContent.Value)
+			}
+			if *h.Range != test.expectedRange {
+				t.Errorf("expected range %v, got %v", test.expectedRange, *h.Range)
+			}
+		})
+	}
+}
+
+func TestCookwarePlainText(t *testing.T) {
+	text := "Boil water in a #pot.\nAdd the pasta to the #pot."
+	h := Cookware("file:///pasta.cook", text, messages.NewPosition(0, 17), false)
+	if h == nil {
+		t.Fatal("expected a hover, got nil")
+	}
+	if h.Contents.MarkupContent.Kind != messages.MarkupKindPlainText {
+		t.Errorf("expected plain text, got %q", h.Contents.MarkupContent.Kind)
+	}
+	// Both lines are in the same step.
+	if expected := "pot\n\nUsed in this step only."; h.Contents.MarkupContent.Value != expected {
+		t.Errorf("expected %q, got %q", expected, h.Contents.MarkupContent.Value)
+	}
+
+	text = "Boil water in a #pot.\n\nAdd the pasta to the #pot."
+	h = Cookware("file:///pasta.cook", text, messages.NewPosition(0, 17), false)
+	if expected := "pot\n\nUsed in steps:\n\n- Step 1 (line 1)\n- Step 2 (line 3)"; h == nil || h.Contents.MarkupContent.Value != expected {
+		t.Errorf("expected %q, got %#v", expected, h)
+	}
+}
@@ -0,0 +1,104 @@
+// Package hover provides hover information for recipes.
+package hover
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Timer returns hover information for a timer at the position, or nil if
+// there isn't a timer at the position. The duration is shown converted to
+// other units, and named timers show where they're started. The contents are
+// markdown if the client supports it, and plain text otherwise.
+func Timer(text string, p messages.Position, markdown bool) *messages.Hover {
+	r := recipe.Parse(text)
+	for _, step := range r.Steps {
+		for _, timer := range step.Timers {
+			if !timer.Range.Contains(p) {
+				continue
+			}
+			kind := messages.MarkupKindPlainText
+			if markdown {
+				kind = messages.MarkupKindMarkdown
+			}
+			value := Duration(timer.Quantity, timer.Unit)
+			if timer.Name != "" {
+				name := timer.Name
+				if markdown {
+					name = "**" + timer.Name + "**"
+				}
+				value = fmt.Sprintf("%s\n\nNot started in any step.", name)
+				if d, ok := r.TimerNames()[strings.ToLower(timer.Name)]; ok {
+					value = fmt.Sprintf("%s\n\n%s, started in step %d.", name, Duration(d.Timer.Quantity, d.Timer.Unit), d.StepIndex+1)
+				}
+			}
+			return &messages.Hover{
+				Contents: messages.HoverContents{
+					MarkupContent: &messages.MarkupContent{
+						Kind:  kind,
+						Value: value,
+					},
+				},
+				Range: &timer.Range,
+			}
+		}
+	}
+	return nil
+}
+
+// Duration returns the duration of a timer, converted to the other units in
+// recipe.TimerUnits, closest first, e.g. "25 minutes = 1500 seconds ≈ 0.42
+// hours ≈ 0.02 days". Conversions that are rounded to 2 decimal places are
+// marked as approximate, and those that round to zero are left out. If the
+// quantity isn't a number, or the unit isn't a unit of time, the duration is
+// returned as it's written.
+func Duration(quantity, unit string) string {
+	written := strings.TrimSpace(quantity + " " + unit)
+	amount, ok := recipe.ParseQuantity(quantity)
+	u, isTime := recipe.LookupUnit(recipe.TimerUnits, unit)
+	if !ok || !isTime {
+		return written
+	}
+	index := 0
+	for i, tu := range recipe.TimerUnits {
+		if tu.Name == u.Name {
+			index = i
+		}
+	}
+	seconds := amount * u.Factor
+	var sb strings.Builder
+	sb.WriteString(written)
+	// The closest units are listed first.
+	var others []recipe.Unit
+	for distance := 1; distance < len(recipe.TimerUnits); distance++ {
+		for _, i := range []int{index - distance, index + distance} {
+			if i >= 0 && i < len(recipe.TimerUnits) {
+				others = append(others, recipe.TimerUnits[i])
+			}
+		}
+	}
+	for _, u := range others {
+		v := seconds / u.Factor
+		rounded := math.Round(v*100) / 100
+		if rounded == 0 {
+			continue
+		}
+		if rounded == v {
+			sb.WriteString(" = ")
+		} else {
+			sb.WriteString(" ≈ ")
+		}
+		// Timer units are named in the plural.
+		name := u.Name
+		if rounded == 1 {
+			name = strings.TrimSuffix(u.Name, "s")
+		}
+		sb.WriteString(strconv.FormatFloat(rounded, 'f', -1, 64) + " " + name)
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package hover
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestTimer(t *testing.T) {
+	text := "Leave to ~marinade{2%hours}.\n\nWhen the ~Marinade{} is done, drain. Rest for ~{5%minutes}.\n\nWhen the ~proving{} is done, bake."
+	tests := []struct {
+		name          string
+		position      messages.Position
+		expected      string
+		expectedRange messages.Range
+	}{
+		{
+			name:          "the declaration shows its duration",
+			position:      messages.NewPosition(0, 12),
+			expected:      "**marinade**\n\n2 hours = 120 minutes ≈ 0.08 days = 7200 seconds, started in step 1.",
+			expectedRange: messages.Range{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 27)},
+		},
+		{
+			name:          "references show the step where the timer was started",
+			position:      messages.NewPosition(2, 9),
+			expected:      "**Marinade**\n\n2 hours = 120 minutes ≈ 0.08 days = 7200 seconds, started in step 1.",
+			expectedRange: messages.Range{Start: messages.NewPosition(2, 9), End: messages.NewPosition(2, 20)},
+		},
+		{
+			name:          "references to undeclared timers are reported",
+			position:      messages.NewPosition(4, 12),
+			expected:      "**proving**\n\nNot started in any step.",
+			expectedRange: messages.Range{Start: messages.NewPosition(4, 9), End: messages.NewPosition(4, 19)},
+		},
+		{
+			name:          "unnamed timers show their duration",
+			position:      messages.NewPosition(2, 48),
+			expected:      "5 minutes = 300 seconds ≈ 0.08 hours",
+			expectedRange: messages.Range{Start: messages.NewPosition(2, 46), End: messages.NewPosition(2, 58)},
+		},
+		{
+			name:     "the position after the timer has no hover",
+			position: messages.NewPosition(0, 27),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := Timer(text, test.position, true)
+			if test.expected == "" {
+				if h != nil {
+					t.Fatalf("expected no hover, got %#v", h)
+				}
+				return
+			}
+			if h == nil {
+				t.Fatal("expected a hover, got nil")
+			}
+			if h.Contents.MarkupContent.Value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, h.Contents.MarkupContent.Value)
+			}
+			if *h.Range != test.expectedRange {
+				t.Errorf("expected range %v, got %v", test.expectedRange, *h.Range)
+			}
+		})
+	}
+}
+
+func TestTimerPlainText(t *testing.T) {
+	h := Timer("Leave to ~marinade{2%hours}.", messages.NewPosition(0, 12), false)
+	if h == nil {
+		t.Fatal("expected a hover, got nil")
+	}
+	if h.Contents.MarkupContent.Kind != messages.MarkupKindPlainText {
+		t.Errorf("expected plain text, got %q", h.Contents.MarkupContent.Kind)
+	}
+	if expected := "marinade\n\n2 hours = 120 minutes ≈ 0.08 days = 7200 seconds, started in step 1."; h.Contents.MarkupContent.Value != expected {
+		t.Errorf("expected %q, got %q", expected, h.Contents.MarkupContent.Value)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		quantity, unit string
+		expected       string
+	}{
+		{quantity: "25", unit: "minutes", expected: "25 minutes = 1500 seconds ≈ 0.42 hours ≈ 0.02 days"},
+		{quantity: "1 1/2", unit: "hours", expected: "1 1/2 hours = 90 minutes ≈ 0.06 days = 5400 seconds"},
+		{quantity: "90", unit: "sec", expected: "90 sec = 1.5 minutes ≈ 0.03 hours"},
+		{quantity: "60", unit: "seconds", expected: "60 seconds = 1 minute ≈ 0.02 hours"},
+		{quantity: "2-3", unit: "minutes", expected: "2-3 minutes"},
+		{quantity: "3", unit: "days", expected: "3 days = 72 hours = 4320 minutes = 259200 seconds"},
+		{quantity: "1", unit: "d", expected: "1 d = 24 hours = 1440 minutes = 86400 seconds"},
+		{quantity: "36", unit: "hrs", expected: "36 hrs = 2160 minutes = 1.5 days = 129600 seconds"},
+		{quantity: "", unit: "", expected: ""},
+	}
+	for _, test := range tests {
+		if actual := Duration(test.quantity, test.unit); actual != test.expected {
+			t.Errorf("%q %q: expected %q, got %q", test.quantity, test.unit, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package hover
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Ingredient returns hover information for an ingredient at the position, or
+// nil if there isn't an ingredient at the position. It shows the quantity of
+// the ingredient, and the total quantity used in the recipe. Quantities in
+// compatible units, including aliases such as grams and kilo, are summed, and
+// the others are listed separately.
+func Ingredient(text string, p messages.Position, markdown bool) *messages.Hover {
+	r := recipe.Parse(text)
+	for _, step := range r.Steps {
+		for _, ingredient := range step.Ingredients {
+			if !ingredient.Range.Contains(p) {
+				continue
+			}
+			kind, name := messages.MarkupKindPlainText, ingredient.Name
+			if markdown {
+				kind, name = messages.MarkupKindMarkdown, "**"+ingredient.Name+"**"
+			}
+			here := strings.TrimSpace(ingredient.Quantity + " " + ingredient.Unit)
+			if here == "" {
+				here = "no quantity"
+			}
+			value := fmt.Sprintf("%s — %s here", name, here)
+			if totals := totalQuantities(r, ingredient); len(totals) > 0 {
+				value += fmt.Sprintf(", %s total in recipe", strings.Join(totals, " and "))
+			}
+			return &messages.Hover{
+				Contents: messages.HoverContents{
+					MarkupContent: &messages.MarkupContent{
+						Kind:  kind,
+						Value: value,
+					},
+				},
+				Range: &ingredient.Range,
+			}
+		}
+	}
+	return nil
+}
+
+// totalQuantities returns the quantities of every use of the ingredient in the
+// recipe. Quantities in units of the same dimension are summed in the unit of
+// the first of them, starting with the unit of the hovered ingredient.
+// Quantities in units that can't be converted, such as counts, are only
+// summed with quantities in the same unit, and quantities that aren't numbers
+// are listed as they're written.
+func totalQuantities(r recipe.Recipe, hovered recipe.Ingredient) (totals []string) {
+	type sum struct {
+		unit   string
+		factor float64
+		amount float64
+	}
+	var sums []*sum
+	sumByGroup := map[string]*sum{}
+	add := func(unit string, amount float64) {
+		group, factor := unitGroup(unit)
+		s, ok := sumByGroup[group]
+		if !ok {
+			s = &sum{unit: unit, factor: factor}
+			sums = append(sums, s)
+			sumByGroup[group] = s
+		}
+		s.amount += amount * factor / s.factor
+	}
+	add(hovered.Unit, 0)
+	var others []string
+	for _, step := range r.Steps {
+		for _, ingredient := range step.Ingredients {
+			if !strings.EqualFold(ingredient.Name, hovered.Name) || strings.TrimSpace(ingredient.Quantity) == "" {
+				continue
+			}
+			amount, ok := recipe.ParseQuantity(ingredient.Quantity)
+			if !ok {
+				others = append(others, strings.TrimSpace(ingredient.Quantity+" "+ingredient.Unit))
+				continue
+			}
+			add(ingredient.Unit, amount)
+		}
+	}
+	for _, s := range sums {
+		if s.amount == 0 {
+			continue
+		}
+		totals = append(totals, strings.TrimSpace(formatAmount(s.amount)+" "+s.unit))
+	}
+	return append(totals, others...)
+}
+
+// unitGroup returns the group of quantities that a quantity in the unit is
+// summed with, and the factor that converts it into the base unit of the
+// group. Units that can't be converted are only grouped with themselves,
+// including their aliases.
+func unitGroup(name string) (group string, factor float64) {
+	u, ok := recipe.LookupUnit(recipe.IngredientUnits, name)
+	if !ok {
+		return "unit:" + strings.ToLower(strings.TrimSpace(name)), 1
+	}
+	if u.Factor == 0 {
+		return "unit:" + u.Name, 1
+	}
+	return "dimension:" + string(u.Dimension), u.Factor
+}
+
+// formatAmount rounds the amount to 3 decimal places, without trailing zeros.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*1000)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,122 @@
+package hover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestIngredient(t *testing.T) {
+	text := "Mix @flour{100%g} with @water{200%ml} and a pinch of @salt.\n\n" +
+		"Add @Flour{0.25%kg}, then @flour{1 1/2%cups}.\n\n" +
+		"Dust with @flour{some}, and add @water{1/2%l} and @salt{1/2%tsp}."
+	tests := []struct {
+		name          string
+		position      messages.Position
+		expected      string
+		expectedRange messages.Range
+	}{
+		{
+			name:          "compatible units are summed in the unit of the hovered ingredient",
+			position:      messages.NewPosition(0, 6),
+			expected:      "**flour** — 100 g here, 350 g and 1.5 cups and some total in recipe",
+			expectedRange: messages.Range{Start: messages.NewPosition(0, 4), End: messages.NewPosition(0, 17)},
+		},
+		{
+			name:          "totals are converted to the unit of the hovered ingredient",
+			position:      messages.NewPosition(2, 6),
+			expected:      "**Flour** — 0.25 kg here, 0.35 kg and 1.5 cups and some total in recipe",
+			expectedRange: messages.Range{Start: messages.NewPosition(2, 4), End: messages.NewPosition(2, 19)},
+		},
+		{
+			name:          "volumes are summed",
+			position:      messages.NewPosition(4, 42),
+			expected:      "**water** — 1/2 l here, 0.7 l total in recipe",
+			expectedRange: messages.Range{Start: messages.NewPosition(4, 32), End: messages.NewPosition(4, 45)},
+		},
+		{
+			name:          "ingredients without a quantity",
+			position:      messages.NewPosition(0, 54),
+			expected:      "**salt** — no quantity here, 0.5 tsp total in recipe",
+			expectedRange: messages.Range{Start: messages.NewPosition(0, 53), End: messages.NewPosition(0, 58)},
+		},
+		{
+			name:     "text outside of ingredients has no hover",
+			position: messages.NewPosition(0, 0),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := Ingredient(text, test.position, true)
+			if test.expected == "" {
+				if h != nil {
+					t.Fatalf("expected no hover, got %#v", h)
+				}
+				return
+			}
+			if h == nil {
+				t.Fatal("expected a hover, got nil")
+			}
+			if h.Contents.MarkupContent.Value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, h.Contents.MarkupContent.Value)
+			}
+			if *h.Range != test.expectedRange {
+				t.Errorf("expected range %v, got %v", test.expectedRange, *h.Range)
+			}
+		})
+	}
+}
+
+func TestIngredientSumsUnitAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "aliases of mass units are summed",
+			text:     "Mix @flour{100%g}, @flour{50%grams} and @flour{1%kilo}.",
+			expected: "flour — 100 g here, 1150 g total in recipe",
+		},
+		{
+			name:     "spoons are summed",
+			text:     "Add @sugar{1%tbsp}, then @sugar{2%teaspoons}.",
+			expected: "sugar — 1 tbsp here, 1.667 tbsp total in recipe",
+		},
+		{
+			name:     "imperial units are summed",
+			text:     "Add @beef{1%lb} and @beef{0.5%pounds}.",
+			expected: "beef — 1 lb here, 1.5 lb total in recipe",
+		},
+		{
+			name:     "counts are only summed with the same unit",
+			text:     "Add @garlic{2%cloves}, @garlic{1%clove} and @garlic{1%pinch}.",
+			expected: "garlic — 2 cloves here, 3 cloves and 1 pinch total in recipe",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := Ingredient(test.text, messages.NewPosition(0, strings.Index(test.text, "@")+1), false)
+			if h == nil {
+				t.Fatal("expected a hover, got nil")
+			}
+			if h.Contents.MarkupContent.Value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, h.Contents.MarkupContent.Value)
+			}
+		})
+	}
+}
+
+func TestIngredientPlainText(t *testing.T) {
+	h := Ingredient("Add @flour{100%g}.", messages.NewPosition(0, 6), false)
+	if h == nil {
+		t.Fatal("expected a hover, got nil")
+	}
+	if h.Contents.MarkupContent.Kind != messages.MarkupKindPlainText {
+		t.Errorf("expected plain text, got %q", h.Contents.MarkupContent.Kind)
+	}
+	if expected := "flour — 100 g here, 100 g total in recipe"; h.Contents.MarkupContent.Value != expected {
+		t.Errorf("expected %q, got %q", expected, h.Contents.MarkupContent.Value)
+	}
+}
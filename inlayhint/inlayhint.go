@@ -0,0 +1,91 @@
+// Package inlayhint shows the duration of named timers where they're used
+// without one.
+package inlayhint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Data is kept in the data of a hint, so that its tooltip can be computed
+// when the hint is resolved.
+type Data struct {
+	// URI of the recipe.
+	URI string `json:"uri"`
+	// Name of the timer, in lower case.
+	Timer string `json:"timer"`
+}
+
+// Timers returns a hint after each use of a named timer that doesn't have a
+// duration, such as "~rest", showing the duration of the timer from the step
+// that starts it. Only hints within r are returned.
+//
+// The hints don't have tooltips, unless tooltips is true. Tooltips are filled
+// in by Resolve, so that they're only computed for hints that the user
+// hovers over.
+func Timers(uri, text string, r messages.Range, tooltips bool) (hints []messages.InlayHint) {
+	hints = []messages.InlayHint{}
+	parsed := recipe.Parse(text)
+	declarations := parsed.TimerNames()
+	for _, step := range parsed.Steps {
+		for _, timer := range step.Timers {
+			if timer.Name == "" || timer.Quantity != "" {
+				continue
+			}
+			key := strings.ToLower(timer.Name)
+			d, ok := declarations[key]
+			if !ok || !(r.Contains(timer.Range.End) || r.End == timer.Range.End) {
+				continue
+			}
+			data, err := json.Marshal(Data{URI: uri, Timer: key})
+			if err != nil {
+				continue
+			}
+			hint := messages.InlayHint{
+				Position:    timer.Range.End,
+				Label:       messages.InlayHintLabel{Value: strings.TrimSpace(d.Timer.Quantity + " " + d.Timer.Unit)},
+				Kind:        messages.InlayHintKindType,
+				PaddingLeft: true,
+				Data:        data,
+			}
+			if tooltips {
+				hint.Tooltip = tooltip(parsed, d)
+			}
+			hints = append(hints, hint)
+		}
+	}
+	return hints
+}
+
+// Resolve fills in the tooltip of a hint returned by Timers, using the text
+// of the recipe in its data. The hint is returned unchanged if the timer is
+// no longer started by the recipe.
+func Resolve(hint messages.InlayHint, text func(uri string) string) (resolved messages.InlayHint, err error) {
+	var data Data
+	if err = json.Unmarshal(hint.Data, &data); err != nil {
+		return hint, fmt.Errorf("inlayhint: invalid data: %w", err)
+	}
+	parsed := recipe.Parse(text(data.URI))
+	d, ok := parsed.TimerNames()[data.Timer]
+	if !ok {
+		return hint, nil
+	}
+	hint.Tooltip = tooltip(parsed, d)
+	return hint, nil
+}
+
+// tooltip describes the step that starts the timer.
+func tooltip(r recipe.Recipe, d recipe.TimerDeclaration) *messages.StringOrMarkupContent {
+	duration := strings.TrimSpace(d.Timer.Quantity + " " + d.Timer.Unit)
+	value := fmt.Sprintf("**%s** is started for %s in step %d:\n\n> %s", d.Timer.Name, duration, d.StepIndex+1, r.Steps[d.StepIndex].Text)
+	return &messages.StringOrMarkupContent{
+		MarkupContent: &messages.MarkupContent{
+			Kind:  messages.MarkupKindMarkdown,
+			Value: value,
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package inlayhint
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+const pasta = "Leave the dough to ~rest{30%minutes}.\n\nRoll the dough.\n\nAfter the ~Rest, and a ~rest{}, cut the ~unknown.\n\nStart the ~boil."
+
+func everything(text string) messages.Range {
+	return messages.Range{Start: messages.NewPosition(0, 0), End: messages.NewPosition(100, 0)}
+}
+
+func TestTimers(t *testing.T) {
+	uri := "file:///pasta.cook"
+	hints := Timers(uri, pasta, everything(pasta), false)
+	expected := []messages.InlayHint{
+		{
+			Position:    messages.NewPosition(4, 15),
+			Label:       messages.InlayHintLabel{Value: "30 minutes"},
+			Kind:        messages.InlayHintKindType,
+			PaddingLeft: true,
+			Data:        json.RawMessage(`{"uri":"file:///pasta.cook","timer":"rest"}`),
+		},
+		{
+			Position:    messages.NewPosition(4, 30),
+			Label:       messages.InlayHintLabel{Value: "30 minutes"},
+			Kind:        messages.InlayHintKindType,
+			PaddingLeft: true,
+			Data:        json.RawMessage(`{"uri":"file:///pasta.cook","timer":"rest"}`),
+		},
+	}
+	if !reflect.DeepEqual(hints, expected) {
+		t.Errorf("expected %#v\ngot %#v", expected, hints)
+	}
+
+	visible := messages.Range{Start: messages.NewPosition(4, 0), End: messages.NewPosition(4, 20)}
+	if hints := Timers(uri, pasta, visible, false); len(hints) != 1 {
+		t.Errorf("expected only the hint in the range, got %#v", hints)
+	}
+	if hints := Timers(uri, "Boil @water.", everything(""), false); hints == nil || len(hints) != 0 {
+		t.Errorf("expected no hints, got %#v", hints)
+	}
+}
+
+func TestTimersWithTooltips(t *testing.T) {
+	hints := Timers("file:///pasta.cook", pasta, everything(pasta), true)
+	if len(hints) != 2 {
+		t.Fatalf("expected 2 hints, got %d", len(hints))
+	}
+	for _, hint := range hints {
+		if hint.Tooltip == nil || hint.Tooltip.MarkupContent == nil {
+			t.Errorf("expected a tooltip, got %#v", hint.Tooltip)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	hints := Timers("file:///pasta.cook", pasta, everything(pasta), false)
+	texts := map[string]string{"file:///pasta.cook": pasta}
+	resolved, err := Resolve(hints[0], func(uri string) string { return texts[uri] })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &messages.StringOrMarkupContent{
+		MarkupContent: &messages.MarkupContent{
+			Kind:  messages.MarkupKindMarkdown,
+			Value: "**rest** is started for 30 minutes in step 1:\n\n> Leave the dough to 30 minutes.",
+		},
+	}
+	if !reflect.DeepEqual(resolved.Tooltip, expected) {
+		t.Errorf("expected %#v, got %#v", expected.MarkupContent, resolved.Tooltip)
+	}
+
+	// The timer was removed before the hint was resolved.
+	texts["file:///pasta.cook"] = "Roll the dough."
+	resolved, err = Resolve(hints[0], func(uri string) string { return texts[uri] })
+	if err != nil || resolved.Tooltip != nil {
+		t.Errorf("expected the hint to be unchanged, got %#v, %v", resolved, err)
+	}
+
+	if _, err := Resolve(messages.InlayHint{Data: json.RawMessage(`[]`)}, nil); err == nil {
+		t.Error("expected an error for invalid data")
+	}
+}
+
+func TestProvideThenResolve(t *testing.T) {
+	uri := "file:///pasta.cook"
+	text := func(string) string { return pasta }
+
+	r, w, client := lsptest.New()
+	t.Cleanup(func() { client.Close() })
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		return lsp.NewCapabilityBuilder(m).Build(capabilities), nil
+	})
+	m.HandleMethod(messages.InlayHintRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var params messages.InlayHintParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		return Timers(params.TextDocument.URI, text(params.TextDocument.URI), params.Range, false), nil
+	})
+	m.HandleMethod(messages.InlayHintResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var hint messages.InlayHint
+		if err = json.Unmarshal(rawParams, &hint); err != nil {
+			return
+		}
+		return Resolve(hint, text)
+	})
+	go m.Process()
+	var initialized messages.InitializeResult
+	if err := client.Call("initialize", messages.InitializeParams{}, &initialized); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if p := initialized.Capabilities.InlayHintProvider; p == nil || !p.ResolveProvider {
+		t.Fatalf("expected inlay hints to be resolved, got %#v", p)
+	}
+	if err := client.Notify("initialized", nil); err != nil {
+		t.Fatalf("failed to send initialized: %v", err)
+	}
+
+	var hints []messages.InlayHint
+	err := client.Call(messages.InlayHintRequestMethod, messages.InlayHintParams{
+		TextDocument: messages.TextDocumentIdentifier{URI: uri},
+		Range:        everything(pasta),
+	}, &hints)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hints) != 2 {
+		t.Fatalf("expected 2 hints, got %d", len(hints))
+	}
+	for _, hint := range hints {
+		if hint.Tooltip != nil {
+			t.Errorf("expected hints to be returned without tooltips, got %#v", hint.Tooltip)
+		}
+	}
+
+	var resolved messages.InlayHint
+	if err := client.Call(messages.InlayHintResolveRequestMethod, hints[1], &resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Tooltip == nil || resolved.Tooltip.MarkupContent == nil || resolved.Tooltip.MarkupContent.Kind != messages.MarkupKindMarkdown {
+		t.Errorf("expected a Markdown tooltip after resolve, got %#v", resolved.Tooltip)
+	}
+	if resolved.Position != hints[1].Position || resolved.Label.Value != hints[1].Label.Value || string(resolved.Data) != string(hints[1].Data) {
+		t.Errorf("expected the hint to be unchanged apart from its tooltip, got %#v", resolved)
+	}
+}
@@ -0,0 +1,186 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestCall(t *testing.T) {
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleMethod("ask", func(params json.RawMessage) (result any, err error) {
+			var answer string
+			err = m.Call(context.Background(), "client/question", "ping", &answer)
+			return answer, err
+		})
+	})
+
+	go func() {
+		req, err := client.WaitForRequest("client/question")
+		if err != nil {
+			t.Errorf("expected a request from the server: %v", err)
+			return
+		}
+		var question string
+		if err := json.Unmarshal(req.Params, &question); err != nil {
+			t.Errorf("failed to decode params: %v", err)
+		}
+		client.Respond(req.ID, question+" pong", nil)
+	}()
+
+	var result string
+	if err := client.Call("ask", nil, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "ping pong" {
+		t.Errorf("expected %q, got %q", "ping pong", result)
+	}
+}
+
+func TestCallErrorResponse(t *testing.T) {
+	callErr := make(chan error, 1)
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleNotification("ask", func(params json.RawMessage) (err error) {
+			callErr <- m.Call(context.Background(), "client/question", nil, nil)
+			return nil
+		})
+	})
+	if err := client.Notify("ask", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	req, err := client.WaitForRequest("client/question")
+	if err != nil {
+		t.Fatalf("expected a request from the server: %v", err)
+	}
+	client.Respond(req.ID, nil, lsp.ErrInvalidParams)
+
+	var rpcErr *lsp.Error
+	if err := <-callErr; !errors.As(err, &rpcErr) || rpcErr.Code != lsp.ErrInvalidParams.Code {
+		t.Errorf("expected an invalid params error, got %v", err)
+	}
+}
+
+func TestCallContextCancelled(t *testing.T) {
+	callErr := make(chan error, 1)
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleNotification("ask", func(params json.RawMessage) (err error) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+			defer cancel()
+			callErr <- m.Call(ctx, "client/question", nil, nil)
+			return nil
+		})
+	})
+	if err := client.Notify("ask", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if err := <-callErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestCallStopped(t *testing.T) {
+	callErr := make(chan error, 1)
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleNotification("ask", func(params json.RawMessage) (err error) {
+			callErr <- m.Call(context.Background(), "client/question", nil, nil)
+			return nil
+		})
+	})
+	if err := client.Notify("ask", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if _, err := client.WaitForRequest("client/question"); err != nil {
+		t.Fatalf("expected a request from the server: %v", err)
+	}
+	client.Close()
+
+	if err := <-callErr; !errors.Is(err, lsp.ErrStopped) {
+		t.Errorf("expected ErrStopped, got %v", err)
+	}
+}
+
+func TestCallIDsAreNamespaced(t *testing.T) {
+	ids := map[string]bool{}
+	// Each Mux is a new connection, which must not reuse the IDs of the
+	// previous connection.
+	for i := 0; i < 2; i++ {
+		_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+			m.HandleNotification("ask", func(params json.RawMessage) (err error) {
+				return m.Call(context.Background(), "client/question", nil, nil)
+			})
+		})
+		for j := 0; j < 2; j++ {
+			if err := client.Notify("ask", nil); err != nil {
+				t.Fatalf("failed to notify: %v", err)
+			}
+			req, err := client.WaitForRequest("client/question")
+			if err != nil {
+				t.Fatalf("expected a request from the server: %v", err)
+			}
+			var id string
+			if err := json.Unmarshal(*req.ID, &id); err != nil || !strings.HasPrefix(id, lsp.CallIDPrefix) {
+				t.Fatalf("expected a string ID starting with %q, got %s", lsp.CallIDPrefix, *req.ID)
+			}
+			if ids[id] {
+				t.Errorf("ID %q was reused", id)
+			}
+			ids[id] = true
+			client.Respond(req.ID, nil, nil)
+		}
+	}
+}
+
+func TestCallRejectsStaleResponses(t *testing.T) {
+	answers := make(chan string, 2)
+	m, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleNotification("ask", func(params json.RawMessage) (err error) {
+			var answer string
+			err = m.Call(context.Background(), "client/question", nil, &answer)
+			answers <- answer
+			return err
+		})
+	})
+	ask := func() lsptest.Request {
+		t.Helper()
+		if err := client.Notify("ask", nil); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+		req, err := client.WaitForRequest("client/question")
+		if err != nil {
+			t.Fatalf("expected a request from the server: %v", err)
+		}
+		return req
+	}
+
+	first := ask()
+	client.Respond(first.ID, "first", nil)
+	if answer := <-answers; answer != "first" {
+		t.Fatalf("expected %q, got %q", "first", answer)
+	}
+
+	live := ask()
+	// A misbehaving client echoes the ID of the completed request, and sends
+	// responses to requests that were never made.
+	client.Respond(first.ID, "stale", nil)
+	for _, id := range []string{`1`, `"examplelsp#999999999"`, `"other#1"`} {
+		raw := json.RawMessage(id)
+		client.Respond(&raw, "unknown", nil)
+	}
+	client.Respond(live.ID, "live", nil)
+	if answer := <-answers; answer != "live" {
+		t.Errorf("expected the live call to get its own response, got %q", answer)
+	}
+
+	stats := m.CallStats()
+	expected := lsp.CallStats{Sent: 2, Pending: 0, Stale: 1, Unknown: 3}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
@@ -0,0 +1,57 @@
+package lsp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// CallIDPrefix starts the ID of every request sent to the client by Call. The
+// IDs are strings, so they can't be confused with the numeric IDs that most
+// clients use for their own requests.
+const CallIDPrefix = "examplelsp#"
+
+// callIDs is shared by every Mux in the process, so that a Mux created for a
+// new connection doesn't reuse the IDs of an earlier connection.
+var callIDs atomic.Int64
+
+func newCallID() string {
+	return CallIDPrefix + strconv.FormatInt(callIDs.Add(1), 10)
+}
+
+// parseCallID returns the ID, and its sequence number, if it was created by
+// newCallID.
+func parseCallID(raw json.RawMessage) (id string, n int64, ok bool) {
+	if err := json.Unmarshal(raw, &id); err != nil || !strings.HasPrefix(id, CallIDPrefix) {
+		return "", 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimPrefix(id, CallIDPrefix), 10, 64)
+	return id, n, err == nil && n > 0
+}
+
+// CallStats counts the requests sent to the client, and the responses that
+// didn't match a pending request.
+type CallStats struct {
+	// Sent is the number of requests sent by Call.
+	Sent int64 `json:"sent"`
+	// Pending is the number of requests waiting for a response.
+	Pending int `json:"pending"`
+	// Stale responses have the ID of a request that has already received a
+	// response, or that was cancelled, or that belongs to an earlier
+	// connection.
+	Stale int64 `json:"stale"`
+	// Unknown responses have an ID that wasn't sent by any Mux.
+	Unknown int64 `json:"unknown"`
+}
+
+// CallStats returns counters for the requests sent to the client.
+func (m *Mux) CallStats() (s CallStats) {
+	m.pendingLock.Lock()
+	s.Pending = len(m.pending)
+	m.pendingLock.Unlock()
+	s.Sent = m.callsSent.Load()
+	s.Stale = m.staleResponses.Load()
+	s.Unknown = m.unknownResponses.Load()
+	return s
+}
@@ -0,0 +1,115 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestHandleDefault(t *testing.T) {
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleMethod("known", func(params json.RawMessage) (result any, err error) {
+			return "known", nil
+		})
+		m.HandleDefault(func(method string, params json.RawMessage) (result any, err error) {
+			switch method {
+			case "result":
+				return "fallback: " + method, nil
+			case "error":
+				return nil, errors.New("fallback failed")
+			}
+			return nil, lsp.ErrMethodNotFound
+		})
+	})
+
+	tests := []struct {
+		method         string
+		expectedResult string
+		expectedCode   int64
+	}{
+		{
+			method:         "known",
+			expectedResult: "known",
+		},
+		{
+			method:         "result",
+			expectedResult: "fallback: result",
+		},
+		{
+			method:       "error",
+			expectedCode: 0,
+		},
+		{
+			method:       "unknown",
+			expectedCode: lsp.ErrMethodNotFound.Code,
+		},
+		{
+			method:       "$/result",
+			expectedCode: lsp.ErrMethodNotFound.Code,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			var result string
+			err := client.Call(test.method, nil, &result)
+			if test.expectedResult != "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result != test.expectedResult {
+					t.Errorf("expected %q, got %q", test.expectedResult, result)
+				}
+				return
+			}
+			var rpcErr *lsp.Error
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("expected an error response, got %v", err)
+			}
+			if rpcErr.Code != test.expectedCode {
+				t.Errorf("expected error code %d, got %d", test.expectedCode, rpcErr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleDefaultNotification(t *testing.T) {
+	received := make(chan string, 4)
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleNotification("initialized", func(params json.RawMessage) (err error) {
+			return nil
+		})
+		m.HandleNotification("known", func(params json.RawMessage) (err error) {
+			received <- "known"
+			return nil
+		})
+		m.HandleDefaultNotification(func(method string, params json.RawMessage) (err error) {
+			received <- "fallback: " + method
+			return nil
+		})
+	})
+
+	for _, method := range []string{"known", "$/cancelRequest", "unknown"} {
+		if err := client.Notify(method, nil); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	expected := map[string]bool{"known": true, "fallback: unknown": true}
+	for count := len(expected); count > 0; count-- {
+		actual := <-received
+		if !expected[actual] {
+			t.Errorf("unexpected notification handled: %q", actual)
+		}
+		delete(expected, actual)
+	}
+	// Notifications are handled concurrently, so allow time for the $/
+	// notification to be handled.
+	select {
+	case actual := <-received:
+		t.Errorf("expected $/ notifications to be dropped, got %q", actual)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
@@ -0,0 +1,111 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+func TestProcessDrainsInFlightHandlers(t *testing.T) {
+	started := make(chan struct{})
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleMethod("slow", func(params json.RawMessage) (result any, err error) {
+			close(started)
+			time.Sleep(time.Millisecond * 100)
+			return "done", nil
+		})
+	})
+
+	var result string
+	callErr := make(chan error, 1)
+	go func() {
+		callErr <- client.Call("slow", nil, &result)
+	}()
+
+	// Close the reader while the handler is running.
+	<-started
+	if err := client.Close(); err != nil {
+		t.Fatalf("failed to close client: %v", err)
+	}
+
+	if err := <-callErr; err != nil {
+		t.Fatalf("expected the slow handler to respond, got %v", err)
+	}
+	if result != "done" {
+		t.Errorf("expected %q, got %q", "done", result)
+	}
+}
+
+func TestProcessWaitsForHandlersBeforeReturning(t *testing.T) {
+	r, w, client := newPipeClient(t)
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	started := make(chan struct{})
+	completed := make(chan struct{})
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		return map[string]any{}, nil
+	})
+	m.HandleNotification("slow", func(params json.RawMessage) (err error) {
+		close(started)
+		time.Sleep(time.Millisecond * 100)
+		close(completed)
+		return nil
+	})
+	processErr := make(chan error, 1)
+	go func() {
+		processErr <- m.Process()
+	}()
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if err := client.Notify("slow", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	<-started
+	client.Close()
+
+	<-processErr
+	select {
+	case <-completed:
+	default:
+		t.Error("expected Process to wait for the handler to complete before returning")
+	}
+}
+
+func TestProcessDrainTimeout(t *testing.T) {
+	r, w, client := newPipeClient(t)
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w, lsp.WithDrainTimeout(time.Millisecond*50))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		return map[string]any{}, nil
+	})
+	m.HandleNotification("stuck", func(params json.RawMessage) (err error) {
+		close(started)
+		<-release
+		return nil
+	})
+	processErr := make(chan error, 1)
+	go func() {
+		processErr <- m.Process()
+	}()
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if err := client.Notify("stuck", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	<-started
+	client.Close()
+
+	select {
+	case <-processErr:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected Process to return after the drain timeout")
+	}
+}
@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+)
+
+// newRunningMux creates a Mux that writes to w, and is ready to send
+// notifications.
+func newRunningMux(w io.Writer, opts ...Option) *Mux {
+	m := NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), strings.NewReader(""), w, opts...)
+	m.setState(StateRunning)
+	return m
+}
+
+func TestWriteDoesNotEscapeHTML(t *testing.T) {
+	var buf bytes.Buffer
+	m := newRunningMux(&buf)
+	params := "<b>Salt</b> & pepper, 🧂 café"
+	for i := 0; i < 2; i++ {
+		if err := m.Notify("test", params); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+
+	r := bufio.NewReader(&buf)
+	for i := 0; i < 2; i++ {
+		frame, err := readMessage(r)
+		if err != nil {
+			t.Fatalf("failed to read message %d: %v", i, err)
+		}
+		var actual string
+		if err := json.Unmarshal(frame.Params, &actual); err != nil {
+			t.Fatalf("failed to decode params: %v", err)
+		}
+		if actual != params {
+			t.Errorf("expected %q, got %q", params, actual)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected Content-Length to match the body, but %q was left over", buf.String())
+	}
+}
+
+func TestWriteContentLength(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		expectedBody string
+	}{
+		{
+			name:         "the default encoder does not escape HTML",
+			expectedBody: `{"jsonrpc":"2.0","method":"test","params":"<é>"}`,
+		},
+		{
+			name: "custom marshal functions are used",
+			opts: []Option{
+				WithMarshal(func(v any) ([]byte, error) {
+					return []byte(`{"custom":"<é>"}`), nil
+				}),
+			},
+			expectedBody: `{"custom":"<é>"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			m := newRunningMux(&buf, test.opts...)
+			if err := m.Notify("test", "<é>"); err != nil {
+				t.Fatalf("failed to notify: %v", err)
+			}
+			header, body, ok := strings.Cut(buf.String(), "\r\n\r\n")
+			if !ok {
+				t.Fatalf("expected a header, got %q", buf.String())
+			}
+			if body != test.expectedBody {
+				t.Errorf("expected body %s, got %s", test.expectedBody, body)
+			}
+			expectedHeader := "Content-Length: " + strconv.Itoa(len(test.expectedBody))
+			if header != expectedHeader {
+				t.Errorf("expected header %q, got %q", expectedHeader, header)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/a-h/examplelsp/completion"
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestHandlersCanBeChangedWhileProcessing(t *testing.T) {
+	m, client := newInitializedMux(t, messages.InitializeParams{}, nil)
+	handler := func(result string) lsp.MethodHandler {
+		return func(params json.RawMessage) (any, error) {
+			return result, nil
+		}
+	}
+	expectResult := func(expected string) {
+		t.Helper()
+		var actual string
+		if err := client.Call("dynamic", nil, &actual); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	}
+	expectMethodNotFound := func() {
+		t.Helper()
+		var rpcErr *lsp.Error
+		if err := client.Call("dynamic", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != lsp.ErrMethodNotFound.Code {
+			t.Errorf("expected method not found, got %v", err)
+		}
+	}
+
+	expectMethodNotFound()
+	m.HandleMethod("dynamic", handler("a"))
+	expectResult("a")
+	m.HandleMethod("dynamic", handler("b"))
+	expectResult("b")
+	m.RemoveMethod("dynamic")
+	expectMethodNotFound()
+
+	// Change the handlers while requests and notifications are being handled.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			m.HandleMethod("dynamic", handler("a"))
+			m.HandleNotification("dynamic", func(params json.RawMessage) error { return nil })
+			m.RemoveMethod("dynamic")
+			m.RemoveNotification("dynamic")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		var rpcErr *lsp.Error
+		if err := client.Call("dynamic", nil, nil); err != nil && !errors.As(err, &rpcErr) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := client.Notify("dynamic", nil); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRangeFormattingHandlerReceivesRange(t *testing.T) {
+	m, client := newInitializedMux(t, messages.InitializeParams{}, nil)
+	received := make(chan messages.DocumentRangeFormattingParams, 1)
+	m.HandleMethod(messages.DocumentRangeFormattingRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var params messages.DocumentRangeFormattingParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		received <- params
+		return []messages.TextEdit{}, nil
+	})
+
+	// The range starts part way through the second line, and ends at the
+	// start of the fifth line.
+	params := json.RawMessage(`{
+		"textDocument": {"uri": "file:///recipes/pasta.cook"},
+		"range": {
+			"start": {"line": 1, "character": 7},
+			"end": {"line": 4, "character": 0}
+		},
+		"options": {"tabSize": 2, "insertSpaces": true}
+	}`)
+	var edits []messages.TextEdit
+	if err := client.Call(messages.DocumentRangeFormattingRequestMethod, params, &edits); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := <-received
+	expected := messages.Range{
+		Start: messages.Position{Line: 1, Character: 7},
+		End:   messages.Position{Line: 4, Character: 0},
+	}
+	if actual.Range != expected {
+		t.Errorf("expected range %#v, got %#v", expected, actual.Range)
+	}
+	if actual.TextDocument.URI != "file:///recipes/pasta.cook" {
+		t.Errorf("expected the document URI to be received, got %q", actual.TextDocument.URI)
+	}
+	if actual.Options.TabSize != 2 || !actual.Options.InsertSpaces {
+		t.Errorf("expected the formatting options to be received, got %#v", actual.Options)
+	}
+}
+
+func TestDocumentLinkResolve(t *testing.T) {
+	targets := map[string]string{
+		"tomato": "file:///recipes/sauces/tomato.cook",
+		"pesto":  "file:///recipes/sauces/pesto.cook",
+	}
+	m, client := newInitializedMux(t, messages.InitializeParams{}, nil)
+	// Links are returned without targets, and the name of the linked recipe
+	// is kept in the data, to be looked up when the link is resolved.
+	m.HandleMethod(messages.DocumentLinkRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		return []messages.DocumentLink{
+			{Range: messages.Range{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 15)}, Data: json.RawMessage(`{"name":"tomato"}`)},
+			{Range: messages.Range{Start: messages.NewPosition(1, 9), End: messages.NewPosition(1, 14)}, Data: json.RawMessage(`{"name":"pesto"}`)},
+		}, nil
+	})
+	m.HandleMethod(messages.DocumentLinkResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var link messages.DocumentLink
+		if err = json.Unmarshal(rawParams, &link); err != nil {
+			return
+		}
+		var data struct {
+			Name string `json:"name"`
+		}
+		if err = json.Unmarshal(link.Data, &data); err != nil {
+			return
+		}
+		target := targets[data.Name]
+		link.Target = &target
+		return link, nil
+	})
+
+	var links []messages.DocumentLink
+	if err := client.Call(messages.DocumentLinkRequestMethod, messages.DocumentLinkParams{
+		TextDocument: messages.TextDocumentIdentifier{URI: "file:///recipes/pasta.cook"},
+	}, &links); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(links))
+	}
+	expectedTargets := []string{targets["tomato"], targets["pesto"]}
+	for i, link := range links {
+		if link.Target != nil {
+			t.Errorf("expected links to be returned without targets, got %q", *link.Target)
+		}
+		data := string(link.Data)
+		var resolved messages.DocumentLink
+		if err := client.Call(messages.DocumentLinkResolveRequestMethod, link, &resolved); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved.Range != link.Range {
+			t.Errorf("expected the range to be kept, got %#v", resolved.Range)
+		}
+		if string(resolved.Data) != data {
+			t.Errorf("expected the data to be unchanged, expected %s, got %s", data, resolved.Data)
+		}
+		if resolved.Target == nil || *resolved.Target != expectedTargets[i] {
+			t.Errorf("expected the target to be resolved to %q, got %#v", expectedTargets[i], resolved)
+		}
+	}
+}
+
+func TestCodeLensResolve(t *testing.T) {
+	m, client := newInitializedMux(t, messages.InitializeParams{}, nil)
+	// Lenses are returned without commands, and resolved into a command that
+	// scales the recipe by the factor in the data.
+	m.HandleMethod(messages.CodeLensRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		return []messages.CodeLens{
+			{Range: messages.Range{Start: messages.NewPosition(0, 0), End: messages.NewPosition(0, 14)}, Data: json.RawMessage(`{"factor":2}`)},
+		}, nil
+	})
+	m.HandleMethod(messages.CodeLensResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var lens messages.CodeLens
+		if err = json.Unmarshal(rawParams, &lens); err != nil {
+			return
+		}
+		var data struct {
+			Factor int `json:"factor"`
+		}
+		if err = json.Unmarshal(lens.Data, &data); err != nil {
+			return
+		}
+		lens.Command = &messages.Command{
+			Title:     fmt.Sprintf("Scale by %d", data.Factor),
+			Command:   "examplelsp.scale",
+			Arguments: []json.RawMessage{lens.Data},
+		}
+		return lens, nil
+	})
+
+	var lenses []messages.CodeLens
+	if err := client.Call(messages.CodeLensRequestMethod, messages.CodeLensParams{
+		TextDocument: messages.TextDocumentIdentifier{URI: "file:///recipes/pasta.cook"},
+	}, &lenses); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lenses) != 1 || lenses[0].Command != nil {
+		t.Fatalf("expected 1 unresolved lens, got %#v", lenses)
+	}
+	var resolved messages.CodeLens
+	if err := client.Call(messages.CodeLensResolveRequestMethod, lenses[0], &resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Range != lenses[0].Range {
+		t.Errorf("expected the range to be kept, got %#v", resolved.Range)
+	}
+	if string(resolved.Data) != `{"factor":2}` {
+		t.Errorf("expected the data to be unchanged, got %s", resolved.Data)
+	}
+	if resolved.Command == nil || resolved.Command.Title != "Scale by 2" || resolved.Command.Command != "examplelsp.scale" {
+		t.Errorf("expected the lens to be resolved into a command, got %#v", resolved.Command)
+	}
+}
+
+func TestCompletionItemResolve(t *testing.T) {
+	m, client := newInitializedMux(t, messages.InitializeParams{}, nil)
+	m.HandleMethod(messages.CompletionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		return messages.CompletionList{Items: completion.Units()}, nil
+	})
+	m.HandleMethod(messages.CompletionItemResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		var item messages.CompletionItem
+		if err = json.Unmarshal(rawParams, &item); err != nil {
+			return
+		}
+		return completion.Resolve(item, true), nil
+	})
+
+	var list messages.CompletionList
+	if err := client.Call(messages.CompletionRequestMethod, messages.CompletionParams{}, &list); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Items) == 0 || list.Items[0].Documentation != nil {
+		t.Fatalf("expected items without documentation, got %#v", list.Items)
+	}
+	var resolved messages.CompletionItem
+	if err := client.Call(messages.CompletionItemResolveRequestMethod, list.Items[0], &resolved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Label != list.Items[0].Label || resolved.SortText != list.Items[0].SortText || string(resolved.Data) != string(list.Items[0].Data) {
+		t.Errorf("expected the item to be kept, got %#v", resolved)
+	}
+	if resolved.Documentation == nil || resolved.Documentation.MarkupContent == nil {
+		t.Errorf("expected the documentation to be filled in, got %#v", resolved.Documentation)
+	}
+}
@@ -0,0 +1,214 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// InitializeHandler returns the capabilities of the server, based on the
+// parameters sent by the client.
+type InitializeHandler func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error)
+
+// WithServerInfo sets the server information returned by the initialize
+// handler registered with HandleInitialize.
+func WithServerInfo(info messages.ServerInfo) Option {
+	return func(m *Mux) {
+		m.serverInfo = &info
+	}
+}
+
+// HandleInitialize sets the handler for the initialize request. The params are
+// decoded and stored, so that they're available from InitializeParams, and
+// the result includes the server information set by WithServerInfo.
+//
+// The initialize request is only accepted once. If the handler returns an
+// error, the client can try again.
+func (m *Mux) HandleInitialize(h InitializeHandler) {
+	m.HandleMethod("initialize", func(rawParams json.RawMessage) (result any, err error) {
+		var params messages.InitializeParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return nil, ErrInvalidParams
+		}
+		capabilities, err := h(params)
+		if err != nil {
+			return nil, err
+		}
+		m.initializeParams.Store(&params)
+		m.serverCapabilities.Store(&capabilities)
+		return messages.InitializeResult{
+			Capabilities: capabilities,
+			ServerInfo:   m.serverInfo,
+		}, nil
+	})
+}
+
+// InitializeParams returns the params of the initialize request handled by
+// the handler registered with HandleInitialize. ok is false until the request
+// has been handled successfully.
+func (m *Mux) InitializeParams() (params messages.InitializeParams, ok bool) {
+	p := m.initializeParams.Load()
+	if p == nil {
+		return params, false
+	}
+	return *p, true
+}
+
+// ServerCapabilities returns the capabilities returned by the handler
+// registered with HandleInitialize. ok is false until the request has been
+// handled successfully.
+func (m *Mux) ServerCapabilities() (capabilities messages.ServerCapabilities, ok bool) {
+	c := m.serverCapabilities.Load()
+	if c == nil {
+		return capabilities, false
+	}
+	return *c, true
+}
+
+// PositionEncoding returns the position encoding in the capabilities returned
+// by the initialize handler, or UTF-16 if the server didn't pick one.
+func (m *Mux) PositionEncoding() messages.PositionEncodingKind {
+	if c := m.serverCapabilities.Load(); c != nil && c.PositionEncoding != "" {
+		return c.PositionEncoding
+	}
+	return messages.PositionEncodingKindUTF16
+}
+
+// NegotiatePositionEncoding returns UTF-8 if the client supports it, and
+// UTF-16, which all clients support, otherwise. The server must convert
+// positions to and from the encoding that it advertises.
+func NegotiatePositionEncoding(c messages.ClientCapabilities) messages.PositionEncodingKind {
+	if c.General != nil {
+		for _, encoding := range c.General.PositionEncodings {
+			if encoding == messages.PositionEncodingKindUTF8 {
+				return messages.PositionEncodingKindUTF8
+			}
+		}
+	}
+	return messages.PositionEncodingKindUTF16
+}
+
+// CapabilityBuilder enables the capabilities for the textDocument/* and
+// workspace/symbol methods that have handlers registered, so that the capabilities advertised to the
+// client match the handlers. Handlers must be registered before Build is
+// called.
+type CapabilityBuilder struct {
+	m *Mux
+}
+
+func NewCapabilityBuilder(m *Mux) CapabilityBuilder {
+	return CapabilityBuilder{m: m}
+}
+
+// Build returns c with the capabilities for registered handlers enabled.
+// Capabilities that are already set in c are not changed.
+func (b CapabilityBuilder) Build(c messages.ServerCapabilities) messages.ServerCapabilities {
+	b.m.handlersLock.RLock()
+	defer b.m.handlersLock.RUnlock()
+	for method := range b.m.methodHandlers {
+		switch method {
+		case messages.CompletionRequestMethod:
+			if c.CompletionProvider == nil {
+				_, resolve := b.m.methodHandlers[messages.CompletionItemResolveRequestMethod]
+				c.CompletionProvider = &messages.CompletionOptions{ResolveProvider: resolve}
+			}
+		case messages.CodeActionRequestMethod:
+			if c.CodeActionProvider == nil {
+				c.CodeActionProvider = &messages.CodeActionOptions{}
+			}
+		case messages.SignatureHelpRequestMethod:
+			if c.SignatureHelpProvider == nil {
+				c.SignatureHelpProvider = &messages.SignatureHelpOptions{}
+			}
+		case messages.HoverRequestMethod:
+			c.HoverProvider = true
+		case messages.DefinitionRequestMethod:
+			c.DefinitionProvider = true
+		case messages.TypeDefinitionRequestMethod:
+			if c.TypeDefinitionProvider == nil {
+				c.TypeDefinitionProvider = &messages.BoolOrTypeDefinitionOptions{Bool: true}
+			}
+		case messages.ImplementationRequestMethod:
+			if c.ImplementationProvider == nil {
+				c.ImplementationProvider = &messages.BoolOrImplementationOptions{Bool: true}
+			}
+		case messages.ReferencesRequestMethod:
+			c.ReferencesProvider = true
+		case messages.RenameRequestMethod:
+			c.RenameProvider = true
+		case messages.DocumentSymbolRequestMethod:
+			c.DocumentSymbolProvider = true
+		case messages.LinkedEditingRangeRequestMethod:
+			c.LinkedEditingRangeProvider = true
+		case messages.InlineValueRequestMethod:
+			c.InlineValueProvider = true
+		case messages.FoldingRangeRequestMethod:
+			c.FoldingRangeProvider = true
+		case messages.WorkspaceSymbolRequestMethod:
+			if c.WorkspaceSymbolProvider == nil {
+				c.WorkspaceSymbolProvider = &messages.BoolOrWorkspaceSymbolOptions{Bool: true}
+				if _, resolve := b.m.methodHandlers[messages.WorkspaceSymbolResolveRequestMethod]; resolve {
+					c.WorkspaceSymbolProvider.Options = &messages.WorkspaceSymbolOptions{ResolveProvider: true}
+				}
+			}
+		case messages.DocumentFormattingRequestMethod:
+			c.DocumentFormattingProvider = true
+		case messages.DocumentRangeFormattingRequestMethod:
+			c.DocumentRangeFormattingProvider = true
+		case messages.DocumentLinkRequestMethod:
+			if c.DocumentLinkProvider == nil {
+				_, resolve := b.m.methodHandlers[messages.DocumentLinkResolveRequestMethod]
+				c.DocumentLinkProvider = &messages.DocumentLinkOptions{ResolveProvider: resolve}
+			}
+		case messages.CodeLensRequestMethod:
+			if c.CodeLensProvider == nil {
+				_, resolve := b.m.methodHandlers[messages.CodeLensResolveRequestMethod]
+				c.CodeLensProvider = &messages.CodeLensOptions{ResolveProvider: resolve}
+			}
+		case messages.DocumentColorRequestMethod:
+			if c.ColorProvider == nil {
+				c.ColorProvider = &messages.BoolOrDocumentColorOptions{Bool: true}
+			}
+		case messages.DocumentDiagnosticRequestMethod:
+			if c.DiagnosticProvider == nil {
+				c.DiagnosticProvider = &messages.DiagnosticRegistrationOptions{}
+			}
+		case messages.InlayHintRequestMethod:
+			if c.InlayHintProvider == nil {
+				_, resolve := b.m.methodHandlers[messages.InlayHintResolveRequestMethod]
+				c.InlayHintProvider = &messages.InlayHintOptions{ResolveProvider: resolve}
+			}
+		}
+	}
+	_, didOpen := b.m.notificationHandlers[messages.DidOpenTextDocumentNotification]
+	_, didChange := b.m.notificationHandlers[messages.DidChangeTextDocumentNotification]
+	_, didClose := b.m.notificationHandlers[messages.DidCloseTextDocumentNotification]
+	_, didSave := b.m.notificationHandlers[messages.DidSaveTextDocumentNotification]
+	if didOpen || didChange || didClose {
+		c.TextDocumentSync.OpenClose = true
+		if c.TextDocumentSync.Change == messages.TextDocumentSyncKindNone {
+			c.TextDocumentSync.Change = messages.TextDocumentSyncKindFull
+		}
+	}
+	if didSave && c.TextDocumentSync.Save == nil {
+		c.TextDocumentSync.Save = &messages.SaveOptions{}
+	}
+	if _, ok := b.m.notificationHandlers[messages.DidChangeWorkspaceFoldersNotification]; ok {
+		if c.Workspace == nil {
+			c.Workspace = &messages.WorkspaceServerCapabilities{}
+		}
+		if c.Workspace.WorkspaceFolders == nil {
+			c.Workspace.WorkspaceFolders = &messages.WorkspaceFoldersServerCapabilities{
+				Supported:           true,
+				ChangeNotifications: &messages.StringOrBool{Bool: true},
+			}
+		}
+	}
+	if _, ok := b.m.notificationHandlers[messages.WillSaveTextDocumentNotification]; ok {
+		c.TextDocumentSync.WillSave = true
+	}
+	if _, ok := b.m.methodHandlers[messages.WillSaveWaitUntilTextDocumentRequestMethod]; ok {
+		c.TextDocumentSync.WillSaveWaitUntil = true
+	}
+	return c
+}
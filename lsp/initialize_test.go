@@ -0,0 +1,308 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+func TestHandleInitialize(t *testing.T) {
+	r, w, client := newPipeClient(t)
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w, lsp.WithServerInfo(messages.ServerInfo{Name: "test"}))
+	var calls int
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		calls++
+		if calls == 1 {
+			return capabilities, errors.New("not ready")
+		}
+		return messages.ServerCapabilities{HoverProvider: true}, nil
+	})
+	go m.Process()
+
+	params := messages.InitializeParams{ClientInfo: &messages.ClientInfo{Name: "client"}}
+	if err := client.Call("initialize", params, nil); err == nil {
+		t.Fatal("expected the first initialize request to fail")
+	}
+	if _, ok := m.InitializeParams(); ok {
+		t.Error("expected params not to be stored when initialize fails")
+	}
+
+	var result messages.InitializeResult
+	if err := client.Call("initialize", params, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name != "test" {
+		t.Errorf("expected the server info to be returned, got %#v", result.ServerInfo)
+	}
+	if !result.Capabilities.HoverProvider {
+		t.Errorf("expected the capabilities to be returned, got %#v", result.Capabilities)
+	}
+	stored, ok := m.InitializeParams()
+	if !ok || stored.ClientInfo == nil || stored.ClientInfo.Name != "client" {
+		t.Errorf("expected the params to be stored, got %#v", stored)
+	}
+
+	var rpcErr *lsp.Error
+	if err := client.Call("initialize", params, nil); !errors.As(err, &rpcErr) || rpcErr.Code != lsp.ErrInvalidRequest.Code {
+		t.Errorf("expected a second initialize request to be rejected, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the handler to be called twice, got %d", calls)
+	}
+}
+
+func TestCapabilityBuilder(t *testing.T) {
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), nil, io.Discard)
+	handler := func(params json.RawMessage) (result any, err error) { return nil, nil }
+
+	if actual := lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{}); actual.HoverProvider || actual.CompletionProvider != nil || actual.TextDocumentSync != (messages.TextDocumentSyncOptions{}) {
+		t.Errorf("expected no capabilities without handlers, got %#v", actual)
+	}
+
+	m.HandleMethod(messages.HoverRequestMethod, handler)
+	m.HandleMethod(messages.CompletionRequestMethod, handler)
+	m.HandleMethod(messages.CompletionItemResolveRequestMethod, handler)
+	m.HandleMethod(messages.CodeActionRequestMethod, handler)
+	m.HandleMethod(messages.WorkspaceSymbolRequestMethod, handler)
+	m.HandleMethod(messages.DefinitionRequestMethod, handler)
+	m.HandleMethod(messages.TypeDefinitionRequestMethod, handler)
+	m.HandleMethod(messages.ImplementationRequestMethod, handler)
+	m.HandleMethod(messages.ReferencesRequestMethod, handler)
+	m.HandleMethod(messages.RenameRequestMethod, handler)
+	m.HandleMethod(messages.DocumentSymbolRequestMethod, handler)
+	m.HandleMethod(messages.LinkedEditingRangeRequestMethod, handler)
+	m.HandleMethod(messages.InlineValueRequestMethod, handler)
+	m.HandleMethod(messages.FoldingRangeRequestMethod, handler)
+	m.HandleMethod(messages.DocumentFormattingRequestMethod, handler)
+	m.HandleMethod(messages.DocumentRangeFormattingRequestMethod, handler)
+	m.HandleMethod(messages.DocumentLinkRequestMethod, handler)
+	m.HandleMethod(messages.DocumentLinkResolveRequestMethod, handler)
+	m.HandleMethod(messages.InlayHintRequestMethod, handler)
+	m.HandleMethod(messages.SignatureHelpRequestMethod, handler)
+	m.HandleMethod(messages.CodeLensRequestMethod, handler)
+	m.HandleMethod(messages.CodeLensResolveRequestMethod, handler)
+	m.HandleMethod(messages.WorkspaceSymbolResolveRequestMethod, handler)
+	m.HandleMethod(messages.DocumentColorRequestMethod, handler)
+	m.HandleMethod(messages.DocumentDiagnosticRequestMethod, handler)
+	m.HandleNotification(messages.DidChangeTextDocumentNotification, func(params json.RawMessage) (err error) { return nil })
+	m.HandleNotification(messages.DidSaveTextDocumentNotification, func(params json.RawMessage) (err error) { return nil })
+	m.HandleNotification(messages.WillSaveTextDocumentNotification, func(params json.RawMessage) (err error) { return nil })
+	m.HandleNotification(messages.DidChangeWorkspaceFoldersNotification, func(params json.RawMessage) (err error) { return nil })
+	m.HandleMethod(messages.WillSaveWaitUntilTextDocumentRequestMethod, handler)
+	completion := &messages.CompletionOptions{TriggerCharacters: []string{"%"}}
+	actual := lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{
+		TextDocumentSync:   messages.TextDocumentSyncOptions{Change: messages.TextDocumentSyncKindIncremental},
+		CompletionProvider: completion,
+	})
+	if !actual.HoverProvider {
+		t.Error("expected hover to be enabled")
+	}
+	if actual.CodeActionProvider == nil {
+		t.Error("expected code actions to be enabled")
+	}
+	if !actual.DefinitionProvider {
+		t.Error("expected definition to be enabled")
+	}
+	if actual.TypeDefinitionProvider == nil || !actual.TypeDefinitionProvider.Bool {
+		t.Errorf("expected type definition to be enabled, got %#v", actual.TypeDefinitionProvider)
+	}
+	if actual.ImplementationProvider == nil || !actual.ImplementationProvider.Bool {
+		t.Errorf("expected implementation to be enabled, got %#v", actual.ImplementationProvider)
+	}
+	if !actual.ReferencesProvider {
+		t.Error("expected references to be enabled")
+	}
+	if !actual.RenameProvider {
+		t.Error("expected rename to be enabled")
+	}
+	if !actual.DocumentSymbolProvider {
+		t.Error("expected document symbols to be enabled")
+	}
+	if !actual.LinkedEditingRangeProvider {
+		t.Error("expected linked editing ranges to be enabled")
+	}
+	if !actual.InlineValueProvider {
+		t.Error("expected inline values to be enabled")
+	}
+	if !actual.FoldingRangeProvider {
+		t.Error("expected folding ranges to be enabled")
+	}
+	if actual.WorkspaceSymbolProvider == nil || actual.WorkspaceSymbolProvider.Options == nil || !actual.WorkspaceSymbolProvider.Options.ResolveProvider {
+		t.Errorf("expected workspace symbols to be enabled with resolve, got %#v", actual.WorkspaceSymbolProvider)
+	}
+	if !actual.DocumentFormattingProvider {
+		t.Error("expected formatting to be enabled")
+	}
+	if !actual.DocumentRangeFormattingProvider {
+		t.Error("expected range formatting to be enabled")
+	}
+	if actual.DocumentLinkProvider == nil || !actual.DocumentLinkProvider.ResolveProvider {
+		t.Errorf("expected document links to be enabled with resolve, got %#v", actual.DocumentLinkProvider)
+	}
+	if actual.CodeLensProvider == nil || !actual.CodeLensProvider.ResolveProvider {
+		t.Errorf("expected code lenses to be enabled with resolve, got %#v", actual.CodeLensProvider)
+	}
+	if actual.ColorProvider == nil || !actual.ColorProvider.Bool {
+		t.Errorf("expected colors to be enabled, got %#v", actual.ColorProvider)
+	}
+	if actual.DiagnosticProvider == nil {
+		t.Error("expected pull diagnostics to be enabled")
+	}
+	if actual.SignatureHelpProvider == nil {
+		t.Error("expected signature help to be enabled")
+	}
+	if actual.InlayHintProvider == nil || actual.InlayHintProvider.ResolveProvider {
+		t.Errorf("expected inlay hints to be enabled without resolve, got %#v", actual.InlayHintProvider)
+	}
+	if actual.Workspace == nil || actual.Workspace.WorkspaceFolders == nil || !actual.Workspace.WorkspaceFolders.Supported || !actual.Workspace.WorkspaceFolders.ChangeNotifications.Bool {
+		t.Errorf("expected workspace folder change notifications to be enabled, got %#v", actual.Workspace)
+	}
+	if actual.CompletionProvider != completion || completion.ResolveProvider {
+		t.Errorf("expected existing completion options to be kept, got %#v", actual.CompletionProvider)
+	}
+	if actual.TextDocumentSync.Change != messages.TextDocumentSyncKindIncremental {
+		t.Errorf("expected the existing sync kind to be kept, got %v", actual.TextDocumentSync.Change)
+	}
+	if !actual.TextDocumentSync.OpenClose {
+		t.Error("expected open and close notifications to be enabled")
+	}
+	if actual.TextDocumentSync.Save == nil {
+		t.Error("expected save notifications to be enabled")
+	}
+	if !actual.TextDocumentSync.WillSave || !actual.TextDocumentSync.WillSaveWaitUntil {
+		t.Errorf("expected will save notifications and requests to be enabled, got %#v", actual.TextDocumentSync)
+	}
+	if actual := lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{}); actual.CompletionProvider == nil || !actual.CompletionProvider.ResolveProvider {
+		t.Errorf("expected completion to be enabled with resolve, got %#v", actual.CompletionProvider)
+	}
+	if actual := lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{}); actual.TextDocumentSync.Change != messages.TextDocumentSyncKindFull {
+		t.Errorf("expected full sync to be enabled, got %v", actual.TextDocumentSync.Change)
+	}
+}
+
+func TestCapabilityBuilderDocumentLinksWithoutResolve(t *testing.T) {
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), nil, io.Discard)
+	m.HandleMethod(messages.DocumentLinkRequestMethod, func(params json.RawMessage) (result any, err error) { return nil, nil })
+	if actual := lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{}); actual.DocumentLinkProvider == nil || actual.DocumentLinkProvider.ResolveProvider {
+		t.Errorf("expected document links without resolve, got %#v", actual.DocumentLinkProvider)
+	}
+}
+
+func TestInitializeWithLegacyTextDocumentSync(t *testing.T) {
+	r, w, client := newPipeClient(t)
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		return messages.ServerCapabilities{
+			TextDocumentSync: messages.TextDocumentSyncOptions{OpenClose: true, Change: messages.TextDocumentSyncKindFull},
+		}, nil
+	})
+	go m.Process()
+
+	// Older clients don't send synchronization capabilities, and only
+	// understand the numeric sync kind.
+	var result struct {
+		Capabilities struct {
+			TextDocumentSync json.RawMessage `json:"textDocumentSync"`
+		} `json:"capabilities"`
+	}
+	if err := client.Call("initialize", json.RawMessage(`{"capabilities":{}}`), &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Capabilities.TextDocumentSync) != "1" {
+		t.Errorf("expected the sync kind to be sent as a number, got %s", result.Capabilities.TextDocumentSync)
+	}
+	var sync messages.TextDocumentSyncOptions
+	if err := json.Unmarshal(result.Capabilities.TextDocumentSync, &sync); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !sync.OpenClose || sync.Change != messages.TextDocumentSyncKindFull {
+		t.Errorf("expected full sync with open and close, got %#v", sync)
+	}
+}
+
+func TestInitializeNegotiatesPositionEncoding(t *testing.T) {
+	tests := []struct {
+		name              string
+		capabilities      string
+		expectedEncoding  messages.PositionEncodingKind
+		expectedCharacter int
+	}{
+		{
+			name:              "UTF-16 is used when the client doesn't offer encodings",
+			capabilities:      `{}`,
+			expectedEncoding:  messages.PositionEncodingKindUTF16,
+			expectedCharacter: 11,
+		},
+		{
+			name:              "UTF-16 is used when the client doesn't offer UTF-8",
+			capabilities:      `{"general":{"positionEncodings":["utf-32","utf-16"]}}`,
+			expectedEncoding:  messages.PositionEncodingKindUTF16,
+			expectedCharacter: 11,
+		},
+		{
+			name:              "UTF-8 is used when the client offers it",
+			capabilities:      `{"general":{"positionEncodings":["utf-16","utf-8"]}}`,
+			expectedEncoding:  messages.PositionEncodingKindUTF8,
+			expectedCharacter: 12,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, w, client := newPipeClient(t)
+			m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+			m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+				return messages.ServerCapabilities{
+					PositionEncoding: lsp.NegotiatePositionEncoding(params.Capabilities),
+				}, nil
+			})
+			go m.Process()
+
+			var result messages.InitializeResult
+			if err := client.Call("initialize", json.RawMessage(`{"capabilities":`+test.capabilities+`}`), &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Capabilities.PositionEncoding != test.expectedEncoding {
+				t.Errorf("expected %q to be advertised, got %q", test.expectedEncoding, result.Capabilities.PositionEncoding)
+			}
+			if actual := m.PositionEncoding(); actual != test.expectedEncoding {
+				t.Errorf("expected the mux to use %q, got %q", test.expectedEncoding, actual)
+			}
+			if err := client.Notify("initialized", struct{}{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// The trailing whitespace starts after "é", which is two bytes
+			// in UTF-8, and a single code unit in UTF-16.
+			text := "Add @café{}  "
+			diagnostics := analyzers.Whitespace(text)
+			if len(diagnostics) != 1 {
+				t.Fatalf("expected one diagnostic, got %d", len(diagnostics))
+			}
+			diagnostics[0].Range = documents.EncodeRange(text, diagnostics[0].Range, m.PositionEncoding())
+			if err := m.Notify(messages.PublishDiagnosticsMethod, messages.PublishDiagnosticsParams{URI: "file:///recipe.cook", Diagnostics: diagnostics}); err != nil {
+				t.Fatalf("failed to notify: %v", err)
+			}
+			n, err := client.WaitForNotification(messages.PublishDiagnosticsMethod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var published messages.PublishDiagnosticsParams
+			if err := json.Unmarshal(n.Params, &published); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if len(published.Diagnostics) != 1 {
+				t.Fatalf("expected one diagnostic, got %d", len(published.Diagnostics))
+			}
+			if actual := published.Diagnostics[0].Range.Start.Character; actual != test.expectedCharacter {
+				t.Errorf("expected the diagnostic to start at character %d, got %d", test.expectedCharacter, actual)
+			}
+		})
+	}
+}
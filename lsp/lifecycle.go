@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"errors"
+
+	"golang.org/x/exp/slog"
+)
+
+// State of the server lifecycle.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#lifeCycleMessages
+type State int32
+
+const (
+	// StateUninitialized is the state until the initialize request is received.
+	StateUninitialized State = iota
+	// StateInitializing is the state between the initialize request and the
+	// initialized notification.
+	StateInitializing
+	// StateRunning is the state after the initialized notification.
+	StateRunning
+	// StateShuttingDown is the state after the shutdown request.
+	StateShuttingDown
+	// StateExited is the state after the exit notification.
+	StateExited
+)
+
+func (s State) String() string {
+	switch s {
+	case StateUninitialized:
+		return "uninitialized"
+	case StateInitializing:
+		return "initializing"
+	case StateRunning:
+		return "running"
+	case StateShuttingDown:
+		return "shutting down"
+	case StateExited:
+		return "exited"
+	}
+	return "unknown"
+}
+
+// ErrExitWithoutShutdown is returned by Process when the client sends the exit
+// notification without first sending the shutdown request. The spec requires
+// the server to exit with a non-zero exit code in this case.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+// State returns the current lifecycle state of the server.
+func (m *Mux) State() State {
+	return State(m.state.Load())
+}
+
+func (m *Mux) setState(s State) (previous State) {
+	previous = State(m.state.Swap(int32(s)))
+	if previous != s {
+		m.log.Info("server state changed", slog.String("from", previous.String()), slog.String("to", s.String()))
+	}
+	return previous
+}
+
+// accept moves the server through its lifecycle, and returns true if the
+// message is valid in the current state. Invalid requests are responded to
+// with an error, and invalid notifications are dropped.
+func (m *Mux) accept(req Request) (ok bool, err error) {
+	log := m.log.With(slog.String("method", req.Method), slog.String("state", m.State().String()))
+	switch m.State() {
+	case StateUninitialized:
+		if req.IsNotification() {
+			log.Debug("dropping notification sent before initialization")
+			return false, nil
+		}
+		if req.Method != "initialize" {
+			log.Warn("the client sent a method before initialization")
+			return false, m.write(NewResponseError(req.ID, ErrServerNotInitialized))
+		}
+		m.setState(StateInitializing)
+		return true, nil
+	case StateInitializing, StateRunning:
+		if req.Method == "initialize" && !req.IsNotification() {
+			log.Warn("the client sent a second initialize request")
+			return false, m.write(NewResponseError(req.ID, ErrInvalidRequest))
+		}
+		if req.Method == "initialized" && req.IsNotification() {
+			if m.State() != StateInitializing {
+				log.Warn("dropping duplicate initialized notification")
+				return false, nil
+			}
+			m.run()
+			m.log.Info("initialization complete")
+			return true, nil
+		}
+		if req.Method == "shutdown" && !req.IsNotification() {
+			m.setState(StateShuttingDown)
+		}
+		return true, nil
+	case StateShuttingDown:
+		if req.IsNotification() {
+			log.Debug("dropping notification sent after shutdown")
+			return false, nil
+		}
+		if req.Method != "shutdown" {
+			log.Warn("the client sent a method after shutdown")
+			return false, m.write(NewResponseError(req.ID, ErrInvalidRequest))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// allowedDuringInitialization returns true for notifications that the server
+// may send before the client sends the initialized notification.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#initialize
+func allowedDuringInitialization(method string) bool {
+	switch method {
+	case "window/showMessage", "window/logMessage", "telemetry/event", "$/progress":
+		return true
+	}
+	return false
+}
+
+// queue the notification if the client hasn't sent the initialized
+// notification yet, returning true if it was queued.
+func (m *Mux) queue(n Notification) (queued bool) {
+	if allowedDuringInitialization(n.Method) {
+		return false
+	}
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+	switch m.State() {
+	case StateUninitialized, StateInitializing:
+		m.queued = append(m.queued, n)
+		return true
+	}
+	return false
+}
+
+// run moves the server to the running state, and sends the notifications that
+// were queued during initialization, in order. Holding the queue lock ensures
+// that notifications sent in the meantime are written after them.
+func (m *Mux) run() {
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+	m.setState(StateRunning)
+	for _, n := range m.queued {
+		if err := m.write(n); err != nil {
+			m.log.Error("failed to send queued notification", slog.String("method", n.Method), slog.Any("error", err))
+		}
+	}
+	m.queued = nil
+}
@@ -0,0 +1,171 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"golang.org/x/exp/slog"
+)
+
+func TestLifecycle(t *testing.T) {
+	r, w, client := lsptest.New()
+	defer client.Close()
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	var initializeCalls, notificationCalls atomic.Int32
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		initializeCalls.Add(1)
+		return map[string]any{}, nil
+	})
+	m.HandleMethod("hover", func(params json.RawMessage) (result any, err error) {
+		return "hover", nil
+	})
+	m.HandleNotification("didChange", func(params json.RawMessage) (err error) {
+		notificationCalls.Add(1)
+		return nil
+	})
+	processErr := make(chan error, 1)
+	go func() {
+		processErr <- m.Process()
+	}()
+
+	expectState := func(expected lsp.State) {
+		t.Helper()
+		// Notifications don't have a response, so wait for the state to change.
+		deadline := time.Now().Add(time.Second * 5)
+		for m.State() != expected && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if actual := m.State(); actual != expected {
+			t.Fatalf("expected state %v, got %v", expected, actual)
+		}
+	}
+	expectError := func(method string, expected *lsp.Error) {
+		t.Helper()
+		err := client.Call(method, nil, nil)
+		var actual *lsp.Error
+		if !errors.As(err, &actual) {
+			t.Fatalf("%s: expected error %v, got %v", method, expected, err)
+		}
+		if actual.Code != expected.Code {
+			t.Errorf("%s: expected error code %d, got %d", method, expected.Code, actual.Code)
+		}
+	}
+	expectState(lsp.StateUninitialized)
+
+	// Requests before initialize are rejected.
+	expectError("hover", lsp.ErrServerNotInitialized)
+	// Notifications before initialize are dropped.
+	if err := client.Notify("didChange", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	expectError("hover", lsp.ErrServerNotInitialized)
+	if notificationCalls.Load() != 0 {
+		t.Errorf("expected notifications before initialize to be dropped")
+	}
+	expectState(lsp.StateUninitialized)
+
+	// Initialize.
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	expectState(lsp.StateInitializing)
+
+	// A second initialize is rejected, whether it's before or after initialized.
+	expectError("initialize", lsp.ErrInvalidRequest)
+	if err := client.Notify("initialized", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	expectState(lsp.StateRunning)
+	expectError("initialize", lsp.ErrInvalidRequest)
+	if initializeCalls.Load() != 1 {
+		t.Errorf("expected initialize to be handled once, got %d", initializeCalls.Load())
+	}
+
+	// Requests work while running.
+	var result string
+	if err := client.Call("hover", nil, &result); err != nil || result != "hover" {
+		t.Errorf("expected hover result, got %q, %v", result, err)
+	}
+
+	// Shutdown is handled without a registered handler.
+	if err := client.Call("shutdown", nil, nil); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+	expectState(lsp.StateShuttingDown)
+
+	// Requests after shutdown are rejected, except for shutdown.
+	expectError("hover", lsp.ErrInvalidRequest)
+	expectError("initialize", lsp.ErrInvalidRequest)
+	if err := client.Call("shutdown", nil, nil); err != nil {
+		t.Errorf("expected a repeated shutdown to succeed, got %v", err)
+	}
+	expectState(lsp.StateShuttingDown)
+
+	// Exit stops processing.
+	if err := client.Notify("exit", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	select {
+	case err := <-processErr:
+		if err != nil {
+			t.Errorf("expected no error after a clean exit, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for Process to return")
+	}
+	expectState(lsp.StateExited)
+}
+
+func TestLifecycleExitWithoutShutdown(t *testing.T) {
+	r, w, client := lsptest.New()
+	defer client.Close()
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	processErr := make(chan error, 1)
+	go func() {
+		processErr <- m.Process()
+	}()
+	if err := client.Notify("exit", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	select {
+	case err := <-processErr:
+		if !errors.Is(err, lsp.ErrExitWithoutShutdown) {
+			t.Errorf("expected ErrExitWithoutShutdown, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for Process to return")
+	}
+}
+
+func TestLifecycleFailedInitializeCanBeRetried(t *testing.T) {
+	r, w, client := lsptest.New()
+	defer client.Close()
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	var calls atomic.Int32
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("not yet")
+		}
+		return map[string]any{}, nil
+	})
+	go m.Process()
+
+	if err := client.Call("initialize", nil, nil); err == nil {
+		t.Fatal("expected the first initialize to fail")
+	}
+	if m.State() != lsp.StateUninitialized {
+		t.Errorf("expected state %v, got %v", lsp.StateUninitialized, m.State())
+	}
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("expected the second initialize to succeed, got %v", err)
+	}
+	if m.State() != lsp.StateInitializing {
+		t.Errorf("expected state %v, got %v", lsp.StateInitializing, m.State())
+	}
+}
@@ -0,0 +1,219 @@
+// Package lsptest provides a client for testing language servers built on the
+// lsp package.
+package lsptest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+)
+
+// DefaultTimeout is the time that the client waits for a response.
+const DefaultTimeout = time.Second * 5
+
+// ErrTimeout is returned when the server doesn't respond in time.
+var ErrTimeout = errors.New("lsptest: timed out waiting for the server")
+
+// Notification received from the server.
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Request received from the server. Use Respond to send the response.
+type Request struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type message struct {
+	ProtocolVersion string           `json:"jsonrpc"`
+	ID              *json.RawMessage `json:"id"`
+	Method          string           `json:"method"`
+	Params          json.RawMessage  `json:"params"`
+	Result          json.RawMessage  `json:"result"`
+	Error           *lsp.Error       `json:"error"`
+}
+
+// Client is connected to a server using in-memory pipes.
+type Client struct {
+	Timeout time.Duration
+	// Notifications received from the server, in the order they were received.
+	Notifications chan Notification
+	// Requests received from the server, in the order they were received.
+	Requests chan Request
+
+	serverIn  *io.PipeWriter
+	writer    *bufio.Writer
+	writeLock sync.Mutex
+
+	pending     map[string]chan message
+	pendingLock sync.Mutex
+	nextID      int64
+	done        chan struct{}
+}
+
+// New creates a client. Pass the returned reader and writer to lsp.NewMux to
+// connect the server to the client.
+func New() (serverReader io.Reader, serverWriter io.Writer, c *Client) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c = &Client{
+		Timeout:       DefaultTimeout,
+		Notifications: make(chan Notification, 64),
+		Requests:      make(chan Request, 64),
+		serverIn:      inW,
+		writer:        bufio.NewWriter(inW),
+		pending:       map[string]chan message{},
+		done:          make(chan struct{}),
+	}
+	go c.read(bufio.NewReader(outR))
+	return inR, outW, c
+}
+
+func (c *Client) read(r *bufio.Reader) {
+	defer close(c.done)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		if msg.ID == nil {
+			c.Notifications <- Notification{Method: msg.Method, Params: msg.Params}
+			continue
+		}
+		if msg.Method != "" {
+			c.Requests <- Request{ID: msg.ID, Method: msg.Method, Params: msg.Params}
+			continue
+		}
+		c.pendingLock.Lock()
+		ch, ok := c.pending[string(*msg.ID)]
+		delete(c.pending, string(*msg.ID))
+		c.pendingLock.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+}
+
+func readMessage(r *bufio.Reader) (msg message, err error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return
+	}
+	contentLength, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return msg, lsp.ErrInvalidContentLengthHeader
+	}
+	err = json.NewDecoder(io.LimitReader(r, contentLength)).Decode(&msg)
+	return
+}
+
+// Call sends a request to the server and waits for the response. If the
+// server responds with an error, it's returned as an *lsp.Error.
+func (c *Client) Call(method string, params any, result any) (err error) {
+	c.pendingLock.Lock()
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	ch := make(chan message, 1)
+	c.pending[string(id)] = ch
+	c.pendingLock.Unlock()
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	err = c.write(lsp.Request{
+		ProtocolVersion: "2.0",
+		ID:              &id,
+		Method:          method,
+		Params:          rawParams,
+	})
+	if err != nil {
+		return
+	}
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-c.done:
+		return fmt.Errorf("lsptest: server closed the connection")
+	case <-time.After(c.Timeout):
+		return ErrTimeout
+	}
+}
+
+// Notify sends a notification to the server.
+func (c *Client) Notify(method string, params any) (err error) {
+	return c.write(lsp.Notification{
+		ProtocolVersion: "2.0",
+		Method:          method,
+		Params:          params,
+	})
+}
+
+// Respond to a request from the server. If err is not nil, an error response
+// is sent.
+func (c *Client) Respond(id *json.RawMessage, result any, err error) error {
+	if err != nil {
+		return c.write(lsp.NewResponseError(id, err))
+	}
+	return c.write(lsp.NewResponse(id, result))
+}
+
+// WaitForRequest waits for the next request from the server with the given
+// method, discarding any others.
+func (c *Client) WaitForRequest(method string) (r Request, err error) {
+	timeout := time.After(c.Timeout)
+	for {
+		select {
+		case r = <-c.Requests:
+			if r.Method == method {
+				return r, nil
+			}
+		case <-timeout:
+			return r, ErrTimeout
+		}
+	}
+}
+
+func (c *Client) write(msg lsp.Message) (err error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return lsp.Write(c.writer, msg)
+}
+
+// WaitForNotification waits for the next notification with the given method,
+// discarding any others.
+func (c *Client) WaitForNotification(method string) (n Notification, err error) {
+	timeout := time.After(c.Timeout)
+	for {
+		select {
+		case n = <-c.Notifications:
+			if n.Method == method {
+				return n, nil
+			}
+		case <-timeout:
+			return n, ErrTimeout
+		}
+	}
+}
+
+// Close the connection to the server, which causes the server to stop reading.
+func (c *Client) Close() error {
+	return c.serverIn.Close()
+}
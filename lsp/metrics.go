@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives a callback each time the Mux dispatches a request
+// or notification to a handler.
+type MetricsCollector interface {
+	// RequestHandled is called after a method handler returns. err is the error
+	// returned to the client, if any.
+	RequestHandled(method string, duration time.Duration, err error)
+	// NotificationHandled is called after a notification handler returns.
+	NotificationHandled(method string, duration time.Duration, err error)
+}
+
+// maxSamples is the number of recent durations kept per method to calculate
+// latency percentiles.
+const maxSamples = 1000
+
+// NewMemoryMetrics creates a MetricsCollector that keeps counters in memory.
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		requests:      map[string]*methodMetrics{},
+		notifications: map[string]*methodMetrics{},
+	}
+}
+
+// MemoryMetrics is a MetricsCollector that keeps counters in memory.
+type MemoryMetrics struct {
+	m             sync.Mutex
+	requests      map[string]*methodMetrics
+	notifications map[string]*methodMetrics
+}
+
+type methodMetrics struct {
+	count   int64
+	errors  int64
+	samples []time.Duration
+	next    int
+}
+
+func (mm *methodMetrics) add(duration time.Duration, err error) {
+	mm.count++
+	if err != nil {
+		mm.errors++
+	}
+	if len(mm.samples) < maxSamples {
+		mm.samples = append(mm.samples, duration)
+		return
+	}
+	mm.samples[mm.next] = duration
+	mm.next = (mm.next + 1) % maxSamples
+}
+
+func (mm *methodMetrics) snapshot() MethodMetrics {
+	sorted := make([]time.Duration, len(mm.samples))
+	copy(sorted, mm.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return MethodMetrics{
+		Count:  mm.count,
+		Errors: mm.errors,
+		P95:    percentile(sorted, 95),
+	}
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (len(sorted)*p+99)/100 - 1
+	return sorted[index]
+}
+
+func (m *MemoryMetrics) RequestHandled(method string, duration time.Duration, err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	get(m.requests, method).add(duration, err)
+}
+
+func (m *MemoryMetrics) NotificationHandled(method string, duration time.Duration, err error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	get(m.notifications, method).add(duration, err)
+}
+
+func get(metrics map[string]*methodMetrics, method string) *methodMetrics {
+	mm, ok := metrics[method]
+	if !ok {
+		mm = &methodMetrics{}
+		metrics[method] = mm
+	}
+	return mm
+}
+
+// Snapshot returns a copy of the current metrics.
+func (m *MemoryMetrics) Snapshot() (s MetricsSnapshot) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	s.Requests = make(map[string]MethodMetrics, len(m.requests))
+	for method, mm := range m.requests {
+		s.Requests[method] = mm.snapshot()
+	}
+	s.Notifications = make(map[string]MethodMetrics, len(m.notifications))
+	for method, mm := range m.notifications {
+		s.Notifications[method] = mm.snapshot()
+	}
+	return
+}
+
+type MetricsSnapshot struct {
+	Requests      map[string]MethodMetrics `json:"requests"`
+	Notifications map[string]MethodMetrics `json:"notifications"`
+}
+
+type MethodMetrics struct {
+	// Count of messages handled.
+	Count int64 `json:"count"`
+	// Errors returned by the handler.
+	Errors int64 `json:"errors"`
+	// P95 handler latency of the most recent messages, marshalled to JSON in
+	// nanoseconds.
+	P95 time.Duration `json:"p95"`
+}
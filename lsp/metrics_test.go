@@ -0,0 +1,89 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"golang.org/x/exp/slog"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := lsp.NewMemoryMetrics()
+	r, w, client := lsptest.New()
+	defer client.Close()
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w, lsp.WithMetrics(metrics))
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		return map[string]any{}, nil
+	})
+	m.HandleMethod("fail", func(params json.RawMessage) (result any, err error) {
+		return nil, errors.New("failed")
+	})
+	m.HandleNotification("textDocument/didChange", func(params json.RawMessage) (err error) {
+		return nil
+	})
+	go m.Process()
+
+	// Run a scripted session.
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := client.Notify("textDocument/didChange", nil); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	if err := client.Call("fail", nil, nil); err == nil {
+		t.Error("expected an error from the fail method")
+	}
+	if err := client.Call("unknown", nil, nil); err == nil {
+		t.Error("expected an error from the unknown method")
+	}
+
+	// Notifications are handled concurrently, so wait for them to complete.
+	var s lsp.MetricsSnapshot
+	deadline := time.Now().Add(time.Second * 5)
+	for time.Now().Before(deadline) {
+		s = metrics.Snapshot()
+		if s.Notifications["textDocument/didChange"].Count == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	expectedRequests := map[string]lsp.MethodMetrics{
+		"initialize": {Count: 1, Errors: 0},
+		"fail":       {Count: 1, Errors: 1},
+		"unknown":    {Count: 1, Errors: 1},
+	}
+	for method, expected := range expectedRequests {
+		actual := s.Requests[method]
+		if actual.Count != expected.Count || actual.Errors != expected.Errors {
+			t.Errorf("%s: expected count %d and errors %d, got count %d and errors %d", method, expected.Count, expected.Errors, actual.Count, actual.Errors)
+		}
+	}
+	if len(s.Requests) != len(expectedRequests) {
+		t.Errorf("expected %d request methods, got %d", len(expectedRequests), len(s.Requests))
+	}
+	if actual := s.Notifications["textDocument/didChange"]; actual.Count != 3 || actual.Errors != 0 {
+		t.Errorf("didChange: expected count 3 and errors 0, got count %d and errors %d", actual.Count, actual.Errors)
+	}
+}
+
+func TestMetricsPercentile(t *testing.T) {
+	metrics := lsp.NewMemoryMetrics()
+	for i := 1; i <= 100; i++ {
+		metrics.RequestHandled("textDocument/completion", time.Duration(i)*time.Millisecond, nil)
+	}
+	actual := metrics.Snapshot().Requests["textDocument/completion"]
+	if actual.P95 != 95*time.Millisecond {
+		t.Errorf("expected p95 of 95ms, got %v", actual.P95)
+	}
+	if actual.Count != 100 {
+		t.Errorf("expected count of 100, got %d", actual.Count)
+	}
+}
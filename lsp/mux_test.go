@@ -0,0 +1,42 @@
+package lsp_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+// newInitializedMux creates a Mux, registers handlers using setup, and
+// completes initialization with the params using the returned client. The
+// server has no capabilities, unless setup registers its own initialize
+// handler with HandleInitialize.
+func newInitializedMux(t *testing.T, params messages.InitializeParams, setup func(m *lsp.Mux), opts ...lsp.Option) (m *lsp.Mux, client *lsptest.Client) {
+	t.Helper()
+	r, w, client := newPipeClient(t)
+	m = lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w, opts...)
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		return capabilities, nil
+	})
+	if setup != nil {
+		setup(m)
+	}
+	go m.Process()
+	if err := client.Call("initialize", params, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if err := client.Notify("initialized", nil); err != nil {
+		t.Fatalf("failed to send initialized: %v", err)
+	}
+	return m, client
+}
+
+// newPipeClient creates a test client that is closed when the test completes.
+func newPipeClient(t *testing.T) (r io.Reader, w io.Writer, client *lsptest.Client) {
+	r, w, client = lsptest.New()
+	t.Cleanup(func() { client.Close() })
+	return r, w, client
+}
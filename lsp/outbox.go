@@ -0,0 +1,120 @@
+package lsp
+
+import (
+	"bufio"
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// outbox writes messages to the client in the order they're sent, from a
+// single goroutine, so that messages can wait while the client is slow to
+// read.
+//
+// Only the latest diagnostics for a document matter, so while the client is
+// slow, a publishDiagnostics notification replaces one for the same document
+// that's still waiting. The new notification takes the place of the old one
+// at the end of the queue, so it's never written before a message that was
+// sent before it.
+type outbox struct {
+	w     *bufio.Writer
+	error func(err error)
+	once  sync.Once
+	wake  chan struct{}
+	lock  sync.Mutex
+	items []outgoing
+}
+
+type outgoing struct {
+	// body of the message, or nil to flush the writer.
+	body []byte
+	// uri of the document, if the message is a publishDiagnostics
+	// notification.
+	uri string
+	// done receives the result of the write. It's nil if the sender doesn't
+	// wait for the message to be written.
+	done chan error
+}
+
+func newOutbox(w *bufio.Writer, onError func(err error)) *outbox {
+	return &outbox{
+		w:     w,
+		error: onError,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// send queues the message, returning a channel that receives the result of
+// the write if wait is true.
+func (o *outbox) send(body []byte, uri string, wait bool) (done chan error) {
+	o.once.Do(func() { go o.run() })
+	if wait {
+		done = make(chan error, 1)
+	}
+	o.lock.Lock()
+	if uri != "" {
+		for i, item := range o.items {
+			if item.uri == uri {
+				o.items = append(o.items[:i], o.items[i+1:]...)
+				break
+			}
+		}
+	}
+	o.items = append(o.items, outgoing{body: body, uri: uri, done: done})
+	o.lock.Unlock()
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return done
+}
+
+// flush waits for the messages that are already queued to be written, then
+// flushes the writer.
+func (o *outbox) flush() error {
+	return <-o.send(nil, "", true)
+}
+
+func (o *outbox) run() {
+	for range o.wake {
+		for {
+			o.lock.Lock()
+			if len(o.items) == 0 {
+				o.lock.Unlock()
+				break
+			}
+			item := o.items[0]
+			o.items = o.items[1:]
+			o.lock.Unlock()
+			var err error
+			if item.body == nil {
+				err = o.w.Flush()
+			} else {
+				err = writeBody(o.w, item.body)
+			}
+			if item.done != nil {
+				item.done <- err
+				continue
+			}
+			if err != nil {
+				o.error(err)
+			}
+		}
+	}
+}
+
+// diagnosticsURI returns the URI of the document if the notification
+// publishes diagnostics.
+func diagnosticsURI(msg Message) (uri string) {
+	n, ok := msg.(Notification)
+	if !ok || n.Method != messages.PublishDiagnosticsMethod {
+		return ""
+	}
+	switch params := n.Params.(type) {
+	case messages.PublishDiagnosticsParams:
+		return params.URI
+	case *messages.PublishDiagnosticsParams:
+		return params.URI
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// blockingWriter blocks writes until it's unblocked.
+type blockingWriter struct {
+	unblock chan struct{}
+	blocked chan struct{}
+	once    sync.Once
+	lock    sync.Mutex
+	buf     bytes.Buffer
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		unblock: make(chan struct{}),
+		blocked: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(p []byte) (n int, err error) {
+	w.once.Do(func() { close(w.blocked) })
+	<-w.unblock
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Bytes() []byte {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]byte(nil), w.buf.Bytes()...)
+}
+
+func publish(t *testing.T, m *Mux, uri string, version int) {
+	t.Helper()
+	err := m.Notify(messages.PublishDiagnosticsMethod, messages.PublishDiagnosticsParams{
+		URI:         uri,
+		Version:     &version,
+		Diagnostics: []messages.Diagnostic{},
+	})
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+}
+
+// readPublished returns the URI and version of each publishDiagnostics
+// notification written.
+func readPublished(t *testing.T, data []byte) (published []string) {
+	t.Helper()
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			return published
+		}
+		var params messages.PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatalf("failed to decode params: %v", err)
+		}
+		published = append(published, fmt.Sprintf("%s@%d", params.URI, *params.Version))
+	}
+}
+
+func TestPublishDiagnosticsAreCoalesced(t *testing.T) {
+	w := newBlockingWriter()
+	m := newRunningMux(w)
+
+	// Block the writer with diagnostics for another document.
+	publish(t, m, "file:///a.cook", 1)
+	select {
+	case <-w.blocked:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the writer to block")
+	}
+	for version := 1; version <= 5; version++ {
+		publish(t, m, "file:///b.cook", version)
+	}
+	close(w.unblock)
+	if err := m.outbox.flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	expected := []string{"file:///a.cook@1", "file:///b.cook@5"}
+	if actual := readPublished(t, w.Bytes()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestPublishDiagnosticsKeepOrder(t *testing.T) {
+	w := newBlockingWriter()
+	m := newRunningMux(w)
+
+	publish(t, m, "file:///a.cook", 1)
+	<-w.blocked
+	publish(t, m, "file:///b.cook", 1)
+	publish(t, m, "file:///c.cook", 1)
+	// The replacement is written after the diagnostics that were published
+	// before it.
+	publish(t, m, "file:///b.cook", 2)
+	close(w.unblock)
+	if err := m.outbox.flush(); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+
+	expected := []string{"file:///a.cook@1", "file:///c.cook@1", "file:///b.cook@2"}
+	if actual := readPublished(t, w.Bytes()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// ErrPartialResultNotArray is returned when a batch of partial results isn't
+// a JSON array.
+var ErrPartialResultNotArray = errors.New("lsp: partial results must be arrays")
+
+// PartialResultSender sends the result of a request in batches.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#partialResults
+type PartialResultSender struct {
+	m     *Mux
+	token *messages.ProgressToken
+	lock  sync.Mutex
+	items []json.RawMessage
+}
+
+// PartialResultSender returns a sender that streams batches of results to the
+// client as $/progress notifications if the client provided a
+// partialResultToken. Without a token, the batches are accumulated, and
+// returned together by Result.
+func (m *Mux) PartialResultSender(token *messages.ProgressToken) *PartialResultSender {
+	return &PartialResultSender{
+		m:     m,
+		token: token,
+		items: []json.RawMessage{},
+	}
+}
+
+// Streaming returns true if batches are sent to the client as they're
+// produced.
+func (s *PartialResultSender) Streaming() bool {
+	return s.token != nil
+}
+
+// Send a batch of results. The batch must marshal to a JSON array.
+func (s *PartialResultSender) Send(batch any) (err error) {
+	body, err := Marshal(batch)
+	if err != nil {
+		return err
+	}
+	var items []json.RawMessage
+	if err = json.Unmarshal(body, &items); err != nil {
+		return ErrPartialResultNotArray
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if s.Streaming() {
+		return s.m.Notify(messages.ProgressMethod, messages.ProgressParams{
+			Token: *s.token,
+			Value: json.RawMessage(body),
+		})
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items = append(s.items, items...)
+	return nil
+}
+
+// Result to return from the handler. When streaming, the result is empty,
+// because the client has already received the batches.
+func (s *PartialResultSender) Result() any {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.items
+}
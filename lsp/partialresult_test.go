@@ -0,0 +1,88 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+)
+
+// handleNumbers registers a handler that sends its result in two batches.
+func handleNumbers(m *lsp.Mux) {
+	m.HandleMethod("numbers", func(rawParams json.RawMessage) (result any, err error) {
+		var params messages.PartialResultParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		sender := m.PartialResultSender(params.PartialResultToken)
+		for _, batch := range [][]int{{1, 2}, {}, {3}} {
+			if err = sender.Send(batch); err != nil {
+				return
+			}
+		}
+		return sender.Result(), nil
+	})
+}
+
+func TestPartialResultSenderStreaming(t *testing.T) {
+	_, client := newInitializedMux(t, messages.InitializeParams{}, handleNumbers)
+
+	var result []int
+	token := messages.NewStringProgressToken("token-1")
+	params := messages.PartialResultParams{PartialResultToken: &token}
+	if err := client.Call("numbers", params, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected an empty final result, got %v", result)
+	}
+
+	var batches [][]int
+	for i := 0; i < 2; i++ {
+		n, err := client.WaitForNotification(messages.ProgressMethod)
+		if err != nil {
+			t.Fatalf("expected a progress notification: %v", err)
+		}
+		var progress struct {
+			Token string `json:"token"`
+			Value []int  `json:"value"`
+		}
+		if err := json.Unmarshal(n.Params, &progress); err != nil {
+			t.Fatalf("failed to decode progress: %v", err)
+		}
+		if progress.Token != "token-1" {
+			t.Errorf("expected the token to be returned, got %q", progress.Token)
+		}
+		batches = append(batches, progress.Value)
+	}
+	if expected := [][]int{{1, 2}, {3}}; !reflect.DeepEqual(batches, expected) {
+		t.Errorf("expected batches %v, got %v", expected, batches)
+	}
+}
+
+func TestPartialResultSenderAccumulates(t *testing.T) {
+	_, client := newInitializedMux(t, messages.InitializeParams{}, handleNumbers)
+
+	var result []int
+	if err := client.Call("numbers", messages.PartialResultParams{}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []int{1, 2, 3}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+	select {
+	case n := <-client.Notifications:
+		t.Errorf("expected no notifications, got %v", n.Method)
+	default:
+	}
+}
+
+func TestPartialResultSenderRejectsNonArrays(t *testing.T) {
+	m, _ := newInitializedMux(t, messages.InitializeParams{}, nil)
+	if err := m.PartialResultSender(nil).Send("text"); !errors.Is(err, lsp.ErrPartialResultNotArray) {
+		t.Errorf("expected ErrPartialResultNotArray, got %v", err)
+	}
+}
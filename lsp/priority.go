@@ -0,0 +1,43 @@
+package lsp
+
+import "github.com/a-h/examplelsp/messages"
+
+// Priority of a request. High priority requests can use a pool of reserved
+// slots, so that they're handled promptly even when slow requests are using
+// all of the general slots.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
+// DefaultPriorities gives latency-sensitive requests, where the user is waiting
+// for the result as they type, a high priority.
+var DefaultPriorities = map[string]Priority{
+	messages.CompletionRequestMethod:    PriorityHigh,
+	messages.HoverRequestMethod:         PriorityHigh,
+	messages.SignatureHelpRequestMethod: PriorityHigh,
+}
+
+// DefaultReservedSlots is the number of slots reserved for high priority
+// requests.
+const DefaultReservedSlots = 2
+
+// WithPriorities sets the priority of requests by method, and the number of
+// slots reserved for high priority requests. Requests for methods that aren't
+// in the map, and all notifications, have normal priority.
+func WithPriorities(reservedSlots int, priorities map[string]Priority) Option {
+	return func(m *Mux) {
+		m.reservedSlots = reservedSlots
+		m.priorities = priorities
+	}
+}
+
+// priority of the request.
+func (m *Mux) priority(req Request) Priority {
+	if req.IsNotification() {
+		return PriorityNormal
+	}
+	return m.priorities[req.Method]
+}
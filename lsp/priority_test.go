@@ -0,0 +1,98 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestHighPriorityRequestsUseReservedSlots(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleMethod("textDocument/documentSymbol", func(params json.RawMessage) (result any, err error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		})
+		m.HandleMethod(messages.CompletionRequestMethod, func(params json.RawMessage) (result any, err error) {
+			return []string{"tbsp"}, nil
+		})
+	})
+	defer close(release)
+
+	// Saturate the general pool, and queue another request behind it.
+	slow := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			slow <- client.Call("textDocument/documentSymbol", nil, nil)
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("expected 4 slow requests to start, got %d", i)
+		}
+	}
+
+	answered := make(chan error, 1)
+	go func() {
+		var items []string
+		answered <- client.Call(messages.CompletionRequestMethod, nil, &items)
+	}()
+	select {
+	case err := <-answered:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected completion to be answered while the general pool is in use")
+	}
+	select {
+	case <-started:
+		t.Error("expected the fifth slow request to wait for a general slot")
+	default:
+	}
+}
+
+func TestQueuedRequestsRunInOrder(t *testing.T) {
+	release := make(chan struct{})
+	order := make(chan int, 10)
+	_, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleMethod("slow", func(params json.RawMessage) (result any, err error) {
+			<-release
+			return nil, nil
+		})
+		m.HandleNotification("record", func(params json.RawMessage) (err error) {
+			var i int
+			if err = json.Unmarshal(params, &i); err != nil {
+				return
+			}
+			order <- i
+			return nil
+		})
+	}, lsp.WithConcurrencyLimit(1), lsp.WithPriorities(0, nil))
+
+	// Occupy the only general slot, so that the notifications are queued.
+	go client.Call("slow", nil, nil)
+	for i := 0; i < 5; i++ {
+		if err := client.Notify("record", i); err != nil {
+			t.Fatalf("failed to notify: %v", err)
+		}
+	}
+	close(release)
+	for i := 0; i < 5; i++ {
+		select {
+		case actual := <-order:
+			if actual != i {
+				t.Errorf("expected notification %d, got %d", i, actual)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected notification %d to be handled", i)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+// Progress reports the progress of a long running operation to the client.
+// If the client doesn't support work done progress, or rejects the token,
+// its methods do nothing.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#serverInitiatedProgress
+type Progress struct {
+	m      *Mux
+	token  *messages.ProgressToken
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// NewProgress creates a progress token and shows the title to the user. The
+// returned context is cancelled if the user cancels the operation, or when
+// End is called.
+//
+// NewProgress waits for the client to respond to the create request, so it
+// must not be used from the initialize handler.
+func (m *Mux) NewProgress(ctx context.Context, title string) (context.Context, *Progress) {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Progress{m: m, cancel: cancel}
+	if !m.clientSupportsWorkDoneProgress() {
+		return ctx, p
+	}
+	token := messages.NewStringProgressToken(fmt.Sprintf("examplelsp-progress-%d", m.nextProgressID.Add(1)))
+	err := m.Call(ctx, messages.WorkDoneProgressCreateRequestMethod, messages.WorkDoneProgressCreateParams{Token: token}, nil)
+	if err != nil {
+		m.log.Warn("client rejected progress token", slog.String("title", title), slog.Any("error", err))
+		return ctx, p
+	}
+	p.begin(token, title)
+	return ctx, p
+}
+
+// WorkDoneProgress shows the progress of a request using the workDoneToken
+// sent by the client with the request. If the client didn't send a token, a
+// new one is created by NewProgress.
+func (m *Mux) WorkDoneProgress(ctx context.Context, token *messages.ProgressToken, title string) (context.Context, *Progress) {
+	if token == nil {
+		return m.NewProgress(ctx, title)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	p := &Progress{m: m, cancel: cancel}
+	p.begin(*token, title)
+	return ctx, p
+}
+
+// begin registers the token so that the client can cancel the progress, and
+// shows the title.
+func (p *Progress) begin(token messages.ProgressToken, title string) {
+	p.m.progressLock.Lock()
+	p.m.progress[progressKey(token)] = p.cancel
+	p.m.progressLock.Unlock()
+	p.token = &token
+	p.notify(messages.WorkDoneProgressBegin{
+		Kind:        "begin",
+		Title:       title,
+		Cancellable: true,
+	})
+}
+
+// progressKey returns the JSON of the token, so that integer and string
+// tokens with the same value are kept apart.
+func progressKey(token messages.ProgressToken) string {
+	key, err := json.Marshal(token)
+	if err != nil {
+		return ""
+	}
+	return string(key)
+}
+
+func (m *Mux) clientSupportsWorkDoneProgress() bool {
+	params, ok := m.InitializeParams()
+	return ok && params.Capabilities.Window != nil && params.Capabilities.Window.WorkDoneProgress
+}
+
+// Report progress to the user. Percentages outside of 0 to 100 are not shown.
+func (p *Progress) Report(message string, percentage int) {
+	report := messages.WorkDoneProgressReport{
+		Kind:        "report",
+		Cancellable: true,
+		Message:     message,
+	}
+	if percentage >= 0 && percentage <= 100 {
+		report.Percentage = &percentage
+	}
+	p.notify(report)
+}
+
+// End the progress, showing a final message, and cancel the context. Calls
+// after the first do nothing.
+func (p *Progress) End(message string) {
+	p.once.Do(func() {
+		p.notify(messages.WorkDoneProgressEnd{
+			Kind:    "end",
+			Message: message,
+		})
+		if p.token != nil {
+			p.m.progressLock.Lock()
+			delete(p.m.progress, progressKey(*p.token))
+			p.m.progressLock.Unlock()
+		}
+		p.cancel()
+	})
+}
+
+func (p *Progress) notify(value any) {
+	if p.token == nil {
+		return
+	}
+	err := p.m.Notify(messages.ProgressMethod, messages.ProgressParams{
+		Token: *p.token,
+		Value: value,
+	})
+	if err != nil {
+		p.m.log.Warn("failed to send progress", slog.Any("error", err))
+	}
+}
+
+// handleProgressCancel cancels the context of the progress when the user
+// cancels the operation.
+func (m *Mux) handleProgressCancel(rawParams json.RawMessage) (err error) {
+	var params messages.WorkDoneProgressCancelParams
+	if err = json.Unmarshal(rawParams, &params); err != nil {
+		return
+	}
+	m.progressLock.Lock()
+	cancel, ok := m.progress[progressKey(params.Token)]
+	m.progressLock.Unlock()
+	if !ok {
+		m.log.Warn("cancel received for unknown progress token", slog.String("token", progressKey(params.Token)))
+		return nil
+	}
+	cancel()
+	return nil
+}
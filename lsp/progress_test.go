@@ -0,0 +1,219 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+)
+
+// newProgressMux creates an initialized Mux, where the client declares whether
+// it supports work done progress. The work handler is run in response to a
+// "work" notification.
+func newProgressMux(t *testing.T, workDoneProgress bool, work func(m *lsp.Mux)) (client *lsptest.Client) {
+	t.Helper()
+	params := messages.InitializeParams{
+		Capabilities: messages.ClientCapabilities{
+			Window: &messages.WindowClientCapabilities{WorkDoneProgress: workDoneProgress},
+		},
+	}
+	_, client = newInitializedMux(t, params, func(m *lsp.Mux) {
+		m.HandleNotification("work", func(params json.RawMessage) (err error) {
+			work(m)
+			return nil
+		})
+	})
+	if err := client.Notify("work", nil); err != nil {
+		t.Fatalf("failed to send work: %v", err)
+	}
+	return client
+}
+
+type progressValue struct {
+	Token string `json:"token"`
+	Value struct {
+		Kind       string `json:"kind"`
+		Title      string `json:"title"`
+		Message    string `json:"message"`
+		Percentage *int   `json:"percentage"`
+	} `json:"value"`
+}
+
+func waitForProgress(t *testing.T, client *lsptest.Client) (p progressValue) {
+	t.Helper()
+	n, err := client.WaitForNotification(messages.ProgressMethod)
+	if err != nil {
+		t.Fatalf("expected a progress notification: %v", err)
+	}
+	if err := json.Unmarshal(n.Params, &p); err != nil {
+		t.Fatalf("failed to decode progress: %v", err)
+	}
+	return p
+}
+
+func TestProgress(t *testing.T) {
+	client := newProgressMux(t, true, func(m *lsp.Mux) {
+		_, p := m.NewProgress(context.Background(), "Indexing")
+		p.Report("1 of 2", 50)
+		p.End("Done")
+	})
+	req, err := client.WaitForRequest(messages.WorkDoneProgressCreateRequestMethod)
+	if err != nil {
+		t.Fatalf("expected a create request: %v", err)
+	}
+	var create struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(req.Params, &create); err != nil || create.Token == "" {
+		t.Fatalf("expected a token, got %s: %v", req.Params, err)
+	}
+	client.Respond(req.ID, nil, nil)
+
+	begin := waitForProgress(t, client)
+	if begin.Token != create.Token || begin.Value.Kind != "begin" || begin.Value.Title != "Indexing" {
+		t.Errorf("unexpected begin: %+v", begin)
+	}
+	report := waitForProgress(t, client)
+	if report.Value.Kind != "report" || report.Value.Message != "1 of 2" || report.Value.Percentage == nil || *report.Value.Percentage != 50 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	end := waitForProgress(t, client)
+	if end.Value.Kind != "end" || end.Value.Message != "Done" {
+		t.Errorf("unexpected end: %+v", end)
+	}
+}
+
+func TestProgressWithoutClientCapability(t *testing.T) {
+	done := make(chan struct{})
+	client := newProgressMux(t, false, func(m *lsp.Mux) {
+		_, p := m.NewProgress(context.Background(), "Indexing")
+		p.Report("1 of 2", 50)
+		p.End("Done")
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected progress to complete without waiting for the client")
+	}
+	client.Timeout = time.Millisecond * 100
+	if _, err := client.WaitForNotification(messages.ProgressMethod); err == nil {
+		t.Error("expected no progress notifications")
+	}
+	select {
+	case req := <-client.Requests:
+		t.Errorf("expected no requests, got %q", req.Method)
+	default:
+	}
+}
+
+func TestProgressCreateRejected(t *testing.T) {
+	done := make(chan struct{})
+	client := newProgressMux(t, true, func(m *lsp.Mux) {
+		_, p := m.NewProgress(context.Background(), "Indexing")
+		p.Report("1 of 2", 50)
+		p.End("Done")
+		close(done)
+	})
+	req, err := client.WaitForRequest(messages.WorkDoneProgressCreateRequestMethod)
+	if err != nil {
+		t.Fatalf("expected a create request: %v", err)
+	}
+	client.Respond(req.ID, nil, lsp.ErrInvalidRequest)
+	<-done
+	client.Timeout = time.Millisecond * 100
+	if _, err := client.WaitForNotification(messages.ProgressMethod); err == nil {
+		t.Error("expected no progress notifications after the token was rejected")
+	}
+}
+
+func TestProgressCancelledByClient(t *testing.T) {
+	cancelled := make(chan error, 1)
+	client := newProgressMux(t, true, func(m *lsp.Mux) {
+		ctx, p := m.NewProgress(context.Background(), "Indexing")
+		defer p.End("Cancelled")
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(time.Second):
+			cancelled <- nil
+		}
+	})
+	req, err := client.WaitForRequest(messages.WorkDoneProgressCreateRequestMethod)
+	if err != nil {
+		t.Fatalf("expected a create request: %v", err)
+	}
+	client.Respond(req.ID, nil, nil)
+	begin := waitForProgress(t, client)
+
+	err = client.Notify(messages.WorkDoneProgressCancelMethod, messages.WorkDoneProgressCancelParams{
+		Token: messages.NewStringProgressToken(begin.Token),
+	})
+	if err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("expected the context to be cancelled, got %v", err)
+	}
+	if end := waitForProgress(t, client); end.Value.Kind != "end" {
+		t.Errorf("expected the progress to end, got %+v", end)
+	}
+}
+
+func TestWorkDoneProgressUsesClientToken(t *testing.T) {
+	cancelled := make(chan error, 1)
+	client := newProgressMux(t, false, func(m *lsp.Mux) {
+		token := messages.NewIntegerProgressToken(7)
+		ctx, p := m.WorkDoneProgress(context.Background(), &token, "Renaming")
+		defer p.End("Cancelled")
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(time.Second):
+			cancelled <- nil
+		}
+	})
+	n, err := client.WaitForNotification(messages.ProgressMethod)
+	if err != nil {
+		t.Fatalf("expected a progress notification: %v", err)
+	}
+	var begin struct {
+		Token json.RawMessage `json:"token"`
+		Value struct {
+			Kind  string `json:"kind"`
+			Title string `json:"title"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(n.Params, &begin); err != nil {
+		t.Fatalf("failed to decode progress: %v", err)
+	}
+	if string(begin.Token) != "7" || begin.Value.Kind != "begin" || begin.Value.Title != "Renaming" {
+		t.Errorf("expected progress to begin with the client's token, got %s %+v", begin.Token, begin.Value)
+	}
+	select {
+	case req := <-client.Requests:
+		t.Errorf("expected the client's token to be used without a request, got %q", req.Method)
+	default:
+	}
+
+	// A string token with the same value is a different token.
+	err = client.Notify(messages.WorkDoneProgressCancelMethod, messages.WorkDoneProgressCancelParams{
+		Token: messages.NewStringProgressToken("7"),
+	})
+	if err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+	err = client.Notify(messages.WorkDoneProgressCancelMethod, messages.WorkDoneProgressCancelParams{
+		Token: messages.NewIntegerProgressToken(7),
+	})
+	if err != nil {
+		t.Fatalf("failed to cancel: %v", err)
+	}
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("expected the context to be cancelled, got %v", err)
+	}
+}
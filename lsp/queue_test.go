@@ -0,0 +1,65 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"golang.org/x/exp/slog"
+)
+
+func TestNotificationsAreQueuedUntilInitialized(t *testing.T) {
+	r, w, client := newPipeClient(t)
+	m := lsp.NewMux(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w)
+	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
+		return map[string]any{}, nil
+	})
+	go m.Process()
+
+	if err := m.Notify("before", 1); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if err := client.Call("initialize", nil, nil); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if err := m.Notify("before", 2); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if err := m.Notify("window/logMessage", "log"); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+
+	// Only the notification allowed during initialization is sent.
+	n, err := client.WaitForNotification("window/logMessage")
+	if err != nil {
+		t.Fatalf("expected window/logMessage to be sent during initialization: %v", err)
+	}
+	select {
+	case n = <-client.Notifications:
+		t.Fatalf("expected notifications to be queued until initialized, got %q", n.Method)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	if err := client.Notify("initialized", nil); err != nil {
+		t.Fatalf("failed to send initialized: %v", err)
+	}
+	for _, expected := range []string{"1", "2"} {
+		n, err := client.WaitForNotification("before")
+		if err != nil {
+			t.Fatalf("expected queued notification %s after initialized: %v", expected, err)
+		}
+		if string(n.Params) != expected {
+			t.Errorf("expected queued notifications in order, got %s, expected %s", n.Params, expected)
+		}
+	}
+
+	// Once running, notifications are sent immediately.
+	if err := m.Notify("after", nil); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	if _, err := client.WaitForNotification("after"); err != nil {
+		t.Errorf("expected notification to be sent: %v", err)
+	}
+}
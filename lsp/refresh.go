@@ -0,0 +1,136 @@
+package lsp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/a-h/examplelsp/messages"
+	"golang.org/x/exp/slog"
+)
+
+// DefaultRefreshDelay is the time that a Refresher waits for more changes
+// before asking the client to refresh.
+const DefaultRefreshDelay = time.Millisecond * 250
+
+// RefreshTimeout is the maximum time that a Refresher waits for the client to
+// respond to each refresh request.
+const RefreshTimeout = time.Second * 5
+
+// Refresher asks the client to request semantic tokens, code lenses, inlay
+// hints, pulled diagnostics and inline values again, after a change that
+// affects many documents, such as a change to the settings.
+//
+// Calls to Refresh within the delay of each other result in a single refresh,
+// so that a burst of changes doesn't flood the client. Only the features that
+// the server advertised, and that the client can refresh, are refreshed.
+type Refresher struct {
+	m     *Mux
+	delay time.Duration
+	lock  sync.Mutex
+	timer *time.Timer
+}
+
+// NewRefresher creates a Refresher that waits for the delay after the last
+// call to Refresh before refreshing.
+func NewRefresher(m *Mux, delay time.Duration) *Refresher {
+	return &Refresher{m: m, delay: delay}
+}
+
+// Refresh the client after the delay, unless Refresh is called again.
+func (r *Refresher) Refresh() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(r.delay, r.refresh)
+}
+
+func (r *Refresher) refresh() {
+	for _, kind := range r.kinds() {
+		ctx, cancel := context.WithTimeout(context.Background(), RefreshTimeout)
+		r.m.Refresh(ctx, kind)
+		cancel()
+	}
+}
+
+// kinds returns the features to refresh, which are the features that the
+// server advertised.
+func (r *Refresher) kinds() (kinds []RefreshKind) {
+	server, _ := r.m.ServerCapabilities()
+	advertised := map[RefreshKind]bool{
+		RefreshSemanticTokens: server.SemanticTokensProvider != nil,
+		RefreshCodeLens:       server.CodeLensProvider != nil,
+		RefreshInlayHints:     server.InlayHintProvider != nil,
+		RefreshDiagnostics:    server.DiagnosticProvider != nil,
+		RefreshInlineValues:   server.InlineValueProvider,
+	}
+	for _, kind := range refreshKinds {
+		if advertised[kind] && r.m.supportsRefresh(kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// RefreshKind is a feature whose results are cached by the client, which it
+// can be asked to request again. Its value is the method of the refresh
+// request.
+type RefreshKind string
+
+const (
+	RefreshSemanticTokens RefreshKind = messages.SemanticTokensRefreshRequestMethod
+	RefreshCodeLens       RefreshKind = messages.CodeLensRefreshRequestMethod
+	RefreshInlayHints     RefreshKind = messages.InlayHintRefreshRequestMethod
+	RefreshDiagnostics    RefreshKind = messages.DiagnosticRefreshRequestMethod
+	RefreshInlineValues   RefreshKind = messages.InlineValueRefreshRequestMethod
+)
+
+var refreshKinds = []RefreshKind{
+	RefreshSemanticTokens,
+	RefreshCodeLens,
+	RefreshInlayHints,
+	RefreshDiagnostics,
+	RefreshInlineValues,
+}
+
+// Refresh asks the client to request the results of the feature again. The
+// request is only sent if the client declared that it supports refreshing
+// the feature. Error responses are logged, since there's nothing the server
+// can do about them, and refreshed is false.
+//
+// Refresh waits for the client to respond, so it must not be used from the
+// initialize handler.
+func (m *Mux) Refresh(ctx context.Context, kind RefreshKind) (refreshed bool) {
+	if !m.supportsRefresh(kind) {
+		return false
+	}
+	if err := m.Call(ctx, string(kind), nil, nil); err != nil {
+		m.log.Warn("client failed to refresh", slog.String("method", string(kind)), slog.Any("error", err))
+		return false
+	}
+	return true
+}
+
+// supportsRefresh returns true if the client can refresh the feature.
+func (m *Mux) supportsRefresh(kind RefreshKind) bool {
+	params, ok := m.InitializeParams()
+	if !ok || params.Capabilities.Workspace == nil {
+		return false
+	}
+	client := params.Capabilities.Workspace
+	switch kind {
+	case RefreshSemanticTokens:
+		return client.SemanticTokens.SupportsRefresh()
+	case RefreshCodeLens:
+		return client.CodeLens.SupportsRefresh()
+	case RefreshInlayHints:
+		return client.InlayHint.SupportsRefresh()
+	case RefreshDiagnostics:
+		return client.Diagnostics.SupportsRefresh()
+	case RefreshInlineValues:
+		return client.InlineValue.SupportsRefresh()
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package lsp_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/settings"
+	"github.com/a-h/examplelsp/workspace"
+)
+
+const testRefreshDelay = time.Millisecond * 20
+
+// newRefresher creates an initialized Mux that advertises code lenses and
+// inlay hints, but not semantic tokens, pulled diagnostics or inline values,
+// to a client that can refresh all of them.
+func newRefresher(t *testing.T) (r *lsp.Refresher, client *lsptest.Client) {
+	t.Helper()
+	refresh := &messages.RefreshClientCapabilities{RefreshSupport: true}
+	params := messages.InitializeParams{
+		Capabilities: messages.ClientCapabilities{
+			Workspace: &messages.WorkspaceClientCapabilities{
+				SemanticTokens: refresh,
+				CodeLens:       refresh,
+				InlayHint:      refresh,
+				Diagnostics:    refresh,
+				InlineValue:    refresh,
+			},
+		},
+	}
+	m, client := newInitializedMux(t, params, func(m *lsp.Mux) {
+		m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+			capabilities.CodeLensProvider = &messages.CodeLensOptions{}
+			capabilities.InlayHintProvider = &messages.InlayHintOptions{}
+			return capabilities, nil
+		})
+	})
+	return lsp.NewRefresher(m, testRefreshDelay), client
+}
+
+// expectRefreshes responds to the refresh requests sent by the server, and
+// checks that each of the advertised features is refreshed exactly once.
+func expectRefreshes(t *testing.T, client *lsptest.Client) {
+	t.Helper()
+	var methods []string
+	quiet := time.After(testRefreshDelay * 10)
+	for done := false; !done; {
+		select {
+		case req := <-client.Requests:
+			methods = append(methods, req.Method)
+			if err := client.Respond(req.ID, nil, nil); err != nil {
+				t.Fatalf("failed to respond: %v", err)
+			}
+		case <-quiet:
+			done = true
+		}
+	}
+	sort.Strings(methods)
+	expected := []string{messages.CodeLensRefreshRequestMethod, messages.InlayHintRefreshRequestMethod}
+	if !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected refresh requests %v, got %v", expected, methods)
+	}
+}
+
+func TestRefresherAfterSettingsChange(t *testing.T) {
+	r, client := newRefresher(t)
+	store := settings.NewStore()
+	store.Subscribe(r.Refresh)
+	for i := 0; i < 10; i++ {
+		store.SetDefaults(settings.Settings{Format: i%2 == 0})
+	}
+	expectRefreshes(t, client)
+}
+
+func TestRefresherAfterIndexUpdate(t *testing.T) {
+	r, client := newRefresher(t)
+	root := t.TempDir()
+	ix := workspace.NewIndex(root)
+	ix.Subscribe(r.Refresh)
+	for _, name := range []string{"a.cook", "b.cook", "c.cook"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("Boil the @pasta."), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := ix.Refresh(); err != nil {
+			t.Fatalf("failed to refresh index: %v", err)
+		}
+	}
+	expectRefreshes(t, client)
+}
+
+func TestRefresherWithoutClientSupport(t *testing.T) {
+	m, client := newInitializedMux(t, messages.InitializeParams{}, func(m *lsp.Mux) {
+		m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+			capabilities.CodeLensProvider = &messages.CodeLensOptions{}
+			return capabilities, nil
+		})
+	})
+	lsp.NewRefresher(m, testRefreshDelay).Refresh()
+	select {
+	case req := <-client.Requests:
+		t.Errorf("expected no requests, got %q", req.Method)
+	case <-time.After(testRefreshDelay * 10):
+	}
+}
+
+// diagnosticsRefreshParams are sent by a client that can only refresh
+// diagnostics.
+var diagnosticsRefreshParams = messages.InitializeParams{
+	Capabilities: messages.ClientCapabilities{
+		Workspace: &messages.WorkspaceClientCapabilities{
+			Diagnostics: &messages.RefreshClientCapabilities{RefreshSupport: true},
+		},
+	},
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name     string
+		response error
+		expected bool
+	}{
+		{
+			name:     "client acknowledges",
+			expected: true,
+		},
+		{
+			name:     "client responds with an error",
+			response: errors.New("diagnostics are being computed"),
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, client := newInitializedMux(t, diagnosticsRefreshParams, nil)
+			refreshed := make(chan bool, 1)
+			go func() {
+				refreshed <- m.Refresh(context.Background(), lsp.RefreshDiagnostics)
+			}()
+			req, err := client.WaitForRequest(messages.DiagnosticRefreshRequestMethod)
+			if err != nil {
+				t.Fatalf("expected a refresh request: %v", err)
+			}
+			if err := client.Respond(req.ID, nil, test.response); err != nil {
+				t.Fatalf("failed to respond: %v", err)
+			}
+			if actual := <-refreshed; actual != test.expected {
+				t.Errorf("expected refreshed to be %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRefreshWithoutClientSupport(t *testing.T) {
+	m, client := newInitializedMux(t, diagnosticsRefreshParams, nil)
+	if m.Refresh(context.Background(), lsp.RefreshCodeLens) {
+		t.Error("expected code lenses not to be refreshed")
+	}
+	select {
+	case req := <-client.Requests:
+		t.Errorf("expected no requests, got %q", req.Method)
+	case <-time.After(testRefreshDelay * 5):
+	}
+}
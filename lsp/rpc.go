@@ -2,14 +2,20 @@ package lsp
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/textproto"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/a-h/examplelsp/messages"
 	"golang.org/x/exp/slog"
 )
 
@@ -53,7 +59,7 @@ func NewResponseError(id *json.RawMessage, err error) (resp Response) {
 }
 
 func newError(err error) *Error {
-	if err != nil {
+	if err == nil {
 		return nil
 	}
 	if e, isError := err.(*Error); isError {
@@ -112,7 +118,24 @@ func (n Notification) IsJSONRPC() bool {
 	return n.ProtocolVersion == protocolVersion
 }
 
+// message is any message received from the client. Responses to requests
+// sent by the server using Call have a Result or Error, and no Method.
+type message struct {
+	Request
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func (msg message) isResponse() bool {
+	return msg.Method == "" && msg.ID != nil
+}
+
 func Read(r *bufio.Reader) (req Request, err error) {
+	msg, err := readMessage(r)
+	return msg.Request, err
+}
+
+func readMessage(r *bufio.Reader) (msg message, err error) {
 	// Read header.
 	header, err := textproto.NewReader(r).ReadMIMEHeader()
 	if err != nil {
@@ -120,27 +143,57 @@ func Read(r *bufio.Reader) (req Request, err error) {
 	}
 	contentLength, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
 	if err != nil {
-		return req, ErrInvalidContentLengthHeader
+		return msg, ErrInvalidContentLengthHeader
 	}
 	// Read body.
-	err = json.NewDecoder(io.LimitReader(r, contentLength)).Decode(&req)
+	err = json.NewDecoder(io.LimitReader(r, contentLength)).Decode(&msg)
 	if err != nil {
 		return
 	}
-	if !req.IsJSONRPC() {
-		return req, ErrInvalidRequest
+	if !msg.IsJSONRPC() {
+		return msg, ErrInvalidRequest
 	}
 	return
 }
 
 var ErrInvalidContentLengthHeader = errors.New("missing or invalid Content-Length header")
 
+// MarshalFunc encodes a message as JSON.
+type MarshalFunc func(v any) ([]byte, error)
+
+// Marshal encodes v as JSON. Unlike json.Marshal, the characters <, > and &
+// are not escaped, so that Markdown and HTML content is readable by clients.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	return encode(newEncoder(&buf), &buf, v)
+}
+
+func newEncoder(buf *bytes.Buffer) *json.Encoder {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+// encode v into buf, which is reset first. The returned slice is only valid
+// until buf is next modified.
+func encode(enc *json.Encoder, buf *bytes.Buffer, v any) ([]byte, error) {
+	buf.Reset()
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	// Encode terminates each value with a newline.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 func Write(w *bufio.Writer, msg Message) (err error) {
-	// Calculate body size.
-	body, err := json.Marshal(msg)
+	body, err := Marshal(msg)
 	if err != nil {
 		return
 	}
+	return writeBody(w, body)
+}
+
+func writeBody(w *bufio.Writer, body []byte) (err error) {
 	// Write the header.
 	_, err = w.WriteString(fmt.Sprintf("Content-Length: %d\r\n\r\n", len(body)))
 	if err != nil {
@@ -156,12 +209,63 @@ func Write(w *bufio.Writer, msg Message) (err error) {
 	return
 }
 
-func NewMux(log *slog.Logger, r io.Reader, w io.Writer) *Mux {
-	return &Mux{
-		reader:               bufio.NewReader(r),
-		concurrencyLimit:     4,
-		methodHandlers:       map[string]MethodHandler{},
+// Option configures a Mux.
+type Option func(m *Mux)
+
+// WithMetrics configures the Mux to call the collector each time a request or
+// notification is handled.
+func WithMetrics(collector MetricsCollector) Option {
+	return func(m *Mux) {
+		m.metrics = collector
+	}
+}
+
+// WithMarshal sets the function used to encode messages, e.g. to use a
+// different JSON library. By default, messages are encoded in the same way as
+// Marshal, using a buffer that is reused between messages.
+func WithMarshal(f MarshalFunc) Option {
+	return func(m *Mux) {
+		m.marshal = f
+	}
+}
+
+// WithConcurrencyLimit sets the number of general slots, which limits the
+// number of messages that are handled concurrently.
+func WithConcurrencyLimit(n int64) Option {
+	return func(m *Mux) {
+		m.concurrencyLimit = n
+	}
+}
+
+// WithDrainTimeout sets the maximum time that Process waits for in-flight
+// handlers to complete before returning.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(m *Mux) {
+		m.drainTimeout = d
+	}
+}
+
+// DefaultDrainTimeout is the maximum time that Process waits for in-flight
+// handlers to complete before returning.
+const DefaultDrainTimeout = time.Second * 5
+
+func NewMux(log *slog.Logger, r io.Reader, w io.Writer, opts ...Option) *Mux {
+	m := &Mux{
+		reader:           bufio.NewReader(r),
+		concurrencyLimit: 4,
+		reservedSlots:    DefaultReservedSlots,
+		priorities:       DefaultPriorities,
+		drainTimeout:     DefaultDrainTimeout,
+		methodHandlers: map[string]MethodHandler{
+			// Respond to shutdown requests, even if no handler is registered.
+			"shutdown": func(params json.RawMessage) (result any, err error) {
+				return nil, nil
+			},
+		},
 		notificationHandlers: map[string]NotificationHandler{},
+		pending:              map[string]chan message{},
+		progress:             map[string]context.CancelFunc{},
+		stopped:              make(chan struct{}),
 		writer:               bufio.NewWriter(w),
 		writeLock:            &sync.Mutex{},
 		log:                  log,
@@ -169,87 +273,380 @@ func NewMux(log *slog.Logger, r io.Reader, w io.Writer) *Mux {
 			return
 		},
 	}
+	m.notificationHandlers[messages.WorkDoneProgressCancelMethod] = m.handleProgressCancel
+	m.outbox = newOutbox(m.writer, func(err error) {
+		m.log.Error("failed to write message", slog.Any("error", err))
+		m.error(fmt.Errorf("failed to write message: %w", err))
+	})
+	m.encoder = newEncoder(&m.buffer)
+	m.marshal = func(v any) ([]byte, error) {
+		return encode(m.encoder, &m.buffer, v)
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 type Mux struct {
-	initialized          bool
+	state                atomic.Int32
 	reader               *bufio.Reader
 	concurrencyLimit     int64
+	reservedSlots        int
+	priorities           map[string]Priority
+	drainTimeout         time.Duration
+	handlersLock         sync.RWMutex
 	methodHandlers       map[string]MethodHandler
 	notificationHandlers map[string]NotificationHandler
+	queued               []Notification
+	queueLock            sync.Mutex
+	pending              map[string]chan message
+	pendingLock          sync.Mutex
+	callsSent            atomic.Int64
+	staleResponses       atomic.Int64
+	unknownResponses     atomic.Int64
+	progress             map[string]context.CancelFunc
+	progressLock         sync.Mutex
+	nextProgressID       atomic.Int64
+	stopped              chan struct{}
 	writer               *bufio.Writer
+	outbox               *outbox
 	writeLock            *sync.Mutex
+	marshal              MarshalFunc
+	serverInfo           *messages.ServerInfo
+	initializeParams     atomic.Pointer[messages.InitializeParams]
+	serverCapabilities   atomic.Pointer[messages.ServerCapabilities]
 	log                  *slog.Logger
 	error                func(err error)
+	metrics              MetricsCollector
+
+	// encoder writes to buffer, and both are protected by writeLock.
+	encoder *json.Encoder
+	buffer  bytes.Buffer
+
+	defaultMethodHandler       DefaultMethodHandler
+	defaultNotificationHandler DefaultNotificationHandler
 }
 
 type MethodHandler func(params json.RawMessage) (result any, err error)
 type NotificationHandler func(params json.RawMessage) (err error)
 
+// DefaultMethodHandler handles requests for methods that don't have a handler.
+// Returning ErrMethodNotFound results in the standard error response.
+type DefaultMethodHandler func(method string, params json.RawMessage) (result any, err error)
+
+// DefaultNotificationHandler handles notifications that don't have a handler.
+type DefaultNotificationHandler func(method string, params json.RawMessage) (err error)
+
+// HandleMethod sets the handler for requests with the given method name,
+// replacing any existing handler. Handlers can be changed while Process is
+// running, e.g. after registering a capability with the client.
 func (m *Mux) HandleMethod(name string, method MethodHandler) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
 	m.methodHandlers[name] = method
 }
 
+// RemoveMethod removes the handler for requests with the given method name.
+// Requests that are already being handled are not affected.
+func (m *Mux) RemoveMethod(name string) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+	delete(m.methodHandlers, name)
+}
+
+// HandleNotification sets the handler for notifications with the given method
+// name, replacing any existing handler.
 func (m *Mux) HandleNotification(name string, notification NotificationHandler) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
 	m.notificationHandlers[name] = notification
 }
 
+// RemoveNotification removes the handler for notifications with the given
+// method name.
+func (m *Mux) RemoveNotification(name string) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+	delete(m.notificationHandlers, name)
+}
+
+// HandleDefault sets the handler for requests that don't have a method
+// handler. Requests for methods starting with "$/" are not passed to the
+// default handler.
+func (m *Mux) HandleDefault(method DefaultMethodHandler) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+	m.defaultMethodHandler = method
+}
+
+// HandleDefaultNotification sets the handler for notifications that don't have
+// a notification handler. Notifications starting with "$/" are not passed to
+// the default handler.
+func (m *Mux) HandleDefaultNotification(notification DefaultNotificationHandler) {
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+	m.defaultNotificationHandler = notification
+}
+
+// notificationHandler returns the handler for the notification, falling back
+// to the default notification handler.
+func (m *Mux) notificationHandler(method string) (nh NotificationHandler, ok bool) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+	if nh, ok = m.notificationHandlers[method]; ok {
+		return nh, true
+	}
+	if isProtocolImplementationDependent(method) || m.defaultNotificationHandler == nil {
+		return nil, false
+	}
+	dnh := m.defaultNotificationHandler
+	return func(params json.RawMessage) error {
+		return dnh(method, params)
+	}, true
+}
+
+// methodHandler returns the handler for the method, falling back to the
+// default method handler.
+func (m *Mux) methodHandler(method string) (mh MethodHandler, ok bool) {
+	m.handlersLock.RLock()
+	defer m.handlersLock.RUnlock()
+	if mh, ok = m.methodHandlers[method]; ok {
+		return mh, true
+	}
+	if isProtocolImplementationDependent(method) || m.defaultMethodHandler == nil {
+		return nil, false
+	}
+	dmh := m.defaultMethodHandler
+	return func(params json.RawMessage) (any, error) {
+		return dmh(method, params)
+	}, true
+}
+
+// isProtocolImplementationDependent returns true for messages that servers
+// are free to ignore.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#dollarRequests
+func isProtocolImplementationDependent(method string) bool {
+	return strings.HasPrefix(method, "$/")
+}
+
+// Notify sends a notification to the client. Until the client sends the
+// initialized notification, notifications are queued, unless the spec allows
+// them to be sent during initialization.
 func (m *Mux) Notify(method string, params any) (err error) {
 	n := Notification{
 		ProtocolVersion: protocolVersion,
 		Method:          method,
 		Params:          params,
 	}
+	if m.queue(n) {
+		return nil
+	}
 	return m.write(n)
 }
 
+// ErrStopped is returned by Call when the Mux stops processing messages
+// before the client responds.
+var ErrStopped = errors.New("lsp: stopped processing messages")
+
+// Call sends a request to the client and waits for the response. If the client
+// responds with an error, it's returned as an *Error.
+//
+// Responses are read by Process, so Call must not be used from the initialize
+// handler, which blocks Process until it returns.
+func (m *Mux) Call(ctx context.Context, method string, params any, result any) (err error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	key := newCallID()
+	id, err := json.Marshal(key)
+	if err != nil {
+		return
+	}
+	rawID := json.RawMessage(id)
+	ch := make(chan message, 1)
+	m.pendingLock.Lock()
+	m.pending[key] = ch
+	m.pendingLock.Unlock()
+	defer func() {
+		m.pendingLock.Lock()
+		delete(m.pending, key)
+		m.pendingLock.Unlock()
+	}()
+	m.callsSent.Add(1)
+	err = m.write(Request{
+		ProtocolVersion: protocolVersion,
+		ID:              &rawID,
+		Method:          method,
+		Params:          rawParams,
+	})
+	if err != nil {
+		return
+	}
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil || len(msg.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-m.stopped:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resolve passes a response from the client to the waiting Call. Responses
+// that don't match a pending call are logged and dropped.
+func (m *Mux) resolve(msg message) {
+	key, n, isCallID := parseCallID(*msg.ID)
+	m.pendingLock.Lock()
+	ch, ok := m.pending[key]
+	delete(m.pending, key)
+	m.pendingLock.Unlock()
+	if ok {
+		ch <- msg
+		return
+	}
+	if isCallID && n <= callIDs.Load() {
+		m.staleResponses.Add(1)
+		m.log.Warn("received duplicate or stale response", slog.String("id", string(*msg.ID)))
+		return
+	}
+	m.unknownResponses.Add(1)
+	m.log.Warn("received response to unknown request", slog.String("id", string(*msg.ID)))
+}
+
+// write sends the message to the client, and waits for it to be written,
+// except for publishDiagnostics notifications, which may be replaced by later
+// diagnostics for the same document before they're written, see outbox.
 func (m *Mux) write(msg Message) (err error) {
 	m.writeLock.Lock()
-	defer m.writeLock.Unlock()
-	return Write(m.writer, msg)
+	body, err := m.marshal(msg)
+	if err != nil {
+		m.writeLock.Unlock()
+		return
+	}
+	// The body refers to the encoder's buffer, which is reused.
+	body = append([]byte(nil), body...)
+	uri := diagnosticsURI(msg)
+	done := m.outbox.send(body, uri, uri == "")
+	m.writeLock.Unlock()
+	if done == nil {
+		return nil
+	}
+	return <-done
 }
 
+// Process reads messages from the client and dispatches them to handlers
+// until the client sends the exit notification, or reading fails.
+//
+// The initialize request is handled before any other message is read. Other
+// messages are handled concurrently, up to the concurrency limit. High
+// priority requests can also use the reserved slots (see WithPriorities).
+//
+// Before Process returns, it waits for in-flight handlers to complete, up to
+// the drain timeout, so that their responses are written and flushed. Once
+// Process has returned, the Mux doesn't write to the writer unless a handler
+// exceeded the drain timeout, or Notify is called.
+//
+// Responses to requests sent using Call are passed to the waiting caller.
+// Calls that are waiting when Process stops reading return ErrStopped.
 func (m *Mux) Process() (err error) {
-	// Handle initialization.
+	var wg sync.WaitGroup
+	defer m.drain(&wg)
+	defer close(m.stopped)
+	general := make(chan struct{}, m.concurrencyLimit)
+	reserved := make(chan struct{}, m.reservedSlots)
+	handle := func(req Request, slots chan struct{}) {
+		defer wg.Done()
+		m.handleMessage(req)
+		<-slots
+	}
+	// Normal priority messages wait for a general slot in the order they were
+	// received, without blocking the reader, so that high priority requests
+	// can be read while the general slots are in use.
+	queue := make(chan Request, 1024)
+	defer close(queue)
+	go func() {
+		for req := range queue {
+			general <- struct{}{}
+			go handle(req, general)
+		}
+	}()
 	for {
-		req, err := Read(m.reader)
+		msg, err := readMessage(m.reader)
 		if err != nil {
 			return err
 		}
-		if req.IsNotification() {
-			if req.Method != "exit" {
-				// Drop notifications sent before initialization.
-				m.log.Warn("dropping notification sent before initialization", slog.Any("req", req))
-				continue
+		if msg.isResponse() {
+			m.resolve(msg)
+			continue
+		}
+		req := msg.Request
+		if req.Method == "exit" && req.IsNotification() {
+			m.handleNotification(req)
+			if previous := m.setState(StateExited); previous != StateShuttingDown {
+				return ErrExitWithoutShutdown
 			}
-			m.handleMessage(req)
+			return nil
+		}
+		ok, err := m.accept(req)
+		if err != nil {
+			return err
+		}
+		if !ok {
 			continue
 		}
-		if req.Method != "initialize" {
-			// Return an error if methods used before initialization.
-			m.log.Warn("the client sent a method before initialization", slog.Any("req", req))
-			if err = m.write(NewResponseError(req.ID, ErrServerNotInitialized)); err != nil {
-				return err
+		if req.Method == "initialize" && !req.IsNotification() {
+			res, err := m.call(req)
+			if err != nil {
+				// Allow the client to try again.
+				m.setState(StateUninitialized)
 			}
+			m.respond(req, res)
 			continue
 		}
-		m.handleMessage(req)
-		break
+		if req.Method == messages.WorkDoneProgressCancelMethod && req.IsNotification() {
+			// Cancel immediately, rather than waiting for a slot, which may be
+			// in use by the operation being cancelled.
+			m.handleNotification(req)
+			continue
+		}
+		wg.Add(1)
+		if m.priority(req) != PriorityHigh {
+			queue <- req
+			continue
+		}
+		select {
+		case reserved <- struct{}{}:
+			go handle(req, reserved)
+		case general <- struct{}{}:
+			go handle(req, general)
+		}
 	}
-	m.log.Info("initialization complete")
+}
 
-	// Handle standard flow.
-	sem := make(chan struct{}, m.concurrencyLimit)
-	for {
-		sem <- struct{}{}
-		req, err := Read(m.reader)
-		if err != nil {
-			return err
-		}
-		go func(req Request) {
-			m.handleMessage(req)
-			<-sem
-		}(req)
+// drain waits for in-flight handlers to complete, up to the drain timeout,
+// then waits for queued messages to be written.
+func (m *Mux) drain(wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(m.drainTimeout):
+		m.log.Warn("timed out waiting for in-flight handlers to complete", slog.Duration("timeout", m.drainTimeout))
+	}
+	if err := m.outbox.flush(); err != nil {
+		m.log.Warn("failed to flush writer", slog.Any("error", err))
 	}
 }
 
@@ -263,39 +660,63 @@ func (m *Mux) handleMessage(req Request) {
 
 func (m *Mux) handleNotification(req Request) {
 	log := m.log.With(slog.String("method", req.Method))
-	nh, ok := m.notificationHandlers[req.Method]
+	nh, ok := m.notificationHandler(req.Method)
+	if !ok && isProtocolImplementationDependent(req.Method) {
+		log.Debug("dropping notification")
+		return
+	}
 	if !ok {
 		log.Warn("notification not handled")
 		return
 	}
+	start := time.Now()
+	err := nh(req.Params)
+	if m.metrics != nil {
+		m.metrics.NotificationHandled(req.Method, time.Since(start), err)
+	}
 	// We don't need to notify clients if the notification results in an error.
-	if err := nh(req.Params); err != nil && m.error != nil {
+	if err != nil && m.error != nil {
 		log.Error("failed to handle notification", slog.Any("error", err))
 		m.error(err)
 	}
 }
 
 func (m *Mux) handleRequestResponse(req Request) {
+	res, _ := m.call(req)
+	m.respond(req, res)
+}
+
+// call the method handler, returning the response to send to the client, and
+// the error returned by the handler.
+func (m *Mux) call(req Request) (res Response, err error) {
 	log := m.log.With(slog.Any("id", req.ID), slog.String("method", req.Method))
-	mh, ok := m.methodHandlers[req.Method]
+	mh, ok := m.methodHandler(req.Method)
 	if !ok {
 		log.Error("method not found")
-		if err := m.write(NewResponseError(req.ID, ErrMethodNotFound)); err != nil {
-			log.Error("failed to respond", slog.Any("error", err))
-			m.error(fmt.Errorf("failed to respond: %w", err))
+		if m.metrics != nil {
+			m.metrics.RequestHandled(req.Method, 0, ErrMethodNotFound)
 		}
-		return
+		return NewResponseError(req.ID, ErrMethodNotFound), ErrMethodNotFound
 	}
-	var res Response
+	start := time.Now()
 	result, err := mh(req.Params)
+	if m.metrics != nil {
+		m.metrics.RequestHandled(req.Method, time.Since(start), err)
+	}
+	if errors.Is(err, ErrMethodNotFound) {
+		log.Error("method not found")
+		return NewResponseError(req.ID, ErrMethodNotFound), err
+	}
 	if err != nil {
 		log.Error("failed to handle", slog.Any("error", err))
-		res = NewResponseError(req.ID, err)
-	} else {
-		res = NewResponse(req.ID, result)
+		return NewResponseError(req.ID, err), err
 	}
-	if err = m.write(res); err != nil {
-		log.Error("failed to respond", slog.Any("error", err))
+	return NewResponse(req.ID, result), nil
+}
+
+func (m *Mux) respond(req Request, res Response) {
+	if err := m.write(res); err != nil {
+		m.log.Error("failed to respond", slog.Any("id", req.ID), slog.String("method", req.Method), slog.Any("error", err))
 		m.error(fmt.Errorf("failed to respond: %w", err))
 	}
 }
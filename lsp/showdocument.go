@@ -0,0 +1,36 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// UnsupportedError is returned instead of sending a request that the client
+// hasn't declared support for.
+type UnsupportedError struct {
+	Method string
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("lsp: client does not support %s", e.Method)
+}
+
+// ShowDocument asks the client to show a document, or to open a URI in an
+// external program. It returns false if the client couldn't show it, and an
+// UnsupportedError if the client doesn't support window/showDocument.
+//
+// ShowDocument waits for the client to respond, so it must not be used from
+// the initialize handler.
+func (m *Mux) ShowDocument(ctx context.Context, params messages.ShowDocumentParams) (success bool, err error) {
+	initializeParams, _ := m.InitializeParams()
+	if window := initializeParams.Capabilities.Window; window == nil || !window.ShowDocument.Supported() {
+		return false, UnsupportedError{Method: messages.ShowDocumentRequestMethod}
+	}
+	var result messages.ShowDocumentResult
+	if err = m.Call(ctx, messages.ShowDocumentRequestMethod, params, &result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,110 @@
+package lsp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+)
+
+type showDocumentResult struct {
+	success bool
+	err     error
+}
+
+// newShowDocumentMux creates an initialized Mux, where the client declares
+// whether it supports window/showDocument. The document is shown in response
+// to a "show" notification, and the result is sent to the returned channel.
+func newShowDocumentMux(t *testing.T, window *messages.WindowClientCapabilities) (client *lsptest.Client, results chan showDocumentResult) {
+	t.Helper()
+	results = make(chan showDocumentResult, 1)
+	params := messages.InitializeParams{
+		Capabilities: messages.ClientCapabilities{Window: window},
+	}
+	_, client = newInitializedMux(t, params, func(m *lsp.Mux) {
+		m.HandleNotification("show", func(params json.RawMessage) (err error) {
+			var result showDocumentResult
+			result.success, result.err = m.ShowDocument(context.Background(), messages.ShowDocumentParams{URI: "https://cooklang.org", External: true})
+			results <- result
+			return nil
+		})
+	})
+	if err := client.Notify("show", nil); err != nil {
+		t.Fatalf("failed to send show: %v", err)
+	}
+	return client, results
+}
+
+func TestShowDocument(t *testing.T) {
+	for _, success := range []bool{true, false} {
+		client, results := newShowDocumentMux(t, &messages.WindowClientCapabilities{
+			ShowDocument: &messages.ShowDocumentClientCapabilities{Support: true},
+		})
+		req, err := client.WaitForRequest(messages.ShowDocumentRequestMethod)
+		if err != nil {
+			t.Fatalf("expected a request from the server: %v", err)
+		}
+		var params messages.ShowDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			t.Fatalf("failed to decode params: %v", err)
+		}
+		if params.URI != "https://cooklang.org" || !params.External {
+			t.Errorf("unexpected params: %#v", params)
+		}
+		client.Respond(req.ID, messages.ShowDocumentResult{Success: success}, nil)
+
+		result := <-results
+		if result.err != nil {
+			t.Fatalf("unexpected error: %v", result.err)
+		}
+		if result.success != success {
+			t.Errorf("expected success to be %v, got %v", success, result.success)
+		}
+	}
+}
+
+func TestShowDocumentUnsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		window *messages.WindowClientCapabilities
+	}{
+		{
+			name: "no window capabilities",
+		},
+		{
+			name:   "no show document capabilities",
+			window: &messages.WindowClientCapabilities{WorkDoneProgress: true},
+		},
+		{
+			name: "show document not supported",
+			window: &messages.WindowClientCapabilities{
+				ShowDocument: &messages.ShowDocumentClientCapabilities{Support: false},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, results := newShowDocumentMux(t, test.window)
+			var result showDocumentResult
+			select {
+			case result = <-results:
+			case req := <-client.Requests:
+				t.Fatalf("expected no request to be sent, got %q", req.Method)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for the result")
+			}
+			var unsupported lsp.UnsupportedError
+			if !errors.As(result.err, &unsupported) || unsupported.Method != messages.ShowDocumentRequestMethod {
+				t.Errorf("expected an unsupported error, got %v", result.err)
+			}
+			if result.success {
+				t.Error("expected success to be false")
+			}
+		})
+	}
+}
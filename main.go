@@ -1,20 +1,33 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/documents"
 	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/a-h/examplelsp/settings"
 	"github.com/aquilax/cooklang-go"
 	"golang.org/x/exp/slog"
 )
 
 func main() {
-	lf, err := os.Create("examplelsp.log")
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		os.Exit(runFmt(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	logPath, err := filepath.Abs("examplelsp.log")
+	if err != nil {
+		slog.Error("failed to find log output file", slog.Any("error", err))
+		os.Exit(1)
+	}
+	lf, err := os.Create(logPath)
 	if err != nil {
 		slog.Error("failed to create log output file", slog.Any("error", err))
 		os.Exit(1)
@@ -27,153 +40,217 @@ func main() {
 		}
 	}()
 
-	m := lsp.NewMux(log, os.Stdin, os.Stdout)
-
-	fileURIToContents := map[string]string{}
-
-	m.HandleMethod("initialize", func(params json.RawMessage) (result any, err error) {
-		var initializeParams messages.InitializeParams
-		if err = json.Unmarshal(params, &initializeParams); err != nil {
-			return
-		}
-		log.Info("recevied initialize method", slog.Any("params", initializeParams))
-
-		result = messages.InitializeResult{
-			Capabilities: messages.ServerCapabilities{
-				TextDocumentSync: messages.TextDocumentSyncKindFull,
-				CompletionProvider: &messages.CompletionOptions{
-					TriggerCharacters: []string{"%"},
-				},
-			},
-			ServerInfo: &messages.ServerInfo{
-				Name: "examplelsp",
-			},
-		}
-		return
-	})
+	m := newServer(log, os.Stdin, os.Stdout, logPath)
+	if err := m.Process(); err != nil {
+		log.Error("processing stopped", slog.Any("error", err))
+	}
+}
 
-	m.HandleNotification("initialized", func(params json.RawMessage) (err error) {
-		log.Info("received initialized notification", slog.Any("params", params))
-		return nil
-	})
+// encodeDiagnostics returns a copy of the diagnostics of the text, with their
+// ranges converted from UTF-16 into the position encoding used by the client.
+func encodeDiagnostics(text string, diagnostics []messages.Diagnostic, encoding messages.PositionEncodingKind) []messages.Diagnostic {
+	if encoding == messages.PositionEncodingKindUTF16 {
+		return diagnostics
+	}
+	encoded := make([]messages.Diagnostic, len(diagnostics))
+	for i, d := range diagnostics {
+		d.Range = documents.EncodeRange(text, d.Range, encoding)
+		encoded[i] = d
+	}
+	return encoded
+}
 
-	m.HandleMethod(messages.CompletionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
-		log.Info("received completion request", slog.Any("params", rawParams))
+// encodeDocumentSymbols returns a copy of the symbols of the text, and their
+// children, with their ranges converted from UTF-16 into the position
+// encoding used by the client.
+func encodeDocumentSymbols(text string, symbols []messages.DocumentSymbol, encoding messages.PositionEncodingKind) []messages.DocumentSymbol {
+	if encoding == messages.PositionEncodingKindUTF16 || symbols == nil {
+		return symbols
+	}
+	encoded := make([]messages.DocumentSymbol, len(symbols))
+	for i, s := range symbols {
+		s.Range = documents.EncodeRange(text, s.Range, encoding)
+		s.SelectionRange = documents.EncodeRange(text, s.SelectionRange, encoding)
+		s.Children = encodeDocumentSymbols(text, s.Children, encoding)
+		encoded[i] = s
+	}
+	return encoded
+}
 
-		var params messages.CompletionParams
-		if err = json.Unmarshal(rawParams, &params); err != nil {
-			return
+// encodeDefinition returns a copy of the definition result, with its ranges
+// converted from UTF-16 into the position encoding used by the client. The
+// definitions are in the same document as the request, so all of the ranges
+// are positions in the text.
+func encodeDefinition(text string, result messages.DefinitionResult, encoding messages.PositionEncodingKind) messages.DefinitionResult {
+	if encoding == messages.PositionEncodingKindUTF16 {
+		return result
+	}
+	encoded := messages.DefinitionResult{}
+	if result.Location != nil {
+		l := *result.Location
+		l.Range = documents.EncodeRange(text, l.Range, encoding)
+		encoded.Location = &l
+	}
+	if result.Locations != nil {
+		encoded.Locations = make([]messages.Location, len(result.Locations))
+		for i, l := range result.Locations {
+			l.Range = documents.EncodeRange(text, l.Range, encoding)
+			encoded.Locations[i] = l
 		}
-
-		doc, _ := cooklang.ParseString(fileURIToContents[params.TextDocument.URI])
-		var r []messages.CompletionItem
-		for _, step := range doc.Steps {
-			for _, ingredient := range step.Ingredients {
-				if positionIsInRange(ingredient.Range, params.Position) {
-					r = append(r, ingredientUnitCompletionItems...)
-				}
+	}
+	if result.LocationLinks != nil {
+		encoded.LocationLinks = make([]messages.LocationLink, len(result.LocationLinks))
+		for i, l := range result.LocationLinks {
+			if l.OriginSelectionRange != nil {
+				r := documents.EncodeRange(text, *l.OriginSelectionRange, encoding)
+				l.OriginSelectionRange = &r
 			}
+			l.TargetRange = documents.EncodeRange(text, l.TargetRange, encoding)
+			l.TargetSelectionRange = documents.EncodeRange(text, l.TargetSelectionRange, encoding)
+			encoded.LocationLinks[i] = l
 		}
-		return r, nil
-	})
+	}
+	return encoded
+}
 
-	// Create a queue to process document updates in the order they're received.
-	documentUpdates := make(chan messages.TextDocumentItem, 10)
-	go func() {
-		for doc := range documentUpdates {
-			fileURIToContents[doc.URI] = doc.Text
-			diagnostics := []messages.Diagnostic{}
-			diagnostics = append(diagnostics, getRecipeParseErrorDiagnostics(doc.Text)...)
-			diagnostics = append(diagnostics, getAmericanMeasurementsDiagnostics(doc.Text)...)
-			diagnostics = append(diagnostics, getSwearwordDiagnostics(doc.Text)...)
-			m.Notify(messages.PublishDiagnosticsMethod, messages.PublishDiagnosticsParams{
-				URI:         doc.URI,
-				Version:     &doc.Version,
-				Diagnostics: diagnostics,
-			})
+// encodeCompletionList returns a copy of the completion list, with the
+// ranges of its edits converted from UTF-16 into the position encoding used
+// by the client.
+func encodeCompletionList(text string, list messages.CompletionList, encoding messages.PositionEncodingKind) messages.CompletionList {
+	if encoding == messages.PositionEncodingKindUTF16 {
+		return list
+	}
+	encodeInsertReplace := func(insert, replace messages.Range) (messages.Range, messages.Range) {
+		return documents.EncodeRange(text, insert, encoding), documents.EncodeRange(text, replace, encoding)
+	}
+	if d := list.ItemDefaults; d != nil && d.EditRange != nil {
+		defaults := *d
+		editRange := messages.RangeOrInsertReplaceRange{}
+		if r := d.EditRange.Range; r != nil {
+			encoded := documents.EncodeRange(text, *r, encoding)
+			editRange.Range = &encoded
 		}
-	}()
-
-	m.HandleNotification(messages.DidOpenTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
-		log.Info("received didOpenTextDocument notification")
-
-		var params messages.DidOpenTextDocumentParams
-		if err = json.Unmarshal(rawParams, &params); err != nil {
-			return
+		if r := d.EditRange.InsertReplace; r != nil {
+			encoded := messages.InsertReplaceRange{}
+			encoded.Insert, encoded.Replace = encodeInsertReplace(r.Insert, r.Replace)
+			editRange.InsertReplace = &encoded
 		}
-		documentUpdates <- params.TextDocument
-
-		return nil
-	})
-
-	m.HandleNotification(messages.DidChangeTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
-		log.Info("received didChangeTextDocument notification")
-
-		var params messages.DidChangeTextDocumentParams
-		if err = json.Unmarshal(rawParams, &params); err != nil {
-			return
+		defaults.EditRange = &editRange
+		list.ItemDefaults = &defaults
+	}
+	items := make([]messages.CompletionItem, len(list.Items))
+	for i, item := range list.Items {
+		if item.TextEdit != nil {
+			edit := messages.TextEditOrInsertReplaceEdit{}
+			if e := item.TextEdit.TextEdit; e != nil {
+				encoded := *e
+				encoded.Range = documents.EncodeRange(text, e.Range, encoding)
+				edit.TextEdit = &encoded
+			}
+			if e := item.TextEdit.InsertReplace; e != nil {
+				encoded := *e
+				encoded.Insert, encoded.Replace = encodeInsertReplace(e.Insert, e.Replace)
+				edit.InsertReplace = &encoded
+			}
+			item.TextEdit = &edit
 		}
-
-		// In our response to Initializes, we told the client that we need the
-		// full content of every document every time - we can't handle partial
-		// updates, so there's got to only be one event.
-		documentUpdates <- messages.TextDocumentItem{
-			URI:     params.TextDocument.URI,
-			Version: params.TextDocument.Version,
-			Text:    params.ContentChanges[0].Text,
+		if item.AdditionalTextEdits != nil {
+			edits := make([]messages.TextEdit, len(item.AdditionalTextEdits))
+			for j, e := range item.AdditionalTextEdits {
+				e.Range = documents.EncodeRange(text, e.Range, encoding)
+				edits[j] = e
+			}
+			item.AdditionalTextEdits = edits
 		}
+		items[i] = item
+	}
+	list.Items = items
+	return list
+}
 
-		return nil
-	})
+// encodeSymbols returns a copy of the symbols of the text, with their ranges
+// converted from UTF-16 into the position encoding used by the client.
+func encodeSymbols(text string, symbols []messages.SymbolInformation, encoding messages.PositionEncodingKind) []messages.SymbolInformation {
+	if encoding == messages.PositionEncodingKindUTF16 || symbols == nil {
+		return symbols
+	}
+	encoded := make([]messages.SymbolInformation, len(symbols))
+	for i, s := range symbols {
+		s.Location.Range = documents.EncodeRange(text, s.Location.Range, encoding)
+		encoded[i] = s
+	}
+	return encoded
+}
 
-	if err := m.Process(); err != nil {
-		log.Error("processing stopped", slog.Any("error", err))
+// encodeWorkspaceSymbols returns a copy of the workspace symbols of the text,
+// with the ranges of those that have one converted from UTF-16 into the
+// position encoding used by the client.
+func encodeWorkspaceSymbols(text string, symbols []messages.WorkspaceSymbol, encoding messages.PositionEncodingKind) []messages.WorkspaceSymbol {
+	if encoding == messages.PositionEncodingKindUTF16 || symbols == nil {
+		return symbols
+	}
+	encoded := make([]messages.WorkspaceSymbol, len(symbols))
+	for i, s := range symbols {
+		if s.Location.Range != nil {
+			r := documents.EncodeRange(text, *s.Location.Range, encoding)
+			s.Location.Range = &r
+		}
+		encoded[i] = s
 	}
+	return encoded
 }
 
-var ingredientUnitCompletionItems = []messages.CompletionItem{
-	{
-		Label:         "g",
-		Kind:          messages.CompletionItemKindUnit,
-		Detail:        "grams",
-		Documentation: "Grams are a unit of mass.",
-	},
-	{
-		Label:         "kg",
-		Kind:          messages.CompletionItemKindUnit,
-		Detail:        "kilograms",
-		Documentation: "Kilograms are a unit of mass.",
-	},
-	{
-		Label:         "ml",
-		Kind:          messages.CompletionItemKindUnit,
-		Detail:        "milliliters",
-		Documentation: "Milliliters are a unit of volume.",
-	},
+// codeActionKindRequested returns true if actions of the given kind should be
+// returned for a code action request that is restricted to the only kinds.
+func codeActionKindRequested(only []messages.CodeActionKind, kind messages.CodeActionKind) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, o := range only {
+		if o == kind || strings.HasPrefix(string(kind), string(o)+".") {
+			return true
+		}
+	}
+	return false
 }
 
-func positionIsInRange(r cooklang.Range, position messages.Position) bool {
-	return position.Line >= r.Start.Line &&
-		position.Line <= r.End.Line &&
-		position.Character >= r.Start.Character &&
-		position.Character <= r.End.Character
+func uriToDir(uri string) (dir string, err error) {
+	path, err := messages.DocumentURI(uri).Filename()
+	if err != nil {
+		return
+	}
+	return filepath.Dir(path), nil
 }
 
-func getSwearwordDiagnostics(text string) (diagnostics []messages.Diagnostic) {
-	swearWordRanges := findSwearWords(text)
+// Codes and sources of the diagnostics produced by the checks in this file.
+const (
+	codeParseError          = "parse-error"
+	codeAmericanMeasurement = "american-measurement"
+	codeSwearword           = "swearword"
+	codeInvalidSettings     = "invalid-settings"
+
+	sourceParser       = analyzers.Source + ".parser"
+	sourceMeasurements = analyzers.Source + ".measurements"
+	sourceSwearwords   = analyzers.Source + ".swearwords"
+	sourceSettings     = analyzers.Source + ".settings"
+)
+
+func getSwearwordDiagnostics(text string, words map[string]struct{}) (diagnostics []messages.Diagnostic) {
+	swearWordRanges := findSwearWords(text, words)
 	for _, r := range swearWordRanges {
 		diagnostics = append(diagnostics, messages.Diagnostic{
 			Range:    r,
 			Severity: ptr(messages.DiagnosticSeverityWarning),
-			Source:   ptr("examplelsp"),
+			Code:     ptr(codeSwearword),
+			Source:   ptr(sourceSwearwords),
 			Message:  "Mild swearword",
 		})
 	}
 	return
 }
 
-func getAmericanMeasurementsDiagnostics(text string) (diagnostics []messages.Diagnostic) {
-	recipe, err := cooklang.ParseString(text)
+func getAmericanMeasurementsDiagnostics(p parser.Parser, text string) (diagnostics []messages.Diagnostic) {
+	recipe, err := p.Parse(text)
 	if err != nil {
 		return
 	}
@@ -195,8 +272,12 @@ func getAmericanMeasurementsDiagnostics(text string) (diagnostics []messages.Dia
 							End:   messages.NewPosition(lineIndex, ingredientIndex+len(im)),
 						},
 						Severity: ptr(messages.DiagnosticSeverityInformation),
-						Source:   ptr("examplelsp"),
+						Code:     ptr(codeAmericanMeasurement),
+						Source:   ptr(sourceMeasurements),
 						Message:  "Cups are a silly measurement, consider grams",
+						// Clients that support it strike through the
+						// measurement.
+						Tags: []messages.DiagnosticTag{messages.DiagnosticTagDeprecated},
 					})
 				}
 			}
@@ -205,8 +286,8 @@ func getAmericanMeasurementsDiagnostics(text string) (diagnostics []messages.Dia
 	return
 }
 
-func getRecipeParseErrorDiagnostics(text string) (diagnostics []messages.Diagnostic) {
-	_, err := cooklang.ParseString(text)
+func getRecipeParseErrorDiagnostics(p parser.Parser, text string) (diagnostics []messages.Diagnostic) {
+	_, err := p.Parse(text)
 	if err == nil {
 		return
 	}
@@ -220,7 +301,8 @@ func getRecipeParseErrorDiagnostics(text string) (diagnostics []messages.Diagnos
 			End:   messages.NewPosition(cerr.Range.End.Line, cerr.Range.End.Character),
 		},
 		Severity: ptr(messages.DiagnosticSeverityError),
-		Source:   ptr("examplelsp"),
+		Code:     ptr(codeParseError),
+		Source:   ptr(sourceParser),
 		Message:  cerr.Message,
 	})
 	return
@@ -269,25 +351,73 @@ var swearWords = map[string]struct{}{
 	"sod off":      {},
 }
 
-var wordRegexp = regexp.MustCompile(`\w+`)
+// configuredSwearWords returns the swear words to report, which are the
+// built-in swear words unless the settings replace them.
+func configuredSwearWords(s settings.Snapshot) map[string]struct{} {
+	if !s.SwearWordCheckEnabled() {
+		return nil
+	}
+	custom, ok := s.SwearWords()
+	if !ok {
+		return swearWords
+	}
+	words := make(map[string]struct{}, len(custom))
+	for _, word := range custom {
+		words[word] = struct{}{}
+	}
+	return words
+}
 
-func findSwearWords(text string) (ranges []messages.Range) {
+// findSwearWords returns the ranges of the swear words in the text. Words are
+// matched as literal text, ignoring case, so they can be phrases, or contain
+// hyphens. They only match whole words, e.g. "git" doesn't match "digit".
+func findSwearWords(text string, words map[string]struct{}) (ranges []messages.Range) {
+	re, ok := swearWordsRegexp(words)
+	if !ok {
+		return nil
+	}
 	for lineIndex, line := range strings.Split(text, "\n") {
-		for _, wordPosition := range wordRegexp.FindAllStringIndex(line, -1) {
-			word := strings.ToLower(line[wordPosition[0]:wordPosition[1]])
-			if _, isSwearword := swearWords[word]; isSwearword {
-				ranges = append(ranges, messages.Range{
-					Start: messages.Position{
-						Line:      lineIndex,
-						Character: wordPosition[0],
-					},
-					End: messages.Position{
-						Line:      lineIndex,
-						Character: wordPosition[0] + wordPosition[1],
-					},
-				})
-			}
+		for _, wordPosition := range re.FindAllStringIndex(line, -1) {
+			ranges = append(ranges, messages.Range{
+				Start: messages.NewPosition(lineIndex, documents.PositionAt(line, wordPosition[0]).Character),
+				End:   messages.NewPosition(lineIndex, documents.PositionAt(line, wordPosition[1]).Character),
+			})
 		}
 	}
 	return ranges
 }
+
+// swearWordsRegexp returns a case-insensitive expression that matches any of
+// the words. Longer words are tried first, so that a phrase is matched as a
+// whole, rather than a word at its start.
+func swearWordsRegexp(words map[string]struct{}) (re *regexp.Regexp, ok bool) {
+	patterns := make([]string, 0, len(words))
+	for word := range words {
+		if word == "" {
+			continue
+		}
+		pattern := regexp.QuoteMeta(word)
+		if isWordByte(word[0]) {
+			pattern = `\b` + pattern
+		}
+		if isWordByte(word[len(word)-1]) {
+			pattern += `\b`
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, false
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return regexp.MustCompile(`(?i)` + strings.Join(patterns, "|")), true
+}
+
+// isWordByte returns true if b is matched by \w.
+func isWordByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestFindSwearWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		words    []string
+		expected []messages.Range
+	}{
+		{
+			name:     "words are matched ignoring case",
+			text:     "Stir the BLOODY sauce.",
+			words:    []string{"bloody"},
+			expected: []messages.Range{{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 15)}},
+		},
+		{
+			name:  "only whole words are matched",
+			text:  "Add a digit of salt.",
+			words: []string{"git"},
+		},
+		{
+			name:     "phrases are matched",
+			text:     "Oh sod off,\nJesus Christ.",
+			words:    []string{"sod off", "jesus christ"},
+			expected: []messages.Range{{Start: messages.NewPosition(0, 3), End: messages.NewPosition(0, 10)}, {Start: messages.NewPosition(1, 0), End: messages.NewPosition(1, 12)}},
+		},
+		{
+			name:     "phrases are matched before the words they start with",
+			text:     "Sod off.",
+			words:    []string{"sod", "sod off"},
+			expected: []messages.Range{{Start: messages.NewPosition(0, 0), End: messages.NewPosition(0, 7)}},
+		},
+		{
+			name:     "words can contain hyphens",
+			text:     "A half-arsed stew.",
+			words:    []string{"half-arsed"},
+			expected: []messages.Range{{Start: messages.NewPosition(0, 2), End: messages.NewPosition(0, 12)}},
+		},
+		{
+			name:  "special characters are literal",
+			text:  "Mix for 10 minutes.",
+			words: []string{"m.x", "1+"},
+		},
+		{
+			name:  "no words",
+			text:  "Stir the bloody sauce.",
+			words: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words := map[string]struct{}{}
+			for _, w := range test.words {
+				words[w] = struct{}{}
+			}
+			actual := findSwearWords(test.text, words)
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, actual)
+			}
+			for i, r := range actual {
+				if r != test.expected[i] {
+					t.Errorf("expected range %v, got %v", test.expected[i], r)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package messages
+
+const ApplyWorkspaceEditRequestMethod = "workspace/applyEdit"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_applyEdit
+type ApplyWorkspaceEditParams struct {
+	// An optional label of the workspace edit. This label is presented in the
+	// user interface for example on an undo stack to undo the workspace edit.
+	Label string `json:"label,omitempty"`
+	// The edits to apply.
+	Edit WorkspaceEdit `json:"edit"`
+}
+
+type ApplyWorkspaceEditResult struct {
+	// Indicates whether the edit was applied or not.
+	Applied bool `json:"applied"`
+	// An optional textual description for why the edit was not applied.
+	FailureReason string `json:"failureReason,omitempty"`
+}
@@ -0,0 +1,127 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	CodeActionRequestMethod        = "textDocument/codeAction"
+	CodeActionResolveRequestMethod = "codeAction/resolve"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_codeAction
+type CodeActionParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      CodeActionContext      `json:"context"`
+}
+
+type CodeActionContext struct {
+	// An array of diagnostics known on the client side overlapping the range
+	// provided to the `textDocument/codeAction` request.
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Requested kind of actions to return.
+	//
+	// Actions not of this kind are filtered out by the client before being
+	// shown. So servers can omit computing them.
+	Only []CodeActionKind `json:"only,omitempty"`
+	// The reason why code actions were requested.
+	TriggerKind CodeActionTriggerKind `json:"triggerKind,omitempty"`
+}
+
+type CodeActionTriggerKind int
+
+const (
+	// Code actions were explicitly requested by the user or by an extension.
+	CodeActionTriggerKindInvoked CodeActionTriggerKind = 1
+	// Code actions were requested automatically, e.g. when the selection
+	// changes.
+	CodeActionTriggerKindAutomatic CodeActionTriggerKind = 2
+)
+
+type CodeActionKind string
+
+const (
+	CodeActionKindEmpty                 CodeActionKind = ""
+	CodeActionKindQuickFix              CodeActionKind = "quickfix"
+	CodeActionKindRefactor              CodeActionKind = "refactor"
+	CodeActionKindRefactorExtract       CodeActionKind = "refactor.extract"
+	CodeActionKindRefactorInline        CodeActionKind = "refactor.inline"
+	CodeActionKindRefactorRewrite       CodeActionKind = "refactor.rewrite"
+	CodeActionKindSource                CodeActionKind = "source"
+	CodeActionKindSourceOrganizeImports CodeActionKind = "source.organizeImports"
+	CodeActionKindSourceFixAll          CodeActionKind = "source.fixAll"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeAction
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        CodeActionKind `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool           `json:"isPreferred,omitempty"`
+	// Disabled is set when the action can't currently be applied. Clients
+	// show the reason instead of running the action.
+	Disabled *CodeActionDisabled `json:"disabled,omitempty"`
+	Edit     *WorkspaceEdit      `json:"edit,omitempty"`
+	// Command executed after the edit, if any, is applied.
+	Command *Command `json:"command,omitempty"`
+	// A data entry field that is preserved between a textDocument/codeAction
+	// request and a codeAction/resolve request.
+	Data any `json:"data,omitempty"`
+}
+
+type CodeActionDisabled struct {
+	// Human readable description of why the code action is currently
+	// disabled.
+	Reason string `json:"reason"`
+}
+
+type CodeActionOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// CodeActionKinds that this server may return.
+	CodeActionKinds []CodeActionKind `json:"codeActionKinds,omitempty"`
+	// The server provides support to resolve additional information for a
+	// code action.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
+
+// ErrInvalidCommandOrCodeAction is returned when an item of a code action
+// result is neither a Command nor a CodeAction.
+var ErrInvalidCommandOrCodeAction = errors.New("messages: invalid command or code action")
+
+// CommandOrCodeAction is an item of the result of a textDocument/codeAction
+// request. Only one of the fields should be set.
+type CommandOrCodeAction struct {
+	Command    *Command
+	CodeAction *CodeAction
+}
+
+func (c CommandOrCodeAction) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Command != nil:
+		return json.Marshal(c.Command)
+	case c.CodeAction != nil:
+		return json.Marshal(c.CodeAction)
+	}
+	return nil, ErrInvalidCommandOrCodeAction
+}
+
+func (c *CommandOrCodeAction) UnmarshalJSON(data []byte) error {
+	*c = CommandOrCodeAction{}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return ErrInvalidCommandOrCodeAction
+	}
+	// The command of a Command is its identifier, while the command of a
+	// CodeAction is a Command object.
+	if command, ok := fields["command"]; ok && bytes.HasPrefix(bytes.TrimSpace(command), []byte(`"`)) {
+		c.Command = &Command{}
+		return json.Unmarshal(data, c.Command)
+	}
+	c.CodeAction = &CodeAction{}
+	return json.Unmarshal(data, c.CodeAction)
+}
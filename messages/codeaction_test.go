@@ -0,0 +1,89 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCodeActionParamsJSON(t *testing.T) {
+	var params CodeActionParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 12}},
+		"context": {
+			"diagnostics": [],
+			"only": ["quickfix", "source.fixAll"],
+			"triggerKind": 2
+		},
+		"workDoneToken": "1d546990-40a3-4b77-b134-46622995f6ae",
+		"partialResultToken": 7
+	}`), &params)
+	if params.Context.TriggerKind != CodeActionTriggerKindAutomatic {
+		t.Errorf("expected an automatic trigger, got %v", params.Context.TriggerKind)
+	}
+	if len(params.Context.Only) != 2 || params.Context.Only[1] != CodeActionKindSourceFixAll {
+		t.Errorf("unexpected kinds: %v", params.Context.Only)
+	}
+}
+
+func TestCodeActionOptionsJSON(t *testing.T) {
+	var options CodeActionOptions
+	roundTrip(t, []byte(`{
+		"workDoneProgress": true,
+		"codeActionKinds": ["quickfix", "refactor.rewrite", "source.organizeImports"],
+		"resolveProvider": true
+	}`), &options)
+}
+
+func TestCommandOrCodeActionJSON(t *testing.T) {
+	var result []CommandOrCodeAction
+	roundTrip(t, []byte(`[
+		{
+			"title": "Fix all whitespace problems",
+			"command": "examplelsp.fixAll",
+			"arguments": [{"uri": "file:///Users/alice/recipes/pasta.cook"}]
+		},
+		{
+			"title": "Remove duplicate step",
+			"kind": "quickfix",
+			"isPreferred": true,
+			"edit": {
+				"changes": {
+					"file:///Users/alice/recipes/pasta.cook": [
+						{"range": {"start": {"line": 4, "character": 0}, "end": {"line": 6, "character": 0}}, "newText": ""}
+					]
+				}
+			},
+			"command": {"title": "Collect logs", "command": "examplelsp.collectLogs"},
+			"data": {"id": 3}
+		},
+		{
+			"title": "Extract step",
+			"kind": "refactor.extract",
+			"disabled": {"reason": "Select a step to extract"}
+		}
+	]`), &result)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(result))
+	}
+	if result[0].Command == nil || result[0].Command.Command != "examplelsp.fixAll" {
+		t.Errorf("expected a command, got %#v", result[0])
+	}
+	if result[1].CodeAction == nil || result[1].CodeAction.Command == nil || result[1].CodeAction.Command.Command != "examplelsp.collectLogs" {
+		t.Errorf("expected a code action with a command, got %#v", result[1])
+	}
+	if result[2].CodeAction == nil || result[2].CodeAction.Disabled == nil || result[2].CodeAction.Disabled.Reason != "Select a step to extract" {
+		t.Errorf("expected a disabled code action, got %#v", result[2])
+	}
+}
+
+func TestCommandOrCodeActionInvalid(t *testing.T) {
+	if _, err := json.Marshal(CommandOrCodeAction{}); !errors.Is(err, ErrInvalidCommandOrCodeAction) {
+		t.Errorf("expected an empty item not to marshal, got %v", err)
+	}
+	var item CommandOrCodeAction
+	if err := json.Unmarshal([]byte(`"examplelsp.fixAll"`), &item); !errors.Is(err, ErrInvalidCommandOrCodeAction) {
+		t.Errorf("expected a string to be rejected, got %v", err)
+	}
+}
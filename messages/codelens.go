@@ -0,0 +1,43 @@
+package messages
+
+import "encoding/json"
+
+const (
+	CodeLensRequestMethod        = "textDocument/codeLens"
+	CodeLensResolveRequestMethod = "codeLens/resolve"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_codeLens
+//
+// The result of the request is []CodeLens, or null.
+type CodeLensParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The document to request code lens for.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// A CodeLens represents a command that should be shown along with source
+// text, like the number of references, or a way to run tests.
+//
+// A code lens is unresolved when no command is associated to it. For
+// performance reasons the creation of a code lens and resolving should be
+// done in two stages.
+type CodeLens struct {
+	// The range in which this code lens is valid. Should only span a single
+	// line.
+	Range Range `json:"range"`
+	// The command this code lens represents.
+	Command *Command `json:"command,omitempty"`
+	// A data entry field that is preserved on a code lens item between a
+	// code lens and a code lens resolve request. It's kept as raw JSON, so
+	// that it's sent back to the client exactly as it was received.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#codeLensOptions
+type CodeLensOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// Code lens has a resolve provider as well.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
@@ -0,0 +1,28 @@
+package messages
+
+import "testing"
+
+func TestCodeLensJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{
+			name:    "unresolved",
+			payload: `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":14}},"data":{"factor":2}}`,
+		},
+		{
+			name:    "resolved",
+			payload: `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":14}},"command":{"title":"Scale by 2","command":"examplelsp.scale","arguments":[{"factor":2}]},"data":{"factor":2}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var lens CodeLens
+			roundTrip(t, []byte(test.payload), &lens)
+			if string(lens.Data) != `{"factor":2}` {
+				t.Errorf("expected the data to be kept, got %s", lens.Data)
+			}
+		})
+	}
+}
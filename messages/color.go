@@ -0,0 +1,110 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+)
+
+const (
+	DocumentColorRequestMethod     = "textDocument/documentColor"
+	ColorPresentationRequestMethod = "textDocument/colorPresentation"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_documentColor
+//
+// The result of the request is []ColorInformation.
+type DocumentColorParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type ColorInformation struct {
+	// The range in the document where this color appears.
+	Range Range `json:"range"`
+	// The actual color value for this color range.
+	Color Color `json:"color"`
+}
+
+// Color in RGBA space. Each component is in the range [0-1].
+type Color struct {
+	Red   float64 `json:"red"`
+	Green float64 `json:"green"`
+	Blue  float64 `json:"blue"`
+	Alpha float64 `json:"alpha"`
+}
+
+// MarshalJSON writes the components in decimal notation. The standard
+// encoder uses exponents for values smaller than 1e-6, which some clients
+// can't parse.
+func (c Color) MarshalJSON() ([]byte, error) {
+	for _, v := range []float64{c.Red, c.Green, c.Blue, c.Alpha} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, ErrInvalidColor
+		}
+	}
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return []byte(`{"red":` + f(c.Red) + `,"green":` + f(c.Green) + `,"blue":` + f(c.Blue) + `,"alpha":` + f(c.Alpha) + `}`), nil
+}
+
+// ErrInvalidColor is returned when a color component isn't a number.
+var ErrInvalidColor = errors.New("messages: invalid color")
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_colorPresentation
+//
+// The result of the request is []ColorPresentation.
+type ColorPresentationParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The color information to request presentations for.
+	Color Color `json:"color"`
+	// The range where the color would be inserted.
+	Range Range `json:"range"`
+}
+
+type ColorPresentation struct {
+	// The label of this color presentation. It will be shown on the color
+	// picker header. By default this is also the text that is inserted when
+	// selecting this color presentation.
+	Label string `json:"label"`
+	// An edit which is applied to a document when selecting this
+	// presentation for the color. When omitted the label is used.
+	TextEdit *TextEdit `json:"textEdit,omitempty"`
+	// An optional array of additional text edits that are applied when
+	// selecting this color presentation.
+	AdditionalTextEdits []TextEdit `json:"additionalTextEdits,omitempty"`
+}
+
+type DocumentColorOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+}
+
+// BoolOrDocumentColorOptions is the colorProvider server capability. It's
+// marshalled as the options if they're set, and as Bool otherwise.
+type BoolOrDocumentColorOptions struct {
+	Bool    bool
+	Options *DocumentColorOptions
+}
+
+// ErrInvalidColorProvider is returned when the colorProvider capability isn't
+// a boolean or an object.
+var ErrInvalidColorProvider = errors.New("messages: invalid color provider")
+
+func (b BoolOrDocumentColorOptions) MarshalJSON() ([]byte, error) {
+	if b.Options != nil {
+		return json.Marshal(b.Options)
+	}
+	return json.Marshal(b.Bool)
+}
+
+func (b *BoolOrDocumentColorOptions) UnmarshalJSON(data []byte) error {
+	*b = BoolOrDocumentColorOptions{}
+	return unmarshalBoolOrOptions(data, &b.Bool, &b.Options, ErrInvalidColorProvider)
+}
@@ -0,0 +1,115 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentColorJSON(t *testing.T) {
+	var params DocumentColorParams
+	roundTrip(t, []byte(`{"textDocument":{"uri":"file:///Users/alice/recipes/pasta.cook"}}`), &params)
+
+	var colors []ColorInformation
+	roundTrip(t, []byte(`[{
+		"range": {"start": {"line": 1, "character": 4}, "end": {"line": 1, "character": 11}},
+		"color": {"red": 1, "green": 0.388, "blue": 0.278, "alpha": 1}
+	}]`), &colors)
+	expected := Color{Red: 1, Green: 0.388, Blue: 0.278, Alpha: 1}
+	if len(colors) != 1 || colors[0].Color != expected {
+		t.Errorf("expected %#v, got %#v", expected, colors)
+	}
+}
+
+func TestColorPresentationJSON(t *testing.T) {
+	var params ColorPresentationParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"color": {"red": 0.5, "green": 0.25, "blue": 0, "alpha": 0.75},
+		"range": {"start": {"line": 1, "character": 4}, "end": {"line": 1, "character": 11}}
+	}`), &params)
+
+	var presentations []ColorPresentation
+	roundTrip(t, []byte(`[
+		{"label": "tomato"},
+		{
+			"label": "#ff6347",
+			"textEdit": {"range": {"start": {"line": 1, "character": 4}, "end": {"line": 1, "character": 11}}, "newText": "#ff6347"},
+			"additionalTextEdits": [{"range": {"start": {"line": 0, "character": 0}, "end": {"line": 0, "character": 0}}, "newText": ">> colors: hex\n"}]
+		}
+	]`), &presentations)
+	if len(presentations) != 2 || presentations[0].TextEdit != nil || presentations[1].TextEdit == nil {
+		t.Errorf("unexpected presentations: %#v", presentations)
+	}
+}
+
+func TestColorMarshalsDecimals(t *testing.T) {
+	data, err := json.Marshal(Color{Red: 0.0000001, Green: 1, Blue: 0.5, Alpha: 1e-9})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	expected := `{"red":0.0000001,"green":1,"blue":0.5,"alpha":0.000000001}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+	var c Color
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if c.Red != 0.0000001 || c.Alpha != 1e-9 {
+		t.Errorf("expected the values to be kept, got %#v", c)
+	}
+	if _, err := json.Marshal(Color{Red: math.NaN()}); !errors.Is(err, ErrInvalidColor) {
+		t.Errorf("expected ErrInvalidColor, got %v", err)
+	}
+}
+
+func TestColorProviderJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected BoolOrDocumentColorOptions
+	}{
+		{
+			name:     "true",
+			payload:  `true`,
+			expected: BoolOrDocumentColorOptions{Bool: true},
+		},
+		{
+			name:     "false",
+			payload:  `false`,
+			expected: BoolOrDocumentColorOptions{},
+		},
+		{
+			name:     "options",
+			payload:  `{"workDoneProgress":true}`,
+			expected: BoolOrDocumentColorOptions{Options: &DocumentColorOptions{WorkDoneProgress: true}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var provider BoolOrDocumentColorOptions
+			roundTrip(t, []byte(test.payload), &provider)
+			if !reflect.DeepEqual(provider, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, provider)
+			}
+		})
+	}
+	var provider BoolOrDocumentColorOptions
+	if err := json.Unmarshal([]byte(`"yes"`), &provider); !errors.Is(err, ErrInvalidColorProvider) {
+		t.Errorf("expected ErrInvalidColorProvider, got %v", err)
+	}
+	data, err := json.Marshal(ServerCapabilities{ColorProvider: &BoolOrDocumentColorOptions{Bool: true}})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(fields["colorProvider"]) != "true" {
+		t.Errorf("expected the capability to be true, got %s", fields["colorProvider"])
+	}
+}
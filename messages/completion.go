@@ -1,11 +1,21 @@
 package messages
 
-const CompletionRequestMethod = "textDocument/completion"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	CompletionRequestMethod            = "textDocument/completion"
+	CompletionItemResolveRequestMethod = "completionItem/resolve"
+)
 
 type CompletionParams struct {
-	TextDocument TextDocumentIdentifier `json:"textDocument"`
-	Position     Position               `json:"position"`
-	Context      *CompletionContext     `json:"context"`
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+	Context *CompletionContext `json:"context"`
 }
 
 type TriggerKind int
@@ -21,15 +31,289 @@ type CompletionContext struct {
 	TriggerCharacter string      `json:"triggerCharacter"`
 }
 
-type CompletionResult struct {
+// CompletionResult is the previous name of CompletionList.
+//
+// Deprecated: use CompletionList.
+type CompletionResult = CompletionList
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionList
+//
+// A completion request can be answered with a CompletionList, or a bare
+// []CompletionItem, which is the same as a complete list without defaults.
+type CompletionList struct {
+	// This list is not complete. Further typing should result in recomputing
+	// this list.
+	IsIncomplete bool `json:"isIncomplete"`
+	// Defaults used for the items that don't set the field. Only the defaults
+	// that the client supports should be sent, see SupportsItemDefault.
+	ItemDefaults *CompletionItemDefaults `json:"itemDefaults,omitempty"`
+	// The completion items.
 	Items []CompletionItem `json:"items"`
 }
 
+// CompletionItemDefaults are the defaults of the items in a CompletionList.
+type CompletionItemDefaults struct {
+	// A default commit character set.
+	CommitCharacters []string `json:"commitCharacters,omitempty"`
+	// A default edit range, used with the insert text or label of items that
+	// don't have a TextEdit.
+	EditRange *RangeOrInsertReplaceRange `json:"editRange,omitempty"`
+	// A default insert text format.
+	InsertTextFormat InsertTextFormat `json:"insertTextFormat,omitempty"`
+	// A default insert text mode.
+	InsertTextMode InsertTextMode `json:"insertTextMode,omitempty"`
+	// A default data value.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// RangeOrInsertReplaceRange is the default edit range of a CompletionList.
+// Only one of the fields should be set.
+type RangeOrInsertReplaceRange struct {
+	Range         *Range
+	InsertReplace *InsertReplaceRange
+}
+
+// InsertReplaceRange are the ranges of an InsertReplaceEdit.
+type InsertReplaceRange struct {
+	Insert  Range `json:"insert"`
+	Replace Range `json:"replace"`
+}
+
+// ErrInvalidRangeOrInsertReplaceRange is returned when an edit range isn't a
+// Range or an InsertReplaceRange.
+var ErrInvalidRangeOrInsertReplaceRange = errors.New("messages: invalid range or insert replace range")
+
+func (r RangeOrInsertReplaceRange) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.Range != nil:
+		return json.Marshal(r.Range)
+	case r.InsertReplace != nil:
+		return json.Marshal(r.InsertReplace)
+	}
+	return nil, ErrInvalidRangeOrInsertReplaceRange
+}
+
+func (r *RangeOrInsertReplaceRange) UnmarshalJSON(data []byte) error {
+	*r = RangeOrInsertReplaceRange{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return ErrInvalidRangeOrInsertReplaceRange
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["insert"]; ok {
+		r.InsertReplace = &InsertReplaceRange{}
+		return json.Unmarshal(data, r.InsertReplace)
+	}
+	if _, ok := fields["start"]; ok {
+		r.Range = &Range{}
+		return json.Unmarshal(data, r.Range)
+	}
+	return ErrInvalidRangeOrInsertReplaceRange
+}
+
 type CompletionItem struct {
-	Label         string             `json:"label"`
-	Kind          CompletionItemKind `json:"kind,omitempty"`
-	Detail        string             `json:"detail,omitempty"`
-	Documentation string             `json:"documentation,omitempty"`
+	// The label of this completion item. It's also the text that's inserted
+	// when selecting this completion, unless InsertText or TextEdit is set.
+	Label string `json:"label"`
+	// Additional details for the label.
+	LabelDetails *CompletionItemLabelDetails `json:"labelDetails,omitempty"`
+	Kind         CompletionItemKind          `json:"kind,omitempty"`
+	// Tags for this completion item.
+	Tags []CompletionItemTag `json:"tags,omitempty"`
+	// A human-readable string with additional information about this item,
+	// like type or symbol information.
+	Detail string `json:"detail,omitempty"`
+	// A human-readable string that represents a doc-comment. It's plain
+	// text, or MarkupContent in one of the client's documentation formats.
+	Documentation *StringOrMarkupContent `json:"documentation,omitempty"`
+	// Indicates if this item is deprecated.
+	//
+	// Deprecated: use Tags instead.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Select this item when showing.
+	Preselect bool `json:"preselect,omitempty"`
+	// A string that should be used when comparing this item with other items.
+	// When omitted the label is used.
+	SortText string `json:"sortText,omitempty"`
+	// A string that should be used when filtering a set of completion items.
+	// When omitted the label is used.
+	FilterText string `json:"filterText,omitempty"`
+	// A string that should be inserted into a document when selecting this
+	// completion. When omitted the label is used as the insert text.
+	InsertText string `json:"insertText,omitempty"`
+	// The format of the insert text. Defaults to plain text.
+	InsertTextFormat InsertTextFormat `json:"insertTextFormat,omitempty"`
+	// How whitespace and indentation is handled during completion item
+	// insertion. If not provided, the client's default value is used.
+	InsertTextMode InsertTextMode `json:"insertTextMode,omitempty"`
+	// An edit which is applied to a document when selecting this completion.
+	// When an edit is provided, the value of InsertText is ignored.
+	TextEdit *TextEditOrInsertReplaceEdit `json:"textEdit,omitempty"`
+	// Additional edits that are applied when selecting this completion, which
+	// must not overlap with the main edit nor with themselves.
+	AdditionalTextEdits []TextEdit `json:"additionalTextEdits,omitempty"`
+	// Characters that, when typed while this completion is active, accept it
+	// first and then type that character.
+	CommitCharacters []string `json:"commitCharacters,omitempty"`
+	// A data entry field that is preserved on a completion item between a
+	// completion and a completion resolve request.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Additional details for a completion item label.
+type CompletionItemLabelDetails struct {
+	// An optional string which is rendered less prominently directly after
+	// the label, without any spacing.
+	Detail string `json:"detail,omitempty"`
+	// An optional string which is rendered less prominently after
+	// CompletionItemLabelDetails.Detail.
+	Description string `json:"description,omitempty"`
+}
+
+// Completion item tags are extra annotations that tweak the rendering of a
+// completion item.
+type CompletionItemTag int
+
+const (
+	// Render a completion as obsolete, usually using a strike-out.
+	CompletionItemTagDeprecated CompletionItemTag = 1
+)
+
+// How whitespace and indentation is handled during completion item insertion.
+type InsertTextMode int
+
+const (
+	// The insertion or replace strings is taken as it is.
+	InsertTextModeAsIs InsertTextMode = 1
+	// The editor adjusts leading whitespace of new lines so that they match
+	// the indentation up to the cursor of the line for which the item is
+	// accepted.
+	InsertTextModeAdjustIndentation InsertTextMode = 2
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#insertReplaceEdit
+type InsertReplaceEdit struct {
+	// The string to be inserted.
+	NewText string `json:"newText"`
+	// The range if the insert is requested.
+	Insert Range `json:"insert"`
+	// The range if the replace is requested.
+	Replace Range `json:"replace"`
+}
+
+// TextEditOrInsertReplaceEdit is the edit of a completion item. Only one of
+// the fields should be set. InsertReplace edits should only be sent to
+// clients that support them.
+type TextEditOrInsertReplaceEdit struct {
+	TextEdit      *TextEdit
+	InsertReplace *InsertReplaceEdit
+}
+
+// ErrInvalidTextEditOrInsertReplaceEdit is returned when a completion item
+// edit isn't a TextEdit or an InsertReplaceEdit.
+var ErrInvalidTextEditOrInsertReplaceEdit = errors.New("messages: invalid text edit or insert replace edit")
+
+func (e TextEditOrInsertReplaceEdit) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.TextEdit != nil:
+		return json.Marshal(e.TextEdit)
+	case e.InsertReplace != nil:
+		return json.Marshal(e.InsertReplace)
+	}
+	return nil, ErrInvalidTextEditOrInsertReplaceEdit
+}
+
+func (e *TextEditOrInsertReplaceEdit) UnmarshalJSON(data []byte) error {
+	*e = TextEditOrInsertReplaceEdit{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return ErrInvalidTextEditOrInsertReplaceEdit
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["insert"]; ok {
+		e.InsertReplace = &InsertReplaceEdit{}
+		return json.Unmarshal(data, e.InsertReplace)
+	}
+	if _, ok := fields["range"]; ok {
+		e.TextEdit = &TextEdit{}
+		return json.Unmarshal(data, e.TextEdit)
+	}
+	return ErrInvalidTextEditOrInsertReplaceEdit
+}
+
+type InsertTextFormat int
+
+const (
+	InsertTextFormatPlainText InsertTextFormat = 1
+	// The insert text is a snippet, with tabstops and placeholders, see the
+	// snippet package.
+	InsertTextFormatSnippet InsertTextFormat = 2
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#completionClientCapabilities
+type CompletionClientCapabilities struct {
+	// The client supports the following `CompletionItem` specific
+	// capabilities.
+	CompletionItem *struct {
+		// Client supports snippets as insert text.
+		SnippetSupport bool `json:"snippetSupport,omitempty"`
+		// The client supports these content formats for the documentation
+		// property. The order describes the preferred format of the client.
+		DocumentationFormat []MarkupKind `json:"documentationFormat,omitempty"`
+	} `json:"completionItem,omitempty"`
+	// The completion item kinds that the client supports.
+	CompletionItemKind *struct {
+		// The completion item kind values the client supports. If it's
+		// missing, only the kinds from Text to Reference are supported.
+		ValueSet []CompletionItemKind `json:"valueSet,omitempty"`
+	} `json:"completionItemKind,omitempty"`
+	// The client supports the following `CompletionList` specific
+	// capabilities.
+	CompletionList *struct {
+		// The names of the item defaults that the client supports, e.g.
+		// "editRange".
+		ItemDefaults []string `json:"itemDefaults,omitempty"`
+	} `json:"completionList,omitempty"`
+}
+
+// SnippetSupport returns true if the client accepts completion items with
+// snippets as their insert text.
+func (c *CompletionClientCapabilities) SnippetSupport() bool {
+	return c != nil && c.CompletionItem != nil && c.CompletionItem.SnippetSupport
+}
+
+// SupportsDocumentationFormat returns true if the client accepts completion
+// item documentation in the format. Plain text strings are always accepted.
+func (c *CompletionClientCapabilities) SupportsDocumentationFormat(kind MarkupKind) bool {
+	if c == nil || c.CompletionItem == nil {
+		return false
+	}
+	for _, k := range c.CompletionItem.DocumentationFormat {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsItemDefault returns true if the client accepts the named default in
+// the itemDefaults of a CompletionList, e.g. "editRange".
+func (c *CompletionClientCapabilities) SupportsItemDefault(name string) bool {
+	if c == nil || c.CompletionList == nil {
+		return false
+	}
+	for _, n := range c.CompletionList.ItemDefaults {
+		if n == name {
+			return true
+		}
+	}
+	return false
 }
 
 type CompletionItemKind int
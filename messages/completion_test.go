@@ -0,0 +1,178 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCompletionItemDocumentation(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		var item CompletionItem
+		roundTrip(t, []byte(`{"label":"g","kind":11,"documentation":"Grams are a unit of mass."}`), &item)
+		if item.Documentation == nil || item.Documentation.String == nil || *item.Documentation.String != "Grams are a unit of mass." || item.Documentation.MarkupContent != nil {
+			t.Errorf("expected plain text documentation, got %#v", item.Documentation)
+		}
+	})
+	t.Run("markup content", func(t *testing.T) {
+		var item CompletionItem
+		roundTrip(t, []byte(`{"label":"g","kind":11,"documentation":{"kind":"markdown","value":"`+"```cooklang\\n@flour{500%g}\\n```"+`"}}`), &item)
+		if item.Documentation == nil || item.Documentation.MarkupContent == nil || item.Documentation.MarkupContent.Kind != MarkupKindMarkdown || item.Documentation.String != nil {
+			t.Errorf("expected Markdown documentation, got %#v", item.Documentation)
+		}
+	})
+	t.Run("none", func(t *testing.T) {
+		var item CompletionItem
+		roundTrip(t, []byte(`{"label":"g"}`), &item)
+		if item.Documentation != nil {
+			t.Errorf("expected no documentation, got %#v", item.Documentation)
+		}
+	})
+}
+
+func TestCompletionClientCapabilitiesDocumentationFormat(t *testing.T) {
+	var nilCapabilities *CompletionClientCapabilities
+	if nilCapabilities.SupportsDocumentationFormat(MarkupKindMarkdown) {
+		t.Error("expected clients without completion capabilities not to support Markdown")
+	}
+	var c CompletionClientCapabilities
+	if err := json.Unmarshal([]byte(`{"completionItem":{"snippetSupport":true,"documentationFormat":["markdown","plaintext"]}}`), &c); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !c.SupportsDocumentationFormat(MarkupKindMarkdown) || !c.SupportsDocumentationFormat(MarkupKindPlainText) {
+		t.Errorf("expected both formats to be supported, got %v", c.CompletionItem.DocumentationFormat)
+	}
+	if err := json.Unmarshal([]byte(`{"completionItem":{"documentationFormat":["plaintext"]}}`), &c); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if c.SupportsDocumentationFormat(MarkupKindMarkdown) {
+		t.Error("expected Markdown not to be supported")
+	}
+}
+
+func TestCompletionItemJSON(t *testing.T) {
+	t.Run("minimal", func(t *testing.T) {
+		data, err := json.Marshal(CompletionItem{Label: "g", Kind: CompletionItemKindUnit, Detail: "grams"})
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if expected := `{"label":"g","kind":11,"detail":"grams"}`; string(data) != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+		}
+	})
+	t.Run("fully populated", func(t *testing.T) {
+		documentation := "Grams are a unit of mass."
+		item := CompletionItem{
+			Label:            "g",
+			LabelDetails:     &CompletionItemLabelDetails{Detail: "(mass)", Description: "metric"},
+			Kind:             CompletionItemKindUnit,
+			Tags:             []CompletionItemTag{CompletionItemTagDeprecated},
+			Detail:           "grams",
+			Documentation:    &StringOrMarkupContent{String: &documentation},
+			Deprecated:       true,
+			Preselect:        true,
+			SortText:         "00",
+			FilterText:       "grams",
+			InsertText:       "g",
+			InsertTextFormat: InsertTextFormatPlainText,
+			InsertTextMode:   InsertTextModeAsIs,
+			TextEdit: &TextEditOrInsertReplaceEdit{
+				TextEdit: &TextEdit{
+					Range:   Range{Start: Position{Line: 0, Character: 12}, End: Position{Line: 0, Character: 13}},
+					NewText: "g",
+				},
+			},
+			AdditionalTextEdits: []TextEdit{
+				{Range: Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 0}}, NewText: "\n"},
+			},
+			CommitCharacters: []string{"}"},
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		expected := `{"label":"g","labelDetails":{"detail":"(mass)","description":"metric"},"kind":11,"tags":[1],` +
+			`"detail":"grams","documentation":"Grams are a unit of mass.","deprecated":true,"preselect":true,` +
+			`"sortText":"00","filterText":"grams","insertText":"g","insertTextFormat":1,"insertTextMode":1,` +
+			`"textEdit":{"range":{"start":{"line":0,"character":12},"end":{"line":0,"character":13}},"newText":"g"},` +
+			`"additionalTextEdits":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":0}},"newText":"\n"}],` +
+			`"commitCharacters":["}"]}`
+		if string(data) != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+		}
+		var decoded CompletionItem
+		roundTrip(t, data, &decoded)
+	})
+	t.Run("insert replace edit", func(t *testing.T) {
+		var item CompletionItem
+		roundTrip(t, []byte(`{"label":"kg","textEdit":{"newText":"kg","insert":{"start":{"line":0,"character":12},"end":{"line":0,"character":13}},"replace":{"start":{"line":0,"character":12},"end":{"line":0,"character":14}}}}`), &item)
+		if item.TextEdit == nil || item.TextEdit.InsertReplace == nil || item.TextEdit.TextEdit != nil || item.TextEdit.InsertReplace.Replace.End.Character != 14 {
+			t.Errorf("expected an insert replace edit, got %#v", item.TextEdit)
+		}
+		var edit TextEditOrInsertReplaceEdit
+		if err := json.Unmarshal([]byte(`{"newText":"kg"}`), &edit); !errors.Is(err, ErrInvalidTextEditOrInsertReplaceEdit) {
+			t.Errorf("expected ErrInvalidTextEditOrInsertReplaceEdit, got %v", err)
+		}
+	})
+}
+
+func TestCompletionListJSON(t *testing.T) {
+	list := CompletionList{
+		IsIncomplete: true,
+		ItemDefaults: &CompletionItemDefaults{
+			CommitCharacters: []string{"}"},
+			EditRange: &RangeOrInsertReplaceRange{
+				Range: &Range{Start: Position{Line: 2, Character: 7}, End: Position{Line: 2, Character: 9}},
+			},
+			InsertTextFormat: InsertTextFormatPlainText,
+			InsertTextMode:   InsertTextModeAsIs,
+			Data:             json.RawMessage(`{"uri":"file:///pasta.cook"}`),
+		},
+		Items: []CompletionItem{{Label: "boil"}},
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	expected := `{"isIncomplete":true,"itemDefaults":{"commitCharacters":["}"],` +
+		`"editRange":{"start":{"line":2,"character":7},"end":{"line":2,"character":9}},` +
+		`"insertTextFormat":1,"insertTextMode":1,"data":{"uri":"file:///pasta.cook"}},` +
+		`"items":[{"label":"boil"}]}`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	t.Run("complete lists without defaults", func(t *testing.T) {
+		data, err := json.Marshal(CompletionList{Items: []CompletionItem{}})
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if expected := `{"isIncomplete":false,"items":[]}`; string(data) != expected {
+			t.Errorf("expected %s, got %s", expected, data)
+		}
+	})
+	t.Run("insert replace edit range", func(t *testing.T) {
+		var decoded CompletionList
+		roundTrip(t, []byte(`{"isIncomplete":false,"itemDefaults":{"editRange":{"insert":{"start":{"line":0,"character":1},"end":{"line":0,"character":2}},"replace":{"start":{"line":0,"character":1},"end":{"line":0,"character":5}}}},"items":[]}`), &decoded)
+		if r := decoded.ItemDefaults.EditRange; r == nil || r.InsertReplace == nil || r.Range != nil || r.InsertReplace.Replace.End.Character != 5 {
+			t.Errorf("expected an insert replace range, got %#v", r)
+		}
+		var r RangeOrInsertReplaceRange
+		if err := json.Unmarshal([]byte(`{"line":0}`), &r); !errors.Is(err, ErrInvalidRangeOrInsertReplaceRange) {
+			t.Errorf("expected ErrInvalidRangeOrInsertReplaceRange, got %v", err)
+		}
+	})
+	t.Run("item defaults capability", func(t *testing.T) {
+		var c CompletionClientCapabilities
+		if err := json.Unmarshal([]byte(`{"completionList":{"itemDefaults":["commitCharacters","editRange"]}}`), &c); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if !c.SupportsItemDefault("editRange") || c.SupportsItemDefault("data") {
+			t.Errorf("unexpected item defaults support: %v", c.CompletionList.ItemDefaults)
+		}
+		var nilCapabilities *CompletionClientCapabilities
+		if nilCapabilities.SupportsItemDefault("editRange") {
+			t.Error("expected clients without completion capabilities not to support item defaults")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const DefinitionRequestMethod = "textDocument/definition"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_definition
+type DefinitionParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#definitionClientCapabilities
+type DefinitionClientCapabilities struct {
+	// Whether definition supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The client supports additional metadata in the form of definition links.
+	LinkSupport bool `json:"linkSupport,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#locationLink
+type LocationLink struct {
+	// Span of the origin of this link, used as the underlined span for mouse
+	// interaction. Defaults to the word range at the mouse position.
+	OriginSelectionRange *Range `json:"originSelectionRange,omitempty"`
+	// The target resource identifier of this link.
+	TargetURI string `json:"targetUri"`
+	// The full target range of this link, e.g. the whole step that declares
+	// a timer.
+	TargetRange Range `json:"targetRange"`
+	// The range that should be selected and revealed when this link is being
+	// followed, e.g. the name of the timer. Must be contained by TargetRange.
+	TargetSelectionRange Range `json:"targetSelectionRange"`
+}
+
+// DefinitionResult is one of Location, []Location or []LocationLink. Only one
+// of the fields should be set. If none are, it's marshalled as null, meaning
+// that there's no definition.
+type DefinitionResult struct {
+	Location      *Location
+	Locations     []Location
+	LocationLinks []LocationLink
+}
+
+func (dr DefinitionResult) MarshalJSON() ([]byte, error) {
+	switch {
+	case dr.Location != nil:
+		return json.Marshal(dr.Location)
+	case dr.Locations != nil:
+		return json.Marshal(dr.Locations)
+	case dr.LocationLinks != nil:
+		return json.Marshal(dr.LocationLinks)
+	}
+	return []byte("null"), nil
+}
+
+func (dr *DefinitionResult) UnmarshalJSON(data []byte) error {
+	*dr = DefinitionResult{}
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		dr.Location = &Location{}
+		return json.Unmarshal(data, dr.Location)
+	}
+	// Arrays contain either locations or links, which have a targetUri.
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		if _, isLink := items[0]["targetUri"]; isLink {
+			return json.Unmarshal(data, &dr.LocationLinks)
+		}
+	}
+	return json.Unmarshal(data, &dr.Locations)
+}
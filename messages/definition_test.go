@@ -0,0 +1,95 @@
+package messages
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// roundTrip decodes the payload into v, encodes it again, and checks that the
+// result is equivalent to the payload.
+func roundTrip(t *testing.T, payload []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(payload, v); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	actual, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var expected, got any
+	if err := json.Unmarshal(payload, &expected); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if err := json.Unmarshal(actual, &got); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected:\n%s\ngot:\n%s", payload, actual)
+	}
+}
+
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("testdata", "vscode", name))
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return payload
+}
+
+func TestDefinitionParamsJSON(t *testing.T) {
+	var params DefinitionParams
+	roundTrip(t, readPayload(t, "definition-params.json"), &params)
+	if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(4, 17) {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
+
+func TestDefinitionClientCapabilitiesJSON(t *testing.T) {
+	var capabilities ClientCapabilities
+	roundTrip(t, readPayload(t, "definition-client-capabilities.json"), &capabilities)
+	if capabilities.TextDocument == nil || capabilities.TextDocument.Definition == nil || !capabilities.TextDocument.Definition.LinkSupport {
+		t.Errorf("expected link support, got %#v", capabilities.TextDocument)
+	}
+}
+
+func TestDefinitionResultJSON(t *testing.T) {
+	tests := []struct {
+		payload string
+		check   func(r DefinitionResult) bool
+	}{
+		{
+			payload: "definition-result-location.json",
+			check:   func(r DefinitionResult) bool { return r.Location != nil },
+		},
+		{
+			payload: "definition-result-locations.json",
+			check:   func(r DefinitionResult) bool { return len(r.Locations) == 2 },
+		},
+		{
+			payload: "definition-result-links.json",
+			check: func(r DefinitionResult) bool {
+				return len(r.LocationLinks) == 1 && r.LocationLinks[0].OriginSelectionRange != nil
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.payload, func(t *testing.T) {
+			var r DefinitionResult
+			roundTrip(t, readPayload(t, test.payload), &r)
+			if !test.check(r) {
+				t.Errorf("unexpected result: %#v", r)
+			}
+		})
+	}
+	t.Run("no definition", func(t *testing.T) {
+		var r DefinitionResult
+		roundTrip(t, []byte("null"), &r)
+		if !reflect.DeepEqual(r, DefinitionResult{}) {
+			t.Errorf("expected an empty result, got %#v", r)
+		}
+	})
+}
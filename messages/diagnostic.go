@@ -1,5 +1,7 @@
 package messages
 
+import "encoding/json"
+
 const PublishDiagnosticsMethod = "textDocument/publishDiagnostics"
 
 type PublishDiagnosticsParams struct {
@@ -18,9 +20,61 @@ type Diagnostic struct {
 	// diagnostic, e.g. 'typescript' or 'super lint'.
 	Source             *string                        `json:"source"`
 	Message            string                         `json:"message"`
-	Tags               []DiagnosticTag                `json:"tags"`
+	Tags               []DiagnosticTag                `json:"tags,omitempty"`
 	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation"`
-	Data               any                            `json:"any"`
+	// Data is kept by the client, and sent back in the context of code action
+	// requests, if the client declares dataSupport. Use SetData and GetData
+	// to store and read typed data.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// SetData marshals v into the diagnostic's data.
+func (d *Diagnostic) SetData(v any) (err error) {
+	d.Data, err = json.Marshal(v)
+	return err
+}
+
+// GetData unmarshals the diagnostic's data into v. It returns false if the
+// diagnostic doesn't have any data.
+func (d Diagnostic) GetData(v any) (ok bool, err error) {
+	if len(d.Data) == 0 || string(d.Data) == "null" {
+		return false, nil
+	}
+	return true, json.Unmarshal(d.Data, v)
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#publishDiagnosticsClientCapabilities
+type PublishDiagnosticsClientCapabilities struct {
+	// Whether the client accepts diagnostics with related information.
+	RelatedInformation bool `json:"relatedInformation,omitempty"`
+	// Client supports the tag property to provide meta data about a
+	// diagnostic.
+	TagSupport *struct {
+		// The tags supported by the client.
+		ValueSet []DiagnosticTag `json:"valueSet"`
+	} `json:"tagSupport,omitempty"`
+	// Whether the client interprets the version property of the
+	// `textDocument/publishDiagnostics` notification's parameter.
+	VersionSupport bool `json:"versionSupport,omitempty"`
+	// Client supports a codeDescription property.
+	CodeDescriptionSupport bool `json:"codeDescriptionSupport,omitempty"`
+	// Whether the client preserves the data property between a
+	// `textDocument/publishDiagnostics` notification and a
+	// `textDocument/codeAction` request.
+	DataSupport bool `json:"dataSupport,omitempty"`
+}
+
+// SupportsTag returns true if the client accepts diagnostics with the tag.
+func (c *PublishDiagnosticsClientCapabilities) SupportsTag(tag DiagnosticTag) bool {
+	if c == nil || c.TagSupport == nil {
+		return false
+	}
+	for _, t := range c.TagSupport.ValueSet {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 type CodeDescription struct {
@@ -0,0 +1,87 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiagnosticDataJSON(t *testing.T) {
+	type measurement struct {
+		Quantity string `json:"quantity"`
+		Unit     string `json:"unit"`
+	}
+	d := Diagnostic{
+		Range:   Range{Start: NewPosition(2, 4), End: NewPosition(2, 18)},
+		Message: "Cups are a silly measurement, consider grams",
+	}
+	if err := d.SetData(measurement{Quantity: "1 1/2", Unit: "cup"}); err != nil {
+		t.Fatalf("failed to set data: %v", err)
+	}
+	version := 4
+	data, err := json.Marshal(PublishDiagnosticsParams{URI: "file:///recipe.cook", Version: &version, Diagnostics: []Diagnostic{d}})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var fields struct {
+		Diagnostics []map[string]json.RawMessage `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if actual := string(fields.Diagnostics[0]["data"]); actual != `{"quantity":"1 1/2","unit":"cup"}` {
+		t.Errorf("expected the data to be sent under the data key, got %s", actual)
+	}
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(params.Diagnostics[0].Data) != string(d.Data) {
+		t.Errorf("expected the data to round trip, expected %s, got %s", d.Data, params.Diagnostics[0].Data)
+	}
+	var m measurement
+	ok, err := params.Diagnostics[0].GetData(&m)
+	if err != nil || !ok {
+		t.Fatalf("expected data, got %v, %v", ok, err)
+	}
+	if m.Quantity != "1 1/2" || m.Unit != "cup" {
+		t.Errorf("unexpected data: %#v", m)
+	}
+}
+
+func TestDiagnosticDataRaw(t *testing.T) {
+	// Data set by another server, or by the client, is kept byte for byte.
+	payload := `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"message":"m","data":[1, "two", {"three": 3.0}]}`
+	var d Diagnostic
+	if err := json.Unmarshal([]byte(payload), &d); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(d.Data) != `[1, "two", {"three": 3.0}]` {
+		t.Errorf("expected the data to be kept, got %s", d.Data)
+	}
+}
+
+func TestDiagnosticWithoutData(t *testing.T) {
+	for _, payload := range []string{`{"message":"m"}`, `{"message":"m","data":null}`} {
+		var d Diagnostic
+		if err := json.Unmarshal([]byte(payload), &d); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		var v any
+		if ok, err := d.GetData(&v); ok || err != nil {
+			t.Errorf("%s: expected no data, got %v, %v", payload, ok, err)
+		}
+	}
+	data, err := json.Marshal(Diagnostic{Message: "m"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if _, ok := fields["data"]; ok {
+		t.Errorf("expected no data key, got %s", data)
+	}
+}
@@ -0,0 +1,12 @@
+package messages
+
+import "encoding/json"
+
+const DidChangeConfigurationNotification = "workspace/didChangeConfiguration"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_didChangeConfiguration
+type DidChangeConfigurationParams struct {
+	// The actual changed settings. The shape depends on the client, so it's
+	// kept as it was received.
+	Settings json.RawMessage `json:"settings"`
+}
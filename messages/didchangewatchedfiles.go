@@ -0,0 +1,146 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const DidChangeWatchedFilesNotification = "workspace/didChangeWatchedFiles"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_didChangeWatchedFiles
+type DidChangeWatchedFilesParams struct {
+	// The actual file events.
+	Changes []FileEvent `json:"changes"`
+}
+
+// An event describing a file change.
+type FileEvent struct {
+	// The file's URI.
+	URI string `json:"uri"`
+	// The change type.
+	Type FileChangeType `json:"type"`
+}
+
+type FileChangeType int
+
+const (
+	// The file got created.
+	FileChangeTypeCreated FileChangeType = 1
+	// The file got changed.
+	FileChangeTypeChanged FileChangeType = 2
+	// The file got deleted.
+	FileChangeTypeDeleted FileChangeType = 3
+)
+
+// Describe options to be used when registering for file system change events.
+type DidChangeWatchedFilesRegistrationOptions struct {
+	// The watchers to register.
+	Watchers []FileSystemWatcher `json:"watchers"`
+}
+
+type FileSystemWatcher struct {
+	// The glob pattern to watch.
+	GlobPattern GlobPattern `json:"globPattern"`
+	// The kind of events of interest. If omitted it defaults to
+	// WatchKindCreate | WatchKindChange | WatchKindDelete.
+	Kind *WatchKind `json:"kind,omitempty"`
+}
+
+// WatchKind is a set of bit flags of the events to watch for.
+type WatchKind int
+
+const (
+	// Interested in create events.
+	WatchKindCreate WatchKind = 1
+	// Interested in change events.
+	WatchKindChange WatchKind = 2
+	// Interested in delete events.
+	WatchKindDelete WatchKind = 4
+)
+
+// GlobPattern is a glob pattern string, or a RelativePattern. It's
+// marshalled as the RelativePattern if it isn't nil.
+//
+// Glob patterns can have the following syntax:
+//   - `*` to match zero or more characters in a path segment
+//   - `?` to match on one character in a path segment
+//   - `**` to match any number of path segments, including none
+//   - `{}` to group sub patterns into an OR expression
+//   - `[]` to declare a range of characters to match in a path segment
+//   - `[!...]` to negate a range of characters to match in a path segment
+type GlobPattern struct {
+	Pattern  string
+	Relative *RelativePattern
+}
+
+// ErrInvalidGlobPattern is returned when a glob pattern isn't a string or a
+// relative pattern.
+var ErrInvalidGlobPattern = errors.New("messages: invalid glob pattern")
+
+func (p GlobPattern) MarshalJSON() ([]byte, error) {
+	if p.Relative != nil {
+		return json.Marshal(p.Relative)
+	}
+	return json.Marshal(p.Pattern)
+}
+
+func (p *GlobPattern) UnmarshalJSON(data []byte) error {
+	*p = GlobPattern{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidGlobPattern
+	}
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &p.Pattern)
+	case '{':
+		p.Relative = &RelativePattern{}
+		return json.Unmarshal(data, p.Relative)
+	}
+	return ErrInvalidGlobPattern
+}
+
+// A relative pattern is a helper to construct glob patterns that are matched
+// relatively to a base URI.
+type RelativePattern struct {
+	// A workspace folder or a base URI to which this pattern will be matched
+	// against relatively.
+	BaseURI WorkspaceFolderOrURI `json:"baseUri"`
+	// The actual glob pattern.
+	Pattern string `json:"pattern"`
+}
+
+// WorkspaceFolderOrURI is a WorkspaceFolder, or a URI. It's marshalled as the
+// WorkspaceFolder if it isn't nil.
+type WorkspaceFolderOrURI struct {
+	WorkspaceFolder *WorkspaceFolder
+	URI             string
+}
+
+// ErrInvalidWorkspaceFolderOrURI is returned when a base URI isn't a string or
+// a workspace folder.
+var ErrInvalidWorkspaceFolderOrURI = errors.New("messages: invalid workspace folder or URI")
+
+func (w WorkspaceFolderOrURI) MarshalJSON() ([]byte, error) {
+	if w.WorkspaceFolder != nil {
+		return json.Marshal(w.WorkspaceFolder)
+	}
+	return json.Marshal(w.URI)
+}
+
+func (w *WorkspaceFolderOrURI) UnmarshalJSON(data []byte) error {
+	*w = WorkspaceFolderOrURI{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidWorkspaceFolderOrURI
+	}
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &w.URI)
+	case '{':
+		w.WorkspaceFolder = &WorkspaceFolder{}
+		return json.Unmarshal(data, w.WorkspaceFolder)
+	}
+	return ErrInvalidWorkspaceFolderOrURI
+}
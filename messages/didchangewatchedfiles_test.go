@@ -0,0 +1,88 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDidChangeWatchedFilesParams(t *testing.T) {
+	payload := []byte(`{
+		"changes": [
+			{"uri": "file:///recipes/pasta.cook", "type": 1},
+			{"uri": "file:///recipes/soup.cook", "type": 2},
+			{"uri": "file:///recipes/tea.cook", "type": 3}
+		]
+	}`)
+	var params DidChangeWatchedFilesParams
+	roundTrip(t, payload, &params)
+	expected := []FileEvent{
+		{URI: "file:///recipes/pasta.cook", Type: FileChangeTypeCreated},
+		{URI: "file:///recipes/soup.cook", Type: FileChangeTypeChanged},
+		{URI: "file:///recipes/tea.cook", Type: FileChangeTypeDeleted},
+	}
+	if !reflect.DeepEqual(params.Changes, expected) {
+		t.Errorf("expected %#v, got %#v", expected, params.Changes)
+	}
+}
+
+func TestDidChangeWatchedFilesRegistrationOptions(t *testing.T) {
+	payload := []byte(`{
+		"watchers": [
+			{"globPattern": "**/*.cook"},
+			{
+				"globPattern": {
+					"baseUri": {"uri": "file:///recipes", "name": "recipes"},
+					"pattern": "**/.examplelsp.json"
+				},
+				"kind": 6
+			},
+			{
+				"globPattern": {
+					"baseUri": "file:///recipes/sauces",
+					"pattern": "*.cook"
+				},
+				"kind": 1
+			}
+		]
+	}`)
+	var options DidChangeWatchedFilesRegistrationOptions
+	roundTrip(t, payload, &options)
+	if len(options.Watchers) != 3 {
+		t.Fatalf("expected 3 watchers, got %d", len(options.Watchers))
+	}
+
+	if pattern := options.Watchers[0].GlobPattern; pattern.Pattern != "**/*.cook" || pattern.Relative != nil {
+		t.Errorf("expected a string pattern, got %#v", pattern)
+	}
+	if options.Watchers[0].Kind != nil {
+		t.Errorf("expected the default kind, got %v", *options.Watchers[0].Kind)
+	}
+
+	folder := options.Watchers[1]
+	expectedFolder := &RelativePattern{
+		BaseURI: WorkspaceFolderOrURI{WorkspaceFolder: &WorkspaceFolder{URI: "file:///recipes", Name: "recipes"}},
+		Pattern: "**/.examplelsp.json",
+	}
+	if !reflect.DeepEqual(folder.GlobPattern.Relative, expectedFolder) {
+		t.Errorf("expected %#v, got %#v", expectedFolder, folder.GlobPattern.Relative)
+	}
+	if folder.Kind == nil || *folder.Kind != WatchKindChange|WatchKindDelete {
+		t.Errorf("expected change and delete events, got %v", folder.Kind)
+	}
+
+	uri := options.Watchers[2]
+	if uri.GlobPattern.Relative == nil || uri.GlobPattern.Relative.BaseURI.URI != "file:///recipes/sauces" || uri.GlobPattern.Relative.BaseURI.WorkspaceFolder != nil {
+		t.Errorf("expected a base URI, got %#v", uri.GlobPattern.Relative)
+	}
+
+	var pattern GlobPattern
+	if err := json.Unmarshal([]byte(`["*.cook"]`), &pattern); !errors.Is(err, ErrInvalidGlobPattern) {
+		t.Errorf("expected ErrInvalidGlobPattern, got %v", err)
+	}
+	var base WorkspaceFolderOrURI
+	if err := json.Unmarshal([]byte(`1`), &base); !errors.Is(err, ErrInvalidWorkspaceFolderOrURI) {
+		t.Errorf("expected ErrInvalidWorkspaceFolderOrURI, got %v", err)
+	}
+}
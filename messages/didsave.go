@@ -0,0 +1,11 @@
+package messages
+
+const DidSaveTextDocumentNotification = "textDocument/didSave"
+
+type DidSaveTextDocumentParams struct {
+	// The document that was saved.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The content when saved. Depends on the includeText value when the save
+	// notification was requested.
+	Text *string `json:"text,omitempty"`
+}
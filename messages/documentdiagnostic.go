@@ -0,0 +1,149 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const DocumentDiagnosticRequestMethod = "textDocument/diagnostic"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_pullDiagnostics
+//
+// The result of the request is a DocumentDiagnosticReport.
+type DocumentDiagnosticParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The additional identifier provided during registration.
+	Identifier *string `json:"identifier,omitempty"`
+	// The result id of a previous response if provided.
+	PreviousResultID *string `json:"previousResultId,omitempty"`
+}
+
+type DocumentDiagnosticReportKind string
+
+const (
+	// A diagnostic report with a full set of problems.
+	DocumentDiagnosticReportKindFull DocumentDiagnosticReportKind = "full"
+	// A report indicating that the last returned report is still accurate.
+	DocumentDiagnosticReportKindUnchanged DocumentDiagnosticReportKind = "unchanged"
+)
+
+// FullDocumentDiagnosticReport is a diagnostic report with a full set of
+// problems. Kind is set when it's marshalled as part of a
+// DocumentDiagnosticReport.
+type FullDocumentDiagnosticReport struct {
+	Kind DocumentDiagnosticReportKind `json:"kind"`
+	// An optional result id. If provided it will be sent on the next
+	// diagnostic request for the same document.
+	ResultID *string `json:"resultId,omitempty"`
+	// The actual items.
+	Items []Diagnostic `json:"items"`
+}
+
+// UnchangedDocumentDiagnosticReport indicates that nothing has changed
+// compared to a previous report. Kind is set when it's marshalled as part of a
+// DocumentDiagnosticReport.
+type UnchangedDocumentDiagnosticReport struct {
+	Kind DocumentDiagnosticReportKind `json:"kind"`
+	// A result id which will be sent on the next diagnostic request for the
+	// same document.
+	ResultID string `json:"resultId"`
+}
+
+// RelatedFullDocumentDiagnosticReport is a full report for the document,
+// with the reports of other documents that changed because of it.
+type RelatedFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	// Diagnostics of related documents, keyed by URI. The reports of related
+	// documents have no related documents of their own.
+	RelatedDocuments map[string]DocumentDiagnosticReport `json:"relatedDocuments,omitempty"`
+}
+
+// RelatedUnchangedDocumentDiagnosticReport is an unchanged report for the
+// document, with the reports of other documents that changed because of it.
+type RelatedUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+	// Diagnostics of related documents, keyed by URI. The reports of related
+	// documents have no related documents of their own.
+	RelatedDocuments map[string]DocumentDiagnosticReport `json:"relatedDocuments,omitempty"`
+}
+
+// DocumentDiagnosticReport is a full or an unchanged report, told apart by
+// the kind field. Only one of the fields should be set.
+type DocumentDiagnosticReport struct {
+	Full      *RelatedFullDocumentDiagnosticReport
+	Unchanged *RelatedUnchangedDocumentDiagnosticReport
+}
+
+// ErrInvalidDocumentDiagnosticReport is returned when a report isn't a full
+// or an unchanged report.
+var ErrInvalidDocumentDiagnosticReport = errors.New("messages: invalid document diagnostic report")
+
+func (r DocumentDiagnosticReport) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.Full != nil:
+		full := *r.Full
+		full.Kind = DocumentDiagnosticReportKindFull
+		return json.Marshal(full)
+	case r.Unchanged != nil:
+		unchanged := *r.Unchanged
+		unchanged.Kind = DocumentDiagnosticReportKindUnchanged
+		return json.Marshal(unchanged)
+	}
+	return nil, ErrInvalidDocumentDiagnosticReport
+}
+
+func (r *DocumentDiagnosticReport) UnmarshalJSON(data []byte) error {
+	*r = DocumentDiagnosticReport{}
+	var discriminator struct {
+		Kind DocumentDiagnosticReportKind `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &discriminator); err != nil {
+		return ErrInvalidDocumentDiagnosticReport
+	}
+	switch discriminator.Kind {
+	case DocumentDiagnosticReportKindFull:
+		r.Full = &RelatedFullDocumentDiagnosticReport{}
+		return json.Unmarshal(data, r.Full)
+	case DocumentDiagnosticReportKindUnchanged:
+		r.Unchanged = &RelatedUnchangedDocumentDiagnosticReport{}
+		return json.Unmarshal(data, r.Unchanged)
+	}
+	return ErrInvalidDocumentDiagnosticReport
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#diagnosticOptions
+type DiagnosticOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// An optional identifier under which the diagnostics are managed by the
+	// client.
+	Identifier *string `json:"identifier,omitempty"`
+	// Whether the language has inter file dependencies, meaning that editing
+	// code in one file can result in different diagnostic sets in another
+	// file.
+	InterFileDependencies bool `json:"interFileDependencies"`
+	// The server provides support for workspace diagnostics as well.
+	WorkspaceDiagnostics bool `json:"workspaceDiagnostics"`
+}
+
+// DiagnosticRegistrationOptions are DiagnosticOptions with the fields used
+// for dynamic registration. Without a document selector, they're marshalled
+// as DiagnosticOptions.
+type DiagnosticRegistrationOptions struct {
+	// The documents that the registration applies to. If it's nil, the
+	// document selector provided on the client side is used.
+	DocumentSelector DocumentSelector `json:"documentSelector,omitempty"`
+	DiagnosticOptions
+	// The id used to register the request. The id can be used to deregister
+	// the request again.
+	ID *string `json:"id,omitempty"`
+}
+
+// DiagnosticServerCancellationData is the data of the error returned when a
+// diagnostic request is cancelled by the server.
+type DiagnosticServerCancellationData struct {
+	// Whether the client should send the request again.
+	RetriggerRequest bool `json:"retriggerRequest"`
+}
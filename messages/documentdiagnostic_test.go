@@ -0,0 +1,144 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentDiagnosticParamsJSON(t *testing.T) {
+	var params DocumentDiagnosticParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"identifier": "examplelsp",
+		"previousResultId": "7"
+	}`), &params)
+	if params.PreviousResultID == nil || *params.PreviousResultID != "7" {
+		t.Errorf("expected the previous result id, got %#v", params.PreviousResultID)
+	}
+}
+
+func TestDocumentDiagnosticReportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		check   func(t *testing.T, r DocumentDiagnosticReport)
+	}{
+		{
+			name:    "full",
+			payload: `{"kind":"full","resultId":"8","items":[]}`,
+			check: func(t *testing.T, r DocumentDiagnosticReport) {
+				if r.Full == nil || r.Unchanged != nil || *r.Full.ResultID != "8" {
+					t.Errorf("expected a full report, got %#v", r)
+				}
+			},
+		},
+		{
+			name:    "unchanged",
+			payload: `{"kind":"unchanged","resultId":"7"}`,
+			check: func(t *testing.T, r DocumentDiagnosticReport) {
+				if r.Unchanged == nil || r.Full != nil || r.Unchanged.ResultID != "7" {
+					t.Errorf("expected an unchanged report, got %#v", r)
+				}
+			},
+		},
+		{
+			name: "related documents",
+			payload: `{
+				"kind": "unchanged",
+				"resultId": "7",
+				"relatedDocuments": {
+					"file:///Users/alice/recipes/sauces/tomato.cook": {"kind": "full", "items": []},
+					"file:///Users/alice/recipes/sauces/pesto.cook": {"kind": "unchanged", "resultId": "3"}
+				}
+			}`,
+			check: func(t *testing.T, r DocumentDiagnosticReport) {
+				if r.Unchanged == nil || len(r.Unchanged.RelatedDocuments) != 2 {
+					t.Fatalf("expected an unchanged report with 2 related documents, got %#v", r)
+				}
+				if r.Unchanged.RelatedDocuments["file:///Users/alice/recipes/sauces/tomato.cook"].Full == nil {
+					t.Error("expected a full report for tomato.cook")
+				}
+				if r.Unchanged.RelatedDocuments["file:///Users/alice/recipes/sauces/pesto.cook"].Unchanged == nil {
+					t.Error("expected an unchanged report for pesto.cook")
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var r DocumentDiagnosticReport
+			roundTrip(t, []byte(test.payload), &r)
+			test.check(t, r)
+		})
+	}
+}
+
+func TestDocumentDiagnosticReportSetsKind(t *testing.T) {
+	resultID := "1"
+	report := DocumentDiagnosticReport{
+		Full: &RelatedFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{
+				ResultID: &resultID,
+				Items: []Diagnostic{
+					{Range: Range{Start: NewPosition(1, 5), End: NewPosition(1, 11)}, Message: "Mild swearword"},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var actual DocumentDiagnosticReport
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if actual.Full == nil {
+		t.Fatalf("expected a full report, got %s", data)
+	}
+	if actual.Full.Kind != DocumentDiagnosticReportKindFull {
+		t.Errorf("expected the kind to be set, got %q", actual.Full.Kind)
+	}
+	actual.Full.Kind = ""
+	if !reflect.DeepEqual(actual, report) {
+		t.Errorf("expected %#v, got %#v", report, actual)
+	}
+	if report.Full.Kind != "" {
+		t.Error("expected the report not to be modified")
+	}
+}
+
+func TestDocumentDiagnosticReportInvalid(t *testing.T) {
+	for _, payload := range []string{`{"kind":"partial"}`, `{}`, `[]`} {
+		var r DocumentDiagnosticReport
+		if err := json.Unmarshal([]byte(payload), &r); !errors.Is(err, ErrInvalidDocumentDiagnosticReport) {
+			t.Errorf("%s: expected ErrInvalidDocumentDiagnosticReport, got %v", payload, err)
+		}
+	}
+	if _, err := json.Marshal(DocumentDiagnosticReport{}); !errors.Is(err, ErrInvalidDocumentDiagnosticReport) {
+		t.Errorf("expected ErrInvalidDocumentDiagnosticReport, got %v", err)
+	}
+}
+
+func TestDiagnosticOptionsJSON(t *testing.T) {
+	var options DiagnosticRegistrationOptions
+	roundTrip(t, []byte(`{"identifier":"examplelsp","interFileDependencies":true,"workspaceDiagnostics":false}`), &options)
+	if options.DocumentSelector != nil || !options.InterFileDependencies {
+		t.Errorf("unexpected options: %#v", options)
+	}
+	var registration DiagnosticRegistrationOptions
+	roundTrip(t, []byte(`{
+		"documentSelector": [{"language": "cooklang", "scheme": "file"}],
+		"interFileDependencies": false,
+		"workspaceDiagnostics": false,
+		"id": "diagnostics"
+	}`), &registration)
+	if len(registration.DocumentSelector) != 1 || *registration.ID != "diagnostics" {
+		t.Errorf("unexpected registration options: %#v", registration)
+	}
+
+	var data DiagnosticServerCancellationData
+	roundTrip(t, []byte(`{"retriggerRequest":true}`), &data)
+}
@@ -0,0 +1,40 @@
+package messages
+
+import "encoding/json"
+
+const (
+	DocumentLinkRequestMethod        = "textDocument/documentLink"
+	DocumentLinkResolveRequestMethod = "documentLink/resolve"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_documentLink
+//
+// The result of the request is []DocumentLink, or null.
+type DocumentLinkParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The document to provide document links for.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// A DocumentLink is a range in a text document that links to an internal or
+// external resource, like another text document or a web site.
+type DocumentLink struct {
+	// The range this link applies to.
+	Range Range `json:"range"`
+	// The uri this link points to. If missing a resolve request is sent
+	// later.
+	Target *string `json:"target,omitempty"`
+	// The tooltip text when you hover over this link.
+	Tooltip *string `json:"tooltip,omitempty"`
+	// A data entry field that is preserved on a document link between a
+	// DocumentLinkRequest and a DocumentLinkResolveRequest. It's kept as raw
+	// JSON, so that it's sent back to the client exactly as it was received.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type DocumentLinkOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// Document links have a resolve provider as well.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
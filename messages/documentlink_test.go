@@ -0,0 +1,29 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDocumentLinkJSON(t *testing.T) {
+	var link DocumentLink
+	payload := []byte(`{
+		"range": {"start": {"line": 0, "character": 9}, "end": {"line": 0, "character": 15}},
+		"tooltip": "Open tomato.cook",
+		"data": {"name": "tomato", "ids": [1, 2.50, null]}
+	}`)
+	roundTrip(t, payload, &link)
+	if link.Target != nil {
+		t.Errorf("expected no target, got %q", *link.Target)
+	}
+	if string(link.Data) != `{"name": "tomato", "ids": [1, 2.50, null]}` {
+		t.Errorf("expected the data to be kept exactly, got %s", link.Data)
+	}
+	data, err := json.Marshal(DocumentLink{})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if expected := `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}}}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
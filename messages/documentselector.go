@@ -0,0 +1,78 @@
+package messages
+
+import (
+	"net/url"
+)
+
+// DocumentFilter denotes a document by properties like its language, its
+// scheme, or a glob pattern applied to its path. At least one of the fields
+// should be set.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentFilter
+type DocumentFilter struct {
+	// A language id, like `cooklang`.
+	Language *string `json:"language,omitempty"`
+	// A URI scheme, like `file` or `untitled`.
+	Scheme *string `json:"scheme,omitempty"`
+	// A glob pattern, like `**/*.cook`, with the syntax of a GlobPattern.
+	Pattern *string `json:"pattern,omitempty"`
+}
+
+// Matches returns true if the document matches every field that's set on the
+// filter. A filter without any fields set doesn't match any document.
+func (f DocumentFilter) Matches(doc TextDocumentItem) bool {
+	if f.Language == nil && f.Scheme == nil && f.Pattern == nil {
+		return false
+	}
+	if f.Language != nil && *f.Language != doc.LanguageID {
+		return false
+	}
+	if f.Scheme == nil && f.Pattern == nil {
+		return true
+	}
+	u, err := url.Parse(doc.URI)
+	if err != nil {
+		return false
+	}
+	if f.Scheme != nil && *f.Scheme != u.Scheme {
+		return false
+	}
+	if f.Pattern != nil && !matchGlob(*f.Pattern, normalizeDriveLetter(u.Path)) {
+		return false
+	}
+	return true
+}
+
+// DocumentSelector is the combination of one or more document filters.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentSelector
+type DocumentSelector []DocumentFilter
+
+// Matches returns true if any of the filters match the document.
+func (s DocumentSelector) Matches(doc TextDocumentItem) bool {
+	for _, f := range s {
+		if f.Matches(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+// General text document registration options.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocumentRegistrationOptions
+type TextDocumentRegistrationOptions struct {
+	// A document selector to identify the scope of the registration. If set
+	// to null, the document selector provided on the client side will be
+	// used.
+	DocumentSelector DocumentSelector `json:"documentSelector"`
+}
+
+// Static registration options to be returned in the initialize request.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#staticRegistrationOptions
+type StaticRegistrationOptions struct {
+	// The id used to register the request. The id can be used to deregister
+	// the request again.
+	ID *string `json:"id,omitempty"`
+}
@@ -0,0 +1,127 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGlobMatching(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "**/*.cook", path: "/Users/alice/recipes/pasta.cook", expected: true},
+		{pattern: "**/*.cook", path: "pasta.cook", expected: true},
+		{pattern: "**/*.cook", path: "/Users/alice/recipes/pasta.txt", expected: false},
+		{pattern: "*.cook", path: "pasta.cook", expected: true},
+		{pattern: "*.cook", path: "recipes/pasta.cook", expected: false},
+		{pattern: "recipes/**", path: "recipes/italian/pasta.cook", expected: true},
+		{pattern: "recipes/**/pasta.cook", path: "recipes/pasta.cook", expected: true},
+		{pattern: "recipes/**/pasta.cook", path: "recipes/italian/pasta.cook", expected: true},
+		{pattern: "day?.menu", path: "day1.menu", expected: true},
+		{pattern: "day?.menu", path: "day10.menu", expected: false},
+		{pattern: "day?.menu", path: "day/.menu", expected: false},
+		{pattern: "**/*.{cook,menu}", path: "/recipes/week.menu", expected: true},
+		{pattern: "**/*.{cook,menu}", path: "/recipes/week.txt", expected: false},
+		{pattern: "**/{drafts,old}/*.cook", path: "/recipes/old/pasta.cook", expected: true},
+		{pattern: "**/{drafts,old}/*.cook", path: "/recipes/new/pasta.cook", expected: false},
+		{pattern: "day[0-9].menu", path: "day3.menu", expected: true},
+		{pattern: "day[!0-9].menu", path: "day3.menu", expected: false},
+		{pattern: "day[!0-9].menu", path: "dayX.menu", expected: true},
+		{pattern: "**/crème brûlée.cook", path: "/Users/zoë/crème brûlée.cook", expected: true},
+		{pattern: "**/(draft).cook", path: "/recipes/(draft).cook", expected: true},
+		{pattern: "**/*.{cook,menu", path: "/recipes/pasta.cook", expected: false},
+		{pattern: "day[0-9.menu", path: "day3.menu", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			if actual := matchGlob(test.pattern, test.path); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDocumentSelectorMatches(t *testing.T) {
+	var options TextDocumentRegistrationOptions
+	roundTrip(t, readPayload(t, "document-selector.json"), &options)
+	if len(options.DocumentSelector) != 3 {
+		t.Fatalf("expected 3 filters, got %d", len(options.DocumentSelector))
+	}
+	tests := []struct {
+		name     string
+		doc      TextDocumentItem
+		expected bool
+	}{
+		{
+			name:     "saved recipe",
+			doc:      TextDocumentItem{URI: "file:///Users/alice/recipes/pasta.cook", LanguageID: "cooklang"},
+			expected: true,
+		},
+		{
+			name:     "unsaved recipe",
+			doc:      TextDocumentItem{URI: "untitled:Untitled-1", LanguageID: "cooklang"},
+			expected: true,
+		},
+		{
+			name:     "menu with the plain text language",
+			doc:      TextDocumentItem{URI: "file:///Users/alice/recipes/week.menu", LanguageID: "plaintext"},
+			expected: true,
+		},
+		{
+			name:     "path with spaces and non-ASCII characters",
+			doc:      TextDocumentItem{URI: "file:///Users/zo%C3%AB/my%20recipes/cr%C3%A8me%20br%C3%BBl%C3%A9e.cook", LanguageID: "plaintext"},
+			expected: true,
+		},
+		{
+			name:     "Windows path",
+			doc:      TextDocumentItem{URI: "file:///c%3A/Users/alice/pasta.cook", LanguageID: "plaintext"},
+			expected: true,
+		},
+		{
+			name:     "git diff of a plain text file",
+			doc:      TextDocumentItem{URI: "git:///Users/alice/recipes/notes.txt", LanguageID: "plaintext"},
+			expected: false,
+		},
+		{
+			name:     "markdown file",
+			doc:      TextDocumentItem{URI: "file:///Users/alice/README.md", LanguageID: "markdown"},
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := options.DocumentSelector.Matches(test.doc); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDocumentFilterWithoutFieldsMatchesNothing(t *testing.T) {
+	if (DocumentFilter{}).Matches(TextDocumentItem{URI: "file:///pasta.cook", LanguageID: "cooklang"}) {
+		t.Error("expected an empty filter not to match")
+	}
+	if (DocumentSelector{}).Matches(TextDocumentItem{URI: "file:///pasta.cook", LanguageID: "cooklang"}) {
+		t.Error("expected an empty selector not to match")
+	}
+}
+
+func TestRegistrationOptionsJSON(t *testing.T) {
+	var options struct {
+		TextDocumentRegistrationOptions
+		StaticRegistrationOptions
+	}
+	roundTrip(t, []byte(`{"documentSelector":null,"id":"cooklang"}`), &options)
+	if options.DocumentSelector != nil || options.ID == nil || *options.ID != "cooklang" {
+		t.Errorf("unexpected options: %#v", options)
+	}
+	data, err := json.Marshal(StaticRegistrationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{}" {
+		t.Errorf("expected the id to be omitted, got %s", data)
+	}
+}
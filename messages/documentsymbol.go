@@ -0,0 +1,134 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const DocumentSymbolRequestMethod = "textDocument/documentSymbol"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_documentSymbol
+type DocumentSymbolParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentSymbol
+type DocumentSymbol struct {
+	// The name of this symbol. Must not be empty.
+	Name string `json:"name"`
+	// More detail for this symbol, e.g. the quantity of an ingredient.
+	Detail string      `json:"detail,omitempty"`
+	Kind   SymbolKind  `json:"kind"`
+	Tags   []SymbolTag `json:"tags,omitempty"`
+	// The range enclosing this symbol, used to determine if the client's
+	// cursor is inside the symbol.
+	Range Range `json:"range"`
+	// The range that should be selected and revealed when this symbol is
+	// being picked, e.g. the name of an ingredient. Must be contained by
+	// Range.
+	SelectionRange Range `json:"selectionRange"`
+	// Children of this symbol, e.g. the ingredients of a step.
+	Children []DocumentSymbol `json:"children,omitempty"`
+}
+
+// FlattenDocumentSymbols returns the symbols, and all of their children, as
+// SymbolInformation, for clients that don't support hierarchical document
+// symbols. Parents come before their children, and the ContainerName of each
+// child is the name of its parent.
+func FlattenDocumentSymbols(uri string, symbols []DocumentSymbol) (flattened []SymbolInformation) {
+	flattened = []SymbolInformation{}
+	var flatten func(container string, symbols []DocumentSymbol)
+	flatten = func(container string, symbols []DocumentSymbol) {
+		for _, s := range symbols {
+			flattened = append(flattened, SymbolInformation{
+				Name:          s.Name,
+				Kind:          s.Kind,
+				Tags:          s.Tags,
+				Location:      Location{URI: uri, Range: s.Range},
+				ContainerName: container,
+			})
+			flatten(s.Name, s.Children)
+		}
+	}
+	flatten("", symbols)
+	return flattened
+}
+
+// DocumentSymbolResult is either []DocumentSymbol, or []SymbolInformation.
+// Only one of the fields should be set. If neither is, it's marshalled as
+// null.
+type DocumentSymbolResult struct {
+	DocumentSymbols   []DocumentSymbol
+	SymbolInformation []SymbolInformation
+}
+
+// NewDocumentSymbolResult returns the symbols in the shape that the client
+// supports, flattening them if the client doesn't support hierarchical
+// document symbols.
+func NewDocumentSymbolResult(uri string, symbols []DocumentSymbol, c *DocumentSymbolClientCapabilities) DocumentSymbolResult {
+	if c.SupportsHierarchy() {
+		if symbols == nil {
+			symbols = []DocumentSymbol{}
+		}
+		return DocumentSymbolResult{DocumentSymbols: symbols}
+	}
+	return DocumentSymbolResult{SymbolInformation: FlattenDocumentSymbols(uri, symbols)}
+}
+
+func (r DocumentSymbolResult) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.DocumentSymbols != nil:
+		return json.Marshal(r.DocumentSymbols)
+	case r.SymbolInformation != nil:
+		return json.Marshal(r.SymbolInformation)
+	}
+	return []byte("null"), nil
+}
+
+func (r *DocumentSymbolResult) UnmarshalJSON(data []byte) error {
+	*r = DocumentSymbolResult{}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	// SymbolInformation has a location, and DocumentSymbols have a range.
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		if _, isInformation := items[0]["location"]; isInformation {
+			return json.Unmarshal(data, &r.SymbolInformation)
+		}
+	}
+	return json.Unmarshal(data, &r.DocumentSymbols)
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentSymbolClientCapabilities
+type DocumentSymbolClientCapabilities struct {
+	// Whether document symbol supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The symbol kinds that the client supports. If it isn't set, only the
+	// kinds from File to Array are supported.
+	SymbolKind *struct {
+		ValueSet []SymbolKind `json:"valueSet,omitempty"`
+	} `json:"symbolKind,omitempty"`
+	// The client supports hierarchical document symbols.
+	HierarchicalDocumentSymbolSupport bool `json:"hierarchicalDocumentSymbolSupport,omitempty"`
+	// The client supports tags on symbols.
+	TagSupport *struct {
+		ValueSet []SymbolTag `json:"valueSet"`
+	} `json:"tagSupport,omitempty"`
+	// The client supports an additional label presented in the UI when
+	// registering a document symbol provider.
+	LabelSupport bool `json:"labelSupport,omitempty"`
+}
+
+// SupportsHierarchy returns true if the client accepts nested
+// DocumentSymbols. Clients that don't only accept flat SymbolInformation.
+func (c *DocumentSymbolClientCapabilities) SupportsHierarchy() bool {
+	return c != nil && c.HierarchicalDocumentSymbolSupport
+}
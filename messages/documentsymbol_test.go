@@ -0,0 +1,129 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentSymbolResultShape(t *testing.T) {
+	line := func(l, start, end int) Range {
+		return Range{Start: NewPosition(l, start), End: NewPosition(l, end)}
+	}
+	symbols := []DocumentSymbol{
+		{Name: "servings", Detail: "2", Kind: SymbolKindProperty, Range: line(0, 0, 14), SelectionRange: line(0, 0, 14)},
+		{
+			Name:           "Step 1",
+			Kind:           SymbolKindFunction,
+			Range:          line(2, 0, 24),
+			SelectionRange: line(2, 0, 24),
+			Children: []DocumentSymbol{
+				{Name: "@water", Kind: SymbolKindVariable, Range: line(2, 5, 11), SelectionRange: line(2, 6, 11)},
+				{Name: "#pot", Kind: SymbolKindObject, Range: line(2, 17, 23), SelectionRange: line(2, 18, 21)},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		capabilities *DocumentSymbolClientCapabilities
+		expected     string
+	}{
+		{
+			name:         "hierarchical",
+			capabilities: &DocumentSymbolClientCapabilities{HierarchicalDocumentSymbolSupport: true},
+			expected: `[
+				{"name":"servings","detail":"2","kind":7,"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":14}},"selectionRange":{"start":{"line":0,"character":0},"end":{"line":0,"character":14}}},
+				{"name":"Step 1","kind":12,"range":{"start":{"line":2,"character":0},"end":{"line":2,"character":24}},"selectionRange":{"start":{"line":2,"character":0},"end":{"line":2,"character":24}},"children":[
+					{"name":"@water","kind":13,"range":{"start":{"line":2,"character":5},"end":{"line":2,"character":11}},"selectionRange":{"start":{"line":2,"character":6},"end":{"line":2,"character":11}}},
+					{"name":"#pot","kind":19,"range":{"start":{"line":2,"character":17},"end":{"line":2,"character":23}},"selectionRange":{"start":{"line":2,"character":18},"end":{"line":2,"character":21}}}
+				]}
+			]`,
+		},
+		{
+			name:         "flat",
+			capabilities: &DocumentSymbolClientCapabilities{},
+			expected: `[
+				{"name":"servings","kind":7,"location":{"uri":"file:///pasta.cook","range":{"start":{"line":0,"character":0},"end":{"line":0,"character":14}}}},
+				{"name":"Step 1","kind":12,"location":{"uri":"file:///pasta.cook","range":{"start":{"line":2,"character":0},"end":{"line":2,"character":24}}}},
+				{"name":"@water","kind":13,"location":{"uri":"file:///pasta.cook","range":{"start":{"line":2,"character":5},"end":{"line":2,"character":11}}},"containerName":"Step 1"},
+				{"name":"#pot","kind":19,"location":{"uri":"file:///pasta.cook","range":{"start":{"line":2,"character":17},"end":{"line":2,"character":23}}},"containerName":"Step 1"}
+			]`,
+		},
+		{
+			name:     "no capabilities",
+			expected: `[]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := symbols
+			if test.name == "no capabilities" {
+				input = nil
+			}
+			result := NewDocumentSymbolResult("file:///pasta.cook", input, test.capabilities)
+			actual, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			var expected, got any
+			if err := json.Unmarshal([]byte(test.expected), &expected); err != nil {
+				t.Fatalf("invalid expected JSON: %v", err)
+			}
+			if err := json.Unmarshal(actual, &got); err != nil {
+				t.Fatalf("invalid output: %v", err)
+			}
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, actual)
+			}
+			var decoded DocumentSymbolResult
+			roundTrip(t, actual, &decoded)
+		})
+	}
+}
+
+func TestDocumentSymbolResultUnmarshal(t *testing.T) {
+	var r DocumentSymbolResult
+	roundTrip(t, []byte(`[{"name":"Step 1","kind":12,"location":{"uri":"file:///pasta.cook","range":{"start":{"line":0,"character":0},"end":{"line":0,"character":4}}}}]`), &r)
+	if len(r.SymbolInformation) != 1 || r.DocumentSymbols != nil {
+		t.Errorf("expected symbol information, got %#v", r)
+	}
+	roundTrip(t, []byte(`[{"name":"Step 1","kind":12,"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":4}},"selectionRange":{"start":{"line":0,"character":0},"end":{"line":0,"character":4}}}]`), &r)
+	if len(r.DocumentSymbols) != 1 || r.SymbolInformation != nil {
+		t.Errorf("expected document symbols, got %#v", r)
+	}
+	roundTrip(t, []byte(`null`), &r)
+	if !reflect.DeepEqual(r, DocumentSymbolResult{}) {
+		t.Errorf("expected an empty result, got %#v", r)
+	}
+}
+
+func TestFlattenDocumentSymbolsNested(t *testing.T) {
+	symbols := []DocumentSymbol{
+		{Name: "a", Children: []DocumentSymbol{
+			{Name: "b", Children: []DocumentSymbol{{Name: "c"}}},
+		}},
+	}
+	var containers []string
+	for _, s := range FlattenDocumentSymbols("file:///a.cook", symbols) {
+		containers = append(containers, s.Name+" in "+s.ContainerName)
+	}
+	expected := []string{"a in ", "b in a", "c in b"}
+	if !reflect.DeepEqual(containers, expected) {
+		t.Errorf("expected %v, got %v", expected, containers)
+	}
+}
+
+func TestDocumentSymbolClientCapabilitiesJSON(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	c := params.Capabilities.TextDocument.DocumentSymbol
+	if !c.SupportsHierarchy() || !c.LabelSupport || c.TagSupport == nil || len(c.SymbolKind.ValueSet) != 26 {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+	var nilCapabilities *DocumentSymbolClientCapabilities
+	if nilCapabilities.SupportsHierarchy() {
+		t.Error("expected clients without capabilities not to support hierarchy")
+	}
+}
@@ -0,0 +1,31 @@
+package messages
+
+import "encoding/json"
+
+const ExecuteCommandRequestMethod = "workspace/executeCommand"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_executeCommand
+type ExecuteCommandParams struct {
+	WorkDoneProgressParams
+	// The identifier of the actual command handler.
+	Command string `json:"command"`
+	// Arguments that the command should be invoked with. Each command decodes
+	// its own argument shapes.
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#command
+type Command struct {
+	// Title of the command, like `save`.
+	Title string `json:"title"`
+	// The identifier of the actual command handler.
+	Command string `json:"command"`
+	// Arguments that the command handler should be invoked with.
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+type ExecuteCommandOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// The commands to be executed on the server.
+	Commands []string `json:"commands"`
+}
@@ -0,0 +1,48 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExecuteCommandParamsJSON(t *testing.T) {
+	var params ExecuteCommandParams
+	roundTrip(t, []byte(`{
+		"command": "examplelsp.renameIngredientEverywhere",
+		"arguments": [
+			{"uri": "file:///Users/alice/recipes/pasta.cook", "position": {"line": 2, "character": 9}, "newName": "sea salt"},
+			"a string",
+			42,
+			[1, 2, 3],
+			null
+		],
+		"workDoneToken": "b7f5e1a2"
+	}`), &params)
+	if len(params.Arguments) != 5 {
+		t.Fatalf("expected 5 arguments, got %d", len(params.Arguments))
+	}
+	// Each argument is left for the command to decode.
+	var args struct {
+		NewName string `json:"newName"`
+	}
+	if err := json.Unmarshal(params.Arguments[0], &args); err != nil || args.NewName != "sea salt" {
+		t.Errorf("expected the first argument to be decoded, got %#v, %v", args, err)
+	}
+	var n int
+	if err := json.Unmarshal(params.Arguments[2], &n); err != nil || n != 42 {
+		t.Errorf("expected the third argument to be 42, got %d, %v", n, err)
+	}
+}
+
+func TestExecuteCommandParamsWithoutArgumentsJSON(t *testing.T) {
+	var params ExecuteCommandParams
+	roundTrip(t, []byte(`{"command": "examplelsp.collectLogs"}`), &params)
+	if params.Arguments != nil {
+		t.Errorf("expected no arguments, got %v", params.Arguments)
+	}
+}
+
+func TestExecuteCommandOptionsJSON(t *testing.T) {
+	var options ExecuteCommandOptions
+	roundTrip(t, []byte(`{"workDoneProgress": true, "commands": ["examplelsp.fixAll", "examplelsp.collectLogs"]}`), &options)
+}
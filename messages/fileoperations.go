@@ -0,0 +1,136 @@
+package messages
+
+// The will* requests are sent before the files are changed, and the result is
+// a *WorkspaceEdit, which can be null, that's applied before the change. The
+// did* notifications are sent after the files are changed.
+const (
+	WillCreateFilesRequestMethod = "workspace/willCreateFiles"
+	DidCreateFilesNotification   = "workspace/didCreateFiles"
+	WillRenameFilesRequestMethod = "workspace/willRenameFiles"
+	DidRenameFilesNotification   = "workspace/didRenameFiles"
+	WillDeleteFilesRequestMethod = "workspace/willDeleteFiles"
+	DidDeleteFilesNotification   = "workspace/didDeleteFiles"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#createFilesParams
+type CreateFilesParams struct {
+	// An array of all files/folders created in this operation.
+	Files []FileCreate `json:"files"`
+}
+
+// Represents information on a file/folder create.
+type FileCreate struct {
+	// A file:// URI for the location of the file/folder being created.
+	URI string `json:"uri"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#renameFilesParams
+type RenameFilesParams struct {
+	// An array of all files/folders renamed in this operation. When a folder
+	// is renamed, only the folder will be included, and not its children.
+	Files []FileRename `json:"files"`
+}
+
+// Represents information on a file/folder rename.
+type FileRename struct {
+	// A file:// URI for the original location of the file/folder being
+	// renamed.
+	OldURI string `json:"oldUri"`
+	// A file:// URI for the new location of the file/folder being renamed.
+	NewURI string `json:"newUri"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#deleteFilesParams
+type DeleteFilesParams struct {
+	// An array of all files/folders deleted in this operation.
+	Files []FileDelete `json:"files"`
+}
+
+// Represents information on a file/folder delete.
+type FileDelete struct {
+	// A file:// URI for the location of the file/folder being deleted.
+	URI string `json:"uri"`
+}
+
+// FileOperationOptions are the file operations that the server is interested
+// in. Operations that are nil aren't sent.
+type FileOperationOptions struct {
+	// The server is interested in receiving didCreateFiles notifications.
+	DidCreate *FileOperationRegistrationOptions `json:"didCreate,omitempty"`
+	// The server is interested in receiving willCreateFiles requests.
+	WillCreate *FileOperationRegistrationOptions `json:"willCreate,omitempty"`
+	// The server is interested in receiving didRenameFiles notifications.
+	DidRename *FileOperationRegistrationOptions `json:"didRename,omitempty"`
+	// The server is interested in receiving willRenameFiles requests.
+	WillRename *FileOperationRegistrationOptions `json:"willRename,omitempty"`
+	// The server is interested in receiving didDeleteFiles file
+	// notifications.
+	DidDelete *FileOperationRegistrationOptions `json:"didDelete,omitempty"`
+	// The server is interested in receiving willDeleteFiles file requests.
+	WillDelete *FileOperationRegistrationOptions `json:"willDelete,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#fileOperationRegistrationOptions
+type FileOperationRegistrationOptions struct {
+	// The actual filters.
+	Filters []FileOperationFilter `json:"filters"`
+}
+
+// A filter to describe in which file operation requests or notifications the
+// server is interested in.
+type FileOperationFilter struct {
+	// A URI scheme, like `file` or `untitled`.
+	Scheme string `json:"scheme,omitempty"`
+	// The actual file operation pattern.
+	Pattern FileOperationPattern `json:"pattern"`
+}
+
+// A pattern to describe in which file operation requests or notifications the
+// server is interested in.
+type FileOperationPattern struct {
+	// The glob pattern to match, with the same syntax as a GlobPattern
+	// string.
+	Glob string `json:"glob"`
+	// Whether to match files or folders with this pattern. Matches both if
+	// it's empty.
+	Matches FileOperationPatternKind `json:"matches,omitempty"`
+	// Additional options used during matching.
+	Options *FileOperationPatternOptions `json:"options,omitempty"`
+}
+
+// A pattern kind describing if a glob pattern matches a file, a folder, or
+// both.
+type FileOperationPatternKind string
+
+const (
+	// The pattern matches a file only.
+	FileOperationPatternKindFile FileOperationPatternKind = "file"
+	// The pattern matches a folder only.
+	FileOperationPatternKindFolder FileOperationPatternKind = "folder"
+)
+
+// Matching options for the file operation pattern.
+type FileOperationPatternOptions struct {
+	// The pattern should be matched ignoring casing.
+	IgnoreCase bool `json:"ignoreCase,omitempty"`
+}
+
+// FileOperationClientCapabilities are the file operations that the client
+// supports sending.
+type FileOperationClientCapabilities struct {
+	// Whether the client supports dynamic registration for file
+	// requests/notifications.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The client has support for sending didCreateFiles notifications.
+	DidCreate bool `json:"didCreate,omitempty"`
+	// The client has support for sending willCreateFiles requests.
+	WillCreate bool `json:"willCreate,omitempty"`
+	// The client has support for sending didRenameFiles notifications.
+	DidRename bool `json:"didRename,omitempty"`
+	// The client has support for sending willRenameFiles requests.
+	WillRename bool `json:"willRename,omitempty"`
+	// The client has support for sending didDeleteFiles notifications.
+	DidDelete bool `json:"didDelete,omitempty"`
+	// The client has support for sending willDeleteFiles requests.
+	WillDelete bool `json:"willDelete,omitempty"`
+}
@@ -0,0 +1,82 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFileOperationParams(t *testing.T) {
+	var rename RenameFilesParams
+	roundTrip(t, []byte(`{"files":[{"oldUri":"file:///recipes/pasta.cook","newUri":"file:///recipes/spaghetti.cook"}]}`), &rename)
+	if expected := []FileRename{{OldURI: "file:///recipes/pasta.cook", NewURI: "file:///recipes/spaghetti.cook"}}; !reflect.DeepEqual(rename.Files, expected) {
+		t.Errorf("expected %#v, got %#v", expected, rename.Files)
+	}
+	var create CreateFilesParams
+	roundTrip(t, []byte(`{"files":[{"uri":"file:///recipes/soup.cook"}]}`), &create)
+	if len(create.Files) != 1 || create.Files[0].URI != "file:///recipes/soup.cook" {
+		t.Errorf("unexpected files: %#v", create.Files)
+	}
+	var del DeleteFilesParams
+	roundTrip(t, []byte(`{"files":[{"uri":"file:///recipes/old"}]}`), &del)
+	if len(del.Files) != 1 || del.Files[0].URI != "file:///recipes/old" {
+		t.Errorf("unexpected files: %#v", del.Files)
+	}
+}
+
+func TestFileOperationOptions(t *testing.T) {
+	payload := []byte(`{
+		"workspaceFolders": {"supported": true},
+		"fileOperations": {
+			"willRename": {
+				"filters": [
+					{"scheme": "file", "pattern": {"glob": "**/*.cook", "matches": "file", "options": {"ignoreCase": true}}},
+					{"scheme": "file", "pattern": {"glob": "**/", "matches": "folder"}}
+				]
+			},
+			"didDelete": {
+				"filters": [{"pattern": {"glob": "**/*.cook"}}]
+			}
+		}
+	}`)
+	var capabilities WorkspaceServerCapabilities
+	roundTrip(t, payload, &capabilities)
+	operations := capabilities.FileOperations
+	if operations == nil || operations.WillRename == nil || operations.DidDelete == nil {
+		t.Fatalf("expected willRename and didDelete to be set, got %#v", operations)
+	}
+	if operations.DidCreate != nil || operations.WillCreate != nil || operations.DidRename != nil || operations.WillDelete != nil {
+		t.Errorf("expected the other operations not to be set, got %#v", operations)
+	}
+	expected := []FileOperationFilter{
+		{
+			Scheme: "file",
+			Pattern: FileOperationPattern{
+				Glob:    "**/*.cook",
+				Matches: FileOperationPatternKindFile,
+				Options: &FileOperationPatternOptions{IgnoreCase: true},
+			},
+		},
+		{
+			Scheme:  "file",
+			Pattern: FileOperationPattern{Glob: "**/", Matches: FileOperationPatternKindFolder},
+		},
+	}
+	if !reflect.DeepEqual(operations.WillRename.Filters, expected) {
+		t.Errorf("expected %#v, got %#v", expected, operations.WillRename.Filters)
+	}
+	if filter := operations.DidDelete.Filters[0]; filter.Scheme != "" || filter.Pattern.Matches != "" || filter.Pattern.Options != nil {
+		t.Errorf("expected the optional fields not to be set, got %#v", filter)
+	}
+}
+
+func TestWillRenameFilesResult(t *testing.T) {
+	var edit *WorkspaceEdit
+	if err := json.Unmarshal([]byte(`null`), &edit); err != nil || edit != nil {
+		t.Errorf("expected a null result to be nil, got %#v: %v", edit, err)
+	}
+	roundTrip(t, []byte(`{"changes":{"file:///recipes/menu.cook":[{"range":{"start":{"line":0,"character":7},"end":{"line":0,"character":19}},"newText":"spaghetti.cook"}]}}`), &edit)
+	if edit == nil || len(edit.Changes["file:///recipes/menu.cook"]) != 1 {
+		t.Errorf("expected an edit to the referencing recipe, got %#v", edit)
+	}
+}
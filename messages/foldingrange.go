@@ -0,0 +1,78 @@
+package messages
+
+const FoldingRangeRequestMethod = "textDocument/foldingRange"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_foldingRange
+//
+// The result of the request is a list of FoldingRange, or null.
+type FoldingRangeParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// FoldingRangeKind is the kind of a folding range. Clients use it to fold
+// ranges of a kind with a single command.
+type FoldingRangeKind string
+
+const (
+	FoldingRangeKindComment FoldingRangeKind = "comment"
+	FoldingRangeKindImports FoldingRangeKind = "imports"
+	FoldingRangeKindRegion  FoldingRangeKind = "region"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#foldingRange
+type FoldingRange struct {
+	// The zero-based start line of the range to fold. The folded area starts
+	// after the line's last character.
+	StartLine int `json:"startLine"`
+	// The zero-based character offset from where the folded range starts. If
+	// not defined, defaults to the length of the start line.
+	StartCharacter *int `json:"startCharacter,omitempty"`
+	// The zero-based end line of the range to fold. The folded area ends with
+	// the line's last character.
+	EndLine int `json:"endLine"`
+	// The zero-based character offset before the folded range ends. If not
+	// defined, defaults to the length of the end line.
+	EndCharacter *int `json:"endCharacter,omitempty"`
+	// Describes the kind of the folding range.
+	Kind FoldingRangeKind `json:"kind,omitempty"`
+	// The text that the client should show when the range is folded, if the
+	// client supports it.
+	CollapsedText string `json:"collapsedText,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#foldingRangeClientCapabilities
+type FoldingRangeClientCapabilities struct {
+	// Whether the implementation supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The maximum number of folding ranges that the client prefers to receive
+	// per document. The value serves as a hint, servers are free to follow
+	// the limit.
+	RangeLimit int `json:"rangeLimit,omitempty"`
+	// If set, the client signals that it only supports folding complete lines.
+	// If set, the client will ignore StartCharacter and EndCharacter.
+	LineFoldingOnly bool `json:"lineFoldingOnly,omitempty"`
+	// Specific options for the folding range kind.
+	FoldingRangeKind *struct {
+		// The folding range kind values the client supports.
+		ValueSet []FoldingRangeKind `json:"valueSet,omitempty"`
+	} `json:"foldingRangeKind,omitempty"`
+	// Specific options for the folding range.
+	FoldingRange *struct {
+		// If set, the client signals that it supports setting CollapsedText
+		// on folding ranges to display custom labels instead of the default
+		// text.
+		CollapsedText bool `json:"collapsedText,omitempty"`
+	} `json:"foldingRange,omitempty"`
+}
+
+// Limit returns the ranges, limited to the number the client prefers to
+// receive. The first ranges are kept.
+func (c *FoldingRangeClientCapabilities) Limit(ranges []FoldingRange) []FoldingRange {
+	if c == nil || c.RangeLimit <= 0 || len(ranges) <= c.RangeLimit {
+		return ranges
+	}
+	return ranges[:c.RangeLimit]
+}
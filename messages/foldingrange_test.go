@@ -0,0 +1,40 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFoldingRangeJSON(t *testing.T) {
+	var r FoldingRange
+	roundTrip(t, []byte(`{"startLine":7,"startCharacter":0,"endLine":9,"endCharacter":21,"kind":"comment"}`), &r)
+	if r.StartLine != 7 || r.EndLine != 9 || r.Kind != FoldingRangeKindComment || r.EndCharacter == nil || *r.EndCharacter != 21 {
+		t.Errorf("unexpected range: %#v", r)
+	}
+	var lines FoldingRange
+	roundTrip(t, []byte(`{"startLine":4,"endLine":5}`), &lines)
+}
+
+func TestFoldingRangeClientCapabilities(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	c := params.Capabilities.TextDocument.FoldingRange
+	if c == nil || c.RangeLimit != 5000 || !c.LineFoldingOnly {
+		t.Fatalf("unexpected capabilities: %#v", c)
+	}
+	if expected := []FoldingRangeKind{FoldingRangeKindComment, FoldingRangeKindImports, FoldingRangeKindRegion}; c.FoldingRangeKind == nil || !reflect.DeepEqual(c.FoldingRangeKind.ValueSet, expected) {
+		t.Errorf("expected kinds %v, got %#v", expected, c.FoldingRangeKind)
+	}
+
+	ranges := []FoldingRange{{StartLine: 0, EndLine: 2}, {StartLine: 4, EndLine: 5}, {StartLine: 7, EndLine: 9}}
+	if limited := (&FoldingRangeClientCapabilities{RangeLimit: 2}).Limit(ranges); len(limited) != 2 || limited[1].StartLine != 4 {
+		t.Errorf("expected the first 2 ranges, got %#v", limited)
+	}
+	var nilCapabilities *FoldingRangeClientCapabilities
+	if limited := nilCapabilities.Limit(ranges); len(limited) != 3 {
+		t.Errorf("expected all ranges, got %#v", limited)
+	}
+}
@@ -0,0 +1,179 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const DocumentFormattingRequestMethod = "textDocument/formatting"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_formatting
+//
+// The result of the request is []TextEdit, or null.
+type DocumentFormattingParams struct {
+	WorkDoneProgressParams
+	// The document to format.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The format options.
+	Options FormattingOptions `json:"options"`
+}
+
+const DocumentRangeFormattingRequestMethod = "textDocument/rangeFormatting"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_rangeFormatting
+//
+// The result of the request is []TextEdit, or null.
+type DocumentRangeFormattingParams struct {
+	WorkDoneProgressParams
+	// The document to format.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The range to format.
+	Range Range `json:"range"`
+	// The format options.
+	Options FormattingOptions `json:"options"`
+}
+
+const DocumentOnTypeFormattingRequestMethod = "textDocument/onTypeFormatting"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_onTypeFormatting
+//
+// The result of the request is []TextEdit, or null.
+type DocumentOnTypeFormattingParams struct {
+	// The document to format.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The position around which the on type formatting should happen. This
+	// is not necessarily the exact position where the character denoted by
+	// the property `ch` got typed.
+	Position Position `json:"position"`
+	// The character that has been typed that triggered the formatting on
+	// type request. That is not necessarily the last character that got
+	// inserted into the document since the client could auto insert
+	// characters as well (e.g. like automatic brace completion).
+	Ch string `json:"ch"`
+	// The formatting options.
+	Options FormattingOptions `json:"options"`
+}
+
+// DocumentOnTypeFormattingOptions tell the client which typed characters
+// trigger the request.
+type DocumentOnTypeFormattingOptions struct {
+	// A character on which formatting should be triggered, like `{`.
+	FirstTriggerCharacter string `json:"firstTriggerCharacter"`
+	// More trigger characters.
+	MoreTriggerCharacter []string `json:"moreTriggerCharacter,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#formattingOptions
+type FormattingOptions struct {
+	// Size of a tab in spaces.
+	TabSize int
+	// Prefer spaces over tabs.
+	InsertSpaces bool
+	// Trim trailing whitespace on a line.
+	TrimTrailingWhitespace bool
+	// Insert a newline character at the end of the file if one does not exist.
+	InsertFinalNewline bool
+	// Trim all newlines after the final newline at the end of the file.
+	TrimFinalNewlines bool
+	// Properties are further options sent by the client. Each value is a bool,
+	// an int or a string.
+	Properties map[string]any
+}
+
+// formattingOptions are the options defined by the spec.
+type formattingOptions struct {
+	TabSize                int  `json:"tabSize"`
+	InsertSpaces           bool `json:"insertSpaces"`
+	TrimTrailingWhitespace bool `json:"trimTrailingWhitespace,omitempty"`
+	InsertFinalNewline     bool `json:"insertFinalNewline,omitempty"`
+	TrimFinalNewlines      bool `json:"trimFinalNewlines,omitempty"`
+}
+
+var formattingOptionNames = map[string]bool{
+	"tabSize":                true,
+	"insertSpaces":           true,
+	"trimTrailingWhitespace": true,
+	"insertFinalNewline":     true,
+	"trimFinalNewlines":      true,
+}
+
+func (fo FormattingOptions) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(fo.Properties)+len(formattingOptionNames))
+	for k, v := range fo.Properties {
+		if formattingOptionNames[k] {
+			continue
+		}
+		fields[k] = v
+	}
+	known, err := json.Marshal(formattingOptions{
+		TabSize:                fo.TabSize,
+		InsertSpaces:           fo.InsertSpaces,
+		TrimTrailingWhitespace: fo.TrimTrailingWhitespace,
+		InsertFinalNewline:     fo.InsertFinalNewline,
+		TrimFinalNewlines:      fo.TrimFinalNewlines,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var knownFields map[string]json.RawMessage
+	if err = json.Unmarshal(known, &knownFields); err != nil {
+		return nil, err
+	}
+	for k, v := range knownFields {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+func (fo *FormattingOptions) UnmarshalJSON(data []byte) error {
+	var known formattingOptions
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*fo = FormattingOptions{
+		TabSize:                known.TabSize,
+		InsertSpaces:           known.InsertSpaces,
+		TrimTrailingWhitespace: known.TrimTrailingWhitespace,
+		InsertFinalNewline:     known.InsertFinalNewline,
+		TrimFinalNewlines:      known.TrimFinalNewlines,
+	}
+	for k, raw := range fields {
+		if formattingOptionNames[k] {
+			continue
+		}
+		v, err := formattingProperty(raw)
+		if err != nil {
+			return fmt.Errorf("messages: formatting option %q: %w", k, err)
+		}
+		if fo.Properties == nil {
+			fo.Properties = make(map[string]any)
+		}
+		fo.Properties[k] = v
+	}
+	return nil
+}
+
+// formattingProperty decodes a bool, integer or string.
+func formattingProperty(raw json.RawMessage) (v any, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	switch value := v.(type) {
+	case bool, string:
+		return value, nil
+	case json.Number:
+		n, err := value.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("%s is not an integer", value)
+		}
+		return int(n), nil
+	}
+	return nil, fmt.Errorf("expected a bool, integer or string, got %s", raw)
+}
@@ -0,0 +1,148 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocumentFormattingParamsJSON(t *testing.T) {
+	var params DocumentFormattingParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"options": {
+			"tabSize": 4,
+			"insertSpaces": true,
+			"trimTrailingWhitespace": true,
+			"insertFinalNewline": true,
+			"trimFinalNewlines": true
+		},
+		"workDoneToken": "c0ffee"
+	}`), &params)
+	expected := FormattingOptions{
+		TabSize:                4,
+		InsertSpaces:           true,
+		TrimTrailingWhitespace: true,
+		InsertFinalNewline:     true,
+		TrimFinalNewlines:      true,
+	}
+	if !reflect.DeepEqual(params.Options, expected) {
+		t.Errorf("expected %#v, got %#v", expected, params.Options)
+	}
+}
+
+func TestFormattingOptionsProperties(t *testing.T) {
+	var options FormattingOptions
+	roundTrip(t, []byte(`{
+		"tabSize": 2,
+		"insertSpaces": false,
+		"examplelsp.alignAmounts": true,
+		"examplelsp.maxLineLength": 100,
+		"examplelsp.style": "compact"
+	}`), &options)
+	expected := map[string]any{
+		"examplelsp.alignAmounts":  true,
+		"examplelsp.maxLineLength": 100,
+		"examplelsp.style":         "compact",
+	}
+	if !reflect.DeepEqual(options.Properties, expected) {
+		t.Errorf("expected %#v, got %#v", expected, options.Properties)
+	}
+}
+
+func TestFormattingOptionsPropertiesDoNotReplaceOptions(t *testing.T) {
+	data, err := json.Marshal(FormattingOptions{TabSize: 4, Properties: map[string]any{"tabSize": 8}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var options FormattingOptions
+	if err := json.Unmarshal(data, &options); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if options.TabSize != 4 || options.Properties != nil {
+		t.Errorf("expected the tab size option to be kept, got %#v", options)
+	}
+}
+
+func TestFormattingOptionsInvalidProperties(t *testing.T) {
+	for _, payload := range []string{
+		`{"tabSize": 4, "insertSpaces": true, "x": 1.5}`,
+		`{"tabSize": 4, "insertSpaces": true, "x": [1]}`,
+		`{"tabSize": 4, "insertSpaces": true, "x": {"y": true}}`,
+		`{"tabSize": 4, "insertSpaces": true, "x": null}`,
+	} {
+		var options FormattingOptions
+		if err := json.Unmarshal([]byte(payload), &options); err == nil {
+			t.Errorf("expected an error for %s", payload)
+		}
+	}
+}
+
+func TestDocumentRangeFormattingParamsJSON(t *testing.T) {
+	var params DocumentRangeFormattingParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"range": {
+			"start": {"line": 2, "character": 0},
+			"end": {"line": 5, "character": 12}
+		},
+		"options": {"tabSize": 4, "insertSpaces": true}
+	}`), &params)
+	expected := Range{Start: Position{Line: 2, Character: 0}, End: Position{Line: 5, Character: 12}}
+	if params.Range != expected {
+		t.Errorf("expected %#v, got %#v", expected, params.Range)
+	}
+}
+
+func TestDocumentOnTypeFormattingParamsJSON(t *testing.T) {
+	var params DocumentOnTypeFormattingParams
+	roundTrip(t, readPayload(t, "on-type-formatting-params.json"), &params)
+	if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(3, 24) || params.Ch != "}" {
+		t.Errorf("unexpected params: %#v", params)
+	}
+	if params.Options.TabSize != 4 || !params.Options.InsertSpaces {
+		t.Errorf("unexpected options: %#v", params.Options)
+	}
+}
+
+func TestDocumentOnTypeFormattingOptionsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected DocumentOnTypeFormattingOptions
+	}{
+		{
+			name:     "one trigger character",
+			payload:  `{"firstTriggerCharacter":"}"}`,
+			expected: DocumentOnTypeFormattingOptions{FirstTriggerCharacter: "}"},
+		},
+		{
+			name:     "more trigger characters",
+			payload:  `{"firstTriggerCharacter":"}","moreTriggerCharacter":["\n","."]}`,
+			expected: DocumentOnTypeFormattingOptions{FirstTriggerCharacter: "}", MoreTriggerCharacter: []string{"\n", "."}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var options DocumentOnTypeFormattingOptions
+			roundTrip(t, []byte(test.payload), &options)
+			if !reflect.DeepEqual(options, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, options)
+			}
+		})
+	}
+}
+
+func TestServerCapabilitiesOmitOnTypeFormatting(t *testing.T) {
+	data, err := json.Marshal(ServerCapabilities{})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if _, ok := fields["documentOnTypeFormattingProvider"]; ok {
+		t.Errorf("expected on type formatting to be omitted, got %s", data)
+	}
+}
@@ -0,0 +1,73 @@
+package messages
+
+import (
+	"regexp"
+	"strings"
+)
+
+// matchGlob returns true if the path matches the glob pattern, using the
+// syntax described on GlobPattern. Invalid patterns don't match anything.
+func matchGlob(pattern, path string) bool {
+	re, ok := compileGlob(pattern)
+	if !ok {
+		return false
+	}
+	return re.MatchString(path)
+}
+
+// compileGlob converts a glob pattern to a regular expression.
+func compileGlob(pattern string) (re *regexp.Regexp, ok bool) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	var groups int
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			// Any number of path segments, including none.
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '{':
+			groups++
+			sb.WriteString("(?:")
+		case c == '}' && groups > 0:
+			groups--
+			sb.WriteString(")")
+		case c == ',' && groups > 0:
+			sb.WriteString("|")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, false
+			}
+			class := pattern[i+1 : i+1+end]
+			negated := strings.HasPrefix(class, "!")
+			class = strings.TrimPrefix(class, "!")
+			if class == "" {
+				return nil, false
+			}
+			sb.WriteString("[")
+			if negated {
+				sb.WriteString("^/")
+			}
+			sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(class, `\`, `\\`), "[", `\[`))
+			sb.WriteString("]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if groups != 0 {
+		return nil, false
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	return re, err == nil
+}
@@ -0,0 +1,124 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const HoverRequestMethod = "textDocument/hover"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_hover
+type HoverParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#hoverClientCapabilities
+type HoverClientCapabilities struct {
+	// Whether hover supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The content formats that the client supports for the content property,
+	// in order of preference.
+	ContentFormat []MarkupKind `json:"contentFormat,omitempty"`
+}
+
+// SupportsContentFormat returns true if the client accepts hover contents in
+// the format. Clients that don't declare formats only accept plain text.
+func (c *HoverClientCapabilities) SupportsContentFormat(kind MarkupKind) bool {
+	if c == nil {
+		return false
+	}
+	for _, k := range c.ContentFormat {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type Hover struct {
+	// The hover's content.
+	Contents HoverContents `json:"contents"`
+	// An optional range is a range inside a text document that is used to
+	// visualize a hover, e.g. by changing the background color.
+	Range *Range `json:"range,omitempty"`
+}
+
+// HoverContents is one of MarkupContent, MarkedString or []MarkedString. Only
+// one of the fields should be set. MarkedString is deprecated, but older
+// clients may not support MarkupContent.
+type HoverContents struct {
+	MarkupContent *MarkupContent
+	MarkedString  *MarkedString
+	MarkedStrings []MarkedString
+}
+
+// ErrInvalidHoverContents is returned when hover contents aren't one of the
+// types allowed by the spec.
+var ErrInvalidHoverContents = errors.New("messages: invalid hover contents")
+
+func (hc HoverContents) MarshalJSON() ([]byte, error) {
+	switch {
+	case hc.MarkupContent != nil:
+		return json.Marshal(hc.MarkupContent)
+	case hc.MarkedString != nil:
+		return json.Marshal(hc.MarkedString)
+	case hc.MarkedStrings != nil:
+		return json.Marshal(hc.MarkedStrings)
+	}
+	return nil, ErrInvalidHoverContents
+}
+
+func (hc *HoverContents) UnmarshalJSON(data []byte) error {
+	*hc = HoverContents{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidHoverContents
+	}
+	switch data[0] {
+	case '[':
+		return json.Unmarshal(data, &hc.MarkedStrings)
+	case '"':
+		hc.MarkedString = &MarkedString{}
+		return json.Unmarshal(data, hc.MarkedString)
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		if _, ok := fields["kind"]; ok {
+			hc.MarkupContent = &MarkupContent{}
+			return json.Unmarshal(data, hc.MarkupContent)
+		}
+		hc.MarkedString = &MarkedString{}
+		return json.Unmarshal(data, hc.MarkedString)
+	}
+	return ErrInvalidHoverContents
+}
+
+// MarkedString is markdown, or a code block in the given language. It's
+// marshalled as a string if the language is empty.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#markedString
+type MarkedString struct {
+	Language string `json:"language"`
+	Value    string `json:"value"`
+}
+
+func (ms MarkedString) MarshalJSON() ([]byte, error) {
+	if ms.Language == "" {
+		return json.Marshal(ms.Value)
+	}
+	type codeBlock MarkedString
+	return json.Marshal(codeBlock(ms))
+}
+
+func (ms *MarkedString) UnmarshalJSON(data []byte) error {
+	*ms = MarkedString{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &ms.Value)
+	}
+	type codeBlock MarkedString
+	return json.Unmarshal(data, (*codeBlock)(ms))
+}
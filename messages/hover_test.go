@@ -0,0 +1,74 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestHoverJSON(t *testing.T) {
+	r := &Range{Start: NewPosition(1, 2), End: NewPosition(1, 5)}
+	tests := []struct {
+		name     string
+		hover    Hover
+		expected string
+	}{
+		{
+			name: "markup content",
+			hover: Hover{
+				Contents: HoverContents{MarkupContent: &MarkupContent{Kind: MarkupKindMarkdown, Value: "**salt**"}},
+				Range:    r,
+			},
+			expected: `{"contents":{"kind":"markdown","value":"**salt**"},"range":{"start":{"line":1,"character":2},"end":{"line":1,"character":5}}}`,
+		},
+		{
+			name: "marked string without a language",
+			hover: Hover{
+				Contents: HoverContents{MarkedString: &MarkedString{Value: "salt"}},
+			},
+			expected: `{"contents":"salt"}`,
+		},
+		{
+			name: "marked string with a language",
+			hover: Hover{
+				Contents: HoverContents{MarkedString: &MarkedString{Language: "cooklang", Value: "@salt{1%g}"}},
+			},
+			expected: `{"contents":{"language":"cooklang","value":"@salt{1%g}"}}`,
+		},
+		{
+			name: "marked strings",
+			hover: Hover{
+				Contents: HoverContents{MarkedStrings: []MarkedString{{Value: "salt"}, {Language: "cooklang", Value: "@salt"}}},
+			},
+			expected: `{"contents":["salt",{"language":"cooklang","value":"@salt"}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := json.Marshal(test.hover)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+			var decoded Hover
+			if err := json.Unmarshal(actual, &decoded); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, test.hover) {
+				t.Errorf("expected the hover to round trip, got %#v", decoded)
+			}
+		})
+	}
+}
+
+func TestHoverContentsInvalid(t *testing.T) {
+	if _, err := json.Marshal(Hover{}); err == nil {
+		t.Error("expected empty contents to fail to marshal")
+	}
+	var h Hover
+	if err := json.Unmarshal([]byte(`{"contents":42}`), &h); err == nil {
+		t.Error("expected numeric contents to fail to unmarshal")
+	}
+}
@@ -0,0 +1,55 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const ImplementationRequestMethod = "textDocument/implementation"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_implementation
+type ImplementationParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+// ImplementationResult has the same shape as the result of a definition
+// request.
+type ImplementationResult = DefinitionResult
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#implementationClientCapabilities
+type ImplementationClientCapabilities struct {
+	// Whether implementation supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The client supports additional metadata in the form of definition links.
+	LinkSupport bool `json:"linkSupport,omitempty"`
+}
+
+type ImplementationOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+}
+
+// BoolOrImplementationOptions is the implementationProvider server
+// capability. It's marshalled as the options if they're set, and as Bool
+// otherwise.
+type BoolOrImplementationOptions struct {
+	Bool    bool
+	Options *ImplementationOptions
+}
+
+// ErrInvalidImplementationProvider is returned when the
+// implementationProvider capability isn't a boolean or an object.
+var ErrInvalidImplementationProvider = errors.New("messages: invalid implementation provider")
+
+func (b BoolOrImplementationOptions) MarshalJSON() ([]byte, error) {
+	if b.Options != nil {
+		return json.Marshal(b.Options)
+	}
+	return json.Marshal(b.Bool)
+}
+
+func (b *BoolOrImplementationOptions) UnmarshalJSON(data []byte) error {
+	*b = BoolOrImplementationOptions{}
+	return unmarshalBoolOrOptions(data, &b.Bool, &b.Options, ErrInvalidImplementationProvider)
+}
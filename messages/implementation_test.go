@@ -0,0 +1,66 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestImplementationParamsJSON(t *testing.T) {
+	var params ImplementationParams
+	roundTrip(t, readPayload(t, "implementation-params.json"), &params)
+	if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(6, 8) {
+		t.Errorf("unexpected params: %#v", params)
+	}
+	if params.WorkDoneToken == nil || !params.WorkDoneToken.Equal(NewStringProgressToken("2c8a1f4e-9b3d-4e6a-8f0c-7d5b1a3e9c2f")) {
+		t.Errorf("unexpected work done token: %v", params.WorkDoneToken)
+	}
+	if params.PartialResultToken == nil || !params.PartialResultToken.Equal(NewStringProgressToken("6e1b9d3a-0c4f-4a8e-b2d7-5f3c8a1e6b90")) {
+		t.Errorf("unexpected partial result token: %v", params.PartialResultToken)
+	}
+}
+
+func TestImplementationResultJSON(t *testing.T) {
+	var r ImplementationResult
+	roundTrip(t, readPayload(t, "definition-result-locations.json"), &r)
+	if len(r.Locations) != 2 {
+		t.Errorf("expected two locations, got %#v", r)
+	}
+}
+
+func TestImplementationClientCapabilitiesJSON(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	expected := &ImplementationClientCapabilities{DynamicRegistration: true, LinkSupport: true}
+	if !reflect.DeepEqual(params.Capabilities.TextDocument.Implementation, expected) {
+		t.Errorf("expected %#v, got %#v", expected, params.Capabilities.TextDocument.Implementation)
+	}
+}
+
+func TestImplementationProviderJSON(t *testing.T) {
+	for _, payload := range []string{`true`, `false`, `{"workDoneProgress":true}`} {
+		var provider BoolOrImplementationOptions
+		roundTrip(t, []byte(payload), &provider)
+	}
+	var provider BoolOrImplementationOptions
+	if err := json.Unmarshal([]byte(`null`), &provider); !errors.Is(err, ErrInvalidImplementationProvider) {
+		t.Errorf("expected ErrInvalidImplementationProvider, got %v", err)
+	}
+	data, err := json.Marshal(ServerCapabilities{ImplementationProvider: &BoolOrImplementationOptions{Bool: true}})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(fields["implementationProvider"]) != "true" {
+		t.Errorf("expected the implementation provider to be true, got %s", fields["implementationProvider"])
+	}
+	if _, ok := fields["typeDefinitionProvider"]; ok {
+		t.Error("expected the type definition provider to be omitted")
+	}
+}
@@ -1,19 +1,151 @@
 package messages
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#initializeParams
 type InitializeParams struct {
+	WorkDoneProgressParams
+
+	// The process ID of the parent process that started the server, or nil
+	// if the process hasn't been started by another process. If the parent
+	// process isn't alive, the server should exit.
+	ProcessID *int `json:"processId"`
+
 	// Information about the client
 	ClientInfo *ClientInfo `json:"clientInfo"`
 
+	// The locale the client is showing the user interface in, e.g. "en-gb".
+	Locale string `json:"locale,omitempty"`
+
+	// The rootPath of the workspace. Is null if no folder is open.
+	//
+	// Deprecated: in favour of RootURI.
+	RootPath *string `json:"rootPath,omitempty"`
+
+	// The rootUri of the workspace. Is null if no folder is open. If both
+	// RootPath and RootURI are set, RootURI wins.
+	//
+	// Deprecated: in favour of WorkspaceFolders.
+	RootURI *string `json:"rootUri"`
+
+	// The workspace folders configured in the client when the server starts.
+	// It's nil if the client doesn't support workspace folders, and empty if
+	// no folders are configured.
+	WorkspaceFolders []WorkspaceFolder `json:"workspaceFolders,omitempty"`
+
+	// User provided initialization options, which are specific to the
+	// server.
+	InitializationOptions json.RawMessage `json:"initializationOptions,omitempty"`
+
 	// The capabilities provided by the client (editor or tool)
 	Capabilities ClientCapabilities `json:"capabilities"`
+
+	// The initial trace setting. If omitted, trace is disabled ("off").
+	Trace TraceValue `json:"trace,omitempty"`
 }
 
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#traceValue
+type TraceValue string
+
+const (
+	TraceValueOff      TraceValue = "off"
+	TraceValueMessages TraceValue = "messages"
+	TraceValueVerbose  TraceValue = "verbose"
+)
+
 type ClientInfo struct {
 	Name    string  `json:"name"`
 	Version *string `json:"version"`
 }
 
 type ClientCapabilities struct {
+	// Workspace specific client capabilities.
+	Workspace *WorkspaceClientCapabilities `json:"workspace,omitempty"`
+	// Text document specific client capabilities.
+	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	// Window specific client capabilities.
+	Window *WindowClientCapabilities `json:"window,omitempty"`
+	// General client capabilities.
+	General *GeneralClientCapabilities `json:"general,omitempty"`
+}
+
+type WorkspaceClientCapabilities struct {
+	// The client supports the `workspace/configuration` request.
+	Configuration bool `json:"configuration,omitempty"`
+	// The client has support for workspace folders.
+	WorkspaceFolders bool `json:"workspaceFolders,omitempty"`
+	// The client has support for file requests and notifications.
+	FileOperations *FileOperationClientCapabilities `json:"fileOperations,omitempty"`
+	// Capabilities specific to the `workspace/symbol` request.
+	Symbol *WorkspaceSymbolClientCapabilities `json:"symbol,omitempty"`
+	// Capabilities specific to the semantic token requests scoped to the
+	// workspace.
+	SemanticTokens *RefreshClientCapabilities `json:"semanticTokens,omitempty"`
+	// Capabilities specific to the code lens requests scoped to the
+	// workspace.
+	CodeLens *RefreshClientCapabilities `json:"codeLens,omitempty"`
+	// Capabilities specific to the inlay hint requests scoped to the
+	// workspace.
+	InlayHint *RefreshClientCapabilities `json:"inlayHint,omitempty"`
+	// Capabilities specific to the diagnostic requests scoped to the
+	// workspace.
+	Diagnostics *RefreshClientCapabilities `json:"diagnostics,omitempty"`
+	// Capabilities specific to the inline values requests scoped to the
+	// workspace.
+	InlineValue *RefreshClientCapabilities `json:"inlineValue,omitempty"`
+}
+
+type TextDocumentClientCapabilities struct {
+	// Capabilities specific to text document synchronization.
+	Synchronization *TextDocumentSyncClientCapabilities `json:"synchronization,omitempty"`
+	// Capabilities specific to the `textDocument/completion` request.
+	Completion *CompletionClientCapabilities `json:"completion,omitempty"`
+	// Capabilities specific to the `textDocument/hover` request.
+	Hover *HoverClientCapabilities `json:"hover,omitempty"`
+	// Capabilities specific to the `textDocument/definition` request.
+	Definition *DefinitionClientCapabilities `json:"definition,omitempty"`
+	// Capabilities specific to the `textDocument/documentSymbol` request.
+	DocumentSymbol *DocumentSymbolClientCapabilities `json:"documentSymbol,omitempty"`
+	// Capabilities specific to the `textDocument/typeDefinition` request.
+	TypeDefinition *TypeDefinitionClientCapabilities `json:"typeDefinition,omitempty"`
+	// Capabilities specific to the `textDocument/implementation` request.
+	Implementation *ImplementationClientCapabilities `json:"implementation,omitempty"`
+	// Capabilities specific to the `textDocument/publishDiagnostics`
+	// notification.
+	PublishDiagnostics *PublishDiagnosticsClientCapabilities `json:"publishDiagnostics,omitempty"`
+	// Capabilities specific to the `textDocument/rename` request.
+	Rename *RenameClientCapabilities `json:"rename,omitempty"`
+	// Capabilities specific to the `textDocument/linkedEditingRange` request.
+	LinkedEditingRange *LinkedEditingRangeClientCapabilities `json:"linkedEditingRange,omitempty"`
+	// Capabilities specific to the `textDocument/inlineValue` request.
+	InlineValue *InlineValueClientCapabilities `json:"inlineValue,omitempty"`
+	// Capabilities specific to the `textDocument/inlayHint` request.
+	InlayHint *InlayHintClientCapabilities `json:"inlayHint,omitempty"`
+	// Capabilities specific to the `textDocument/foldingRange` request.
+	FoldingRange *FoldingRangeClientCapabilities `json:"foldingRange,omitempty"`
+}
+
+type WindowClientCapabilities struct {
+	// It indicates whether the client supports server initiated progress
+	// using the `window/workDoneProgress/create` request.
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// Capabilities specific to the showMessage request. Clients that don't set
+	// this may not show the actions of a window/showMessageRequest.
+	ShowMessage *ShowMessageRequestClientCapabilities `json:"showMessage,omitempty"`
+	// Capabilities specific to the showDocument request.
+	ShowDocument *ShowDocumentClientCapabilities `json:"showDocument,omitempty"`
+}
+
+type ShowMessageRequestClientCapabilities struct {
+	// Capabilities specific to the `MessageActionItem` type.
+	MessageActionItem *struct {
+		// Whether the client supports additional attributes which are
+		// preserved and sent back to the server in the request's response.
+		AdditionalPropertiesSupport bool `json:"additionalPropertiesSupport,omitempty"`
+	} `json:"messageActionItem,omitempty"`
 }
 
 type InitializeResult struct {
@@ -24,23 +156,65 @@ type InitializeResult struct {
 
 // https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#serverCapabilities
 type ServerCapabilities struct {
-	TextDocumentSync   TextDocumentSyncKind `json:"textDocumentSync"`
-	CompletionProvider *CompletionOptions   `json:"completionProvider,omitempty"`
+	// The position encoding the server picked from the encodings offered by
+	// the client. If it's empty, UTF-16 is used.
+	PositionEncoding                 PositionEncodingKind             `json:"positionEncoding,omitempty"`
+	TextDocumentSync                 TextDocumentSyncOptions          `json:"textDocumentSync"`
+	CompletionProvider               *CompletionOptions               `json:"completionProvider,omitempty"`
+	CodeActionProvider               *CodeActionOptions               `json:"codeActionProvider,omitempty"`
+	HoverProvider                    bool                             `json:"hoverProvider,omitempty"`
+	DefinitionProvider               bool                             `json:"definitionProvider,omitempty"`
+	TypeDefinitionProvider           *BoolOrTypeDefinitionOptions     `json:"typeDefinitionProvider,omitempty"`
+	ImplementationProvider           *BoolOrImplementationOptions     `json:"implementationProvider,omitempty"`
+	ReferencesProvider               bool                             `json:"referencesProvider,omitempty"`
+	RenameProvider                   bool                             `json:"renameProvider,omitempty"`
+	DocumentSymbolProvider           bool                             `json:"documentSymbolProvider,omitempty"`
+	ExecuteCommandProvider           *ExecuteCommandOptions           `json:"executeCommandProvider,omitempty"`
+	WorkspaceSymbolProvider          *BoolOrWorkspaceSymbolOptions    `json:"workspaceSymbolProvider,omitempty"`
+	DocumentFormattingProvider       bool                             `json:"documentFormattingProvider,omitempty"`
+	DocumentRangeFormattingProvider  bool                             `json:"documentRangeFormattingProvider,omitempty"`
+	DocumentOnTypeFormattingProvider *DocumentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider,omitempty"`
+	DocumentLinkProvider             *DocumentLinkOptions             `json:"documentLinkProvider,omitempty"`
+	SignatureHelpProvider            *SignatureHelpOptions            `json:"signatureHelpProvider,omitempty"`
+	ColorProvider                    *BoolOrDocumentColorOptions      `json:"colorProvider,omitempty"`
+	DiagnosticProvider               *DiagnosticRegistrationOptions   `json:"diagnosticProvider,omitempty"`
+	SemanticTokensProvider           *SemanticTokensOptions           `json:"semanticTokensProvider,omitempty"`
+	CodeLensProvider                 *CodeLensOptions                 `json:"codeLensProvider,omitempty"`
+	InlayHintProvider                *InlayHintOptions                `json:"inlayHintProvider,omitempty"`
+	LinkedEditingRangeProvider       bool                             `json:"linkedEditingRangeProvider,omitempty"`
+	InlineValueProvider              bool                             `json:"inlineValueProvider,omitempty"`
+	FoldingRangeProvider             bool                             `json:"foldingRangeProvider,omitempty"`
+	// Workspace specific server capabilities.
+	Workspace *WorkspaceServerCapabilities `json:"workspace,omitempty"`
 }
 
-type TextDocumentSyncKind int
-
-const (
-	TextDocumentSyncKindNone TextDocumentSyncKind = iota
-	TextDocumentSyncKindFull
-	TextDocumentSyncKindIncremental
-)
-
 type CompletionOptions struct {
 	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+	// The server provides support to resolve additional information for a
+	// completion item.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
 }
 
 type ServerInfo struct {
 	Name    string  `json:"name"`
 	Version *string `json:"version"`
 }
+
+// unmarshalBoolOrOptions unmarshals a server capability that's a boolean, or
+// an object of options. The registration options of dynamic registration are
+// also accepted, but only the options are kept. options must be a pointer to
+// a nil pointer to the options type.
+func unmarshalBoolOrOptions[T any](data []byte, b *bool, options **T, invalid error) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return invalid
+	}
+	switch data[0] {
+	case 't', 'f':
+		return json.Unmarshal(data, b)
+	case '{':
+		*options = new(T)
+		return json.Unmarshal(data, *options)
+	}
+	return invalid
+}
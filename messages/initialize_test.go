@@ -0,0 +1,175 @@
+package messages
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInitializeParamsJSON(t *testing.T) {
+	// The payload has capabilities that aren't modelled, which are ignored.
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	c := params.Capabilities
+
+	if c.TextDocument == nil {
+		t.Fatal("expected text document capabilities")
+	}
+	completion := c.TextDocument.Completion
+	if !completion.SnippetSupport() || !completion.SupportsDocumentationFormat(MarkupKindMarkdown) {
+		t.Errorf("expected snippets and markdown documentation, got %#v", completion)
+	}
+	if completion.CompletionItemKind == nil || len(completion.CompletionItemKind.ValueSet) != 25 {
+		t.Errorf("expected 25 completion item kinds, got %#v", completion.CompletionItemKind)
+	}
+	diagnostics := c.TextDocument.PublishDiagnostics
+	if diagnostics == nil || !diagnostics.RelatedInformation || diagnostics.VersionSupport {
+		t.Errorf("expected related information without versions, got %#v", diagnostics)
+	}
+	if !diagnostics.SupportsTag(DiagnosticTagUnnecessary) || !diagnostics.SupportsTag(DiagnosticTagDeprecated) {
+		t.Errorf("expected both diagnostic tags, got %#v", diagnostics.TagSupport)
+	}
+	if !c.TextDocument.Hover.SupportsContentFormat(MarkupKindMarkdown) {
+		t.Errorf("expected markdown hovers, got %#v", c.TextDocument.Hover)
+	}
+
+	if c.Workspace == nil || !c.Workspace.Configuration || !c.Workspace.WorkspaceFolders {
+		t.Errorf("expected configuration and workspace folders, got %#v", c.Workspace)
+	}
+	if c.Window == nil || !c.Window.WorkDoneProgress {
+		t.Errorf("expected work done progress, got %#v", c.Window)
+	}
+	if c.General == nil || len(c.General.PositionEncodings) != 1 || c.General.PositionEncodings[0] != PositionEncodingKindUTF16 {
+		t.Errorf("expected utf-16 position encoding, got %#v", c.General)
+	}
+	if len(params.WorkspaceFolders) != 1 || params.WorkspaceFolders[0].Name != "recipes" {
+		t.Errorf("expected the recipes workspace folder, got %#v", params.WorkspaceFolders)
+	}
+}
+
+func TestClientCapabilitiesAbsence(t *testing.T) {
+	var c ClientCapabilities
+	if err := json.Unmarshal([]byte(`{"textDocument":{"hover":{}}}`), &c); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if c.TextDocument.PublishDiagnostics != nil {
+		t.Errorf("expected missing publish diagnostics capabilities to be nil, got %#v", c.TextDocument.PublishDiagnostics)
+	}
+	if c.TextDocument.Hover == nil {
+		t.Error("expected empty hover capabilities to be present")
+	}
+	if c.TextDocument.Hover.SupportsContentFormat(MarkupKindMarkdown) {
+		t.Error("expected clients without content formats to only support plain text")
+	}
+	if c.TextDocument.PublishDiagnostics.SupportsTag(DiagnosticTagUnnecessary) {
+		t.Error("expected missing capabilities not to support tags")
+	}
+}
+
+func TestInitializeParamsFields(t *testing.T) {
+	strp := func(s string) *string { return &s }
+	intp := func(i int) *int { return &i }
+	tests := []struct {
+		client                        string
+		expectedProcessID             *int
+		expectedClientInfo            ClientInfo
+		expectedLocale                string
+		expectedRootPath              *string
+		expectedRootURI               *string
+		expectedInitializationOptions string
+		expectedTrace                 TraceValue
+		expectedWorkDoneToken         *ProgressToken
+		expectedWorkspaceFolders      []WorkspaceFolder
+	}{
+		{
+			client:                        "vscode",
+			expectedProcessID:             intp(48213),
+			expectedClientInfo:            ClientInfo{Name: "Visual Studio Code", Version: strp("1.85.1")},
+			expectedLocale:                "en",
+			expectedRootPath:              strp("/Users/alice/recipes"),
+			expectedRootURI:               strp("file:///Users/alice/recipes"),
+			expectedInitializationOptions: `{"swearWords":["blimey"]}`,
+			expectedTrace:                 TraceValueOff,
+			expectedWorkspaceFolders:      []WorkspaceFolder{{URI: "file:///Users/alice/recipes", Name: "recipes"}},
+		},
+		{
+			client:                        "neovim",
+			expectedProcessID:             intp(90211),
+			expectedClientInfo:            ClientInfo{Name: "Neovim", Version: strp("0.9.4")},
+			expectedRootPath:              strp("/home/bob/recipes"),
+			expectedRootURI:               strp("file:///home/bob/recipes"),
+			expectedInitializationOptions: `{"swearWords":["crikey","blimey"]}`,
+			expectedTrace:                 TraceValueOff,
+			expectedWorkDoneToken:         &ProgressToken{String: strp("1")},
+			expectedWorkspaceFolders:      []WorkspaceFolder{{URI: "file:///home/bob/recipes", Name: "/home/bob/recipes"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.client, func(t *testing.T) {
+			payload, err := os.ReadFile(filepath.Join("testdata", test.client, "initialize-params.json"))
+			if err != nil {
+				t.Fatalf("failed to read payload: %v", err)
+			}
+			var params InitializeParams
+			if err := json.Unmarshal(payload, &params); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(params.ProcessID, test.expectedProcessID) {
+				t.Errorf("expected process ID %v, got %v", *test.expectedProcessID, params.ProcessID)
+			}
+			if params.ClientInfo == nil || !reflect.DeepEqual(*params.ClientInfo, test.expectedClientInfo) {
+				t.Errorf("expected client info %#v, got %#v", test.expectedClientInfo, params.ClientInfo)
+			}
+			if params.Locale != test.expectedLocale {
+				t.Errorf("expected locale %q, got %q", test.expectedLocale, params.Locale)
+			}
+			if !reflect.DeepEqual(params.RootPath, test.expectedRootPath) {
+				t.Errorf("expected root path %q, got %v", *test.expectedRootPath, params.RootPath)
+			}
+			if !reflect.DeepEqual(params.RootURI, test.expectedRootURI) {
+				t.Errorf("expected root URI %q, got %v", *test.expectedRootURI, params.RootURI)
+			}
+			var options any
+			if err := json.Unmarshal(params.InitializationOptions, &options); err != nil {
+				t.Fatalf("failed to unmarshal initialization options: %v", err)
+			}
+			if actual, _ := json.Marshal(options); string(actual) != test.expectedInitializationOptions {
+				t.Errorf("expected initialization options %s, got %s", test.expectedInitializationOptions, actual)
+			}
+			if params.Trace != test.expectedTrace {
+				t.Errorf("expected trace %q, got %q", test.expectedTrace, params.Trace)
+			}
+			if !reflect.DeepEqual(params.WorkDoneToken, test.expectedWorkDoneToken) {
+				t.Errorf("expected work done token %v, got %v", test.expectedWorkDoneToken, params.WorkDoneToken)
+			}
+			if !reflect.DeepEqual(params.WorkspaceFolders, test.expectedWorkspaceFolders) {
+				t.Errorf("expected workspace folders %#v, got %#v", test.expectedWorkspaceFolders, params.WorkspaceFolders)
+			}
+		})
+	}
+}
+
+func TestInitializeParamsNullProcessID(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal([]byte(`{"processId":null,"capabilities":{}}`), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if params.ProcessID != nil {
+		t.Errorf("expected no process ID, got %d", *params.ProcessID)
+	}
+	actual, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(actual, &fields); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if string(fields["processId"]) != "null" {
+		t.Errorf("expected a null process ID to be sent, got %s", fields["processId"])
+	}
+}
@@ -0,0 +1,130 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	InlayHintRequestMethod        = "textDocument/inlayHint"
+	InlayHintResolveRequestMethod = "inlayHint/resolve"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_inlayHint
+//
+// The result of the request is []InlayHint, or null.
+type InlayHintParams struct {
+	WorkDoneProgressParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The visible document range for which inlay hints should be computed.
+	Range Range `json:"range"`
+}
+
+type InlayHint struct {
+	// The position of this hint.
+	Position Position `json:"position"`
+	// The label of this hint.
+	Label InlayHintLabel `json:"label"`
+	// The kind of this hint. Can be omitted in which case the client should
+	// fall back to a reasonable default.
+	Kind InlayHintKind `json:"kind,omitempty"`
+	// Optional text edits that are performed when accepting this inlay hint.
+	TextEdits []TextEdit `json:"textEdits,omitempty"`
+	// The tooltip text when you hover over this item.
+	Tooltip *StringOrMarkupContent `json:"tooltip,omitempty"`
+	// Render padding before the hint.
+	PaddingLeft bool `json:"paddingLeft,omitempty"`
+	// Render padding after the hint.
+	PaddingRight bool `json:"paddingRight,omitempty"`
+	// A data entry field that is preserved on an inlay hint between a
+	// textDocument/inlayHint and a inlayHint/resolve request.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type InlayHintKind int
+
+const (
+	// An inlay hint that is for a type annotation.
+	InlayHintKindType InlayHintKind = 1
+	// An inlay hint that is for a parameter.
+	InlayHintKindParameter InlayHintKind = 2
+)
+
+// InlayHintLabel is a string, or parts that can have their own tooltip,
+// location and command. It's marshalled as the parts if Parts isn't nil.
+type InlayHintLabel struct {
+	Value string
+	Parts []InlayHintLabelPart
+}
+
+// ErrInvalidInlayHintLabel is returned when an inlay hint label isn't a
+// string or an array of label parts.
+var ErrInvalidInlayHintLabel = errors.New("messages: invalid inlay hint label")
+
+func (l InlayHintLabel) MarshalJSON() ([]byte, error) {
+	if l.Parts != nil {
+		return json.Marshal(l.Parts)
+	}
+	return json.Marshal(l.Value)
+}
+
+func (l *InlayHintLabel) UnmarshalJSON(data []byte) error {
+	*l = InlayHintLabel{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidInlayHintLabel
+	}
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &l.Value)
+	case '[':
+		return json.Unmarshal(data, &l.Parts)
+	}
+	return ErrInvalidInlayHintLabel
+}
+
+type InlayHintLabelPart struct {
+	// The value of this label part.
+	Value string `json:"value"`
+	// The tooltip text when you hover over this label part.
+	Tooltip *StringOrMarkupContent `json:"tooltip,omitempty"`
+	// An optional source code location that represents this label part.
+	Location *Location `json:"location,omitempty"`
+	// An optional command for this label part.
+	Command *Command `json:"command,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#inlayHintOptions
+type InlayHintOptions struct {
+	// The server provides support to resolve additional information for an
+	// inlay hint item.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#inlayHintClientCapabilities
+type InlayHintClientCapabilities struct {
+	// Whether inlay hints support dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// Indicates which properties a client can resolve lazily on an inlay
+	// hint.
+	ResolveSupport *struct {
+		// The properties that a client can resolve lazily.
+		Properties []string `json:"properties"`
+	} `json:"resolveSupport,omitempty"`
+}
+
+// Resolves returns true if the client can resolve the property of an inlay
+// hint, e.g. "tooltip". It returns false if the capabilities are nil.
+func (c *InlayHintClientCapabilities) Resolves(property string) bool {
+	if c == nil || c.ResolveSupport == nil {
+		return false
+	}
+	for _, p := range c.ResolveSupport.Properties {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInlayHintParamsJSON(t *testing.T) {
+	var params InlayHintParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+		"range": {"start": {"line": 0, "character": 0}, "end": {"line": 42, "character": 0}}
+	}`), &params)
+	if params.Range.End != NewPosition(42, 0) {
+		t.Errorf("unexpected range: %#v", params.Range)
+	}
+}
+
+func TestInlayHintJSON(t *testing.T) {
+	timer := "Timer"
+	tests := []struct {
+		name     string
+		payload  string
+		expected InlayHint
+	}{
+		{
+			// The minimal shape, which clients such as Neovim display as
+			// virtual text.
+			name:    "string label",
+			payload: `{"position":{"line":2,"character":18},"label":"(250 g)","kind":1,"paddingLeft":true}`,
+			expected: InlayHint{
+				Position:    NewPosition(2, 18),
+				Label:       InlayHintLabel{Value: "(250 g)"},
+				Kind:        InlayHintKindType,
+				PaddingLeft: true,
+			},
+		},
+		{
+			// Label parts with their own tooltips and locations, which
+			// clients such as VS Code make hoverable and clickable.
+			name: "label parts",
+			payload: `{
+				"position": {"line": 5, "character": 9},
+				"label": [
+					{"value": "~"},
+					{
+						"value": "marinade",
+						"tooltip": {"kind": "markdown", "value": "Started on **line 2**"},
+						"location": {
+							"uri": "file:///Users/alice/recipes/pasta.cook",
+							"range": {"start": {"line": 1, "character": 9}, "end": {"line": 1, "character": 18}}
+						}
+					}
+				],
+				"tooltip": "Timer",
+				"paddingRight": true,
+				"data": {"timer": "marinade"}
+			}`,
+			expected: InlayHint{
+				Position: NewPosition(5, 9),
+				Label: InlayHintLabel{
+					Parts: []InlayHintLabelPart{
+						{Value: "~"},
+						{
+							Value:   "marinade",
+							Tooltip: &StringOrMarkupContent{MarkupContent: &MarkupContent{Kind: MarkupKindMarkdown, Value: "Started on **line 2**"}},
+							Location: &Location{
+								URI:   "file:///Users/alice/recipes/pasta.cook",
+								Range: Range{Start: NewPosition(1, 9), End: NewPosition(1, 18)},
+							},
+						},
+					},
+				},
+				Tooltip:      &StringOrMarkupContent{String: &timer},
+				PaddingRight: true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hint InlayHint
+			roundTrip(t, []byte(test.payload), &hint)
+			hint.Data = nil
+			if !reflect.DeepEqual(hint, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, hint)
+			}
+		})
+	}
+}
+
+func TestInlayHintLabelInvalid(t *testing.T) {
+	var label InlayHintLabel
+	if err := json.Unmarshal([]byte(`42`), &label); !errors.Is(err, ErrInvalidInlayHintLabel) {
+		t.Errorf("expected ErrInvalidInlayHintLabel, got %v", err)
+	}
+}
+
+func TestStringOrMarkupContentJSON(t *testing.T) {
+	if _, err := json.Marshal(StringOrMarkupContent{}); !errors.Is(err, ErrInvalidStringOrMarkupContent) {
+		t.Errorf("expected an error for an empty value, got %v", err)
+	}
+	var s StringOrMarkupContent
+	if err := json.Unmarshal([]byte(`true`), &s); !errors.Is(err, ErrInvalidStringOrMarkupContent) {
+		t.Errorf("expected ErrInvalidStringOrMarkupContent, got %v", err)
+	}
+}
+
+func TestInlayHintClientCapabilitiesResolves(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	c := params.Capabilities.TextDocument.InlayHint
+	if !c.Resolves("tooltip") || !c.Resolves("label.location") {
+		t.Errorf("expected tooltips and label locations to be resolved, got %#v", c.ResolveSupport)
+	}
+	if c.Resolves("position") {
+		t.Error("expected the position not to be resolved")
+	}
+	var nilCapabilities *InlayHintClientCapabilities
+	if nilCapabilities.Resolves("tooltip") {
+		t.Error("expected clients without capabilities not to resolve tooltips")
+	}
+}
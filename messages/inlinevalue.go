@@ -0,0 +1,114 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const InlineValueRequestMethod = "textDocument/inlineValue"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_inlineValue
+type InlineValueParams struct {
+	WorkDoneProgressParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The document range for which inline values should be computed.
+	Range Range `json:"range"`
+	// Additional information about the context in which inline values were
+	// requested.
+	Context InlineValueContext `json:"context"`
+}
+
+type InlineValueContext struct {
+	// The stack frame (as a DAP ID) where the execution has stopped.
+	FrameID int `json:"frameId"`
+	// The document range where execution has stopped. Typically the end
+	// position of the range denotes the line where the inline values are
+	// shown.
+	StoppedLocation Range `json:"stoppedLocation"`
+}
+
+// InlineValue is one of InlineValueText, InlineValueVariableLookup or
+// InlineValueEvaluatableExpression. Only one of the fields should be set.
+// The variants are told apart by their required fields when unmarshalled.
+type InlineValue struct {
+	Text                  *InlineValueText
+	VariableLookup        *InlineValueVariableLookup
+	EvaluatableExpression *InlineValueEvaluatableExpression
+}
+
+// ErrInvalidInlineValue is returned when an inline value isn't one of the
+// types allowed by the spec.
+var ErrInvalidInlineValue = errors.New("messages: invalid inline value")
+
+func (v InlineValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case v.Text != nil:
+		return json.Marshal(v.Text)
+	case v.VariableLookup != nil:
+		return json.Marshal(v.VariableLookup)
+	case v.EvaluatableExpression != nil:
+		return json.Marshal(v.EvaluatableExpression)
+	}
+	return nil, ErrInvalidInlineValue
+}
+
+func (v *InlineValue) UnmarshalJSON(data []byte) error {
+	*v = InlineValue{}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return ErrInvalidInlineValue
+	}
+	if _, ok := fields["text"]; ok {
+		v.Text = &InlineValueText{}
+		return json.Unmarshal(data, v.Text)
+	}
+	if _, ok := fields["caseSensitiveLookup"]; ok {
+		v.VariableLookup = &InlineValueVariableLookup{}
+		return json.Unmarshal(data, v.VariableLookup)
+	}
+	if _, ok := fields["range"]; ok {
+		v.EvaluatableExpression = &InlineValueEvaluatableExpression{}
+		return json.Unmarshal(data, v.EvaluatableExpression)
+	}
+	return ErrInvalidInlineValue
+}
+
+// InlineValueText provides the inline value as text.
+type InlineValueText struct {
+	// The document range for which the inline value applies.
+	Range Range `json:"range"`
+	// The text of the inline value.
+	Text string `json:"text"`
+}
+
+// InlineValueVariableLookup provides the inline value through a variable
+// lookup. If only a range is specified, the variable name will be extracted
+// from the underlying document.
+type InlineValueVariableLookup struct {
+	// The document range for which the inline value applies. The range is
+	// used to extract the variable name from the underlying document.
+	Range Range `json:"range"`
+	// If specified the name of the variable to look up.
+	VariableName string `json:"variableName,omitempty"`
+	// How to perform the lookup.
+	CaseSensitiveLookup bool `json:"caseSensitiveLookup"`
+}
+
+// InlineValueEvaluatableExpression provides the inline value through an
+// expression evaluation. If only a range is specified, the expression will
+// be extracted from the underlying document.
+type InlineValueEvaluatableExpression struct {
+	// The document range for which the inline value applies. The range is
+	// used to extract the evaluatable expression from the underlying
+	// document.
+	Range Range `json:"range"`
+	// If specified the expression overrides the extracted expression.
+	Expression string `json:"expression,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#inlineValueClientCapabilities
+type InlineValueClientCapabilities struct {
+	// Whether the implementation supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
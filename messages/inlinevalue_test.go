@@ -0,0 +1,63 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestInlineValueParamsJSON(t *testing.T) {
+	var params InlineValueParams
+	roundTrip(t, []byte(`{
+		"textDocument": {"uri": "file:///recipe.cook"},
+		"range": {"start": {"line": 0, "character": 0}, "end": {"line": 12, "character": 0}},
+		"context": {
+			"frameId": 7,
+			"stoppedLocation": {"start": {"line": 4, "character": 2}, "end": {"line": 4, "character": 9}}
+		}
+	}`), &params)
+	if params.Context.FrameID != 7 || params.Context.StoppedLocation.End != NewPosition(4, 9) {
+		t.Errorf("unexpected context: %#v", params.Context)
+	}
+}
+
+func TestInlineValueJSON(t *testing.T) {
+	var values []InlineValue
+	roundTrip(t, []byte(`[
+		{"range": {"start": {"line": 1, "character": 0}, "end": {"line": 1, "character": 5}}, "text": "flour = 200g"},
+		{"range": {"start": {"line": 2, "character": 0}, "end": {"line": 2, "character": 5}}, "variableName": "sugar", "caseSensitiveLookup": true},
+		{"range": {"start": {"line": 3, "character": 0}, "end": {"line": 3, "character": 5}}, "caseSensitiveLookup": false},
+		{"range": {"start": {"line": 4, "character": 0}, "end": {"line": 4, "character": 9}}, "expression": "eggs * 2"},
+		{"range": {"start": {"line": 5, "character": 0}, "end": {"line": 5, "character": 4}}}
+	]`), &values)
+	if len(values) != 5 {
+		t.Fatalf("expected 5 values, got %d", len(values))
+	}
+	if values[0].Text == nil || values[0].Text.Text != "flour = 200g" {
+		t.Errorf("expected text, got %#v", values[0])
+	}
+	if values[1].VariableLookup == nil || values[1].VariableLookup.VariableName != "sugar" || !values[1].VariableLookup.CaseSensitiveLookup {
+		t.Errorf("expected a variable lookup, got %#v", values[1])
+	}
+	if values[2].VariableLookup == nil || values[2].VariableLookup.VariableName != "" {
+		t.Errorf("expected a variable lookup without a name, got %#v", values[2])
+	}
+	if values[3].EvaluatableExpression == nil || values[3].EvaluatableExpression.Expression != "eggs * 2" {
+		t.Errorf("expected an evaluatable expression, got %#v", values[3])
+	}
+	if values[4].EvaluatableExpression == nil || values[4].EvaluatableExpression.Range.End != NewPosition(5, 4) {
+		t.Errorf("expected an evaluatable expression without an expression, got %#v", values[4])
+	}
+}
+
+func TestInlineValueInvalid(t *testing.T) {
+	if _, err := json.Marshal(InlineValue{}); !errors.Is(err, ErrInvalidInlineValue) {
+		t.Errorf("expected ErrInvalidInlineValue when marshalling an empty value, got %v", err)
+	}
+	for _, payload := range []string{`{}`, `"text"`, `null`} {
+		var v InlineValue
+		if err := json.Unmarshal([]byte(payload), &v); !errors.Is(err, ErrInvalidInlineValue) {
+			t.Errorf("%s: expected ErrInvalidInlineValue, got %v", payload, err)
+		}
+	}
+}
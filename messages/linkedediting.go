@@ -0,0 +1,29 @@
+package messages
+
+const LinkedEditingRangeRequestMethod = "textDocument/linkedEditingRange"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_linkedEditingRange
+type LinkedEditingRangeParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+}
+
+// LinkedEditingRanges is the result of a linkedEditingRange request. Handlers
+// return a nil *LinkedEditingRanges when there are no linked ranges at the
+// position, which is sent as null.
+type LinkedEditingRanges struct {
+	// A list of ranges that can be renamed together. The ranges must have
+	// identical length and contain identical text content. The ranges cannot
+	// overlap.
+	Ranges []Range `json:"ranges"`
+	// An optional word pattern (regular expression) that describes valid
+	// contents for the given ranges. If no pattern is provided, the client
+	// configuration's word pattern will be used.
+	WordPattern string `json:"wordPattern,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#linkedEditingRangeClientCapabilities
+type LinkedEditingRangeClientCapabilities struct {
+	// Whether the implementation supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
@@ -0,0 +1,58 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLinkedEditingRangeParamsJSON(t *testing.T) {
+	var params LinkedEditingRangeParams
+	roundTrip(t, []byte(`{"textDocument":{"uri":"file:///recipe.cook"},"position":{"line":2,"character":7},"workDoneToken":"a"}`), &params)
+	if params.TextDocument.URI != "file:///recipe.cook" || params.Position != NewPosition(2, 7) {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
+
+func TestLinkedEditingRangesJSON(t *testing.T) {
+	t.Run("no ranges are sent as null", func(t *testing.T) {
+		var result *LinkedEditingRanges
+		actual, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if string(actual) != "null" {
+			t.Errorf("expected null, got %s", actual)
+		}
+		if err := json.Unmarshal([]byte("null"), &result); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if result != nil {
+			t.Errorf("expected nil, got %#v", result)
+		}
+	})
+	t.Run("ranges", func(t *testing.T) {
+		var result LinkedEditingRanges
+		roundTrip(t, []byte(`{
+			"ranges": [
+				{"start": {"line": 0, "character": 5}, "end": {"line": 0, "character": 10}},
+				{"start": {"line": 3, "character": 9}, "end": {"line": 3, "character": 14}}
+			],
+			"wordPattern": "[\\w ]+"
+		}`), &result)
+		if len(result.Ranges) != 2 || result.Ranges[1].Start != NewPosition(3, 9) {
+			t.Errorf("unexpected ranges: %#v", result.Ranges)
+		}
+		if result.WordPattern != `[\w ]+` {
+			t.Errorf("unexpected word pattern: %q", result.WordPattern)
+		}
+	})
+	t.Run("the word pattern is optional", func(t *testing.T) {
+		actual, err := json.Marshal(LinkedEditingRanges{Ranges: []Range{}})
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if string(actual) != `{"ranges":[]}` {
+			t.Errorf("unexpected JSON: %s", actual)
+		}
+	})
+}
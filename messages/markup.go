@@ -0,0 +1,58 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+type MarkupKind string
+
+const (
+	MarkupKindPlainText MarkupKind = "plaintext"
+	MarkupKindMarkdown  MarkupKind = "markdown"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#markupContentInnerDefinition
+type MarkupContent struct {
+	Kind  MarkupKind `json:"kind"`
+	Value string     `json:"value"`
+}
+
+// StringOrMarkupContent is plain text, or MarkupContent. Only one of the
+// fields should be set.
+type StringOrMarkupContent struct {
+	String        *string
+	MarkupContent *MarkupContent
+}
+
+// ErrInvalidStringOrMarkupContent is returned when a value isn't a string or
+// MarkupContent.
+var ErrInvalidStringOrMarkupContent = errors.New("messages: invalid string or markup content")
+
+func (s StringOrMarkupContent) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.String != nil:
+		return json.Marshal(s.String)
+	case s.MarkupContent != nil:
+		return json.Marshal(s.MarkupContent)
+	}
+	return nil, ErrInvalidStringOrMarkupContent
+}
+
+func (s *StringOrMarkupContent) UnmarshalJSON(data []byte) error {
+	*s = StringOrMarkupContent{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidStringOrMarkupContent
+	}
+	switch data[0] {
+	case '"':
+		s.String = new(string)
+		return json.Unmarshal(data, s.String)
+	case '{':
+		s.MarkupContent = &MarkupContent{}
+		return json.Unmarshal(data, s.MarkupContent)
+	}
+	return ErrInvalidStringOrMarkupContent
+}
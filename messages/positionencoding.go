@@ -0,0 +1,24 @@
+package messages
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#positionEncodingKind
+//
+// PositionEncodingKind is the unit that the character offsets of positions
+// are counted in.
+type PositionEncodingKind string
+
+const (
+	// Character offsets count UTF-8 code units, i.e. bytes.
+	PositionEncodingKindUTF8 PositionEncodingKind = "utf-8"
+	// Character offsets count UTF-16 code units. This is the default
+	// position encoding, and must always be supported by servers.
+	PositionEncodingKindUTF16 PositionEncodingKind = "utf-16"
+	// Character offsets count UTF-32 code units, i.e. Unicode code points.
+	PositionEncodingKindUTF32 PositionEncodingKind = "utf-32"
+)
+
+// General client capabilities.
+type GeneralClientCapabilities struct {
+	// The position encodings supported by the client, in order of
+	// preference. If it's empty, only UTF-16 is supported.
+	PositionEncodings []PositionEncodingKind `json:"positionEncodings,omitempty"`
+}
@@ -0,0 +1,136 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const ProgressMethod = "$/progress"
+
+// ProgressToken is an integer or a string, provided by the client or the
+// server. Only one of the fields should be set.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#progress
+type ProgressToken struct {
+	Integer *int
+	String  *string
+}
+
+// ErrInvalidProgressToken is returned when a progress token isn't an integer
+// or a string.
+var ErrInvalidProgressToken = errors.New("messages: invalid progress token")
+
+// NewIntegerProgressToken returns a token that's marshalled as a number.
+func NewIntegerProgressToken(i int) ProgressToken {
+	return ProgressToken{Integer: &i}
+}
+
+// NewStringProgressToken returns a token that's marshalled as a string.
+func NewStringProgressToken(s string) ProgressToken {
+	return ProgressToken{String: &s}
+}
+
+// Equal returns true if the tokens have the same type and value.
+func (t ProgressToken) Equal(other ProgressToken) bool {
+	switch {
+	case t.Integer != nil && other.Integer != nil:
+		return *t.Integer == *other.Integer
+	case t.String != nil && other.String != nil:
+		return *t.String == *other.String
+	}
+	return false
+}
+
+func (t ProgressToken) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Integer != nil:
+		return json.Marshal(*t.Integer)
+	case t.String != nil:
+		return json.Marshal(*t.String)
+	}
+	return nil, ErrInvalidProgressToken
+}
+
+func (t *ProgressToken) UnmarshalJSON(data []byte) error {
+	*t = ProgressToken{}
+	var i int
+	if err := json.Unmarshal(data, &i); err == nil {
+		t.Integer = &i
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		t.String = &s
+		return nil
+	}
+	return ErrInvalidProgressToken
+}
+
+type ProgressParams struct {
+	// The progress token provided by the client or server.
+	Token ProgressToken `json:"token"`
+	// The progress data.
+	Value any `json:"value"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressParams
+type WorkDoneProgressParams struct {
+	// An optional token that a server can use to report work done progress.
+	WorkDoneToken *ProgressToken `json:"workDoneToken,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#partialResultParams
+type PartialResultParams struct {
+	// An optional token that a server can use to report partial results (e.g.
+	// streaming) to the client.
+	PartialResultToken *ProgressToken `json:"partialResultToken,omitempty"`
+}
+
+const (
+	WorkDoneProgressCreateRequestMethod = "window/workDoneProgress/create"
+	WorkDoneProgressCancelMethod        = "window/workDoneProgress/cancel"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_workDoneProgress_create
+type WorkDoneProgressCreateParams struct {
+	// The token to be used to report progress.
+	Token ProgressToken `json:"token"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_workDoneProgress_cancel
+type WorkDoneProgressCancelParams struct {
+	// The token to be used to report progress.
+	Token ProgressToken `json:"token"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressBegin
+type WorkDoneProgressBegin struct {
+	// Always "begin".
+	Kind string `json:"kind"`
+	// Mandatory title of the progress operation, e.g. "Indexing".
+	Title string `json:"title"`
+	// Controls if a cancel button should show to allow the user to cancel the
+	// long running operation.
+	Cancellable bool `json:"cancellable,omitempty"`
+	// Optional, more detailed progress message.
+	Message string `json:"message,omitempty"`
+	// Optional progress percentage to display, from 0 to 100.
+	Percentage *int `json:"percentage,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressReport
+type WorkDoneProgressReport struct {
+	// Always "report".
+	Kind        string `json:"kind"`
+	Cancellable bool   `json:"cancellable,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Percentage  *int   `json:"percentage,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workDoneProgressEnd
+type WorkDoneProgressEnd struct {
+	// Always "end".
+	Kind string `json:"kind"`
+	// Optional, a final message indicating the outcome of the operation.
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,101 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestProgressTokenJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected ProgressToken
+	}{
+		{
+			name:     "integer",
+			payload:  `42`,
+			expected: NewIntegerProgressToken(42),
+		},
+		{
+			name:     "negative integer",
+			payload:  `-1`,
+			expected: NewIntegerProgressToken(-1),
+		},
+		{
+			name:     "string",
+			payload:  `"8b7a0b4e-5f1c-4c0e-9b1a-3f2d6c5e4a10"`,
+			expected: NewStringProgressToken("8b7a0b4e-5f1c-4c0e-9b1a-3f2d6c5e4a10"),
+		},
+		{
+			name:     "numeric string",
+			payload:  `"42"`,
+			expected: NewStringProgressToken("42"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var token ProgressToken
+			roundTrip(t, []byte(test.payload), &token)
+			if !token.Equal(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, token)
+			}
+		})
+	}
+}
+
+func TestProgressTokenInvalid(t *testing.T) {
+	for _, payload := range []string{`1.5`, `true`, `{}`, `["1"]`} {
+		var token ProgressToken
+		if err := json.Unmarshal([]byte(payload), &token); !errors.Is(err, ErrInvalidProgressToken) {
+			t.Errorf("%s: expected ErrInvalidProgressToken, got %v", payload, err)
+		}
+	}
+	if _, err := json.Marshal(ProgressToken{}); !errors.Is(err, ErrInvalidProgressToken) {
+		t.Errorf("expected an empty token not to marshal, got %v", err)
+	}
+}
+
+func TestProgressTokenEqual(t *testing.T) {
+	if NewIntegerProgressToken(1).Equal(NewStringProgressToken("1")) {
+		t.Error("expected integer and string tokens not to be equal")
+	}
+	if !NewStringProgressToken("a").Equal(NewStringProgressToken("a")) {
+		t.Error("expected string tokens with the same value to be equal")
+	}
+}
+
+func TestCompletionParamsTokens(t *testing.T) {
+	t.Run("with tokens", func(t *testing.T) {
+		var params CompletionParams
+		roundTrip(t, []byte(`{
+			"textDocument": {"uri": "file:///pasta.cook"},
+			"position": {"line": 1, "character": 4},
+			"context": {"triggerKind": 1, "triggerCharacter": ""},
+			"workDoneToken": 3,
+			"partialResultToken": "results-1"
+		}`), &params)
+		if params.WorkDoneToken == nil || !params.WorkDoneToken.Equal(NewIntegerProgressToken(3)) {
+			t.Errorf("unexpected work done token: %v", params.WorkDoneToken)
+		}
+		if params.PartialResultToken == nil || !params.PartialResultToken.Equal(NewStringProgressToken("results-1")) {
+			t.Errorf("unexpected partial result token: %v", params.PartialResultToken)
+		}
+	})
+	t.Run("without tokens", func(t *testing.T) {
+		var params CompletionParams
+		roundTrip(t, readPayload(t, "completion-params.json"), &params)
+		if params.WorkDoneToken != nil || params.PartialResultToken != nil {
+			t.Errorf("expected no tokens, got %v and %v", params.WorkDoneToken, params.PartialResultToken)
+		}
+	})
+	t.Run("null tokens", func(t *testing.T) {
+		var params CompletionParams
+		if err := json.Unmarshal([]byte(`{"workDoneToken":null,"partialResultToken":null}`), &params); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if params.WorkDoneToken != nil || params.PartialResultToken != nil {
+			t.Errorf("expected no tokens, got %v and %v", params.WorkDoneToken, params.PartialResultToken)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package messages
+
+import "testing"
+
+func TestReferenceParamsJSON(t *testing.T) {
+	t.Run("context", func(t *testing.T) {
+		var params ReferenceParams
+		roundTrip(t, readPayload(t, "references-params.json"), &params)
+		if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(2, 11) {
+			t.Errorf("unexpected position: %#v", params.TextDocumentPositionParams)
+		}
+		if !params.Context.IncludeDeclaration {
+			t.Error("expected the declaration to be included")
+		}
+	})
+	t.Run("tokens", func(t *testing.T) {
+		var params ReferenceParams
+		roundTrip(t, readPayload(t, "references-params-tokens.json"), &params)
+		if params.WorkDoneToken == nil || !params.WorkDoneToken.Equal(NewStringProgressToken("4f0e2a8c-1b1d-4a51-a2f6-4cb0bcbf3d37")) {
+			t.Errorf("unexpected work done token: %v", params.WorkDoneToken)
+		}
+		if params.PartialResultToken == nil || !params.PartialResultToken.Equal(NewStringProgressToken("f3a5d7e1-8a2b-4c2e-9d6b-0e1f2a3b4c5d")) {
+			t.Errorf("unexpected partial result token: %v", params.PartialResultToken)
+		}
+		if params.Context.IncludeDeclaration {
+			t.Error("expected the declaration to be excluded")
+		}
+	})
+}
+
+func TestTextDocumentPositionParamsJSON(t *testing.T) {
+	var hover HoverParams
+	roundTrip(t, readPayload(t, "hover-params.json"), &hover)
+	if hover.Position != NewPosition(0, 12) {
+		t.Errorf("unexpected hover position: %#v", hover.Position)
+	}
+	var completion CompletionParams
+	roundTrip(t, readPayload(t, "completion-params.json"), &completion)
+	if completion.Position != NewPosition(1, 20) || completion.Context == nil {
+		t.Errorf("unexpected completion params: %#v", completion)
+	}
+}
@@ -0,0 +1,27 @@
+package messages
+
+// Requests sent by the server to ask the client to request semantic tokens,
+// code lenses, inlay hints, diagnostics and inline values again, after a
+// change that affects many documents, such as a change to the settings. They
+// have no params, and a null result.
+const (
+	SemanticTokensRefreshRequestMethod = "workspace/semanticTokens/refresh"
+	CodeLensRefreshRequestMethod       = "workspace/codeLens/refresh"
+	InlayHintRefreshRequestMethod      = "workspace/inlayHint/refresh"
+	DiagnosticRefreshRequestMethod     = "workspace/diagnostic/refresh"
+	InlineValueRefreshRequestMethod    = "workspace/inlineValue/refresh"
+)
+
+// RefreshClientCapabilities are declared by clients that support a refresh
+// request.
+type RefreshClientCapabilities struct {
+	// Whether the client implementation supports a refresh request sent from
+	// the server to the client.
+	RefreshSupport bool `json:"refreshSupport,omitempty"`
+}
+
+// SupportsRefresh returns true if the client supports the refresh request. It
+// returns false if the capabilities are nil.
+func (c *RefreshClientCapabilities) SupportsRefresh() bool {
+	return c != nil && c.RefreshSupport
+}
@@ -0,0 +1,24 @@
+package messages
+
+const RenameRequestMethod = "textDocument/rename"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_rename
+type RenameParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	// The new name of the symbol. If the given name is not valid the request
+	// must return a ResponseError with an appropriate message set.
+	NewName string `json:"newName"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#renameClientCapabilities
+type RenameClientCapabilities struct {
+	// Whether rename supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// Client supports testing for validity of rename operations before
+	// execution.
+	PrepareSupport bool `json:"prepareSupport,omitempty"`
+	// Whether the client honors the change annotations in text edits and
+	// resource operations returned via the rename request's workspace edit.
+	HonorsChangeAnnotations bool `json:"honorsChangeAnnotations,omitempty"`
+}
@@ -0,0 +1,28 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenameParamsJSON(t *testing.T) {
+	var params RenameParams
+	roundTrip(t, readPayload(t, "rename-params.json"), &params)
+	if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(2, 11) {
+		t.Errorf("unexpected position: %#v", params.TextDocumentPositionParams)
+	}
+	if params.NewName != "tamari" {
+		t.Errorf("expected new name %q, got %q", "tamari", params.NewName)
+	}
+}
+
+func TestRenameClientCapabilities(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	c := params.Capabilities.TextDocument.Rename
+	if c == nil || !c.DynamicRegistration || !c.PrepareSupport || !c.HonorsChangeAnnotations {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+}
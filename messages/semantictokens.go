@@ -0,0 +1,294 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const (
+	SemanticTokensFullRequestMethod      = "textDocument/semanticTokens/full"
+	SemanticTokensFullDeltaRequestMethod = "textDocument/semanticTokens/full/delta"
+	SemanticTokensRangeRequestMethod     = "textDocument/semanticTokens/range"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_semanticTokens
+//
+// The result of the request is SemanticTokens, or null.
+type SemanticTokensParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type SemanticTokens struct {
+	// An optional result id. If provided and clients support delta updating
+	// the client will include the result id in the next semantic token
+	// request.
+	ResultID string `json:"resultId,omitempty"`
+	// The tokens, encoded by EncodeSemanticTokens.
+	Data []uint32 `json:"data"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokens_deltaRequest
+//
+// The result of the request is SemanticTokens, SemanticTokensDelta, or null.
+type SemanticTokensDeltaParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The result id of a previous response. The result id can either point
+	// to a full response or a delta response depending on what was received
+	// last.
+	PreviousResultID string `json:"previousResultId"`
+}
+
+type SemanticTokensDelta struct {
+	// An optional result id, used in the next delta request.
+	ResultID string `json:"resultId,omitempty"`
+	// The semantic token edits to transform a previous result into a new
+	// result.
+	Edits []SemanticTokensEdit `json:"edits"`
+}
+
+type SemanticTokensEdit struct {
+	// The start offset of the edit in the previous data.
+	Start uint32 `json:"start"`
+	// The count of elements to remove.
+	DeleteCount uint32 `json:"deleteCount"`
+	// The elements to insert.
+	Data []uint32 `json:"data,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokens_rangeRequest
+//
+// The result of the request is SemanticTokens, or null.
+type SemanticTokensRangeParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The range the semantic tokens are requested for.
+	Range Range `json:"range"`
+}
+
+// SemanticTokensLegend lists the token types and modifiers used by the server.
+// Tokens refer to types by their index in TokenTypes, and to modifiers by a
+// bit set of their indexes in TokenModifiers.
+type SemanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#semanticTokensOptions
+type SemanticTokensOptions struct {
+	Legend SemanticTokensLegend `json:"legend"`
+	// Server supports providing semantic tokens for a specific range of a
+	// document.
+	Range bool `json:"range,omitempty"`
+	// Server supports providing semantic tokens for a full document, if it's
+	// not nil.
+	Full *SemanticTokensFullOptions `json:"full,omitempty"`
+}
+
+type SemanticTokensFullOptions struct {
+	// The server supports deltas for full documents.
+	Delta bool `json:"delta,omitempty"`
+}
+
+// UnmarshalJSON accepts the boolean forms of range and full, as well as the
+// object forms.
+func (o *SemanticTokensOptions) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Legend SemanticTokensLegend `json:"legend"`
+		Range  json.RawMessage      `json:"range"`
+		Full   json.RawMessage      `json:"full"`
+	}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*o = SemanticTokensOptions{Legend: raw.Legend}
+	o.Range = isEnabled(raw.Range)
+	if !isEnabled(raw.Full) {
+		return nil
+	}
+	o.Full = &SemanticTokensFullOptions{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw.Full), []byte("{")) {
+		if err = json.Unmarshal(raw.Full, o.Full); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEnabled returns true if the value is true, or an object.
+func isEnabled(value json.RawMessage) bool {
+	value = bytes.TrimSpace(value)
+	return bytes.Equal(value, []byte("true")) || bytes.HasPrefix(value, []byte("{"))
+}
+
+// Standard semantic token types.
+const (
+	SemanticTokenTypeNamespace     = "namespace"
+	SemanticTokenTypeType          = "type"
+	SemanticTokenTypeClass         = "class"
+	SemanticTokenTypeEnum          = "enum"
+	SemanticTokenTypeInterface     = "interface"
+	SemanticTokenTypeStruct        = "struct"
+	SemanticTokenTypeTypeParameter = "typeParameter"
+	SemanticTokenTypeParameter     = "parameter"
+	SemanticTokenTypeVariable      = "variable"
+	SemanticTokenTypeProperty      = "property"
+	SemanticTokenTypeEnumMember    = "enumMember"
+	SemanticTokenTypeEvent         = "event"
+	SemanticTokenTypeFunction      = "function"
+	SemanticTokenTypeMethod        = "method"
+	SemanticTokenTypeMacro         = "macro"
+	SemanticTokenTypeKeyword       = "keyword"
+	SemanticTokenTypeModifier      = "modifier"
+	SemanticTokenTypeComment       = "comment"
+	SemanticTokenTypeString        = "string"
+	SemanticTokenTypeNumber        = "number"
+	SemanticTokenTypeRegexp        = "regexp"
+	SemanticTokenTypeOperator      = "operator"
+	SemanticTokenTypeDecorator     = "decorator"
+)
+
+// Standard semantic token modifiers.
+const (
+	SemanticTokenModifierDeclaration    = "declaration"
+	SemanticTokenModifierDefinition     = "definition"
+	SemanticTokenModifierReadonly       = "readonly"
+	SemanticTokenModifierStatic         = "static"
+	SemanticTokenModifierDeprecated     = "deprecated"
+	SemanticTokenModifierAbstract       = "abstract"
+	SemanticTokenModifierAsync          = "async"
+	SemanticTokenModifierModification   = "modification"
+	SemanticTokenModifierDocumentation  = "documentation"
+	SemanticTokenModifierDefaultLibrary = "defaultLibrary"
+)
+
+// SemanticToken is a token at an absolute position in a document. Positions
+// and lengths are in the position encoding of the session.
+type SemanticToken struct {
+	Line      uint32
+	StartChar uint32
+	Length    uint32
+	// Type is the index of the token type in the legend.
+	Type uint32
+	// Modifiers is a bit set of the indexes of the token modifiers in the
+	// legend.
+	Modifiers uint32
+}
+
+// EncodeSemanticTokens returns the data of SemanticTokens for the tokens. Each
+// token is encoded as 5 integers: the line relative to the line of the
+// previous token, the start character relative to the start of the previous
+// token if it's on the same line, or to the start of the line if it isn't,
+// the length, the type and the modifiers.
+//
+// The tokens are sorted by position before they're encoded. The slice isn't
+// modified.
+func EncodeSemanticTokens(tokens []SemanticToken) (data []uint32) {
+	sorted := make([]SemanticToken, len(tokens))
+	copy(sorted, tokens)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Line != sorted[j].Line {
+			return sorted[i].Line < sorted[j].Line
+		}
+		return sorted[i].StartChar < sorted[j].StartChar
+	})
+	data = make([]uint32, 0, len(sorted)*5)
+	var line, start uint32
+	for _, t := range sorted {
+		deltaLine := t.Line - line
+		deltaStart := t.StartChar
+		if deltaLine == 0 {
+			deltaStart = t.StartChar - start
+		}
+		data = append(data, deltaLine, deltaStart, t.Length, t.Type, t.Modifiers)
+		line, start = t.Line, t.StartChar
+	}
+	return data
+}
+
+// DecodeSemanticTokens returns the tokens encoded in the data of
+// SemanticTokens, at absolute positions.
+func DecodeSemanticTokens(data []uint32) (tokens []SemanticToken, err error) {
+	if len(data)%5 != 0 {
+		return nil, fmt.Errorf("semantic tokens: expected a multiple of 5 integers, got %d", len(data))
+	}
+	var line, start uint32
+	for i := 0; i < len(data); i += 5 {
+		if data[i] > 0 {
+			start = 0
+		}
+		line += data[i]
+		start += data[i+1]
+		tokens = append(tokens, SemanticToken{
+			Line:      line,
+			StartChar: start,
+			Length:    data[i+2],
+			Type:      data[i+3],
+			Modifiers: data[i+4],
+		})
+	}
+	return tokens, nil
+}
+
+// DiffSemanticTokens returns the edits that turn the previous data of
+// SemanticTokens into the current data. The elements the arrays start and
+// end with are kept, and the elements between them are replaced by a single
+// edit. There are no edits if the arrays are the same.
+func DiffSemanticTokens(previous, current []uint32) (edits []SemanticTokensEdit) {
+	var prefix int
+	for prefix < len(previous) && prefix < len(current) && previous[prefix] == current[prefix] {
+		prefix++
+	}
+	var suffix int
+	for suffix < len(previous)-prefix && suffix < len(current)-prefix &&
+		previous[len(previous)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	deleteCount := len(previous) - prefix - suffix
+	data := current[prefix : len(current)-suffix]
+	if deleteCount == 0 && len(data) == 0 {
+		return []SemanticTokensEdit{}
+	}
+	edit := SemanticTokensEdit{
+		Start:       uint32(prefix),
+		DeleteCount: uint32(deleteCount),
+	}
+	if len(data) > 0 {
+		edit.Data = make([]uint32, len(data))
+		copy(edit.Data, data)
+	}
+	return []SemanticTokensEdit{edit}
+}
+
+// ApplySemanticTokensEdits returns the data of SemanticTokens after the edits
+// are applied to the previous data. The offsets of the edits refer to the
+// previous data, and the edits mustn't overlap. The previous data isn't
+// modified.
+func ApplySemanticTokensEdits(previous []uint32, edits []SemanticTokensEdit) (data []uint32, err error) {
+	sorted := make([]SemanticTokensEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	data = make([]uint32, 0, len(previous))
+	var offset uint32
+	for _, edit := range sorted {
+		end := uint64(edit.Start) + uint64(edit.DeleteCount)
+		if edit.Start < offset || end > uint64(len(previous)) {
+			return nil, fmt.Errorf("semantic tokens: edit of %d elements at %d is outside of the %d elements, or overlaps another edit", edit.DeleteCount, edit.Start, len(previous))
+		}
+		data = append(data, previous[offset:edit.Start]...)
+		data = append(data, edit.Data...)
+		offset = uint32(end)
+	}
+	return append(data, previous[offset:]...), nil
+}
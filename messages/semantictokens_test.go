@@ -0,0 +1,321 @@
+package messages
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestEncodeSemanticTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   []SemanticToken
+		expected []uint32
+	}{
+		{
+			name:     "no tokens",
+			tokens:   nil,
+			expected: []uint32{},
+		},
+		{
+			name:     "the first token is relative to the start of the document",
+			tokens:   []SemanticToken{{Line: 3, StartChar: 7, Length: 5, Type: 1, Modifiers: 0}},
+			expected: []uint32{3, 7, 5, 1, 0},
+		},
+		{
+			// The example from the specification.
+			name: "tokens on the same line are relative to the previous token",
+			tokens: []SemanticToken{
+				{Line: 2, StartChar: 5, Length: 3, Type: 0, Modifiers: 3},
+				{Line: 2, StartChar: 10, Length: 4, Type: 1, Modifiers: 0},
+				{Line: 5, StartChar: 2, Length: 7, Type: 2, Modifiers: 0},
+			},
+			expected: []uint32{
+				2, 5, 3, 0, 3,
+				0, 5, 4, 1, 0,
+				3, 2, 7, 2, 0,
+			},
+		},
+		{
+			name: "tokens on a new line are relative to the start of the line",
+			tokens: []SemanticToken{
+				{Line: 0, StartChar: 20, Length: 2, Type: 0},
+				{Line: 1, StartChar: 4, Length: 2, Type: 0},
+			},
+			expected: []uint32{
+				0, 20, 2, 0, 0,
+				1, 4, 2, 0, 0,
+			},
+		},
+		{
+			name: "adjacent tokens",
+			tokens: []SemanticToken{
+				{Line: 0, StartChar: 0, Length: 1, Type: 4},
+				{Line: 0, StartChar: 1, Length: 6, Type: 5},
+				{Line: 0, StartChar: 7, Length: 1, Type: 4},
+			},
+			expected: []uint32{
+				0, 0, 1, 4, 0,
+				0, 1, 6, 5, 0,
+				0, 6, 1, 4, 0,
+			},
+		},
+		{
+			name: "tokens are sorted by position",
+			tokens: []SemanticToken{
+				{Line: 4, StartChar: 1, Length: 1, Type: 2},
+				{Line: 1, StartChar: 8, Length: 1, Type: 1},
+				{Line: 1, StartChar: 2, Length: 1, Type: 0},
+			},
+			expected: []uint32{
+				1, 2, 1, 0, 0,
+				0, 6, 1, 1, 0,
+				3, 1, 1, 2, 0,
+			},
+		},
+		{
+			name: "modifiers are a bit set",
+			tokens: []SemanticToken{
+				{Line: 0, StartChar: 0, Length: 3, Type: 0, Modifiers: 1<<0 | 1<<2 | 1<<9},
+			},
+			expected: []uint32{0, 0, 3, 0, 0b1000000101},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := EncodeSemanticTokens(test.tokens)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+			decoded, err := DecodeSemanticTokens(actual)
+			if err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			if len(decoded) != len(test.tokens) {
+				t.Fatalf("expected %d decoded tokens, got %d", len(test.tokens), len(decoded))
+			}
+			for _, token := range test.tokens {
+				if !containsToken(decoded, token) {
+					t.Errorf("expected %#v to be decoded, got %#v", token, decoded)
+				}
+			}
+		})
+	}
+}
+
+func containsToken(tokens []SemanticToken, token SemanticToken) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEncodeSemanticTokensDoesNotModifyInput(t *testing.T) {
+	tokens := []SemanticToken{
+		{Line: 2, StartChar: 0, Length: 1},
+		{Line: 1, StartChar: 0, Length: 1},
+	}
+	EncodeSemanticTokens(tokens)
+	if tokens[0].Line != 2 || tokens[1].Line != 1 {
+		t.Errorf("expected the tokens to be unchanged, got %#v", tokens)
+	}
+}
+
+func TestDecodeSemanticTokensRejectsPartialTokens(t *testing.T) {
+	if _, err := DecodeSemanticTokens([]uint32{0, 1, 2, 3}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSemanticTokensOptionsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected SemanticTokensOptions
+	}{
+		{
+			name:    "objects",
+			payload: `{"legend":{"tokenTypes":["property"],"tokenModifiers":["readonly"]},"range":{},"full":{"delta":true}}`,
+			expected: SemanticTokensOptions{
+				Legend: SemanticTokensLegend{TokenTypes: []string{"property"}, TokenModifiers: []string{"readonly"}},
+				Range:  true,
+				Full:   &SemanticTokensFullOptions{Delta: true},
+			},
+		},
+		{
+			name:    "booleans",
+			payload: `{"legend":{"tokenTypes":[],"tokenModifiers":[]},"range":false,"full":true}`,
+			expected: SemanticTokensOptions{
+				Legend: SemanticTokensLegend{TokenTypes: []string{}, TokenModifiers: []string{}},
+				Full:   &SemanticTokensFullOptions{},
+			},
+		},
+		{
+			name:    "disabled",
+			payload: `{"legend":{"tokenTypes":[],"tokenModifiers":[]},"full":false}`,
+			expected: SemanticTokensOptions{
+				Legend: SemanticTokensLegend{TokenTypes: []string{}, TokenModifiers: []string{}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var options SemanticTokensOptions
+			if err := json.Unmarshal([]byte(test.payload), &options); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(options, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, options)
+			}
+		})
+	}
+}
+
+func TestSemanticTokensJSON(t *testing.T) {
+	var tokens SemanticTokens
+	roundTrip(t, []byte(`{"resultId":"1","data":[2,5,3,0,3,0,5,4,1,0]}`), &tokens)
+	if tokens.ResultID != "1" || len(tokens.Data) != 10 {
+		t.Errorf("unexpected tokens: %#v", tokens)
+	}
+}
+
+func TestSemanticTokensDeltaJSON(t *testing.T) {
+	var delta SemanticTokensDelta
+	roundTrip(t, []byte(`{"resultId":"2","edits":[{"start":5,"deleteCount":1,"data":[2]},{"start":10,"deleteCount":5}]}`), &delta)
+	expected := SemanticTokensDelta{
+		ResultID: "2",
+		Edits: []SemanticTokensEdit{
+			{Start: 5, DeleteCount: 1, Data: []uint32{2}},
+			{Start: 10, DeleteCount: 5},
+		},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("expected %#v, got %#v", expected, delta)
+	}
+
+	var params SemanticTokensDeltaParams
+	roundTrip(t, []byte(`{"textDocument":{"uri":"file:///pasta.cook"},"previousResultId":"1"}`), &params)
+	if params.PreviousResultID != "1" {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
+
+func TestSemanticTokensRangeParamsJSON(t *testing.T) {
+	var params SemanticTokensRangeParams
+	roundTrip(t, []byte(`{"workDoneToken":1,"textDocument":{"uri":"file:///pasta.cook"},"range":{"start":{"line":1,"character":0},"end":{"line":10,"character":0}}}`), &params)
+	if params.Range.End.Line != 10 || params.WorkDoneToken == nil {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
+
+func TestDiffSemanticTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []uint32
+		current  []uint32
+		expected []SemanticTokensEdit
+	}{
+		{
+			name:     "no changes",
+			previous: []uint32{0, 1, 2, 3, 0},
+			current:  []uint32{0, 1, 2, 3, 0},
+			expected: []SemanticTokensEdit{},
+		},
+		{
+			name:     "a token is changed",
+			previous: []uint32{0, 1, 2, 3, 0, 1, 0, 4, 3, 0},
+			current:  []uint32{0, 1, 2, 3, 0, 1, 0, 5, 3, 0},
+			expected: []SemanticTokensEdit{{Start: 7, DeleteCount: 1, Data: []uint32{5}}},
+		},
+		{
+			name:     "a token is added",
+			previous: []uint32{0, 1, 2, 3, 0},
+			current:  []uint32{0, 1, 2, 3, 0, 2, 0, 4, 1, 0},
+			expected: []SemanticTokensEdit{{Start: 5, Data: []uint32{2, 0, 4, 1, 0}}},
+		},
+		{
+			name:     "a token is removed",
+			previous: []uint32{0, 1, 2, 3, 0, 2, 0, 4, 1, 0},
+			current:  []uint32{2, 0, 4, 1, 0},
+			expected: []SemanticTokensEdit{{Start: 0, DeleteCount: 5}},
+		},
+		{
+			name:     "repeated elements aren't counted twice",
+			previous: []uint32{1, 1},
+			current:  []uint32{1, 1, 1},
+			expected: []SemanticTokensEdit{{Start: 2, Data: []uint32{1}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edits := DiffSemanticTokens(test.previous, test.current)
+			if !reflect.DeepEqual(edits, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, edits)
+			}
+		})
+	}
+}
+
+func TestDiffSemanticTokensApplies(t *testing.T) {
+	// Small values make it likely that the arrays share elements.
+	small := func(values []uint8) (data []uint32) {
+		data = make([]uint32, len(values))
+		for i, v := range values {
+			data[i] = uint32(v % 4)
+		}
+		return data
+	}
+	property := func(a, b []uint8) bool {
+		previous, current := small(a), small(b)
+		edits := DiffSemanticTokens(previous, current)
+		applied, err := ApplySemanticTokensEdits(previous, edits)
+		if err != nil {
+			t.Logf("failed to apply %#v to %v: %v", edits, previous, err)
+			return false
+		}
+		return reflect.DeepEqual(applied, current) && len(edits) <= 1
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+	// Edits to the middle of arrays that are otherwise the same.
+	property = func(a, b []uint8) bool {
+		prefix, suffix := small([]uint8{0, 1, 2, 3, 0}), small([]uint8{3, 2, 1})
+		previous := append(append(append([]uint32{}, prefix...), small(a)...), suffix...)
+		current := append(append(append([]uint32{}, prefix...), small(b)...), suffix...)
+		applied, err := ApplySemanticTokensEdits(previous, DiffSemanticTokens(previous, current))
+		return err == nil && reflect.DeepEqual(applied, current)
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApplySemanticTokensEdits(t *testing.T) {
+	previous := []uint32{0, 1, 2, 3, 0, 1, 0, 4, 3, 0}
+	data, err := ApplySemanticTokensEdits(previous, []SemanticTokensEdit{
+		{Start: 7, DeleteCount: 1, Data: []uint32{5}},
+		{Start: 0, DeleteCount: 5},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []uint32{1, 0, 5, 3, 0}; !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+	if previous[7] != 4 {
+		t.Error("expected the previous data not to be modified")
+	}
+	invalid := [][]SemanticTokensEdit{
+		{{Start: 8, DeleteCount: 5}},
+		{{Start: 0, DeleteCount: 5}, {Start: 4, DeleteCount: 1}},
+	}
+	for _, edits := range invalid {
+		if _, err := ApplySemanticTokensEdits(previous, edits); err == nil {
+			t.Errorf("expected an error applying %#v", edits)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package messages
+
+const ShowDocumentRequestMethod = "window/showDocument"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_showDocument
+type ShowDocumentParams struct {
+	// The uri to show.
+	URI string `json:"uri"`
+	// Indicates to show the resource in an external program, such as a web
+	// browser.
+	External bool `json:"external,omitempty"`
+	// Whether the editor showing the document should take focus.
+	TakeFocus bool `json:"takeFocus,omitempty"`
+	// The range to select, if the document is a text document.
+	Selection *Range `json:"selection,omitempty"`
+}
+
+type ShowDocumentResult struct {
+	Success bool `json:"success"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_showDocument
+type ShowDocumentClientCapabilities struct {
+	// The client has support for the showDocument request.
+	Support bool `json:"support"`
+}
+
+// Supported returns true if the client supports the showDocument request. It
+// returns false if the capabilities are nil.
+func (c *ShowDocumentClientCapabilities) Supported() bool {
+	return c != nil && c.Support
+}
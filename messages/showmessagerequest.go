@@ -0,0 +1,16 @@
+package messages
+
+const ShowMessageRequestMethod = "window/showMessageRequest"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#window_showMessageRequest
+type ShowMessageRequestParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+	// The message action items to present.
+	Actions []MessageActionItem `json:"actions,omitempty"`
+}
+
+type MessageActionItem struct {
+	// A short title like 'Retry', 'Open Log' etc.
+	Title string `json:"title"`
+}
@@ -0,0 +1,186 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+const SignatureHelpRequestMethod = "textDocument/signatureHelp"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_signatureHelp
+//
+// The result of the request is SignatureHelp, or null.
+type SignatureHelpParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	// The signature help context. This is only available if the client
+	// specifies to send this using the client capability
+	// `textDocument.signatureHelp.contextSupport === true`.
+	Context *SignatureHelpContext `json:"context,omitempty"`
+}
+
+type SignatureHelpTriggerKind int
+
+const (
+	// Signature help was invoked manually by the user or by a command.
+	SignatureHelpTriggerKindInvoked SignatureHelpTriggerKind = 1
+	// Signature help was triggered by a trigger character.
+	SignatureHelpTriggerKindTriggerCharacter SignatureHelpTriggerKind = 2
+	// Signature help was triggered by the cursor moving or by the document
+	// content changing.
+	SignatureHelpTriggerKindContentChange SignatureHelpTriggerKind = 3
+)
+
+type SignatureHelpContext struct {
+	// Action that caused signature help to be triggered.
+	TriggerKind SignatureHelpTriggerKind `json:"triggerKind"`
+	// Character that caused signature help to be triggered. This is nil when
+	// the trigger kind isn't TriggerCharacter.
+	TriggerCharacter *string `json:"triggerCharacter,omitempty"`
+	// true if signature help was already showing when it was triggered.
+	IsRetrigger bool `json:"isRetrigger"`
+	// The currently active SignatureHelp, with its activeSignature updated
+	// based on the user navigating through available signatures.
+	ActiveSignatureHelp *SignatureHelp `json:"activeSignatureHelp,omitempty"`
+}
+
+// SignatureHelp represents the signature of something callable.
+type SignatureHelp struct {
+	// One or more signatures.
+	Signatures []SignatureInformation `json:"signatures"`
+	// The active signature. If omitted, the client uses 0.
+	ActiveSignature *uint32 `json:"activeSignature,omitempty"`
+	// The active parameter of the active signature. If omitted, the client
+	// uses 0.
+	ActiveParameter *ActiveParameter `json:"activeParameter,omitempty"`
+}
+
+func (sh *SignatureHelp) UnmarshalJSON(data []byte) (err error) {
+	type signatureHelp SignatureHelp
+	var v struct {
+		signatureHelp
+		ActiveParameter json.RawMessage `json:"activeParameter"`
+	}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*sh = SignatureHelp(v.signatureHelp)
+	sh.ActiveParameter, err = decodeActiveParameter(v.ActiveParameter)
+	return err
+}
+
+// SignatureInformation represents the signature of something callable.
+type SignatureInformation struct {
+	// The label of this signature. Will be shown in the UI.
+	Label string `json:"label"`
+	// The human-readable doc-comment of this signature.
+	Documentation *StringOrMarkupContent `json:"documentation,omitempty"`
+	// The parameters of this signature.
+	Parameters []ParameterInformation `json:"parameters,omitempty"`
+	// The index of the active parameter. If set, it's used instead of the
+	// active parameter of the SignatureHelp.
+	ActiveParameter *ActiveParameter `json:"activeParameter,omitempty"`
+}
+
+func (si *SignatureInformation) UnmarshalJSON(data []byte) (err error) {
+	type signatureInformation SignatureInformation
+	var v struct {
+		signatureInformation
+		ActiveParameter json.RawMessage `json:"activeParameter"`
+	}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*si = SignatureInformation(v.signatureInformation)
+	si.ActiveParameter, err = decodeActiveParameter(v.ActiveParameter)
+	return err
+}
+
+// ActiveParameter is the index of the active parameter. If None is set, no
+// parameter is active, and it's marshalled as null.
+type ActiveParameter struct {
+	Index uint32
+	None  bool
+}
+
+func (ap ActiveParameter) MarshalJSON() ([]byte, error) {
+	if ap.None {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatUint(uint64(ap.Index), 10)), nil
+}
+
+// decodeActiveParameter returns nil if the field is missing, and an
+// ActiveParameter with None set if it's null.
+func decodeActiveParameter(raw json.RawMessage) (ap *ActiveParameter, err error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return &ActiveParameter{None: true}, nil
+	}
+	ap = &ActiveParameter{}
+	err = json.Unmarshal(raw, &ap.Index)
+	return ap, err
+}
+
+// ParameterInformation represents a parameter of a callable signature.
+type ParameterInformation struct {
+	// The label of this parameter.
+	Label ParameterLabel `json:"label"`
+	// The human-readable doc-comment of this parameter.
+	Documentation *StringOrMarkupContent `json:"documentation,omitempty"`
+}
+
+// ParameterLabel is a substring of the label of the signature, or the start
+// and end offsets of the parameter in the label, in UTF-16 code units. It's
+// marshalled as the offsets if Offsets isn't nil.
+type ParameterLabel struct {
+	Value   string
+	Offsets *[2]uint32
+}
+
+// ErrInvalidParameterLabel is returned when a parameter label isn't a string
+// or a pair of offsets.
+var ErrInvalidParameterLabel = errors.New("messages: invalid parameter label")
+
+func (l ParameterLabel) MarshalJSON() ([]byte, error) {
+	if l.Offsets != nil {
+		return json.Marshal(l.Offsets)
+	}
+	return json.Marshal(l.Value)
+}
+
+func (l *ParameterLabel) UnmarshalJSON(data []byte) error {
+	*l = ParameterLabel{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidParameterLabel
+	}
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &l.Value)
+	case '[':
+		var offsets []uint32
+		if err := json.Unmarshal(data, &offsets); err != nil {
+			return err
+		}
+		if len(offsets) != 2 {
+			return ErrInvalidParameterLabel
+		}
+		l.Offsets = &[2]uint32{offsets[0], offsets[1]}
+		return nil
+	}
+	return ErrInvalidParameterLabel
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#signatureHelpOptions
+type SignatureHelpOptions struct {
+	// The characters that trigger signature help automatically.
+	TriggerCharacters []string `json:"triggerCharacters,omitempty"`
+	// List of characters that re-trigger signature help. These trigger
+	// characters are only active when signature help is already showing.
+	RetriggerCharacters []string `json:"retriggerCharacters,omitempty"`
+}
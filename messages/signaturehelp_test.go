@@ -0,0 +1,106 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSignatureHelpParamsJSON(t *testing.T) {
+	t.Run("invoked", func(t *testing.T) {
+		var params SignatureHelpParams
+		roundTrip(t, readPayload(t, "signature-help-params-invoked.json"), &params)
+		if params.Context == nil || params.Context.TriggerKind != SignatureHelpTriggerKindInvoked {
+			t.Fatalf("expected an invoked context, got %#v", params.Context)
+		}
+		if params.Context.TriggerCharacter != nil || params.Context.ActiveSignatureHelp != nil {
+			t.Errorf("expected optional fields to be nil, got %#v", params.Context)
+		}
+	})
+	t.Run("retrigger", func(t *testing.T) {
+		var params SignatureHelpParams
+		roundTrip(t, readPayload(t, "signature-help-params-retrigger.json"), &params)
+		ctx := params.Context
+		if ctx == nil || ctx.TriggerKind != SignatureHelpTriggerKindTriggerCharacter || !ctx.IsRetrigger {
+			t.Fatalf("expected a retrigger context, got %#v", ctx)
+		}
+		if ctx.TriggerCharacter == nil || *ctx.TriggerCharacter != "%" {
+			t.Errorf("expected the trigger character, got %v", ctx.TriggerCharacter)
+		}
+		help := ctx.ActiveSignatureHelp
+		if help == nil || len(help.Signatures) != 1 {
+			t.Fatalf("expected the active signature help, got %#v", help)
+		}
+		if help.ActiveParameter == nil || *help.ActiveParameter != (ActiveParameter{Index: 0}) {
+			t.Errorf("expected the first parameter to be active, got %#v", help.ActiveParameter)
+		}
+		expected := []ParameterInformation{
+			{Label: ParameterLabel{Offsets: &[2]uint32{12, 20}}},
+			{Label: ParameterLabel{Offsets: &[2]uint32{21, 25}}},
+		}
+		if !reflect.DeepEqual(help.Signatures[0].Parameters, expected) {
+			t.Errorf("expected %#v, got %#v", expected, help.Signatures[0].Parameters)
+		}
+	})
+}
+
+func TestSignatureHelpActiveParameter(t *testing.T) {
+	tests := []struct {
+		name string
+		// field is added to the JSON objects.
+		field    string
+		expected *ActiveParameter
+	}{
+		{
+			name:     "omitted",
+			field:    ``,
+			expected: nil,
+		},
+		{
+			name:     "null",
+			field:    `,"activeParameter":null`,
+			expected: &ActiveParameter{None: true},
+		},
+		{
+			name:     "zero",
+			field:    `,"activeParameter":0`,
+			expected: &ActiveParameter{},
+		},
+		{
+			name:     "index",
+			field:    `,"activeParameter":2`,
+			expected: &ActiveParameter{Index: 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var help SignatureHelp
+			roundTrip(t, []byte(`{"signatures":[]`+test.field+`}`), &help)
+			if !reflect.DeepEqual(help.ActiveParameter, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, help.ActiveParameter)
+			}
+			var info SignatureInformation
+			roundTrip(t, []byte(`{"label":"@ingredient{}"`+test.field+`}`), &info)
+			if !reflect.DeepEqual(info.ActiveParameter, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, info.ActiveParameter)
+			}
+		})
+	}
+}
+
+func TestParameterLabelJSON(t *testing.T) {
+	var label ParameterLabel
+	roundTrip(t, []byte(`"quantity"`), &label)
+	if label.Value != "quantity" || label.Offsets != nil {
+		t.Errorf("expected a string label, got %#v", label)
+	}
+	for _, payload := range []string{`[1]`, `[1,2,3]`, `{}`, `1`} {
+		if err := json.Unmarshal([]byte(payload), &label); err == nil {
+			t.Errorf("expected an error for %s", payload)
+		}
+	}
+	if err := json.Unmarshal([]byte(`[1]`), &label); !errors.Is(err, ErrInvalidParameterLabel) {
+		t.Errorf("expected ErrInvalidParameterLabel, got %v", err)
+	}
+}
@@ -3,3 +3,21 @@ package messages
 type TextDocumentIdentifier struct {
 	URI string `json:"uri"`
 }
+
+// TextDocumentPositionParams is embedded in requests that refer to a position
+// in a document.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocumentPositionParams
+type TextDocumentPositionParams struct {
+	// The text document.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The position inside the text document.
+	Position Position `json:"position"`
+}
+
+type OptionalVersionedTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+	// The version number of the document, or null if the document isn't open
+	// and the server is using the text on disk.
+	Version *int `json:"version"`
+}
@@ -0,0 +1,145 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocumentSyncOptions
+//
+// TextDocumentSyncOptions is the textDocumentSync server capability. Options
+// that can be expressed as a TextDocumentSyncKind are marshalled as the
+// number, which older clients understand, and as an object otherwise.
+type TextDocumentSyncOptions struct {
+	// Open and close notifications are sent to the server.
+	OpenClose bool `json:"openClose,omitempty"`
+	// Change notifications are sent to the server.
+	Change TextDocumentSyncKind `json:"change"`
+	// Will save notifications are sent to the server.
+	WillSave bool `json:"willSave,omitempty"`
+	// Will save wait until requests are sent to the server.
+	WillSaveWaitUntil bool `json:"willSaveWaitUntil,omitempty"`
+	// Save notifications are sent to the server if it's not nil.
+	Save *SaveOptions `json:"save,omitempty"`
+}
+
+// ErrInvalidTextDocumentSync is returned when the textDocumentSync capability
+// isn't a number or an object.
+var ErrInvalidTextDocumentSync = errors.New("messages: invalid text document sync")
+
+// Kind returns the TextDocumentSyncKind equivalent to the options, and false
+// if the options can't be expressed as one. A kind other than None implies
+// that open and close notifications are sent.
+func (o TextDocumentSyncOptions) Kind() (kind TextDocumentSyncKind, ok bool) {
+	if o.WillSave || o.WillSaveWaitUntil || o.Save != nil {
+		return o.Change, false
+	}
+	return o.Change, o.OpenClose == (o.Change != TextDocumentSyncKindNone)
+}
+
+func (o TextDocumentSyncOptions) MarshalJSON() ([]byte, error) {
+	if kind, ok := o.Kind(); ok {
+		return json.Marshal(kind)
+	}
+	type options TextDocumentSyncOptions
+	return json.Marshal(options(o))
+}
+
+func (o *TextDocumentSyncOptions) UnmarshalJSON(data []byte) error {
+	*o = TextDocumentSyncOptions{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidTextDocumentSync
+	}
+	switch {
+	case data[0] == '{':
+		var options struct {
+			OpenClose         bool                 `json:"openClose"`
+			Change            TextDocumentSyncKind `json:"change"`
+			WillSave          bool                 `json:"willSave"`
+			WillSaveWaitUntil bool                 `json:"willSaveWaitUntil"`
+			Save              *BoolOrSaveOptions   `json:"save"`
+		}
+		if err := json.Unmarshal(data, &options); err != nil {
+			return err
+		}
+		o.OpenClose = options.OpenClose
+		o.Change = options.Change
+		o.WillSave = options.WillSave
+		o.WillSaveWaitUntil = options.WillSaveWaitUntil
+		if options.Save != nil {
+			o.Save = options.Save.SaveOptions()
+		}
+		return nil
+	case data[0] == '-' || (data[0] >= '0' && data[0] <= '9'):
+		if err := json.Unmarshal(data, &o.Change); err != nil {
+			return err
+		}
+		o.OpenClose = o.Change != TextDocumentSyncKindNone
+		return nil
+	}
+	return ErrInvalidTextDocumentSync
+}
+
+type SaveOptions struct {
+	// The client is supposed to include the content on save.
+	IncludeText bool `json:"includeText,omitempty"`
+}
+
+// BoolOrSaveOptions is the save field of the TextDocumentSyncOptions, which
+// servers can set to true instead of sending options.
+type BoolOrSaveOptions struct {
+	Bool    bool
+	Options *SaveOptions
+}
+
+// SaveOptions returns the options, or empty options if save notifications are
+// enabled without options, or nil if they're not enabled.
+func (b BoolOrSaveOptions) SaveOptions() *SaveOptions {
+	if b.Options != nil {
+		return b.Options
+	}
+	if b.Bool {
+		return &SaveOptions{}
+	}
+	return nil
+}
+
+func (b *BoolOrSaveOptions) UnmarshalJSON(data []byte) error {
+	*b = BoolOrSaveOptions{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidTextDocumentSync
+	}
+	switch data[0] {
+	case 't', 'f':
+		return json.Unmarshal(data, &b.Bool)
+	case '{':
+		b.Options = &SaveOptions{}
+		return json.Unmarshal(data, b.Options)
+	}
+	return ErrInvalidTextDocumentSync
+}
+
+type TextDocumentSyncKind int
+
+const (
+	TextDocumentSyncKindNone TextDocumentSyncKind = iota
+	TextDocumentSyncKindFull
+	TextDocumentSyncKindIncremental
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocumentSyncClientCapabilities
+type TextDocumentSyncClientCapabilities struct {
+	// Whether text document synchronization supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The client supports sending will save notifications.
+	WillSave bool `json:"willSave,omitempty"`
+	// The client supports sending a will save request and waits for a
+	// response providing text edits which will be applied to the document
+	// before it is saved.
+	WillSaveWaitUntil bool `json:"willSaveWaitUntil,omitempty"`
+	// The client supports did save notifications.
+	DidSave bool `json:"didSave,omitempty"`
+}
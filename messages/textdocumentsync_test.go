@@ -0,0 +1,92 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTextDocumentSyncJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected TextDocumentSyncOptions
+	}{
+		{
+			name:     "none",
+			payload:  `0`,
+			expected: TextDocumentSyncOptions{},
+		},
+		{
+			name:     "full",
+			payload:  `1`,
+			expected: TextDocumentSyncOptions{OpenClose: true, Change: TextDocumentSyncKindFull},
+		},
+		{
+			name:     "incremental",
+			payload:  `2`,
+			expected: TextDocumentSyncOptions{OpenClose: true, Change: TextDocumentSyncKindIncremental},
+		},
+		{
+			name:     "changes without open and close",
+			payload:  `{"change":1}`,
+			expected: TextDocumentSyncOptions{Change: TextDocumentSyncKindFull},
+		},
+		{
+			name:    "save",
+			payload: `{"openClose":true,"change":1,"save":{"includeText":true}}`,
+			expected: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    TextDocumentSyncKindFull,
+				Save:      &SaveOptions{IncludeText: true},
+			},
+		},
+		{
+			name:    "will save",
+			payload: `{"openClose":true,"change":2,"willSave":true,"willSaveWaitUntil":true}`,
+			expected: TextDocumentSyncOptions{
+				OpenClose:         true,
+				Change:            TextDocumentSyncKindIncremental,
+				WillSave:          true,
+				WillSaveWaitUntil: true,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sync TextDocumentSyncOptions
+			roundTrip(t, []byte(test.payload), &sync)
+			if !reflect.DeepEqual(sync, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, sync)
+			}
+		})
+	}
+	t.Run("save can be a boolean", func(t *testing.T) {
+		var sync TextDocumentSyncOptions
+		if err := json.Unmarshal([]byte(`{"change":1,"save":true}`), &sync); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if sync.Save == nil || sync.Save.IncludeText {
+			t.Errorf("expected save notifications without text, got %#v", sync.Save)
+		}
+		if err := json.Unmarshal([]byte(`{"change":1,"save":false}`), &sync); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if sync.Save != nil {
+			t.Errorf("expected no save notifications, got %#v", sync.Save)
+		}
+	})
+	var sync TextDocumentSyncOptions
+	if err := json.Unmarshal([]byte(`"full"`), &sync); !errors.Is(err, ErrInvalidTextDocumentSync) {
+		t.Errorf("expected ErrInvalidTextDocumentSync, got %v", err)
+	}
+}
+
+func TestWillSaveTextDocumentParams(t *testing.T) {
+	var params WillSaveTextDocumentParams
+	roundTrip(t, []byte(`{"textDocument":{"uri":"file:///pasta.cook"},"reason":3}`), &params)
+	if params.TextDocument.URI != "file:///pasta.cook" || params.Reason != TextDocumentSaveReasonFocusOut {
+		t.Errorf("unexpected params: %#v", params)
+	}
+}
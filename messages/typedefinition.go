@@ -0,0 +1,55 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const TypeDefinitionRequestMethod = "textDocument/typeDefinition"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_typeDefinition
+type TypeDefinitionParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+// TypeDefinitionResult has the same shape as the result of a definition
+// request.
+type TypeDefinitionResult = DefinitionResult
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#typeDefinitionClientCapabilities
+type TypeDefinitionClientCapabilities struct {
+	// Whether type definition supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The client supports additional metadata in the form of definition links.
+	LinkSupport bool `json:"linkSupport,omitempty"`
+}
+
+type TypeDefinitionOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+}
+
+// BoolOrTypeDefinitionOptions is the typeDefinitionProvider server
+// capability. It's marshalled as the options if they're set, and as Bool
+// otherwise.
+type BoolOrTypeDefinitionOptions struct {
+	Bool    bool
+	Options *TypeDefinitionOptions
+}
+
+// ErrInvalidTypeDefinitionProvider is returned when the
+// typeDefinitionProvider capability isn't a boolean or an object.
+var ErrInvalidTypeDefinitionProvider = errors.New("messages: invalid type definition provider")
+
+func (b BoolOrTypeDefinitionOptions) MarshalJSON() ([]byte, error) {
+	if b.Options != nil {
+		return json.Marshal(b.Options)
+	}
+	return json.Marshal(b.Bool)
+}
+
+func (b *BoolOrTypeDefinitionOptions) UnmarshalJSON(data []byte) error {
+	*b = BoolOrTypeDefinitionOptions{}
+	return unmarshalBoolOrOptions(data, &b.Bool, &b.Options, ErrInvalidTypeDefinitionProvider)
+}
@@ -0,0 +1,78 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTypeDefinitionParamsJSON(t *testing.T) {
+	var params TypeDefinitionParams
+	roundTrip(t, readPayload(t, "type-definition-params.json"), &params)
+	if params.TextDocument.URI != "file:///Users/alice/recipes/pasta.cook" || params.Position != NewPosition(3, 12) {
+		t.Errorf("unexpected params: %#v", params)
+	}
+	if params.WorkDoneToken != nil || params.PartialResultToken != nil {
+		t.Errorf("expected no tokens, got %v and %v", params.WorkDoneToken, params.PartialResultToken)
+	}
+}
+
+func TestTypeDefinitionResultJSON(t *testing.T) {
+	var r TypeDefinitionResult
+	roundTrip(t, readPayload(t, "definition-result-links.json"), &r)
+	if len(r.LocationLinks) != 1 {
+		t.Errorf("expected a link, got %#v", r)
+	}
+}
+
+func TestTypeDefinitionClientCapabilitiesJSON(t *testing.T) {
+	var params InitializeParams
+	if err := json.Unmarshal(readPayload(t, "initialize-params.json"), &params); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	expected := &TypeDefinitionClientCapabilities{DynamicRegistration: true, LinkSupport: true}
+	if !reflect.DeepEqual(params.Capabilities.TextDocument.TypeDefinition, expected) {
+		t.Errorf("expected %#v, got %#v", expected, params.Capabilities.TextDocument.TypeDefinition)
+	}
+}
+
+func TestTypeDefinitionProviderJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected BoolOrTypeDefinitionOptions
+	}{
+		{
+			name:     "true",
+			payload:  `true`,
+			expected: BoolOrTypeDefinitionOptions{Bool: true},
+		},
+		{
+			name:     "false",
+			payload:  `false`,
+			expected: BoolOrTypeDefinitionOptions{},
+		},
+		{
+			name:     "options",
+			payload:  `{"workDoneProgress":true}`,
+			expected: BoolOrTypeDefinitionOptions{Options: &TypeDefinitionOptions{WorkDoneProgress: true}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var provider BoolOrTypeDefinitionOptions
+			roundTrip(t, []byte(test.payload), &provider)
+			if !reflect.DeepEqual(provider, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, provider)
+			}
+		})
+	}
+	var provider BoolOrTypeDefinitionOptions
+	if err := json.Unmarshal([]byte(`{"documentSelector":[{"language":"cooklang"}],"id":"types"}`), &provider); err != nil || provider.Options == nil {
+		t.Errorf("expected registration options to be accepted, got %#v, %v", provider, err)
+	}
+	if err := json.Unmarshal([]byte(`1`), &provider); !errors.Is(err, ErrInvalidTypeDefinitionProvider) {
+		t.Errorf("expected ErrInvalidTypeDefinitionProvider, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DocumentURI is the URI of a document. Clients don't always encode the same
+// URI in the same way, e.g. VS Code percent-encodes the colon after Windows
+// drive letters, so URIs should be compared with Equal, or normalized before
+// being used as map keys.
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#documentUri
+type DocumentURI string
+
+// ErrInvalidDocumentURI is returned when a URI can't be parsed, or doesn't
+// have a scheme.
+var ErrInvalidDocumentURI = errors.New("messages: invalid document URI")
+
+// ParseDocumentURI parses and normalizes a URI.
+func ParseDocumentURI(s string) (DocumentURI, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDocumentURI, s)
+	}
+	// The scheme is lowercased by url.Parse. The path is decoded, and
+	// encoded again, so that escapes of unreserved characters are removed,
+	// and the same characters are always escaped.
+	u.Host = strings.ToLower(u.Host)
+	u.Path = normalizeDriveLetter(u.Path)
+	u.RawPath = ""
+	return DocumentURI(u.String()), nil
+}
+
+// DocumentURIFromPath returns the file URI of an absolute path.
+func DocumentURIFromPath(path string) DocumentURI {
+	path = filepath.ToSlash(path)
+	if isDrivePath(path) {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: normalizeDriveLetter(path)}
+	return DocumentURI(u.String())
+}
+
+// Normalize returns the normalized form of the URI, or the URI unchanged if
+// it can't be parsed.
+func (u DocumentURI) Normalize() DocumentURI {
+	normalized, err := ParseDocumentURI(string(u))
+	if err != nil {
+		return u
+	}
+	return normalized
+}
+
+// Equal returns true if the URIs are the same once normalized.
+func (u DocumentURI) Equal(other DocumentURI) bool {
+	return u.Normalize() == other.Normalize()
+}
+
+// Filename returns the path of a file URI, in the form used by the operating
+// system.
+func (u DocumentURI) Filename() (path string, err error) {
+	parsed, err := url.Parse(string(u))
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDocumentURI, u)
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("messages: unsupported URI scheme %q", parsed.Scheme)
+	}
+	path = normalizeDriveLetter(parsed.Path)
+	if runtime.GOOS == "windows" && isDrivePath(strings.TrimPrefix(path, "/")) {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// normalizeDriveLetter uppercases the drive letter at the start of a Windows
+// path, e.g. "/c:/Users" becomes "/C:/Users".
+func normalizeDriveLetter(path string) string {
+	if !strings.HasPrefix(path, "/") || !isDrivePath(path[1:]) {
+		return path
+	}
+	return "/" + strings.ToUpper(path[1:2]) + path[2:]
+}
+
+// isDrivePath returns true if the path starts with a Windows drive letter,
+// e.g. "C:/Users".
+func isDrivePath(path string) bool {
+	if len(path) < 2 || path[1] != ':' {
+		return false
+	}
+	c := path[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,167 @@
+package messages
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseDocumentURI(t *testing.T) {
+	tests := []struct {
+		name     string
+		uri      string
+		expected DocumentURI
+	}{
+		{
+			name:     "normalized URIs are unchanged",
+			uri:      "file:///home/alice/recipes/pizza.cook",
+			expected: "file:///home/alice/recipes/pizza.cook",
+		},
+		{
+			name:     "the scheme is lowercased",
+			uri:      "FILE:///home/alice/recipes/pizza.cook",
+			expected: "file:///home/alice/recipes/pizza.cook",
+		},
+		{
+			name:     "spaces are always escaped",
+			uri:      "file:///home/alice/my recipes/pizza.cook",
+			expected: "file:///home/alice/my%20recipes/pizza.cook",
+		},
+		{
+			name:     "escaped spaces are unchanged",
+			uri:      "file:///home/alice/my%20recipes/pizza.cook",
+			expected: "file:///home/alice/my%20recipes/pizza.cook",
+		},
+		{
+			name:     "unreserved characters are unescaped",
+			uri:      "file:///home/%7Ealice/recipes/pizza%2Dmargherita.cook",
+			expected: "file:///home/~alice/recipes/pizza-margherita.cook",
+		},
+		{
+			name:     "non-ASCII characters are escaped",
+			uri:      "file:///home/alice/recettes/crème brûlée.cook",
+			expected: "file:///home/alice/recettes/cr%C3%A8me%20br%C3%BBl%C3%A9e.cook",
+		},
+		{
+			name:     "escapes of non-ASCII characters are unchanged",
+			uri:      "file:///home/alice/recettes/cr%C3%A8me.cook",
+			expected: "file:///home/alice/recettes/cr%C3%A8me.cook",
+		},
+		{
+			name:     "Windows drive letters are uppercased",
+			uri:      "file:///c:/Users/alice/pizza.cook",
+			expected: "file:///C:/Users/alice/pizza.cook",
+		},
+		{
+			name:     "escaped colons after Windows drive letters are unescaped",
+			uri:      "file:///c%3A/Users/alice/pizza.cook",
+			expected: "file:///C:/Users/alice/pizza.cook",
+		},
+		{
+			name:     "URIs that aren't files are normalized",
+			uri:      "Untitled:Untitled-1",
+			expected: "untitled:Untitled-1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ParseDocumentURI(test.uri)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+			if again := actual.Normalize(); again != actual {
+				t.Errorf("expected normalizing again to return %q, got %q", actual, again)
+			}
+		})
+	}
+}
+
+func TestParseDocumentURIInvalid(t *testing.T) {
+	for _, uri := range []string{"", "pizza.cook", "file://%zz/pizza.cook"} {
+		if _, err := ParseDocumentURI(uri); !errors.Is(err, ErrInvalidDocumentURI) {
+			t.Errorf("%q: expected ErrInvalidDocumentURI, got %v", uri, err)
+		}
+	}
+	if actual := DocumentURI("pizza.cook").Normalize(); actual != "pizza.cook" {
+		t.Errorf("expected invalid URIs to be unchanged by Normalize, got %q", actual)
+	}
+}
+
+func TestDocumentURIEqual(t *testing.T) {
+	tests := []struct {
+		a, b     DocumentURI
+		expected bool
+	}{
+		{a: "file:///c%3A/Users/alice/pizza.cook", b: "file:///C:/Users/alice/pizza.cook", expected: true},
+		{a: "file:///home/alice/my%20recipes/pizza.cook", b: "file:///home/alice/my recipes/pizza.cook", expected: true},
+		{a: "file:///home/alice/cr%c3%a8me.cook", b: "file:///home/alice/crème.cook", expected: true},
+		{a: "file:///home/alice/pizza.cook", b: "file:///home/alice/Pizza.cook", expected: false},
+		{a: "file:///home/alice/pizza.cook", b: "untitled:///home/alice/pizza.cook", expected: false},
+	}
+	for _, test := range tests {
+		if actual := test.a.Equal(test.b); actual != test.expected {
+			t.Errorf("%q = %q: expected %v, got %v", test.a, test.b, test.expected, actual)
+		}
+	}
+}
+
+func TestDocumentURIFilename(t *testing.T) {
+	tests := []struct {
+		uri      DocumentURI
+		expected string
+	}{
+		{uri: "file:///home/alice/recipes/pizza.cook", expected: "/home/alice/recipes/pizza.cook"},
+		{uri: "file:///home/alice/my%20recipes/pizza.cook", expected: "/home/alice/my recipes/pizza.cook"},
+		{uri: "file:///home/alice/recettes/cr%C3%A8me%20br%C3%BBl%C3%A9e.cook", expected: "/home/alice/recettes/crème brûlée.cook"},
+	}
+	if runtime.GOOS == "windows" {
+		tests = []struct {
+			uri      DocumentURI
+			expected string
+		}{
+			{uri: "file:///c%3A/Users/alice/my%20recipes/pizza.cook", expected: `C:\Users\alice\my recipes\pizza.cook`},
+			{uri: "file:///C:/Users/alice/recettes/cr%C3%A8me.cook", expected: `C:\Users\alice\recettes\crème.cook`},
+		}
+	}
+	for _, test := range tests {
+		actual, err := test.uri.Filename()
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.uri, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.uri, test.expected, actual)
+		}
+		if uri := DocumentURIFromPath(actual); !uri.Equal(test.uri) {
+			t.Errorf("%q: expected the path to convert back to the URI, got %q", test.uri, uri)
+		}
+	}
+	if _, err := DocumentURI("untitled:Untitled-1").Filename(); err == nil {
+		t.Error("expected an error for a URI that isn't a file")
+	}
+}
+
+func TestDocumentURIFromPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected DocumentURI
+	}{
+		{path: "/home/alice/my recipes/pizza.cook", expected: "file:///home/alice/my%20recipes/pizza.cook"},
+		{path: "/home/alice/recettes/crème.cook", expected: "file:///home/alice/recettes/cr%C3%A8me.cook"},
+		{path: "c:/Users/alice/pizza.cook", expected: "file:///C:/Users/alice/pizza.cook"},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct {
+			path     string
+			expected DocumentURI
+		}{path: filepath.FromSlash("c:/Users/alice/pizza.cook"), expected: "file:///C:/Users/alice/pizza.cook"})
+	}
+	for _, test := range tests {
+		if actual := DocumentURIFromPath(test.path); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.path, test.expected, actual)
+		}
+	}
+}
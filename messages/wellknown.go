@@ -21,3 +21,43 @@ type Location struct {
 	URI   string `json:"uri"`
 	Range Range  `json:"range"`
 }
+
+// Compare returns -1 if p is before other, 0 if they're the same, and 1 if p
+// is after other.
+func (p Position) Compare(other Position) int {
+	switch {
+	case p.Line < other.Line:
+		return -1
+	case p.Line > other.Line:
+		return 1
+	case p.Character < other.Character:
+		return -1
+	case p.Character > other.Character:
+		return 1
+	}
+	return 0
+}
+
+// Before returns true if p is before other.
+func (p Position) Before(other Position) bool {
+	return p.Compare(other) < 0
+}
+
+// Contains returns true if the position is within the range. Like all LSP
+// ranges, the start is inclusive and the end is exclusive, so an empty range
+// doesn't contain any positions.
+func (r Range) Contains(p Position) bool {
+	return !p.Before(r.Start) && p.Before(r.End)
+}
+
+// Overlaps returns true if the ranges have any positions in common. Ranges
+// that only touch, where one ends where the other starts, don't overlap.
+func (r Range) Overlaps(other Range) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// IsEmpty returns true if the range doesn't contain any positions, because
+// its end isn't after its start.
+func (r Range) IsEmpty() bool {
+	return !r.Start.Before(r.End)
+}
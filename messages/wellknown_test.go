@@ -0,0 +1,112 @@
+package messages
+
+import "testing"
+
+func TestPositionCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		p, other Position
+		expected int
+	}{
+		{name: "same position", p: NewPosition(2, 4), other: NewPosition(2, 4), expected: 0},
+		{name: "earlier character", p: NewPosition(2, 3), other: NewPosition(2, 4), expected: -1},
+		{name: "later character", p: NewPosition(2, 5), other: NewPosition(2, 4), expected: 1},
+		{name: "earlier line, later character", p: NewPosition(1, 9), other: NewPosition(2, 4), expected: -1},
+		{name: "later line, earlier character", p: NewPosition(3, 0), other: NewPosition(2, 4), expected: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.p.Compare(test.other); actual != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, actual)
+			}
+			if actual := test.p.Before(test.other); actual != (test.expected < 0) {
+				t.Errorf("expected Before to return %v, got %v", test.expected < 0, actual)
+			}
+		})
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	singleLine := Range{Start: NewPosition(1, 4), End: NewPosition(1, 10)}
+	multiLine := Range{Start: NewPosition(1, 4), End: NewPosition(3, 2)}
+	tests := []struct {
+		name     string
+		r        Range
+		p        Position
+		expected bool
+	}{
+		{name: "single line: start", r: singleLine, p: NewPosition(1, 4), expected: true},
+		{name: "single line: middle", r: singleLine, p: NewPosition(1, 7), expected: true},
+		{name: "single line: last character", r: singleLine, p: NewPosition(1, 9), expected: true},
+		{name: "single line: end", r: singleLine, p: NewPosition(1, 10), expected: false},
+		{name: "single line: before start", r: singleLine, p: NewPosition(1, 3), expected: false},
+		{name: "single line: line before", r: singleLine, p: NewPosition(0, 7), expected: false},
+		{name: "single line: line after", r: singleLine, p: NewPosition(2, 7), expected: false},
+		{name: "multi line: start", r: multiLine, p: NewPosition(1, 4), expected: true},
+		{name: "multi line: first line before start", r: multiLine, p: NewPosition(1, 0), expected: false},
+		{name: "multi line: first line after the end character", r: multiLine, p: NewPosition(1, 20), expected: true},
+		{name: "multi line: middle line, any character", r: multiLine, p: NewPosition(2, 50), expected: true},
+		{name: "multi line: middle line, start of line", r: multiLine, p: NewPosition(2, 0), expected: true},
+		{name: "multi line: last line before end", r: multiLine, p: NewPosition(3, 1), expected: true},
+		{name: "multi line: end", r: multiLine, p: NewPosition(3, 2), expected: false},
+		{name: "multi line: last line after end", r: multiLine, p: NewPosition(3, 3), expected: false},
+		{name: "multi line: last line after the start character", r: multiLine, p: NewPosition(3, 6), expected: false},
+		{name: "empty range", r: Range{Start: NewPosition(1, 4), End: NewPosition(1, 4)}, p: NewPosition(1, 4), expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.r.Contains(test.p); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRangeOverlaps(t *testing.T) {
+	r := Range{Start: NewPosition(1, 4), End: NewPosition(3, 2)}
+	tests := []struct {
+		name     string
+		other    Range
+		expected bool
+	}{
+		{name: "same range", other: r, expected: true},
+		{name: "inside", other: Range{Start: NewPosition(2, 0), End: NewPosition(2, 5)}, expected: true},
+		{name: "around", other: Range{Start: NewPosition(0, 0), End: NewPosition(5, 0)}, expected: true},
+		{name: "overlapping the start", other: Range{Start: NewPosition(0, 0), End: NewPosition(1, 5)}, expected: true},
+		{name: "overlapping the end", other: Range{Start: NewPosition(3, 1), End: NewPosition(4, 0)}, expected: true},
+		{name: "ending at the start", other: Range{Start: NewPosition(0, 0), End: NewPosition(1, 4)}, expected: false},
+		{name: "starting at the end", other: Range{Start: NewPosition(3, 2), End: NewPosition(4, 0)}, expected: false},
+		{name: "same lines, before the start", other: Range{Start: NewPosition(1, 0), End: NewPosition(1, 3)}, expected: false},
+		{name: "same lines, after the end", other: Range{Start: NewPosition(3, 5), End: NewPosition(3, 9)}, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := r.Overlaps(test.other); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+			if actual := test.other.Overlaps(r); actual != test.expected {
+				t.Errorf("expected the reverse to be %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRangeIsEmpty(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        Range
+		expected bool
+	}{
+		{name: "same start and end", r: Range{Start: NewPosition(1, 4), End: NewPosition(1, 4)}, expected: true},
+		{name: "end before start", r: Range{Start: NewPosition(1, 4), End: NewPosition(1, 2)}, expected: true},
+		{name: "one character", r: Range{Start: NewPosition(1, 4), End: NewPosition(1, 5)}, expected: false},
+		{name: "to the start of the next line", r: Range{Start: NewPosition(1, 4), End: NewPosition(2, 0)}, expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.r.IsEmpty(); actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package messages
+
+const (
+	WillSaveTextDocumentNotification           = "textDocument/willSave"
+	WillSaveWaitUntilTextDocumentRequestMethod = "textDocument/willSaveWaitUntil"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#willSaveTextDocumentParams
+type WillSaveTextDocumentParams struct {
+	// The document that will be saved.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// The reason the document is being saved.
+	Reason TextDocumentSaveReason `json:"reason"`
+}
+
+// TextDocumentSaveReason represents reasons why a text document is saved.
+type TextDocumentSaveReason int
+
+const (
+	// Manually triggered, e.g. by the user pressing save, by starting
+	// debugging, or by an API call.
+	TextDocumentSaveReasonManual TextDocumentSaveReason = 1
+	// Automatic after a delay.
+	TextDocumentSaveReasonAfterDelay TextDocumentSaveReason = 2
+	// When the editor lost focus.
+	TextDocumentSaveReasonFocusOut TextDocumentSaveReason = 3
+)
@@ -0,0 +1,105 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const WorkspaceDiagnosticRequestMethod = "workspace/diagnostic"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_diagnostic
+//
+// The result of the request is a WorkspaceDiagnosticReport. Partial results
+// are sent as WorkspaceDiagnosticReportPartialResult.
+type WorkspaceDiagnosticParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// The additional identifier provided during registration.
+	Identifier *string `json:"identifier,omitempty"`
+	// The currently known diagnostic reports with their previous result ids.
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// PreviousResultID is the result id of the last report for a document.
+type PreviousResultID struct {
+	// The URI for which the client knows a result id.
+	URI string `json:"uri"`
+	// The value of the previous result id.
+	Value string `json:"value"`
+}
+
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+// WorkspaceDiagnosticReportPartialResult is a batch of reports, streamed to
+// the client before the result of the request.
+type WorkspaceDiagnosticReportPartialResult struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+// WorkspaceFullDocumentDiagnosticReport is a full report for a document in
+// the workspace.
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	// The URI for which diagnostic information is reported.
+	URI string `json:"uri"`
+	// The version number for which the diagnostics are reported. If the
+	// document isn't marked as open, it's null.
+	Version *int `json:"version"`
+}
+
+// WorkspaceUnchangedDocumentDiagnosticReport is an unchanged report for a
+// document in the workspace.
+type WorkspaceUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+	// The URI for which diagnostic information is reported.
+	URI string `json:"uri"`
+	// The version number for which the diagnostics are reported. If the
+	// document isn't marked as open, it's null.
+	Version *int `json:"version"`
+}
+
+// WorkspaceDocumentDiagnosticReport is a full or an unchanged report, told
+// apart by the kind field. Only one of the fields should be set.
+type WorkspaceDocumentDiagnosticReport struct {
+	Full      *WorkspaceFullDocumentDiagnosticReport
+	Unchanged *WorkspaceUnchangedDocumentDiagnosticReport
+}
+
+// ErrInvalidWorkspaceDocumentDiagnosticReport is returned when a report
+// isn't a full or an unchanged report.
+var ErrInvalidWorkspaceDocumentDiagnosticReport = errors.New("messages: invalid workspace document diagnostic report")
+
+func (r WorkspaceDocumentDiagnosticReport) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.Full != nil:
+		full := *r.Full
+		full.Kind = DocumentDiagnosticReportKindFull
+		return json.Marshal(full)
+	case r.Unchanged != nil:
+		unchanged := *r.Unchanged
+		unchanged.Kind = DocumentDiagnosticReportKindUnchanged
+		return json.Marshal(unchanged)
+	}
+	return nil, ErrInvalidWorkspaceDocumentDiagnosticReport
+}
+
+func (r *WorkspaceDocumentDiagnosticReport) UnmarshalJSON(data []byte) error {
+	*r = WorkspaceDocumentDiagnosticReport{}
+	var discriminator struct {
+		Kind DocumentDiagnosticReportKind `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &discriminator); err != nil {
+		return ErrInvalidWorkspaceDocumentDiagnosticReport
+	}
+	switch discriminator.Kind {
+	case DocumentDiagnosticReportKindFull:
+		r.Full = &WorkspaceFullDocumentDiagnosticReport{}
+		return json.Unmarshal(data, r.Full)
+	case DocumentDiagnosticReportKindUnchanged:
+		r.Unchanged = &WorkspaceUnchangedDocumentDiagnosticReport{}
+		return json.Unmarshal(data, r.Unchanged)
+	}
+	return ErrInvalidWorkspaceDocumentDiagnosticReport
+}
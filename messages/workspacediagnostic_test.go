@@ -0,0 +1,75 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWorkspaceDiagnosticParamsJSON(t *testing.T) {
+	var params WorkspaceDiagnosticParams
+	roundTrip(t, []byte(`{
+		"identifier": "examplelsp",
+		"previousResultIds": [
+			{"uri": "file:///Users/alice/recipes/pasta.cook", "value": "7"}
+		],
+		"partialResultToken": "3d5c"
+	}`), &params)
+	if len(params.PreviousResultIDs) != 1 || params.PreviousResultIDs[0].Value != "7" {
+		t.Errorf("unexpected previous result ids: %#v", params.PreviousResultIDs)
+	}
+}
+
+func TestWorkspaceDiagnosticReportJSON(t *testing.T) {
+	var report WorkspaceDiagnosticReport
+	roundTrip(t, []byte(`{
+		"items": [
+			{"kind": "full", "uri": "file:///Users/alice/recipes/pasta.cook", "version": 4, "resultId": "8", "items": []},
+			{"kind": "unchanged", "uri": "file:///Users/alice/recipes/soup.cook", "version": null, "resultId": "2"}
+		]
+	}`), &report)
+	if len(report.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(report.Items))
+	}
+	full, unchanged := report.Items[0].Full, report.Items[1].Unchanged
+	if full == nil || full.URI != "file:///Users/alice/recipes/pasta.cook" || full.Version == nil || *full.Version != 4 {
+		t.Errorf("expected a full report for an open document, got %#v", report.Items[0])
+	}
+	if unchanged == nil || unchanged.Version != nil || unchanged.ResultID != "2" {
+		t.Errorf("expected an unchanged report for a closed document, got %#v", report.Items[1])
+	}
+}
+
+func TestWorkspaceDiagnosticReportPartialResultJSON(t *testing.T) {
+	// Clients receive batches of reports in $/progress notifications while
+	// the workspace is analyzed.
+	var batches []WorkspaceDiagnosticReportPartialResult
+	roundTrip(t, []byte(`[
+		{"items": [{"kind": "full", "uri": "file:///Users/alice/recipes/a.cook", "version": null, "items": []}]},
+		{"items": []},
+		{"items": [{"kind": "unchanged", "uri": "file:///Users/alice/recipes/b.cook", "version": 1, "resultId": "5"}]}
+	]`), &batches)
+	if len(batches) != 3 || batches[0].Items[0].Full == nil || len(batches[1].Items) != 0 || batches[2].Items[0].Unchanged == nil {
+		t.Errorf("unexpected batches: %#v", batches)
+	}
+}
+
+func TestWorkspaceDocumentDiagnosticReportVersionIsNull(t *testing.T) {
+	data, err := json.Marshal(WorkspaceDocumentDiagnosticReport{
+		Unchanged: &WorkspaceUnchangedDocumentDiagnosticReport{
+			UnchangedDocumentDiagnosticReport: UnchangedDocumentDiagnosticReport{ResultID: "2"},
+			URI:                               "file:///Users/alice/recipes/soup.cook",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	expected := `{"kind":"unchanged","resultId":"2","uri":"file:///Users/alice/recipes/soup.cook","version":null}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+	var r WorkspaceDocumentDiagnosticReport
+	if err := json.Unmarshal([]byte(`{"kind":"partial"}`), &r); !errors.Is(err, ErrInvalidWorkspaceDocumentDiagnosticReport) {
+		t.Errorf("expected ErrInvalidWorkspaceDocumentDiagnosticReport, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textEdit
+type TextEdit struct {
+	// The range of the text document to be manipulated. To insert
+	// text into a document create a range where start === end.
+	Range Range `json:"range"`
+	// The string to be inserted. For delete operations use an
+	// empty string.
+	NewText string `json:"newText"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#annotatedTextEdit
+//
+// An AnnotatedTextEdit without an AnnotationID is marshalled as a TextEdit.
+type AnnotatedTextEdit struct {
+	TextEdit
+	// The actual annotation identifier, a key of the ChangeAnnotations of the
+	// WorkspaceEdit.
+	AnnotationID string `json:"annotationId,omitempty"`
+}
+
+// AnnotatedTextEdits returns the edits without annotations.
+func AnnotatedTextEdits(edits []TextEdit) (annotated []AnnotatedTextEdit) {
+	annotated = make([]AnnotatedTextEdit, len(edits))
+	for i, edit := range edits {
+		annotated[i] = AnnotatedTextEdit{TextEdit: edit}
+	}
+	return annotated
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#changeAnnotation
+type ChangeAnnotation struct {
+	// A human-readable string describing the actual change. The string is
+	// rendered prominent in the user interface.
+	Label string `json:"label"`
+	// A flag which indicates that user confirmation is needed before applying
+	// the change.
+	NeedsConfirmation bool `json:"needsConfirmation,omitempty"`
+	// A human-readable string which is rendered less prominent in the user
+	// interface.
+	Description string `json:"description,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceEdit
+type WorkspaceEdit struct {
+	// Holds changes to existing resources, keyed by document URI.
+	Changes map[string][]TextEdit `json:"changes,omitempty"`
+	// DocumentChanges are changes to versioned documents, and file
+	// operations if the client supports them. If the client supports
+	// documentChanges, they're preferred over Changes.
+	DocumentChanges []DocumentChange `json:"documentChanges,omitempty"`
+	// A map of change annotations that can be referenced in AnnotatedTextEdits
+	// or file operations.
+	ChangeAnnotations map[string]ChangeAnnotation `json:"changeAnnotations,omitempty"`
+}
+
+// NewWorkspaceEdit returns an edit that changes a single document, for
+// clients that don't support documentChanges.
+func NewWorkspaceEdit(uri string, edits ...TextEdit) WorkspaceEdit {
+	return WorkspaceEdit{
+		Changes: map[string][]TextEdit{uri: edits},
+	}
+}
+
+// NewVersionedWorkspaceEdit returns an edit that changes a single document,
+// which the client rejects if the document's version isn't the same.
+func NewVersionedWorkspaceEdit(document OptionalVersionedTextDocumentIdentifier, edits ...TextEdit) WorkspaceEdit {
+	return WorkspaceEdit{
+		DocumentChanges: []DocumentChange{
+			{TextDocumentEdit: &TextDocumentEdit{TextDocument: document, Edits: AnnotatedTextEdits(edits)}},
+		},
+	}
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocumentEdit
+type TextDocumentEdit struct {
+	// The text document to change.
+	TextDocument OptionalVersionedTextDocumentIdentifier `json:"textDocument"`
+	// The edits to be applied.
+	Edits []AnnotatedTextEdit `json:"edits"`
+}
+
+// TextEdits returns the edits without their annotations.
+func (e TextDocumentEdit) TextEdits() (edits []TextEdit) {
+	edits = make([]TextEdit, len(e.Edits))
+	for i, edit := range e.Edits {
+		edits[i] = edit.TextEdit
+	}
+	return edits
+}
+
+// DocumentChange is an element of the documentChanges of a WorkspaceEdit.
+// Only one of the fields should be set. File operations are told apart by
+// their kind, which is set when they're marshalled, and TextDocumentEdits
+// don't have a kind.
+type DocumentChange struct {
+	TextDocumentEdit *TextDocumentEdit
+	CreateFile       *CreateFile
+	RenameFile       *RenameFile
+	DeleteFile       *DeleteFile
+}
+
+// ErrInvalidDocumentChange is returned when a document change isn't a
+// TextDocumentEdit or a file operation.
+var ErrInvalidDocumentChange = errors.New("messages: invalid document change")
+
+const (
+	ResourceOperationKindCreate = "create"
+	ResourceOperationKindRename = "rename"
+	ResourceOperationKindDelete = "delete"
+)
+
+func (c DocumentChange) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.TextDocumentEdit != nil:
+		return json.Marshal(c.TextDocumentEdit)
+	case c.CreateFile != nil:
+		create := *c.CreateFile
+		create.Kind = ResourceOperationKindCreate
+		return json.Marshal(create)
+	case c.RenameFile != nil:
+		rename := *c.RenameFile
+		rename.Kind = ResourceOperationKindRename
+		return json.Marshal(rename)
+	case c.DeleteFile != nil:
+		del := *c.DeleteFile
+		del.Kind = ResourceOperationKindDelete
+		return json.Marshal(del)
+	}
+	return nil, ErrInvalidDocumentChange
+}
+
+func (c *DocumentChange) UnmarshalJSON(data []byte) error {
+	*c = DocumentChange{}
+	var discriminator struct {
+		Kind *string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &discriminator); err != nil {
+		return ErrInvalidDocumentChange
+	}
+	if discriminator.Kind == nil {
+		c.TextDocumentEdit = &TextDocumentEdit{}
+		return json.Unmarshal(data, c.TextDocumentEdit)
+	}
+	switch *discriminator.Kind {
+	case ResourceOperationKindCreate:
+		c.CreateFile = &CreateFile{}
+		return json.Unmarshal(data, c.CreateFile)
+	case ResourceOperationKindRename:
+		c.RenameFile = &RenameFile{}
+		return json.Unmarshal(data, c.RenameFile)
+	case ResourceOperationKindDelete:
+		c.DeleteFile = &DeleteFile{}
+		return json.Unmarshal(data, c.DeleteFile)
+	}
+	return ErrInvalidDocumentChange
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#createFile
+type CreateFile struct {
+	// A create. It's set by DocumentChange when marshalled.
+	Kind string `json:"kind"`
+	// The resource to create.
+	URI string `json:"uri"`
+	// Additional options.
+	Options *CreateFileOptions `json:"options,omitempty"`
+	// An optional annotation identifier describing the operation.
+	AnnotationID string `json:"annotationId,omitempty"`
+}
+
+// Options to create a file.
+type CreateFileOptions struct {
+	// Overwrite existing file. Overwrite wins over IgnoreIfExists.
+	Overwrite bool `json:"overwrite,omitempty"`
+	// Ignore if exists.
+	IgnoreIfExists bool `json:"ignoreIfExists,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#renameFile
+type RenameFile struct {
+	// A rename. It's set by DocumentChange when marshalled.
+	Kind string `json:"kind"`
+	// The old (existing) location.
+	OldURI string `json:"oldUri"`
+	// The new location.
+	NewURI string `json:"newUri"`
+	// Rename options.
+	Options *RenameFileOptions `json:"options,omitempty"`
+	// An optional annotation identifier describing the operation.
+	AnnotationID string `json:"annotationId,omitempty"`
+}
+
+// Rename file options.
+type RenameFileOptions struct {
+	// Overwrite target if existing. Overwrite wins over IgnoreIfExists.
+	Overwrite bool `json:"overwrite,omitempty"`
+	// Ignores if target exists.
+	IgnoreIfExists bool `json:"ignoreIfExists,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#deleteFile
+type DeleteFile struct {
+	// A delete. It's set by DocumentChange when marshalled.
+	Kind string `json:"kind"`
+	// The file to delete.
+	URI string `json:"uri"`
+	// Delete options.
+	Options *DeleteFileOptions `json:"options,omitempty"`
+	// An optional annotation identifier describing the operation.
+	AnnotationID string `json:"annotationId,omitempty"`
+}
+
+// Delete file options.
+type DeleteFileOptions struct {
+	// Delete the content recursively if a folder is denoted.
+	Recursive bool `json:"recursive,omitempty"`
+	// Ignore the operation if the file doesn't exist.
+	IgnoreIfNotExists bool `json:"ignoreIfNotExists,omitempty"`
+}
@@ -0,0 +1,138 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWorkspaceEditDocumentChanges(t *testing.T) {
+	payload := []byte(`{
+		"documentChanges": [
+			{
+				"textDocument": {"uri": "file:///recipes/pasta.cook", "version": 3},
+				"edits": [
+					{"range": {"start": {"line": 0, "character": 5}, "end": {"line": 0, "character": 10}}, "newText": "spaghetti"},
+					{"range": {"start": {"line": 1, "character": 0}, "end": {"line": 1, "character": 0}}, "newText": "-- ", "annotationId": "comment"}
+				]
+			},
+			{"kind": "create", "uri": "file:///recipes/soup.cook", "options": {"ignoreIfExists": true}},
+			{"kind": "rename", "oldUri": "file:///recipes/old.cook", "newUri": "file:///recipes/new.cook", "options": {"overwrite": true}, "annotationId": "rename"},
+			{"kind": "delete", "uri": "file:///recipes/drafts", "options": {"recursive": true}}
+		],
+		"changeAnnotations": {
+			"comment": {"label": "Comment out the step"},
+			"rename": {"label": "Rename the recipe", "needsConfirmation": true, "description": "Links to the recipe aren't updated."}
+		}
+	}`)
+	var edit WorkspaceEdit
+	roundTrip(t, payload, &edit)
+	if len(edit.DocumentChanges) != 4 {
+		t.Fatalf("expected 4 document changes, got %d", len(edit.DocumentChanges))
+	}
+
+	text := edit.DocumentChanges[0].TextDocumentEdit
+	if text == nil || text.TextDocument.URI != "file:///recipes/pasta.cook" || text.TextDocument.Version == nil || *text.TextDocument.Version != 3 {
+		t.Fatalf("expected a text document edit, got %#v", edit.DocumentChanges[0])
+	}
+	if len(text.Edits) != 2 || text.Edits[0].AnnotationID != "" || text.Edits[1].AnnotationID != "comment" || text.Edits[1].NewText != "-- " {
+		t.Errorf("unexpected edits: %#v", text.Edits)
+	}
+	expectedCreate := &CreateFile{Kind: ResourceOperationKindCreate, URI: "file:///recipes/soup.cook", Options: &CreateFileOptions{IgnoreIfExists: true}}
+	if !reflect.DeepEqual(edit.DocumentChanges[1], DocumentChange{CreateFile: expectedCreate}) {
+		t.Errorf("expected %#v, got %#v", expectedCreate, edit.DocumentChanges[1])
+	}
+	expectedRename := &RenameFile{Kind: ResourceOperationKindRename, OldURI: "file:///recipes/old.cook", NewURI: "file:///recipes/new.cook", Options: &RenameFileOptions{Overwrite: true}, AnnotationID: "rename"}
+	if !reflect.DeepEqual(edit.DocumentChanges[2], DocumentChange{RenameFile: expectedRename}) {
+		t.Errorf("expected %#v, got %#v", expectedRename, edit.DocumentChanges[2])
+	}
+	expectedDelete := &DeleteFile{Kind: ResourceOperationKindDelete, URI: "file:///recipes/drafts", Options: &DeleteFileOptions{Recursive: true}}
+	if !reflect.DeepEqual(edit.DocumentChanges[3], DocumentChange{DeleteFile: expectedDelete}) {
+		t.Errorf("expected %#v, got %#v", expectedDelete, edit.DocumentChanges[3])
+	}
+	if a := edit.ChangeAnnotations["rename"]; !a.NeedsConfirmation || a.Label != "Rename the recipe" {
+		t.Errorf("unexpected annotation: %#v", a)
+	}
+}
+
+func TestDocumentChangeMarshalSetsKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   DocumentChange
+		expected string
+	}{
+		{
+			name:     "create",
+			change:   DocumentChange{CreateFile: &CreateFile{URI: "file:///a.cook"}},
+			expected: `{"kind":"create","uri":"file:///a.cook"}`,
+		},
+		{
+			name:     "rename",
+			change:   DocumentChange{RenameFile: &RenameFile{OldURI: "file:///a.cook", NewURI: "file:///b.cook"}},
+			expected: `{"kind":"rename","oldUri":"file:///a.cook","newUri":"file:///b.cook"}`,
+		},
+		{
+			name:     "delete",
+			change:   DocumentChange{DeleteFile: &DeleteFile{URI: "file:///a.cook", Kind: "wrong"}},
+			expected: `{"kind":"delete","uri":"file:///a.cook"}`,
+		},
+		{
+			name: "text document edit",
+			change: DocumentChange{TextDocumentEdit: &TextDocumentEdit{
+				TextDocument: OptionalVersionedTextDocumentIdentifier{URI: "file:///a.cook"},
+				Edits:        AnnotatedTextEdits([]TextEdit{{NewText: "x"}}),
+			}},
+			expected: `{"textDocument":{"uri":"file:///a.cook","version":null},"edits":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"newText":"x"}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.change)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			if string(data) != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, data)
+			}
+		})
+	}
+	if _, err := json.Marshal(DocumentChange{}); !errors.Is(err, ErrInvalidDocumentChange) {
+		t.Errorf("expected ErrInvalidDocumentChange for an empty change, got %v", err)
+	}
+	var change DocumentChange
+	if err := json.Unmarshal([]byte(`{"kind":"move","uri":"file:///a.cook"}`), &change); !errors.Is(err, ErrInvalidDocumentChange) {
+		t.Errorf("expected ErrInvalidDocumentChange for an unknown kind, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`[]`), &change); !errors.Is(err, ErrInvalidDocumentChange) {
+		t.Errorf("expected ErrInvalidDocumentChange for an array, got %v", err)
+	}
+}
+
+func TestWorkspaceEditHelpers(t *testing.T) {
+	edit := TextEdit{Range: Range{Start: NewPosition(0, 5), End: NewPosition(0, 10)}, NewText: "spaghetti"}
+
+	var changes WorkspaceEdit
+	data, err := json.Marshal(NewWorkspaceEdit("file:///pasta.cook", edit))
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	roundTrip(t, data, &changes)
+	if !reflect.DeepEqual(changes.Changes, map[string][]TextEdit{"file:///pasta.cook": {edit}}) || changes.DocumentChanges != nil {
+		t.Errorf("expected a changes map, got %s", data)
+	}
+
+	version := 2
+	var versioned WorkspaceEdit
+	data, err = json.Marshal(NewVersionedWorkspaceEdit(OptionalVersionedTextDocumentIdentifier{URI: "file:///pasta.cook", Version: &version}, edit))
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	roundTrip(t, data, &versioned)
+	if len(versioned.DocumentChanges) != 1 || versioned.DocumentChanges[0].TextDocumentEdit == nil || versioned.Changes != nil {
+		t.Fatalf("expected a single text document edit, got %s", data)
+	}
+	if actual := versioned.DocumentChanges[0].TextDocumentEdit.TextEdits(); !reflect.DeepEqual(actual, []TextEdit{edit}) {
+		t.Errorf("expected %#v, got %#v", []TextEdit{edit}, actual)
+	}
+}
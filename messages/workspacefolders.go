@@ -0,0 +1,83 @@
+package messages
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const DidChangeWorkspaceFoldersNotification = "workspace/didChangeWorkspaceFolders"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceFolder
+type WorkspaceFolder struct {
+	// The associated URI for this workspace folder.
+	URI string `json:"uri"`
+	// The name of the workspace folder. Used to refer to this workspace
+	// folder in the user interface.
+	Name string `json:"name"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_didChangeWorkspaceFolders
+type DidChangeWorkspaceFoldersParams struct {
+	// The actual workspace folder change event.
+	Event WorkspaceFoldersChangeEvent `json:"event"`
+}
+
+// The workspace folder change event.
+type WorkspaceFoldersChangeEvent struct {
+	// The array of added workspace folders.
+	Added []WorkspaceFolder `json:"added"`
+	// The array of the removed workspace folders.
+	Removed []WorkspaceFolder `json:"removed"`
+}
+
+type WorkspaceServerCapabilities struct {
+	// The server supports workspace folders.
+	WorkspaceFolders *WorkspaceFoldersServerCapabilities `json:"workspaceFolders,omitempty"`
+	// The server is interested in file notifications and requests.
+	FileOperations *FileOperationOptions `json:"fileOperations,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceFoldersServerCapabilities
+type WorkspaceFoldersServerCapabilities struct {
+	// The server has support for workspace folders.
+	Supported bool `json:"supported,omitempty"`
+	// Whether the server wants to receive workspace folder change
+	// notifications. If a string is provided, it's treated as an ID under
+	// which the notification is registered on the client side, so that it can
+	// be unregistered.
+	ChangeNotifications *StringOrBool `json:"changeNotifications,omitempty"`
+}
+
+// StringOrBool is a string, or a boolean. It's marshalled as the string if it
+// isn't nil.
+type StringOrBool struct {
+	String *string
+	Bool   bool
+}
+
+// ErrInvalidStringOrBool is returned when a value isn't a string or a boolean.
+var ErrInvalidStringOrBool = errors.New("messages: invalid string or boolean")
+
+func (s StringOrBool) MarshalJSON() ([]byte, error) {
+	if s.String != nil {
+		return json.Marshal(s.String)
+	}
+	return json.Marshal(s.Bool)
+}
+
+func (s *StringOrBool) UnmarshalJSON(data []byte) error {
+	*s = StringOrBool{}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ErrInvalidStringOrBool
+	}
+	switch data[0] {
+	case '"':
+		s.String = new(string)
+		return json.Unmarshal(data, s.String)
+	case 't', 'f':
+		return json.Unmarshal(data, &s.Bool)
+	}
+	return ErrInvalidStringOrBool
+}
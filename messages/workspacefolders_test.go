@@ -0,0 +1,77 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInitializeParamsWorkspaceFolders(t *testing.T) {
+	payload := []byte(`{
+		"processId": 4321,
+		"clientInfo": {"name": "Visual Studio Code", "version": "1.80.0"},
+		"rootPath": "/recipes",
+		"rootUri": "file:///recipes",
+		"workspaceFolders": [{"uri": "file:///recipes", "name": "recipes"}],
+		"capabilities": {"workspace": {"workspaceFolders": true}}
+	}`)
+	var params InitializeParams
+	roundTrip(t, payload, &params)
+	if params.RootPath == nil || *params.RootPath != "/recipes" || params.RootURI == nil || *params.RootURI != "file:///recipes" {
+		t.Errorf("expected the roots to be set, got %#v", params)
+	}
+	if expected := []WorkspaceFolder{{URI: "file:///recipes", Name: "recipes"}}; !reflect.DeepEqual(params.WorkspaceFolders, expected) {
+		t.Errorf("expected %#v, got %#v", expected, params.WorkspaceFolders)
+	}
+	if params.Capabilities.Workspace == nil || !params.Capabilities.Workspace.WorkspaceFolders {
+		t.Error("expected the client to support workspace folders")
+	}
+}
+
+func TestDidChangeWorkspaceFoldersParams(t *testing.T) {
+	payload := []byte(`{
+		"event": {
+			"added": [{"uri": "file:///cakes", "name": "cakes"}],
+			"removed": [{"uri": "file:///recipes", "name": "recipes"}]
+		}
+	}`)
+	var params DidChangeWorkspaceFoldersParams
+	roundTrip(t, payload, &params)
+	if len(params.Event.Added) != 1 || params.Event.Added[0].Name != "cakes" || len(params.Event.Removed) != 1 || params.Event.Removed[0].URI != "file:///recipes" {
+		t.Errorf("unexpected event: %#v", params.Event)
+	}
+}
+
+func TestWorkspaceFoldersServerCapabilities(t *testing.T) {
+	id := "workspace-folders"
+	tests := []struct {
+		name     string
+		payload  string
+		expected StringOrBool
+	}{
+		{
+			name:     "boolean",
+			payload:  `{"supported":true,"changeNotifications":true}`,
+			expected: StringOrBool{Bool: true},
+		},
+		{
+			name:     "registration ID",
+			payload:  `{"supported":true,"changeNotifications":"workspace-folders"}`,
+			expected: StringOrBool{String: &id},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var capabilities WorkspaceFoldersServerCapabilities
+			roundTrip(t, []byte(test.payload), &capabilities)
+			if !capabilities.Supported || capabilities.ChangeNotifications == nil || !reflect.DeepEqual(*capabilities.ChangeNotifications, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, capabilities.ChangeNotifications)
+			}
+		})
+	}
+	var s StringOrBool
+	if err := json.Unmarshal([]byte(`1`), &s); !errors.Is(err, ErrInvalidStringOrBool) {
+		t.Errorf("expected ErrInvalidStringOrBool, got %v", err)
+	}
+}
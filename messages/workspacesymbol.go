@@ -0,0 +1,150 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const WorkspaceSymbolRequestMethod = "workspace/symbol"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_symbol
+type WorkspaceSymbolParams struct {
+	WorkDoneProgressParams
+	PartialResultParams
+	// A query string to filter symbols by. Clients may send an empty string
+	// here to request all symbols.
+	Query string `json:"query"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#symbolInformation
+type SymbolInformation struct {
+	Name     string      `json:"name"`
+	Kind     SymbolKind  `json:"kind"`
+	Tags     []SymbolTag `json:"tags,omitempty"`
+	Location Location    `json:"location"`
+	// The name of the symbol containing this symbol, e.g. the recipe.
+	ContainerName string `json:"containerName,omitempty"`
+}
+
+type SymbolKind int
+
+const (
+	SymbolKindFile      SymbolKind = 1
+	SymbolKindNamespace SymbolKind = 3
+	SymbolKindProperty  SymbolKind = 7
+	SymbolKindField     SymbolKind = 8
+	SymbolKindFunction  SymbolKind = 12
+	SymbolKindVariable  SymbolKind = 13
+	SymbolKindObject    SymbolKind = 19
+	SymbolKindEvent     SymbolKind = 24
+)
+
+type SymbolTag int
+
+const SymbolTagDeprecated SymbolTag = 1
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceSymbol
+type WorkspaceSymbol struct {
+	Name string      `json:"name"`
+	Kind SymbolKind  `json:"kind"`
+	Tags []SymbolTag `json:"tags,omitempty"`
+	// The name of the symbol containing this symbol, e.g. the recipe.
+	ContainerName string `json:"containerName,omitempty"`
+	// The location of the symbol. The range may be left out if the client
+	// supports resolving it, see WorkspaceSymbolClientCapabilities.
+	Location WorkspaceSymbolLocation `json:"location"`
+	// A data entry field that is preserved between a workspace symbol request
+	// and a workspace symbol resolve request.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// NewWorkspaceSymbol returns the symbol as a WorkspaceSymbol with a full
+// location.
+func NewWorkspaceSymbol(s SymbolInformation) WorkspaceSymbol {
+	r := s.Location.Range
+	return WorkspaceSymbol{
+		Name:          s.Name,
+		Kind:          s.Kind,
+		Tags:          s.Tags,
+		ContainerName: s.ContainerName,
+		Location:      WorkspaceSymbolLocation{URI: s.Location.URI, Range: &r},
+	}
+}
+
+// WorkspaceSymbolLocation is either a Location, or just the URI of the
+// document, when Range is nil.
+type WorkspaceSymbolLocation struct {
+	URI   string `json:"uri"`
+	Range *Range `json:"range,omitempty"`
+}
+
+// Location returns the full location, if the range is set.
+func (l WorkspaceSymbolLocation) Location() (location Location, ok bool) {
+	if l.Range == nil {
+		return Location{URI: l.URI}, false
+	}
+	return Location{URI: l.URI, Range: *l.Range}, true
+}
+
+const WorkspaceSymbolResolveRequestMethod = "workspaceSymbol/resolve"
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspaceSymbolClientCapabilities
+type WorkspaceSymbolClientCapabilities struct {
+	// Symbol request supports dynamic registration.
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+	// The symbol kinds that the client supports. If it isn't set, only the
+	// kinds from File to Array are supported.
+	SymbolKind *struct {
+		ValueSet []SymbolKind `json:"valueSet,omitempty"`
+	} `json:"symbolKind,omitempty"`
+	// The client supports partial workspace symbols, and resolves the listed
+	// properties using the workspaceSymbol/resolve request.
+	ResolveSupport *struct {
+		Properties []string `json:"properties"`
+	} `json:"resolveSupport,omitempty"`
+}
+
+// ResolvesLocationRange returns true if the client accepts a WorkspaceSymbol
+// with a location that doesn't have a range, and resolves the range later.
+func (c WorkspaceSymbolClientCapabilities) ResolvesLocationRange() bool {
+	if c.ResolveSupport == nil {
+		return false
+	}
+	for _, p := range c.ResolveSupport.Properties {
+		if p == "location.range" {
+			return true
+		}
+	}
+	return false
+}
+
+type WorkspaceSymbolOptions struct {
+	WorkDoneProgress bool `json:"workDoneProgress,omitempty"`
+	// The server provides support to resolve additional information for a
+	// workspace symbol.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
+
+// BoolOrWorkspaceSymbolOptions is the workspaceSymbolProvider server
+// capability. It's marshalled as the options if they're set, and as Bool
+// otherwise.
+type BoolOrWorkspaceSymbolOptions struct {
+	Bool    bool
+	Options *WorkspaceSymbolOptions
+}
+
+// ErrInvalidWorkspaceSymbolProvider is returned when the
+// workspaceSymbolProvider capability isn't a boolean or an object.
+var ErrInvalidWorkspaceSymbolProvider = errors.New("messages: invalid workspace symbol provider")
+
+func (b BoolOrWorkspaceSymbolOptions) MarshalJSON() ([]byte, error) {
+	if b.Options != nil {
+		return json.Marshal(b.Options)
+	}
+	return json.Marshal(b.Bool)
+}
+
+func (b *BoolOrWorkspaceSymbolOptions) UnmarshalJSON(data []byte) error {
+	*b = BoolOrWorkspaceSymbolOptions{}
+	return unmarshalBoolOrOptions(data, &b.Bool, &b.Options, ErrInvalidWorkspaceSymbolProvider)
+}
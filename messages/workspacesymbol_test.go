@@ -0,0 +1,80 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWorkspaceSymbolJSON(t *testing.T) {
+	t.Run("location with a range", func(t *testing.T) {
+		var symbol WorkspaceSymbol
+		roundTrip(t, []byte(`{
+			"name": "@garlic",
+			"kind": 13,
+			"containerName": "pasta.cook",
+			"location": {
+				"uri": "file:///Users/alice/recipes/pasta.cook",
+				"range": {"start": {"line": 3, "character": 8}, "end": {"line": 3, "character": 15}}
+			}
+		}`), &symbol)
+		location, ok := symbol.Location.Location()
+		if !ok || location.Range != (Range{Start: NewPosition(3, 8), End: NewPosition(3, 15)}) {
+			t.Errorf("expected a full location, got %#v", location)
+		}
+	})
+	t.Run("location with only a uri", func(t *testing.T) {
+		var symbol WorkspaceSymbol
+		roundTrip(t, []byte(`{
+			"name": "#pan",
+			"kind": 19,
+			"location": {"uri": "file:///Users/alice/recipes/pasta.cook"},
+			"data": {"index": 2}
+		}`), &symbol)
+		if location, ok := symbol.Location.Location(); ok || location.URI != "file:///Users/alice/recipes/pasta.cook" {
+			t.Errorf("expected only a uri, got %#v", location)
+		}
+	})
+}
+
+func TestWorkspaceSymbolClientCapabilitiesJSON(t *testing.T) {
+	var capabilities ClientCapabilities
+	roundTrip(t, []byte(`{
+		"workspace": {
+			"symbol": {
+				"dynamicRegistration": true,
+				"symbolKind": {"valueSet": [1, 2, 3]},
+				"resolveSupport": {"properties": ["location.range"]}
+			}
+		}
+	}`), &capabilities)
+	if capabilities.Workspace == nil || capabilities.Workspace.Symbol == nil || !capabilities.Workspace.Symbol.ResolvesLocationRange() {
+		t.Errorf("expected the client to resolve location ranges, got %#v", capabilities.Workspace)
+	}
+	if (WorkspaceSymbolClientCapabilities{}).ResolvesLocationRange() {
+		t.Error("expected clients without resolve support not to resolve location ranges")
+	}
+}
+
+func TestWorkspaceSymbolData(t *testing.T) {
+	var symbol WorkspaceSymbol
+	roundTrip(t, []byte(`{"name":"Pasta","kind":1,"location":{"uri":"file:///pasta.cook"},"data":{"metadata":"title","index":[1,2]}}`), &symbol)
+	if string(symbol.Data) != `{"metadata":"title","index":[1,2]}` {
+		t.Errorf("expected the data to be preserved, got %s", symbol.Data)
+	}
+}
+
+func TestWorkspaceSymbolProviderJSON(t *testing.T) {
+	var provider BoolOrWorkspaceSymbolOptions
+	roundTrip(t, []byte(`true`), &provider)
+	if !provider.Bool || provider.Options != nil {
+		t.Errorf("expected true, got %#v", provider)
+	}
+	roundTrip(t, []byte(`{"resolveProvider":true}`), &provider)
+	if provider.Options == nil || !provider.Options.ResolveProvider {
+		t.Errorf("expected resolve, got %#v", provider)
+	}
+	if err := json.Unmarshal([]byte(`"yes"`), &provider); !errors.Is(err, ErrInvalidWorkspaceSymbolProvider) {
+		t.Errorf("expected ErrInvalidWorkspaceSymbolProvider, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package parser adapts the cooklang parser for use by the server.
+//
+// The cooklang parser is authoritative about the syntax of recipes. The recipe
+// package has a second, tolerant parser, which is only used for the positions
+// of elements, because cooklang-go doesn't report them.
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/aquilax/cooklang-go"
+	"golang.org/x/exp/slog"
+)
+
+// Parser parses recipes.
+type Parser interface {
+	Parse(text string) (*cooklang.Recipe, error)
+}
+
+// ParserFunc adapts a function to the Parser interface.
+type ParserFunc func(text string) (*cooklang.Recipe, error)
+
+func (f ParserFunc) Parse(text string) (*cooklang.Recipe, error) {
+	return f(text)
+}
+
+// Cooklang parses recipes using cooklang-go.
+var Cooklang Parser = ParserFunc(cooklang.ParseString)
+
+// DefaultLogInterval is the minimum time between log entries for panics
+// caused by the same input.
+const DefaultLogInterval = time.Minute
+
+// maxLogged is the maximum number of inputs to remember for rate limiting.
+const maxLogged = 1000
+
+// Recovering wraps a Parser, and returns panics as parse errors, so that a
+// single bad document can't crash the server.
+type Recovering struct {
+	parser      Parser
+	log         *slog.Logger
+	logInterval time.Duration
+	logLock     sync.Mutex
+	logged      map[string]time.Time
+	now         func() time.Time
+}
+
+func NewRecovering(log *slog.Logger, p Parser) *Recovering {
+	return &Recovering{
+		parser:      p,
+		log:         log,
+		logInterval: DefaultLogInterval,
+		logged:      map[string]time.Time{},
+		now:         time.Now,
+	}
+}
+
+// Parse the text. If the parser panics, a *cooklang.Error is returned that
+// contains the panic message, and the position of the first unclosed brace,
+// if there is one.
+func (r *Recovering) Parse(text string) (recipe *cooklang.Recipe, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			r.logPanic(text, v)
+			recipe, err = nil, &cooklang.Error{
+				Range:   guessRange(text),
+				Message: fmt.Sprintf("Failed to parse recipe: %v", v),
+			}
+		}
+	}()
+	return r.parser.Parse(text)
+}
+
+// logPanic logs the panic, identifying the input by its hash, so that it can
+// be reported upstream. Each input is logged at most once per log interval.
+func (r *Recovering) logPanic(text string, v any) {
+	hash := sha256.Sum256([]byte(text))
+	id := hex.EncodeToString(hash[:])
+	r.logLock.Lock()
+	defer r.logLock.Unlock()
+	now := r.now()
+	if last, ok := r.logged[id]; ok && now.Sub(last) < r.logInterval {
+		return
+	}
+	if len(r.logged) >= maxLogged {
+		r.logged = map[string]time.Time{}
+	}
+	r.logged[id] = now
+	r.log.Error("cooklang parser panicked", slog.String("inputSHA256", id), slog.Any("recovered", v))
+}
+
+// guessRange returns the range from the first unclosed brace to the end of its
+// line. The parser panics on malformed braces, so it's the most likely
+// location of the problem. If there are no unclosed braces, the first line is
+// used.
+func guessRange(text string) cooklang.Range {
+	lines := strings.Split(text, "\n")
+	for lineIndex, line := range lines {
+		open := -1
+		for i, r := range line {
+			switch r {
+			case '{':
+				if open < 0 {
+					open = i
+				}
+			case '}':
+				open = -1
+			}
+		}
+		if open >= 0 {
+			return cooklang.Range{
+				Start: cooklang.Position{Line: lineIndex, Character: utf16Len(line[:open])},
+				End:   cooklang.Position{Line: lineIndex, Character: utf16Len(line)},
+			}
+		}
+	}
+	return cooklang.Range{
+		End: cooklang.Position{Character: utf16Len(lines[0])},
+	}
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aquilax/cooklang-go"
+	"golang.org/x/exp/slog"
+)
+
+var panicking = ParserFunc(func(text string) (*cooklang.Recipe, error) {
+	var steps []cooklang.Step
+	_ = steps[len(text)]
+	return nil, nil
+})
+
+func TestRecoveringFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob("testdata/panics/*.cook")
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("failed to find fixtures: %v", err)
+	}
+	p := NewRecovering(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), Cooklang)
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			text, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			_, err = p.Parse(string(text))
+			var cerr *cooklang.Error
+			if !errors.As(err, &cerr) {
+				t.Errorf("expected a *cooklang.Error, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+func TestRecoveringReturnsParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected cooklang.Range
+	}{
+		{
+			name: "the first unclosed brace is used",
+			text: "Boil @water{500%ml}.\n\nAdd 🧂 @salt{ to the pan.\nUse a #pan{.",
+			expected: cooklang.Range{
+				Start: cooklang.Position{Line: 2, Character: 12},
+				End:   cooklang.Position{Line: 2, Character: 25},
+			},
+		},
+		{
+			name: "without unclosed braces, the first line is used",
+			text: "Boil @water{500%ml}.\nServe.",
+			expected: cooklang.Range{
+				End: cooklang.Position{Line: 0, Character: 20},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewRecovering(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), panicking)
+			recipe, err := p.Parse(test.text)
+			if recipe != nil {
+				t.Errorf("expected no recipe, got %v", recipe)
+			}
+			var cerr *cooklang.Error
+			if !errors.As(err, &cerr) {
+				t.Fatalf("expected a *cooklang.Error, got %v", err)
+			}
+			if !strings.Contains(cerr.Message, "index out of range") {
+				t.Errorf("expected the message to contain the panic, got %q", cerr.Message)
+			}
+			if cerr.Range != test.expected {
+				t.Errorf("expected range %v, got %v", test.expected, cerr.Range)
+			}
+		})
+	}
+}
+
+func TestRecoveringRateLimitsLogs(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewRecovering(slog.New(slog.NewTextHandler(&buf, nil)), panicking)
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	logCount := func() int {
+		return strings.Count(buf.String(), "cooklang parser panicked")
+	}
+
+	p.Parse("@salt{")
+	p.Parse("@salt{")
+	if actual := logCount(); actual != 1 {
+		t.Errorf("expected repeated panics to be logged once, got %d", actual)
+	}
+	if !strings.Contains(buf.String(), "inputSHA256=") {
+		t.Errorf("expected the log to identify the input, got %q", buf.String())
+	}
+	p.Parse("#pan{")
+	if actual := logCount(); actual != 2 {
+		t.Errorf("expected a different input to be logged, got %d entries", actual)
+	}
+	now = now.Add(DefaultLogInterval)
+	p.Parse("@salt{")
+	if actual := logCount(); actual != 3 {
+		t.Errorf("expected the input to be logged again after the interval, got %d entries", actual)
+	}
+}
@@ -0,0 +1,77 @@
+package recipe
+
+import (
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// IngredientAt returns the ingredient at the position, including the position
+// just after its end, where the cursor is after typing it.
+func (r Recipe) IngredientAt(p messages.Position) (ingredient Ingredient, ok bool) {
+	for _, step := range r.Steps {
+		for _, ingredient := range step.Ingredients {
+			if containsInclusive(ingredient.Range, p) {
+				return ingredient, true
+			}
+		}
+	}
+	return ingredient, false
+}
+
+func containsInclusive(r messages.Range, p messages.Position) bool {
+	return r.Contains(p) || r.End == p
+}
+
+// Rename returns an edit that renames the ingredient. Single-word ingredients
+// are given braces if the new name has more than one word. It returns false if
+// the name is empty, or contains characters that are part of the markup.
+func (i Ingredient) Rename(name string) (edit messages.TextEdit, ok bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, "@#~{}%[]\r\n") {
+		return edit, false
+	}
+	edit = messages.TextEdit{Range: i.NameRange, NewText: name}
+	braced := i.Range.End != i.NameRange.End
+	if !braced && !isWord(name) {
+		edit.NewText += "{}"
+	}
+	return edit, true
+}
+
+// RenameIngredient returns the edits that rename every use of the ingredient
+// in the text. Names are matched case insensitively. Empty braces are removed
+// when they're no longer needed. It returns false if the new name isn't a
+// valid ingredient name.
+func RenameIngredient(text, name, newName string) (edits []messages.TextEdit, ok bool) {
+	for _, step := range Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			if !strings.EqualFold(ingredient.Name, name) {
+				continue
+			}
+			edit, ok := ingredient.Rename(newName)
+			if !ok {
+				return nil, false
+			}
+			if ingredient.Quantity == "" && ingredient.Unit == "" && isWord(edit.NewText) && !followedByWord(text, ingredient.Range.End) {
+				edit.Range.End = ingredient.Range.End
+			}
+			edits = append(edits, edit)
+		}
+	}
+	return edits, true
+}
+
+// followedByWord returns true if the text at the position starts with a word
+// character, which would become part of a single-word ingredient's name.
+func followedByWord(text string, p messages.Position) bool {
+	lines := strings.Split(text, "\n")
+	if p.Line < 0 || p.Line >= len(lines) {
+		return false
+	}
+	after := strings.TrimSuffix(lines[p.Line], "\r")[len(LineBefore(text, p)):]
+	for _, r := range after {
+		return isWordRune(r)
+	}
+	return false
+}
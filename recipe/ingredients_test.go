@@ -0,0 +1,86 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestIngredientAt(t *testing.T) {
+	r := Parse("Add the @olive oil{2%tbsp} and the @garlic.")
+	tests := []struct {
+		position messages.Position
+		expected string
+	}{
+		{position: messages.NewPosition(0, 3), expected: ""},
+		{position: messages.NewPosition(0, 8), expected: "olive oil"},
+		{position: messages.NewPosition(0, 26), expected: "olive oil"},
+		{position: messages.NewPosition(0, 40), expected: "garlic"},
+		{position: messages.NewPosition(0, 42), expected: "garlic"},
+		{position: messages.NewPosition(1, 0), expected: ""},
+	}
+	for _, test := range tests {
+		actual, ok := r.IngredientAt(test.position)
+		if ok != (test.expected != "") || actual.Name != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.position, test.expected, actual.Name)
+		}
+	}
+}
+
+func TestIngredientRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		newName  string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "single word to single word",
+			text:     "Add the @salt.",
+			newName:  "pepper",
+			expected: "Add the @pepper.",
+			ok:       true,
+		},
+		{
+			name:     "single word to multiple words gets braces",
+			text:     "Add the @salt.",
+			newName:  "sea salt",
+			expected: "Add the @sea salt{}.",
+			ok:       true,
+		},
+		{
+			name:     "braced ingredients keep their amounts",
+			text:     "Add the @ olive oil {2%tbsp}.",
+			newName:  "rapeseed oil",
+			expected: "Add the @ rapeseed oil {2%tbsp}.",
+			ok:       true,
+		},
+		{
+			name:    "markup characters are rejected",
+			text:    "Add the @salt.",
+			newName: "salt{1%g}",
+		},
+		{
+			name:    "empty names are rejected",
+			text:    "Add the @salt.",
+			newName: "  ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ingredient := Parse(test.text).Steps[0].Ingredients[0]
+			edit, ok := ingredient.Rename(test.newName)
+			if ok != test.ok {
+				t.Fatalf("expected ok to be %v, got %v", test.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			start, end := edit.Range.Start.Character, edit.Range.End.Character
+			if actual := test.text[:start] + edit.NewText + test.text[end:]; actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package recipe
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// LineBefore returns the text of the line that the position is on, up to the
+// position.
+func LineBefore(text string, p messages.Position) string {
+	lines := strings.Split(text, "\n")
+	if p.Line < 0 || p.Line >= len(lines) {
+		return ""
+	}
+	line := strings.TrimSuffix(lines[p.Line], "\r")
+	var character int
+	for i, r := range line {
+		if character >= p.Character {
+			return line[:i]
+		}
+		character += len(utf16.Encode([]rune{r}))
+	}
+	return line
+}
@@ -0,0 +1,27 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestLineBefore(t *testing.T) {
+	text := "Heat to 230°C.\r\nAdd 🧂 ~rest"
+	tests := []struct {
+		position messages.Position
+		expected string
+	}{
+		{position: messages.NewPosition(0, 0), expected: ""},
+		{position: messages.NewPosition(0, 12), expected: "Heat to 230°"},
+		{position: messages.NewPosition(0, 100), expected: "Heat to 230°C."},
+		{position: messages.NewPosition(1, 7), expected: "Add 🧂 "},
+		{position: messages.NewPosition(1, 9), expected: "Add 🧂 ~r"},
+		{position: messages.NewPosition(2, 0), expected: ""},
+	}
+	for _, test := range tests {
+		if actual := LineBefore(text, test.position); actual != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.position, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package recipe
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseQuantity parses a quantity written as a number, a fraction, or a whole
+// number and a fraction, e.g. "100", "1.5", "1/2" or "1 1/2".
+func ParseQuantity(s string) (amount float64, ok bool) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, false
+	}
+	for i, field := range fields {
+		var v float64
+		var err error
+		if numerator, denominator, isFraction := strings.Cut(field, "/"); isFraction {
+			if i < len(fields)-1 {
+				// Only "1 1/2" is allowed, not "1/2 1".
+				return 0, false
+			}
+			var n, d float64
+			if n, err = strconv.ParseFloat(numerator, 64); err != nil {
+				return 0, false
+			}
+			if d, err = strconv.ParseFloat(denominator, 64); err != nil || d == 0 {
+				return 0, false
+			}
+			v = n / d
+		} else if i == len(fields)-1 && len(fields) > 1 {
+			return 0, false
+		} else if v, err = strconv.ParseFloat(field, 64); err != nil {
+			return 0, false
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return 0, false
+		}
+		amount += v
+	}
+	return amount, true
+}
@@ -0,0 +1,17 @@
+package recipe
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := map[string]float64{"100": 100, "1.5": 1.5, "1/2": 0.5, "1 1/2": 1.5, " 2 ": 2}
+	for s, expected := range tests {
+		if actual, ok := ParseQuantity(s); !ok || actual != expected {
+			t.Errorf("%q: expected %v, got %v, %v", s, expected, actual, ok)
+		}
+	}
+	for _, s := range []string{"", "some", "1 2", "1/2 1", "1/0", "-1", "NaN", "1 1/2 3"} {
+		if actual, ok := ParseQuantity(s); ok {
+			t.Errorf("%q: expected not to parse, got %v", s, actual)
+		}
+	}
+}
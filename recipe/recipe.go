@@ -0,0 +1,406 @@
+// Package recipe reads the structure of cooklang recipes, including the
+// position of each element within the source text.
+//
+// Unlike the cooklang parser, Parse never fails, so that editor features can
+// keep working while a recipe is being typed.
+//
+// The cooklang parser, wrapped by the parser package, is authoritative: it
+// decides whether a recipe is valid, and its errors are published as
+// diagnostics. cooklang-go doesn't report where elements are, and stops at
+// the first error, so this package exists to find the ranges of elements in
+// recipes that are valid, or that are still being typed. Where the two
+// disagree about a valid recipe, this package is wrong, and its tests should
+// cover the case.
+package recipe
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+type Recipe struct {
+	// Metadata from lines starting with ">>", in the order they're written.
+	Metadata []Metadata
+	Steps    []Step
+}
+
+// Metadata is a key and value, written as ">> key: value".
+type Metadata struct {
+	Key   string
+	Value string
+	// Range of the line.
+	Range messages.Range
+}
+
+// ParseMetadata returns the metadata of the recipe, without reading the
+// steps.
+func ParseMetadata(text string) (metadata []Metadata) {
+	for lineIndex, line := range strings.Split(text, "\n") {
+		if m, ok := parseMetadata(lineIndex, strings.TrimSuffix(line, "\r")); ok {
+			metadata = append(metadata, m)
+		}
+	}
+	return metadata
+}
+
+func parseMetadata(lineIndex int, line string) (m Metadata, ok bool) {
+	if !strings.HasPrefix(line, ">>") {
+		return m, false
+	}
+	key, value, ok := strings.Cut(strings.TrimPrefix(line, ">>"), ":")
+	if !ok {
+		return m, false
+	}
+	return Metadata{
+		Key:   strings.TrimSpace(key),
+		Value: strings.TrimSpace(value),
+		Range: messages.Range{
+			Start: messages.NewPosition(lineIndex, 0),
+			End:   messages.NewPosition(lineIndex, utf16Len(line)),
+		},
+	}, true
+}
+
+// Step is a paragraph of text, separated from other steps by blank lines.
+type Step struct {
+	// Range from the start of the first line of the step, to the end of the last line.
+	Range       messages.Range
+	Ingredients []Ingredient
+	Cookware    []Cookware
+	Timers      []Timer
+	// Normalized text of the step, with comments removed, elements written in
+	// a canonical form, and whitespace collapsed. Steps that have the same
+	// Normalized text are equivalent.
+	Normalized string
+	// Text of the step as it's read, with comments removed, each element
+	// replaced by its name, or a timer's duration, and whitespace collapsed.
+	Text string
+}
+
+type Ingredient struct {
+	Name     string
+	Quantity string
+	Unit     string
+	// Range of the whole element, including the prefix and any braces.
+	Range messages.Range
+	// NameRange is the range of the name within the element.
+	NameRange messages.Range
+	// UnitRange is the range of the unit within the braces. It's empty if
+	// there isn't a unit.
+	UnitRange messages.Range
+}
+
+func (i Ingredient) String() string {
+	return markup('@', i.Name, i.Quantity, i.Unit)
+}
+
+type Cookware struct {
+	Name      string
+	Quantity  string
+	Range     messages.Range
+	NameRange messages.Range
+}
+
+func (c Cookware) String() string {
+	return markup('#', c.Name, c.Quantity, "")
+}
+
+type Timer struct {
+	Name      string
+	Quantity  string
+	Unit      string
+	Range     messages.Range
+	NameRange messages.Range
+}
+
+func (t Timer) String() string {
+	return markup('~', t.Name, t.Quantity, t.Unit)
+}
+
+// text of the timer in a sentence, which is its duration if it has one.
+func (t Timer) text() string {
+	if t.Quantity == "" {
+		return t.Name
+	}
+	return strings.TrimSpace(t.Quantity + " " + t.Unit)
+}
+
+func markup(prefix rune, name, quantity, unit string) string {
+	if quantity == "" && unit == "" && isWord(name) {
+		return fmt.Sprintf("%c%s", prefix, name)
+	}
+	if unit != "" {
+		return fmt.Sprintf("%c%s{%s%%%s}", prefix, name, quantity, unit)
+	}
+	return fmt.Sprintf("%c%s{%s}", prefix, name, quantity)
+}
+
+func isWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
+
+// Parse the structure of the recipe.
+func Parse(text string) (r Recipe) {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	masked := maskComments(lines)
+
+	var step *Step
+	var normalized, plain []string
+	endStep := func() {
+		if step == nil {
+			return
+		}
+		step.Normalized = strings.Join(strings.Fields(strings.Join(normalized, " ")), " ")
+		step.Text = strings.Join(strings.Fields(strings.Join(plain, " ")), " ")
+		r.Steps = append(r.Steps, *step)
+		step, normalized, plain = nil, nil, nil
+	}
+	for lineIndex, line := range lines {
+		if strings.HasPrefix(line, ">>") {
+			endStep()
+			if m, ok := parseMetadata(lineIndex, line); ok {
+				r.Metadata = append(r.Metadata, m)
+			}
+			continue
+		}
+		if strings.TrimSpace(masked[lineIndex]) == "" {
+			endStep()
+			continue
+		}
+		if step == nil {
+			step = &Step{
+				Range: messages.Range{Start: messages.NewPosition(lineIndex, 0)},
+			}
+		}
+		step.Range.End = messages.NewPosition(lineIndex, utf16Len(line))
+		n, p := parseLine(step, lineIndex, line, masked[lineIndex])
+		normalized, plain = append(normalized, n), append(plain, p)
+	}
+	endStep()
+	return
+}
+
+// MaskedLines returns the lines of the text, without line endings, with the
+// comments replaced by spaces, so that the offsets of everything else are
+// unchanged.
+func MaskedLines(text string) (lines []string) {
+	lines = strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return maskComments(lines)
+}
+
+// maskComments replaces comments with spaces, keeping the byte offsets of
+// everything else unchanged.
+func maskComments(lines []string) (masked []string) {
+	masked = make([]string, len(lines))
+	var inBlockComment bool
+	for lineIndex, line := range lines {
+		masked[lineIndex], inBlockComment = maskLine(line, inBlockComment)
+	}
+	return
+}
+
+// maskLine replaces the comments in the line with spaces. inBlockComment is
+// true if the line starts within a block comment, and after is true if it
+// ends within one.
+func maskLine(line string, inBlockComment bool) (masked string, after bool) {
+	b := []byte(line)
+	for i := 0; i < len(b); i++ {
+		if inBlockComment {
+			if b[i] == '-' && i+1 < len(b) && b[i+1] == ']' {
+				b[i], b[i+1] = ' ', ' '
+				i++
+				inBlockComment = false
+				continue
+			}
+			b[i] = ' '
+			continue
+		}
+		if b[i] == '[' && i+1 < len(b) && b[i+1] == '-' {
+			b[i], b[i+1] = ' ', ' '
+			i++
+			inBlockComment = true
+			continue
+		}
+		if b[i] == '-' && i+1 < len(b) && b[i+1] == '-' {
+			for ; i < len(b); i++ {
+				b[i] = ' '
+			}
+		}
+	}
+	return string(b), inBlockComment
+}
+
+// InBlockComment returns true if the line starts within a block comment.
+func InBlockComment(text string, line int) bool {
+	var inBlockComment bool
+	for lineIndex, l := range strings.Split(text, "\n") {
+		if lineIndex >= line {
+			break
+		}
+		_, inBlockComment = maskLine(l, inBlockComment)
+	}
+	return inBlockComment
+}
+
+// BlockComments returns the ranges of the block comments in the text, from the
+// start of "[-" to the end of "-]". A block comment that isn't closed ends at
+// the end of the text.
+func BlockComments(text string) (ranges []messages.Range) {
+	var start *messages.Position
+	lines := strings.Split(text, "\n")
+	for lineIndex, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		for i := 0; i < len(line); i++ {
+			if start != nil {
+				if line[i] == '-' && i+1 < len(line) && line[i+1] == ']' {
+					ranges = append(ranges, messages.Range{
+						Start: *start,
+						End:   messages.NewPosition(lineIndex, utf16Len(line[:i+2])),
+					})
+					start = nil
+					i++
+				}
+				continue
+			}
+			if line[i] == '[' && i+1 < len(line) && line[i+1] == '-' {
+				p := messages.NewPosition(lineIndex, utf16Len(line[:i]))
+				start = &p
+				i++
+				continue
+			}
+			if line[i] == '-' && i+1 < len(line) && line[i+1] == '-' {
+				break
+			}
+		}
+	}
+	if start != nil {
+		last := strings.TrimSuffix(lines[len(lines)-1], "\r")
+		ranges = append(ranges, messages.Range{
+			Start: *start,
+			End:   messages.NewPosition(len(lines)-1, utf16Len(last)),
+		})
+	}
+	return ranges
+}
+
+// parseLine adds the elements found in the line to the step, and returns the
+// normalized and plain text of the line.
+func parseLine(step *Step, lineIndex int, line, masked string) (normalized, plain string) {
+	var sb, pb strings.Builder
+	for i := 0; i < len(masked); {
+		prefix := masked[i]
+		if prefix != '@' && prefix != '#' && prefix != '~' {
+			sb.WriteByte(masked[i])
+			pb.WriteByte(masked[i])
+			i++
+			continue
+		}
+		e, ok := readElement(masked, i)
+		if !ok {
+			sb.WriteByte(masked[i])
+			pb.WriteByte(masked[i])
+			i++
+			continue
+		}
+		lineRange := func(start, end int) messages.Range {
+			return messages.Range{
+				Start: messages.NewPosition(lineIndex, utf16Len(line[:start])),
+				End:   messages.NewPosition(lineIndex, utf16Len(line[:end])),
+			}
+		}
+		r, nameRange := lineRange(i, e.end), lineRange(e.nameStart, e.nameEnd)
+		switch prefix {
+		case '@':
+			ingredient := Ingredient{Name: e.name, Quantity: e.quantity, Unit: e.unit, Range: r, NameRange: nameRange}
+			if e.unit != "" {
+				ingredient.UnitRange = lineRange(e.unitStart, e.unitEnd)
+			}
+			step.Ingredients = append(step.Ingredients, ingredient)
+			sb.WriteString(ingredient.String())
+			pb.WriteString(ingredient.Name)
+		case '#':
+			cookware := Cookware{Name: e.name, Quantity: e.quantity, Range: r, NameRange: nameRange}
+			step.Cookware = append(step.Cookware, cookware)
+			sb.WriteString(cookware.String())
+			pb.WriteString(cookware.Name)
+		case '~':
+			timer := Timer{Name: e.name, Quantity: e.quantity, Unit: e.unit, Range: r, NameRange: nameRange}
+			step.Timers = append(step.Timers, timer)
+			sb.WriteString(timer.String())
+			pb.WriteString(timer.text())
+		}
+		i = e.end
+	}
+	return sb.String(), pb.String()
+}
+
+// element is an ingredient, cookware or timer read from a line.
+type element struct {
+	name, quantity, unit string
+	// nameStart and nameEnd are the byte offsets of the name within the line,
+	// unitStart and unitEnd are the offsets of the unit, and end is the offset
+	// of the first byte after the element.
+	nameStart, nameEnd, unitStart, unitEnd, end int
+}
+
+// readElement reads an ingredient, cookware or timer that starts at index i of
+// the line. Elements with multi-word names, or amounts, end with a closing
+// brace, while single-word elements end at the first character that isn't
+// part of a word.
+func readElement(line string, i int) (e element, ok bool) {
+	rest := line[i+1:]
+	if closeIndex := strings.IndexAny(rest, "@#~[}"); closeIndex >= 0 && rest[closeIndex] == '}' {
+		if openIndex := strings.Index(rest[:closeIndex], "{"); openIndex >= 0 {
+			rawName := rest[:openIndex]
+			e.name = strings.TrimSpace(rawName)
+			e.nameStart = i + 1 + len(rawName) - len(strings.TrimLeftFunc(rawName, unicode.IsSpace))
+			e.nameEnd = e.nameStart + len(e.name)
+			quantity, unit, _ := strings.Cut(rest[openIndex+1:closeIndex], "%")
+			e.quantity, e.unit = strings.TrimSpace(quantity), strings.TrimSpace(unit)
+			if e.unit != "" {
+				e.unitStart = i + 1 + openIndex + 1 + len(quantity) + 1 + len(unit) - len(strings.TrimLeftFunc(unit, unicode.IsSpace))
+				e.unitEnd = e.unitStart + len(e.unit)
+			}
+			e.end = i + 1 + closeIndex + 1
+			return e, true
+		}
+	}
+	e.end = i + 1
+	for e.end < len(line) {
+		r, size := utf8.DecodeRuneInString(line[e.end:])
+		if !isWordRune(r) {
+			break
+		}
+		e.end += size
+	}
+	e.name = line[i+1 : e.end]
+	e.nameStart, e.nameEnd = i+1, e.end
+	return e, e.name != ""
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
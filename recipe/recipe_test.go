@@ -0,0 +1,201 @@
+package recipe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestParse(t *testing.T) {
+	text := `>> servings: 2
+
+Put the @olive oil{2%tbsp} in a #frying pan{}.
+Add the @garlic and cook for ~{2%minutes} -- until golden.
+
+[- Leave the #oven{} off. -]
+Add the @salt. Rest for ~rest{5%minutes}.`
+
+	r := Parse(text)
+	if len(r.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(r.Steps))
+	}
+
+	expectedMetadata := []Metadata{
+		{Key: "servings", Value: "2", Range: messages.Range{Start: messages.NewPosition(0, 0), End: messages.NewPosition(0, 14)}},
+	}
+	if !reflect.DeepEqual(r.Metadata, expectedMetadata) {
+		t.Errorf("expected metadata %#v, got %#v", expectedMetadata, r.Metadata)
+	}
+	if metadata := ParseMetadata(text); !reflect.DeepEqual(metadata, expectedMetadata) {
+		t.Errorf("expected ParseMetadata to return %#v, got %#v", expectedMetadata, metadata)
+	}
+
+	first := r.Steps[0]
+	expectedText := "Put the olive oil in a frying pan. Add the garlic and cook for 2 minutes"
+	if first.Text != expectedText {
+		t.Errorf("expected first step text %q, got %q", expectedText, first.Text)
+	}
+	expectedRange := messages.Range{Start: messages.NewPosition(2, 0), End: messages.NewPosition(3, 58)}
+	if first.Range != expectedRange {
+		t.Errorf("expected first step range %v, got %v", expectedRange, first.Range)
+	}
+	expectedIngredients := []Ingredient{
+		{
+			Name: "olive oil", Quantity: "2", Unit: "tbsp",
+			Range:     messages.Range{Start: messages.NewPosition(2, 8), End: messages.NewPosition(2, 26)},
+			NameRange: messages.Range{Start: messages.NewPosition(2, 9), End: messages.NewPosition(2, 18)},
+			UnitRange: messages.Range{Start: messages.NewPosition(2, 21), End: messages.NewPosition(2, 25)},
+		},
+		{
+			Name:      "garlic",
+			Range:     messages.Range{Start: messages.NewPosition(3, 8), End: messages.NewPosition(3, 15)},
+			NameRange: messages.Range{Start: messages.NewPosition(3, 9), End: messages.NewPosition(3, 15)},
+		},
+	}
+	if !reflect.DeepEqual(first.Ingredients, expectedIngredients) {
+		t.Errorf("expected ingredients %#v, got %#v", expectedIngredients, first.Ingredients)
+	}
+	expectedCookware := []Cookware{
+		{
+			Name:      "frying pan",
+			Range:     messages.Range{Start: messages.NewPosition(2, 32), End: messages.NewPosition(2, 45)},
+			NameRange: messages.Range{Start: messages.NewPosition(2, 33), End: messages.NewPosition(2, 43)},
+		},
+	}
+	if !reflect.DeepEqual(first.Cookware, expectedCookware) {
+		t.Errorf("expected cookware %#v, got %#v", expectedCookware, first.Cookware)
+	}
+	expectedTimers := []Timer{
+		{
+			Quantity: "2", Unit: "minutes",
+			Range:     messages.Range{Start: messages.NewPosition(3, 29), End: messages.NewPosition(3, 41)},
+			NameRange: messages.Range{Start: messages.NewPosition(3, 30), End: messages.NewPosition(3, 30)},
+		},
+	}
+	if !reflect.DeepEqual(first.Timers, expectedTimers) {
+		t.Errorf("expected timers %#v, got %#v", expectedTimers, first.Timers)
+	}
+
+	second := r.Steps[1]
+	expectedRange = messages.Range{Start: messages.NewPosition(6, 0), End: messages.NewPosition(6, 41)}
+	if second.Range != expectedRange {
+		t.Errorf("expected second step range %v, got %v", expectedRange, second.Range)
+	}
+	if expectedText = "Add the salt. Rest for 5 minutes."; second.Text != expectedText {
+		t.Errorf("expected second step text %q, got %q", expectedText, second.Text)
+	}
+	if len(second.Cookware) != 0 {
+		t.Errorf("expected cookware in comments to be ignored, got %v", second.Cookware)
+	}
+	expectedTimers = []Timer{
+		{
+			Name: "rest", Quantity: "5", Unit: "minutes",
+			Range:     messages.Range{Start: messages.NewPosition(6, 24), End: messages.NewPosition(6, 40)},
+			NameRange: messages.Range{Start: messages.NewPosition(6, 25), End: messages.NewPosition(6, 29)},
+		},
+	}
+	if !reflect.DeepEqual(second.Timers, expectedTimers) {
+		t.Errorf("expected timers %#v, got %#v", expectedTimers, second.Timers)
+	}
+}
+
+func TestParseMultiLineBlockComment(t *testing.T) {
+	text := "Boil the @water.\n[- A comment\nthat continues\n-]\nAdd the @pasta."
+	r := Parse(text)
+	if len(r.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d: %#v", len(r.Steps), r.Steps)
+	}
+	if r.Steps[1].Range.Start.Line != 4 {
+		t.Errorf("expected the second step to start on line 4, got %d", r.Steps[1].Range.Start.Line)
+	}
+}
+
+func TestInBlockComment(t *testing.T) {
+	text := "Boil the @water.\n[- A comment\n\nthat continues -] Add the @pasta.\n"
+	for line, expected := range []bool{false, false, true, true, false} {
+		if actual := InBlockComment(text, line); actual != expected {
+			t.Errorf("line %d: expected %v, got %v", line, expected, actual)
+		}
+	}
+}
+
+func TestBlockComments(t *testing.T) {
+	text := "Boil the @water. [- A 🧂 comment -]\n[- A comment\n\nthat continues -] Add the @pasta. -- [- not a comment\n[- Unclosed\r\ncomment"
+	expected := []messages.Range{
+		{Start: messages.NewPosition(0, 17), End: messages.NewPosition(0, 35)},
+		{Start: messages.NewPosition(1, 0), End: messages.NewPosition(3, 17)},
+		{Start: messages.NewPosition(4, 0), End: messages.NewPosition(5, 7)},
+	}
+	if actual := BlockComments(text); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestParsePositionsAreUTF16(t *testing.T) {
+	r := Parse("Heat to 230°C, add 🧂 @salt{1%g}.")
+	expected := messages.Range{Start: messages.NewPosition(0, 22), End: messages.NewPosition(0, 32)}
+	if actual := r.Steps[0].Ingredients[0].Range; actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{
+			name:     "whitespace is collapsed",
+			a:        "Add the  @salt and\n   stir.",
+			b:        "Add the @salt and stir.",
+			expected: true,
+		},
+		{
+			name:     "empty braces are equivalent to no braces",
+			a:        "Add the @salt{}.",
+			b:        "Add the @salt.",
+			expected: true,
+		},
+		{
+			name:     "spacing within amounts is ignored",
+			a:        "Add the @flour{ 100 % g }.",
+			b:        "Add the @flour{100%g}.",
+			expected: true,
+		},
+		{
+			name:     "comments are ignored",
+			a:        "Add the @salt. -- to taste",
+			b:        "Add the [- fine -] @salt.",
+			expected: true,
+		},
+		{
+			name:     "different amounts are not equivalent",
+			a:        "Add the @flour{100%g}.",
+			b:        "Add the @flour{200%g}.",
+			expected: false,
+		},
+		{
+			name:     "different text is not equivalent",
+			a:        "Add the @salt.",
+			b:        "Add the @salt, then stir.",
+			expected: false,
+		},
+		{
+			name:     "different timers are not equivalent",
+			a:        "Bake for ~{10%minutes}.",
+			b:        "Bake for ~{15%minutes}.",
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := Parse(test.a).Steps[0].Normalized, Parse(test.b).Steps[0].Normalized
+			if actual := a == b; actual != test.expected {
+				t.Errorf("expected equivalence %v, got %v for %q and %q", test.expected, actual, a, b)
+			}
+		})
+	}
+}
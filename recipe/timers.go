@@ -0,0 +1,30 @@
+package recipe
+
+import "strings"
+
+// TimerDeclaration is a named timer that has a duration, which starts the
+// timer.
+type TimerDeclaration struct {
+	Timer Timer
+	// StepIndex is the index of the step that starts the timer.
+	StepIndex int
+}
+
+// TimerNames returns the first declaration of each named timer, keyed by the
+// lower case name of the timer.
+func (r Recipe) TimerNames() (declarations map[string]TimerDeclaration) {
+	declarations = map[string]TimerDeclaration{}
+	for stepIndex, step := range r.Steps {
+		for _, timer := range step.Timers {
+			if timer.Name == "" || timer.Quantity == "" {
+				continue
+			}
+			key := strings.ToLower(timer.Name)
+			if _, ok := declarations[key]; ok {
+				continue
+			}
+			declarations[key] = TimerDeclaration{Timer: timer, StepIndex: stepIndex}
+		}
+	}
+	return
+}
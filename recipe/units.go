@@ -0,0 +1,80 @@
+package recipe
+
+import "strings"
+
+// Unit of a quantity.
+type Unit struct {
+	// Name of the unit, as it's usually written, e.g. "g".
+	Name string
+	// Aliases are the other ways the unit is written, e.g. "grams".
+	Aliases []string
+	// Deprecated units are understood, but discouraged.
+	Deprecated bool
+	// Dimension of the unit. Quantities can only be added together if their
+	// units have the same dimension.
+	Dimension Dimension
+	// Factor converts a quantity in the unit into the base unit of its
+	// dimension: grams for mass, milliliters for volume, and seconds for
+	// time. It's zero for units that can't be converted, like counts, so
+	// quantities in them can only be added to quantities in the same unit.
+	Factor float64
+}
+
+// Dimension of a unit, such as mass or volume.
+type Dimension string
+
+const (
+	DimensionMass   Dimension = "mass"
+	DimensionVolume Dimension = "volume"
+	DimensionCount  Dimension = "count"
+	DimensionTime   Dimension = "time"
+)
+
+// Names returns the name and aliases of the unit.
+func (u Unit) Names() []string {
+	return append([]string{u.Name}, u.Aliases...)
+}
+
+// IngredientUnits are the units of ingredient quantities, grouped into mass,
+// volume and counts.
+var IngredientUnits = []Unit{
+	{Name: "g", Aliases: []string{"gram", "grams"}, Dimension: DimensionMass, Factor: 1},
+	{Name: "kg", Aliases: []string{"kilo", "kilogram", "kilograms"}, Dimension: DimensionMass, Factor: 1000},
+	{Name: "mg", Aliases: []string{"milligram", "milligrams"}, Dimension: DimensionMass, Factor: 0.001},
+	{Name: "oz", Aliases: []string{"ounce", "ounces"}, Deprecated: true, Dimension: DimensionMass, Factor: 28.349523125},
+	{Name: "lb", Aliases: []string{"lbs", "pound", "pounds"}, Dimension: DimensionMass, Factor: 453.59237},
+	{Name: "ml", Aliases: []string{"milliliter", "milliliters", "millilitre", "millilitres"}, Dimension: DimensionVolume, Factor: 1},
+	{Name: "cl", Aliases: []string{"centiliter", "centiliters", "centilitre", "centilitres"}, Dimension: DimensionVolume, Factor: 10},
+	{Name: "dl", Aliases: []string{"deciliter", "deciliters", "decilitre", "decilitres"}, Dimension: DimensionVolume, Factor: 100},
+	{Name: "l", Aliases: []string{"liter", "liters", "litre", "litres"}, Dimension: DimensionVolume, Factor: 1000},
+	// Spoons are metric, and cups and fluid ounces are US customary.
+	{Name: "tsp", Aliases: []string{"teaspoon", "teaspoons"}, Dimension: DimensionVolume, Factor: 5},
+	{Name: "tbsp", Aliases: []string{"tablespoon", "tablespoons"}, Dimension: DimensionVolume, Factor: 15},
+	{Name: "cup", Aliases: []string{"cups"}, Deprecated: true, Dimension: DimensionVolume, Factor: 240},
+	{Name: "fl oz", Aliases: []string{"floz", "fluid ounce", "fluid ounces"}, Dimension: DimensionVolume, Factor: 29.5735295625},
+	{Name: "pinch", Aliases: []string{"pinches"}, Dimension: DimensionCount},
+	{Name: "clove", Aliases: []string{"cloves"}, Dimension: DimensionCount},
+	{Name: "slice", Aliases: []string{"slices"}, Dimension: DimensionCount},
+}
+
+// TimerUnits are the units of timer durations, from the shortest to the
+// longest.
+var TimerUnits = []Unit{
+	{Name: "seconds", Aliases: []string{"s", "sec", "secs", "second"}, Dimension: DimensionTime, Factor: 1},
+	{Name: "minutes", Aliases: []string{"m", "min", "mins", "minute"}, Dimension: DimensionTime, Factor: 60},
+	{Name: "hours", Aliases: []string{"h", "hr", "hrs", "hour"}, Dimension: DimensionTime, Factor: 60 * 60},
+	{Name: "days", Aliases: []string{"d", "day"}, Dimension: DimensionTime, Factor: 24 * 60 * 60},
+}
+
+// LookupUnit returns the unit that has the name or alias, ignoring case.
+func LookupUnit(units []Unit, name string) (u Unit, ok bool) {
+	name = strings.TrimSpace(name)
+	for _, u := range units {
+		for _, n := range u.Names() {
+			if strings.EqualFold(n, name) {
+				return u, true
+			}
+		}
+	}
+	return u, false
+}
@@ -0,0 +1,38 @@
+package recipe
+
+import "testing"
+
+func TestLookupUnit(t *testing.T) {
+	tests := []struct {
+		units    []Unit
+		name     string
+		expected string
+	}{
+		{units: IngredientUnits, name: "g", expected: "g"},
+		{units: IngredientUnits, name: "Grams", expected: "g"},
+		{units: IngredientUnits, name: " fl oz ", expected: "fl oz"},
+		{units: IngredientUnits, name: "grms"},
+		{units: IngredientUnits, name: "minutes"},
+		{units: TimerUnits, name: "mins", expected: "minutes"},
+	}
+	for _, test := range tests {
+		u, ok := LookupUnit(test.units, test.name)
+		if ok != (test.expected != "") || u.Name != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.name, test.expected, u.Name)
+		}
+	}
+}
+
+func TestIngredientUnitFactors(t *testing.T) {
+	for _, u := range IngredientUnits {
+		convertible := u.Dimension == DimensionMass || u.Dimension == DimensionVolume
+		if (u.Factor != 0) != convertible {
+			t.Errorf("%q: unexpected factor %v for a unit of %s", u.Name, u.Factor, u.Dimension)
+		}
+	}
+	g, _ := LookupUnit(IngredientUnits, "grams")
+	kg, _ := LookupUnit(IngredientUnits, "kilo")
+	if kg.Factor/g.Factor != 1000 {
+		t.Errorf("expected a kilo to be 1000 grams, got %v", kg.Factor/g.Factor)
+	}
+}
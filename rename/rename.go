@@ -0,0 +1,33 @@
+// Package rename renames elements of recipes.
+package rename
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// ErrCannotRename is returned when there isn't an element that can be renamed
+// at the position.
+var ErrCannotRename = errors.New("cannot rename this element")
+
+// Ingredient returns the edits that rename every use of the ingredient whose
+// name is at the position. Braces are added to single-word ingredients if the
+// new name has more than one word, and empty braces are removed if it doesn't.
+func Ingredient(text string, p messages.Position, newName string) (edits []messages.TextEdit, err error) {
+	for _, step := range recipe.Parse(text).Steps {
+		for _, ingredient := range step.Ingredients {
+			if !ingredient.NameRange.Contains(p) && ingredient.NameRange.End != p {
+				continue
+			}
+			edits, ok := recipe.RenameIngredient(text, ingredient.Name, newName)
+			if !ok {
+				return nil, fmt.Errorf("%q is not a valid ingredient name", newName)
+			}
+			return edits, nil
+		}
+	}
+	return nil, ErrCannotRename
+}
@@ -0,0 +1,90 @@
+package rename
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestIngredient(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		position messages.Position
+		newName  string
+		expected string
+	}{
+		{
+			name:     "single word to single word",
+			text:     "Add the @salt.\nSeason with more @Salt{1%pinch}.",
+			position: messages.NewPosition(0, 10),
+			newName:  "pepper",
+			expected: "Add the @pepper.\nSeason with more @pepper{1%pinch}.",
+		},
+		{
+			name:     "single word to multiple words adds braces",
+			text:     "Add the @tamari.\nDrizzle the @tamari{2%tbsp} over the top.",
+			position: messages.NewPosition(1, 15),
+			newName:  "soy sauce",
+			expected: "Add the @soy sauce{}.\nDrizzle the @soy sauce{2%tbsp} over the top.",
+		},
+		{
+			name:     "multiple words to single word removes empty braces",
+			text:     "Add the @soy sauce{}.\nDrizzle the @soy sauce{2%tbsp} over the top.",
+			position: messages.NewPosition(0, 13),
+			newName:  "tamari",
+			expected: "Add the @tamari.\nDrizzle the @tamari{2%tbsp} over the top.",
+		},
+		{
+			name:     "empty braces are kept if the next character would join the name",
+			text:     "Add the @soy sauce{}s.",
+			position: messages.NewPosition(0, 9),
+			newName:  "tamari",
+			expected: "Add the @tamari{}s.",
+		},
+		{
+			name:     "the position can be at the end of the name",
+			text:     "Add the @salt",
+			position: messages.NewPosition(0, 13),
+			newName:  "sea salt",
+			expected: "Add the @sea salt{}",
+		},
+		{
+			name:     "other ingredients are not renamed",
+			text:     "Add the @salt and @pepper.",
+			position: messages.NewPosition(0, 20),
+			newName:  "chilli",
+			expected: "Add the @salt and @chilli.",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edits, err := Ingredient(test.text, test.position, test.newName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, err := documents.ApplyEdits(test.text, edits)
+			if err != nil {
+				t.Fatalf("failed to apply edits: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIngredientErrors(t *testing.T) {
+	text := "Put the @flour{100%g} in a #bowl."
+	if _, err := Ingredient(text, messages.NewPosition(0, 30), "tamari"); !errors.Is(err, ErrCannotRename) {
+		t.Errorf("expected %v for cookware, got %v", ErrCannotRename, err)
+	}
+	if _, err := Ingredient(text, messages.NewPosition(0, 17), "tamari"); !errors.Is(err, ErrCannotRename) {
+		t.Errorf("expected %v for the quantity, got %v", ErrCannotRename, err)
+	}
+	if _, err := Ingredient(text, messages.NewPosition(0, 10), "flour{1%kg}"); err == nil || errors.Is(err, ErrCannotRename) {
+		t.Errorf("expected an invalid name error, got %v", err)
+	}
+}
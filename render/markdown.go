@@ -0,0 +1,102 @@
+// Package render writes recipes in other formats.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Markdown writes the recipe as Markdown. The metadata is written as a list,
+// followed by the ingredients and cookware used in the recipe, and a
+// numbered list of the steps.
+//
+// A "title" metadata key is written as the heading.
+func Markdown(w io.Writer, r recipe.Recipe) (err error) {
+	mw := &markdownWriter{w: w}
+	var metadata []recipe.Metadata
+	for _, m := range r.Metadata {
+		if strings.EqualFold(m.Key, "title") {
+			mw.printf("# %s\n\n", escape(m.Value))
+			continue
+		}
+		metadata = append(metadata, m)
+	}
+	if len(metadata) > 0 {
+		for _, m := range metadata {
+			mw.printf("- **%s:** %s\n", escape(m.Key), escape(m.Value))
+		}
+		mw.printf("\n")
+	}
+
+	var ingredients, cookware []string
+	for _, step := range r.Steps {
+		for _, i := range step.Ingredients {
+			ingredients = append(ingredients, amount(i.Name, i.Quantity, i.Unit))
+		}
+		for _, c := range step.Cookware {
+			cookware = append(cookware, amount(c.Name, c.Quantity, ""))
+		}
+	}
+	mw.list("Ingredients", ingredients)
+	mw.list("Cookware", cookware)
+
+	if len(r.Steps) > 0 {
+		mw.printf("## Steps\n\n")
+		for i, step := range r.Steps {
+			mw.printf("%d. %s\n", i+1, escape(step.Text))
+		}
+	}
+	return mw.err
+}
+
+// markdownWriter keeps the first error, so that each write doesn't need to
+// be checked.
+type markdownWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (mw *markdownWriter) printf(format string, args ...any) {
+	if mw.err != nil {
+		return
+	}
+	_, mw.err = fmt.Fprintf(mw.w, format, args...)
+}
+
+func (mw *markdownWriter) list(heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	mw.printf("## %s\n\n", heading)
+	for _, item := range items {
+		mw.printf("- %s\n", escape(item))
+	}
+	mw.printf("\n")
+}
+
+// amount returns the name, followed by the quantity and unit, if there are
+// any, e.g. "flour (100 g)".
+func amount(name, quantity, unit string) string {
+	if q := strings.TrimSpace(quantity + " " + unit); q != "" {
+		return fmt.Sprintf("%s (%s)", name, q)
+	}
+	return name
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	"`", "\\`",
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+)
+
+// escape characters that Markdown treats as formatting.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
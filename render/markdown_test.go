@@ -0,0 +1,55 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/examplelsp/recipe"
+)
+
+func TestMarkdown(t *testing.T) {
+	text := `>> title: Garlic bread
+>> servings: 2
+
+Slice the @bread{1%loaf} with a #bread knife{}.
+Spread the @garlic butter{50%g} on each slice. -- be generous
+
+Bake for ~{10%minutes} in the #oven.`
+
+	var sb strings.Builder
+	if err := Markdown(&sb, recipe.Parse(text)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `# Garlic bread
+
+- **servings:** 2
+
+## Ingredients
+
+- bread (1 loaf)
+- garlic butter (50 g)
+
+## Cookware
+
+- bread knife
+- oven
+
+## Steps
+
+1. Slice the bread with a bread knife. Spread the garlic butter on each slice.
+2. Bake for 10 minutes in the oven.
+`
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
+
+func TestMarkdownEscapesFormatting(t *testing.T) {
+	var sb strings.Builder
+	if err := Markdown(&sb, recipe.Parse("Add the @*secret* sauce{}.")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(sb.String(), `- \*secret\* sauce`) {
+		t.Errorf("expected the ingredient to be escaped, got:\n%s", sb.String())
+	}
+}
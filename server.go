@@ -0,0 +1,956 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/commands"
+	"github.com/a-h/examplelsp/completion"
+	"github.com/a-h/examplelsp/definition"
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/folding"
+	"github.com/a-h/examplelsp/hover"
+	"github.com/a-h/examplelsp/inlayhint"
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/parser"
+	"github.com/a-h/examplelsp/recipe"
+	"github.com/a-h/examplelsp/rename"
+	"github.com/a-h/examplelsp/settings"
+	"github.com/a-h/examplelsp/workspace"
+	"golang.org/x/exp/slog"
+)
+
+// The codes of the checks in this package are registered once, so that more
+// than one server can be created, e.g. in tests.
+func init() {
+	for _, code := range []string{codeParseError, codeAmericanMeasurement, codeSwearword, codeInvalidSettings} {
+		if err := analyzers.Codes.Register(code); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// newServer creates the language server, which reads messages from r and
+// writes them to w. logPath is the log file that's attached to bug reports.
+// Call Process on the returned mux to start handling messages.
+func newServer(log *slog.Logger, r io.Reader, w io.Writer, logPath string) (m *lsp.Mux) {
+	metrics := lsp.NewMemoryMetrics()
+	m = lsp.NewMux(log, r, w,
+		lsp.WithMetrics(metrics),
+		lsp.WithServerInfo(messages.ServerInfo{Name: "examplelsp"}),
+	)
+
+	// Settings and workspace changes can affect many documents, so clients
+	// are asked to request the features that depend on them again.
+	refresher := lsp.NewRefresher(m, lsp.DefaultRefreshDelay)
+
+	p := parser.NewRecovering(log, parser.Cooklang)
+	store := documents.NewStore()
+	cmds := &commands.Commands{
+		Documents: store,
+		ApplyEdit: func(ctx context.Context, params messages.ApplyWorkspaceEditParams) (result messages.ApplyWorkspaceEditResult, err error) {
+			err = m.Call(ctx, messages.ApplyWorkspaceEditRequestMethod, params, &result)
+			return
+		},
+		ShowMessageRequest: func(ctx context.Context, params messages.ShowMessageRequestParams) (action *messages.MessageActionItem, err error) {
+			err = m.Call(ctx, messages.ShowMessageRequestMethod, params, &action)
+			return
+		},
+		ShowDocument: func(ctx context.Context, params messages.ShowDocumentParams) (result messages.ShowDocumentResult, err error) {
+			result.Success, err = m.ShowDocument(ctx, params)
+			return
+		},
+		ClientCapabilities: func() messages.ClientCapabilities {
+			params, _ := m.InitializeParams()
+			return params.Capabilities
+		},
+		PositionEncoding: m.PositionEncoding,
+		Clipboard:        clipboard(),
+	}
+
+	settingsStore := settings.NewStore()
+	settingsStore.Subscribe(refresher.Refresh)
+
+	var folders *workspace.Folders
+	// The initialization options have the same fields as the client's
+	// settings, and are used until the client sends its settings.
+	var initializationOptions settings.Settings
+	m.HandleInitialize(func(params messages.InitializeParams) (capabilities messages.ServerCapabilities, err error) {
+		log.Info("recevied initialize method", slog.Any("params", params))
+
+		if len(params.InitializationOptions) > 0 {
+			options, err := settings.FromClient(params.InitializationOptions)
+			if err != nil {
+				log.Warn("some initialization options are invalid", slog.Any("error", err))
+			}
+			initializationOptions = options
+			settingsStore.SetDefaults(options)
+		}
+
+		folders = workspace.NewFolders(params)
+		roots := folders.Roots()
+		log.Info("resolved workspace roots", slog.Any("roots", roots))
+		// Only the first root is indexed.
+		if len(roots) > 0 {
+			cmds.Workspace = workspace.NewIndex(roots[0])
+			cmds.Workspace.Subscribe(refresher.Refresh)
+		}
+
+		// Hover is enabled by the capability builder, because its handler is
+		// registered.
+		capabilities = lsp.NewCapabilityBuilder(m).Build(messages.ServerCapabilities{
+			// Features work in UTF-16, and every handler converts positions
+			// to and from the negotiated encoding at the protocol boundary.
+			// Command arguments and results stay in UTF-16.
+			PositionEncoding: lsp.NegotiatePositionEncoding(params.Capabilities),
+			TextDocumentSync: messages.TextDocumentSyncOptions{
+				Change: messages.TextDocumentSyncKindFull,
+				// Diagnostics are re-run against the saved text.
+				Save: &messages.SaveOptions{IncludeText: true},
+			},
+			CompletionProvider: &messages.CompletionOptions{
+				TriggerCharacters: completion.TriggerCharacters,
+				ResolveProvider:   true,
+			},
+			ExecuteCommandProvider: &messages.ExecuteCommandOptions{
+				Commands: commands.Names,
+			},
+			CodeActionProvider: &messages.CodeActionOptions{
+				CodeActionKinds: []messages.CodeActionKind{
+					messages.CodeActionKindQuickFix,
+					messages.CodeActionKindRefactor,
+					messages.CodeActionKindSourceFixAll,
+				},
+			},
+		})
+		return capabilities, nil
+	})
+
+	m.HandleNotification("initialized", func(params json.RawMessage) (err error) {
+		log.Info("received initialized notification", slog.Any("params", params))
+		if cmds.Workspace != nil {
+			go func() {
+				// Show the progress of indexing, which the user can cancel.
+				// The index is completed by the next command that needs it.
+				ctx, progress := m.NewProgress(context.Background(), "Indexing recipes")
+				files, err := cmds.Workspace.RefreshContext(ctx, func(done, total int) {
+					progress.Report(fmt.Sprintf("%d/%d", done, total), done*100/total)
+				})
+				if err != nil {
+					log.Warn("failed to index workspace", slog.Any("error", err))
+					progress.End("Indexing stopped")
+					return
+				}
+				log.Info("indexed workspace", slog.Int("files", len(files)))
+				progress.End(fmt.Sprintf("Indexed %d recipes", len(files)))
+			}()
+		}
+		return nil
+	})
+
+	// status of the server, which includes counters for the requests that
+	// the server sent to the client.
+	status := func() any {
+		return struct {
+			lsp.MetricsSnapshot
+			Calls lsp.CallStats `json:"calls"`
+		}{
+			MetricsSnapshot: metrics.Snapshot(),
+			Calls:           m.CallStats(),
+		}
+	}
+	m.HandleMethod("examplelsp/metrics", func(params json.RawMessage) (result any, err error) {
+		return status(), nil
+	})
+
+	m.HandleNotification(messages.DidChangeWorkspaceFoldersNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didChangeWorkspaceFolders notification")
+
+		var params messages.DidChangeWorkspaceFoldersParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		folders.Change(params.Event)
+		log.Info("updated workspace roots", slog.Any("roots", folders.Roots()))
+
+		return nil
+	})
+
+	// Settings sent by the client are used for fields that a project's
+	// settings file doesn't set.
+	m.HandleNotification(messages.DidChangeConfigurationNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didChangeConfiguration notification")
+
+		var params messages.DidChangeConfigurationParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		s, err := settings.FromClient(params.Settings)
+		if err != nil {
+			log.Warn("some client settings are invalid", slog.Any("error", err))
+		}
+		// Clients that only send swear words in the initialization options
+		// keep them when other settings change.
+		if s.SwearWords == nil {
+			s.SwearWords = initializationOptions.SwearWords
+		}
+		if s.SwearWordsEnabled == nil {
+			s.SwearWordsEnabled = initializationOptions.SwearWordsEnabled
+		}
+		// Open documents are analyzed again when the settings change, and
+		// the refresher asks the client to request the features that it
+		// caches again.
+		log.Info("applying client settings", slog.Any("settings", s))
+		settingsStore.SetDefaults(s)
+
+		return nil
+	})
+	getSettings := func(uri string) settings.Snapshot {
+		dir, err := uriToDir(uri)
+		if err != nil {
+			log.Warn("failed to find directory of document", slog.String("uri", uri), slog.Any("error", err))
+			return settings.Settings{}.Snapshot()
+		}
+		s, err := settingsStore.Snapshot(dir)
+		if err != nil {
+			log.Warn("failed to load settings", slog.String("dir", dir), slog.Any("error", err))
+		}
+		return s
+	}
+	getSnippets := func(uri string) map[string]string {
+		dir, err := uriToDir(uri)
+		if err != nil {
+			return nil
+		}
+		s, err := settingsStore.Settings(dir)
+		if err != nil {
+			log.Warn("failed to load settings", slog.String("dir", dir), slog.Any("error", err))
+		}
+		return s.Snippets
+	}
+	snippetSupport := func() bool {
+		params, _ := m.InitializeParams()
+		if params.Capabilities.TextDocument == nil {
+			return false
+		}
+		return params.Capabilities.TextDocument.Completion.SnippetSupport()
+	}
+	markdownDocumentation := func() bool {
+		params, _ := m.InitializeParams()
+		if params.Capabilities.TextDocument == nil {
+			return false
+		}
+		return params.Capabilities.TextDocument.Completion.SupportsDocumentationFormat(messages.MarkupKindMarkdown)
+	}
+
+	m.HandleMethod(messages.CompletionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received completion request", slog.Any("params", rawParams))
+
+		var params messages.CompletionParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		document, _ := store.Get(params.TextDocument.URI)
+		encoding := m.PositionEncoding()
+		params.Position = documents.DecodePosition(document.Text, params.Position, encoding)
+		list := completion.Complete(document.Text, params, completion.Options{
+			Parser:         p,
+			Snippets:       getSnippets(params.TextDocument.URI),
+			SnippetSupport: snippetSupport(),
+		})
+		return encodeCompletionList(document.Text, list, encoding), nil
+	})
+
+	// Documentation is left out of the completion response, and filled in
+	// when the client resolves an item.
+	m.HandleMethod(messages.CompletionItemResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received completion item resolve request", slog.Any("params", rawParams))
+
+		var item messages.CompletionItem
+		if err = json.Unmarshal(rawParams, &item); err != nil {
+			return
+		}
+		return completion.Resolve(item, markdownDocumentation()), nil
+	})
+
+	m.HandleMethod(messages.HoverRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received hover request", slog.Any("params", rawParams))
+
+		var params messages.HoverParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		initializeParams, _ := m.InitializeParams()
+		var markdown bool
+		if textDocument := initializeParams.Capabilities.TextDocument; textDocument != nil {
+			markdown = textDocument.Hover.SupportsContentFormat(messages.MarkupKindMarkdown)
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		encoding := m.PositionEncoding()
+		position := documents.DecodePosition(doc.Text, params.Position, encoding)
+		h := hover.Timer(doc.Text, position, markdown)
+		if h == nil {
+			h = hover.Ingredient(doc.Text, position, markdown)
+		}
+		if h == nil {
+			h = hover.Cookware(params.TextDocument.URI, doc.Text, position, markdown)
+		}
+		if h != nil && h.Range != nil {
+			r := documents.EncodeRange(doc.Text, *h.Range, encoding)
+			h.Range = &r
+		}
+		return h, nil
+	})
+
+	m.HandleMethod(messages.DefinitionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received definition request", slog.Any("params", rawParams))
+
+		var params messages.DefinitionParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		// Return a LocationLink if the client supports it, so that the whole
+		// step that starts the timer can be shown when peeking.
+		initializeParams, _ := m.InitializeParams()
+		textDocument := initializeParams.Capabilities.TextDocument
+		linkSupport := textDocument != nil && textDocument.Definition != nil && textDocument.Definition.LinkSupport
+
+		// Ingredients and cookware are defined by their first use in the
+		// recipe.
+		doc, _ := store.Get(params.TextDocument.URI)
+		encoding := m.PositionEncoding()
+		position := documents.DecodePosition(doc.Text, params.Position, encoding)
+		for _, find := range []func(uri, text string, p messages.Position, linkSupport bool) messages.DefinitionResult{
+			definition.Timer,
+			definition.Ingredient,
+			definition.Cookware,
+		} {
+			if result := find(params.TextDocument.URI, doc.Text, position, linkSupport); result.Location != nil || result.LocationLinks != nil {
+				return encodeDefinition(doc.Text, result, encoding), nil
+			}
+		}
+		return messages.DefinitionResult{}, nil
+	})
+
+	m.HandleMethod(messages.RenameRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received rename request", slog.Any("params", rawParams))
+
+		var params messages.RenameParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		encoding := m.PositionEncoding()
+		position := documents.DecodePosition(doc.Text, params.Position, encoding)
+		edits, err := rename.Ingredient(doc.Text, position, params.NewName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range edits {
+			edits[i].Range = documents.EncodeRange(doc.Text, edits[i].Range, encoding)
+		}
+		return messages.NewWorkspaceEdit(params.TextDocument.URI, edits...), nil
+	})
+
+	m.HandleMethod(messages.DocumentSymbolRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received document symbol request", slog.Any("params", rawParams))
+
+		var params messages.DocumentSymbolParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		// Clients that don't support nested symbols are sent a flat list,
+		// where each element's container is its step.
+		initializeParams, _ := m.InitializeParams()
+		var capabilities *messages.DocumentSymbolClientCapabilities
+		if textDocument := initializeParams.Capabilities.TextDocument; textDocument != nil {
+			capabilities = textDocument.DocumentSymbol
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		symbols := encodeDocumentSymbols(doc.Text, workspace.DocumentSymbols(doc.Text), m.PositionEncoding())
+		return messages.NewDocumentSymbolResult(params.TextDocument.URI, symbols, capabilities), nil
+	})
+
+	// Links to base recipes are returned without targets, which are computed
+	// when the client resolves them.
+	m.HandleMethod(messages.DocumentLinkRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received document link request", slog.Any("params", rawParams))
+
+		var params messages.DocumentLinkParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		links := workspace.DocumentLinks(params.TextDocument.URI, doc.Text)
+		encoding := m.PositionEncoding()
+		for i := range links {
+			links[i].Range = documents.EncodeRange(doc.Text, links[i].Range, encoding)
+		}
+		return links, nil
+	})
+
+	m.HandleMethod(messages.DocumentLinkResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received document link resolve request", slog.Any("params", rawParams))
+
+		var link messages.DocumentLink
+		if err = json.Unmarshal(rawParams, &link); err != nil {
+			return
+		}
+		return workspace.ResolveDocumentLink(link)
+	})
+
+	m.HandleMethod(messages.FoldingRangeRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received folding range request", slog.Any("params", rawParams))
+
+		var params messages.FoldingRangeParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		initializeParams, _ := m.InitializeParams()
+		var capabilities *messages.FoldingRangeClientCapabilities
+		if textDocument := initializeParams.Capabilities.TextDocument; textDocument != nil {
+			capabilities = textDocument.FoldingRange
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		return capabilities.Limit(folding.Ranges(doc.Text)), nil
+	})
+
+	// Hints are returned without tooltips, which are computed when the user
+	// hovers over a hint, and the client resolves it.
+	m.HandleMethod(messages.InlayHintRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received inlay hint request", slog.Any("params", rawParams))
+
+		var params messages.InlayHintParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		initializeParams, _ := m.InitializeParams()
+		var resolvesTooltip bool
+		if textDocument := initializeParams.Capabilities.TextDocument; textDocument != nil {
+			resolvesTooltip = textDocument.InlayHint.Resolves("tooltip")
+		}
+
+		doc, _ := store.Get(params.TextDocument.URI)
+		encoding := m.PositionEncoding()
+		r := documents.DecodeRange(doc.Text, params.Range, encoding)
+		hints := inlayhint.Timers(params.TextDocument.URI, doc.Text, r, !resolvesTooltip)
+		for i := range hints {
+			hints[i].Position = documents.EncodePosition(doc.Text, hints[i].Position, encoding)
+		}
+		return hints, nil
+	})
+
+	m.HandleMethod(messages.InlayHintResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received inlay hint resolve request", slog.Any("params", rawParams))
+
+		var hint messages.InlayHint
+		if err = json.Unmarshal(rawParams, &hint); err != nil {
+			return
+		}
+		return inlayhint.Resolve(hint, func(uri string) string {
+			doc, _ := store.Get(uri)
+			return doc.Text
+		})
+	})
+
+	m.HandleMethod(messages.WorkspaceSymbolRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received workspace symbol request", slog.Any("params", rawParams))
+
+		var params messages.WorkspaceSymbolParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		// Open documents take precedence over the text on disk.
+		texts := map[string]string{}
+		if cmds.Workspace != nil {
+			files, err := cmds.Workspace.Refresh()
+			if err != nil {
+				log.Warn("failed to index workspace", slog.Any("error", err))
+			}
+			for _, f := range files {
+				texts[f.URI] = f.Text
+			}
+		}
+		for _, uri := range store.URIs() {
+			if doc, ok := store.Get(uri); ok {
+				texts[uri] = doc.Text
+			}
+		}
+		uris := make([]string, 0, len(texts))
+		for uri := range texts {
+			uris = append(uris, uri)
+		}
+		sort.Strings(uris)
+
+		// Clients that resolve the ranges of symbols are sent the titles of
+		// recipes without a range, which is found when a title is picked.
+		initializeParams, _ := m.InitializeParams()
+		var resolvesRange bool
+		if w := initializeParams.Capabilities.Workspace; w != nil && w.Symbol != nil {
+			resolvesRange = w.Symbol.ResolvesLocationRange()
+		}
+
+		// Send the symbols of each recipe as a batch, so that clients that
+		// support partial results can show them as they're found.
+		sender := m.PartialResultSender(params.PartialResultToken)
+		encoding := m.PositionEncoding()
+		for _, uri := range uris {
+			var symbols any = encodeSymbols(texts[uri], workspace.Symbols(uri, texts[uri], params.Query), encoding)
+			if resolvesRange {
+				symbols = encodeWorkspaceSymbols(texts[uri], workspace.WorkspaceSymbols(uri, texts[uri], params.Query), encoding)
+			}
+			if err = sender.Send(symbols); err != nil {
+				return
+			}
+		}
+		return sender.Result(), nil
+	})
+
+	m.HandleMethod(messages.WorkspaceSymbolResolveRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received workspace symbol resolve request", slog.Any("params", rawParams))
+
+		var symbol messages.WorkspaceSymbol
+		if err = json.Unmarshal(rawParams, &symbol); err != nil {
+			return
+		}
+		// Open documents take precedence over the text on disk.
+		var text string
+		if doc, ok := store.Get(symbol.Location.URI); ok {
+			text = doc.Text
+		} else if cmds.Workspace != nil {
+			text = cmds.Workspace.Texts()[symbol.Location.URI]
+		}
+		// Symbols that already have a range were encoded when they were
+		// returned.
+		if symbol.Location.Range != nil {
+			return symbol, nil
+		}
+		resolved, err := workspace.ResolveWorkspaceSymbol(symbol, text)
+		if err != nil {
+			return nil, err
+		}
+		return encodeWorkspaceSymbols(text, []messages.WorkspaceSymbol{resolved}, m.PositionEncoding())[0], nil
+	})
+
+	m.HandleMethod(messages.ExecuteCommandRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received execute command request", slog.Any("params", rawParams))
+
+		var params messages.ExecuteCommandParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		log.Info("executing command", slog.String("command", params.Command), slog.Int("arguments", len(params.Arguments)))
+
+		// If the client sent a token, show the progress of the command, so
+		// that the user can cancel it.
+		ctx := context.Background()
+		if params.WorkDoneToken != nil {
+			var progress *lsp.Progress
+			ctx, progress = m.WorkDoneProgress(ctx, params.WorkDoneToken, params.Command)
+			defer progress.End("")
+		}
+		return cmds.Execute(ctx, params)
+	})
+
+	m.HandleMethod(messages.CodeActionRequestMethod, func(rawParams json.RawMessage) (result any, err error) {
+		log.Info("received code action request", slog.Any("params", rawParams))
+
+		var params messages.CodeActionParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		uri := params.TextDocument.URI
+		doc, _ := store.Get(uri)
+		// Fixes are computed in UTF-16, and converted to and from the
+		// position encoding used by the client.
+		encoding := m.PositionEncoding()
+		actions := []messages.CodeAction{}
+		if codeActionKindRequested(params.Context.Only, messages.CodeActionKindQuickFix) {
+			for _, d := range params.Context.Diagnostics {
+				if d.CodeDescription != nil && d.Code != nil {
+					args, err := json.Marshal(commands.OpenDocumentationArgs{URL: d.CodeDescription.HREF})
+					if err != nil {
+						return nil, err
+					}
+					actions = append(actions, messages.CodeAction{
+						Title:       fmt.Sprintf("Open documentation for %s", *d.Code),
+						Kind:        messages.CodeActionKindQuickFix,
+						Diagnostics: []messages.Diagnostic{d},
+						Command: &messages.Command{
+							Title:     "Open documentation",
+							Command:   commands.OpenDocumentation,
+							Arguments: []json.RawMessage{args},
+						},
+					})
+				}
+				decoded := d
+				decoded.Range = documents.DecodeRange(doc.Text, d.Range, encoding)
+				// Some diagnostics can be fixed in more than one way, so none
+				// of the fixes is preferred.
+				var fixes []func(string, messages.Diagnostic) (string, messages.TextEdit, bool)
+				if d.Code != nil && *d.Code == codeSwearword {
+					fixes = append(fixes, analyzers.CensorWordFix, analyzers.RemoveWordFix)
+				}
+				for _, unit := range analyzers.MissingUnitSuggestions {
+					unit := unit
+					fixes = append(fixes, func(text string, d messages.Diagnostic) (string, messages.TextEdit, bool) {
+						return analyzers.AddUnitFix(text, d, unit)
+					})
+				}
+				var fixed bool
+				for _, fix := range fixes {
+					title, edit, ok := fix(doc.Text, decoded)
+					if !ok {
+						continue
+					}
+					fixed = true
+					edit.Range = documents.EncodeRange(doc.Text, edit.Range, encoding)
+					actions = append(actions, messages.CodeAction{
+						Title:       title,
+						Kind:        messages.CodeActionKindQuickFix,
+						Diagnostics: []messages.Diagnostic{d},
+						Edit:        ptr(messages.NewWorkspaceEdit(uri, edit)),
+					})
+				}
+				if fixed {
+					continue
+				}
+				title, edit, ok := analyzers.WhitespaceFix(decoded)
+				if !ok {
+					title, edit, ok = analyzers.DuplicateStepFix(doc.Text, decoded)
+				}
+				if !ok {
+					continue
+				}
+				edit.Range = documents.EncodeRange(doc.Text, edit.Range, encoding)
+				actions = append(actions, messages.CodeAction{
+					Title:       title,
+					Kind:        messages.CodeActionKindQuickFix,
+					Diagnostics: []messages.Diagnostic{d},
+					IsPreferred: true,
+					Edit:        ptr(messages.NewWorkspaceEdit(uri, edit)),
+				})
+			}
+		}
+		if codeActionKindRequested(params.Context.Only, messages.CodeActionKindRefactor) {
+			start := documents.DecodePosition(doc.Text, params.Range.Start, encoding)
+			if ingredient, ok := recipe.Parse(doc.Text).IngredientAt(start); ok {
+				// The client prompts for the new name, and adds it to the
+				// arguments.
+				args, err := json.Marshal(commands.RenameIngredientEverywhereArgs{URI: uri, Position: start})
+				if err != nil {
+					return nil, err
+				}
+				actions = append(actions, messages.CodeAction{
+					Title: fmt.Sprintf("Rename %q in all recipes", ingredient.Name),
+					Kind:  messages.CodeActionKindRefactor,
+					Command: &messages.Command{
+						Title:     "Rename ingredient everywhere",
+						Command:   commands.RenameIngredientEverywhere,
+						Arguments: []json.RawMessage{args},
+					},
+				})
+			}
+		}
+		if codeActionKindRequested(params.Context.Only, messages.CodeActionKindSourceFixAll) && getSettings(uri).StyleEnabled() {
+			if edits := analyzers.WhitespaceFixAll(doc.Text); len(edits) > 0 {
+				for i := range edits {
+					edits[i].Range = documents.EncodeRange(doc.Text, edits[i].Range, encoding)
+				}
+				actions = append(actions, messages.CodeAction{
+					Title: "Fix all whitespace problems",
+					Kind:  messages.CodeActionKindSourceFixAll,
+					Edit:  ptr(messages.NewWorkspaceEdit(uri, edits...)),
+				})
+			}
+		}
+		return actions, nil
+	})
+
+	cmds.Logs = commands.LogConfig{
+		Path:     logPath,
+		Version:  version(),
+		Counters: status,
+		Settings: func() any {
+			if cmds.Workspace == nil {
+				return nil
+			}
+			s, _ := settingsStore.Snapshot(cmds.Workspace.Root())
+			return s
+		},
+		Redactions: func() map[string]string {
+			redactions := map[string]string{}
+			if home, err := os.UserHomeDir(); err == nil {
+				redactions[home] = "~"
+			}
+			if cmds.Workspace != nil {
+				redactions[cmds.Workspace.Root()] = "<workspace>"
+			}
+			return redactions
+		},
+	}
+
+	// Every check declares the codes it produces, so that its diagnostics
+	// link to the documentation of the code.
+	declare := func(a analyzers.Analyzer, codes ...string) analyzers.Analyzer {
+		a, err := analyzers.Codes.Declare(a, codes...)
+		if err != nil {
+			log.Error("failed to declare diagnostic codes", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return a
+	}
+	invalidSettingsDescription, _ := analyzers.Codes.Description(codeInvalidSettings)
+
+	// Cheap analyzers are published first, so that opening a large document
+	// shows parse errors without waiting for the expensive analyzers.
+	documentAnalyzers := []analyzers.Analyzer{
+		declare(analyzers.AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+			return getRecipeParseErrorDiagnostics(p, doc.Text)
+		}), codeParseError),
+		declare(analyzers.AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+			return getAmericanMeasurementsDiagnostics(p, doc.Text)
+		}), codeAmericanMeasurement),
+		declare(analyzers.WithCost(analyzers.AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+			return getSwearwordDiagnostics(doc.Text, configuredSwearWords(s))
+		}), analyzers.CostExpensive), codeSwearword),
+		declare(analyzers.WithCost(analyzers.AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+			_, err := p.Parse(doc.Text)
+			return analyzers.UnknownUnits(doc.Text, err)
+		}), analyzers.CostExpensive), analyzers.CodeUnknownUnit),
+		// Base links can point at recipes that aren't open, so the cycles are
+		// found in the indexed workspace, with open documents taking
+		// precedence over the text on disk.
+		declare(analyzers.WithCost(analyzers.AnalyzerFunc(func(doc messages.TextDocumentItem, s settings.Snapshot) []messages.Diagnostic {
+			texts := map[string]string{}
+			if cmds.Workspace != nil {
+				texts = cmds.Workspace.Texts()
+			}
+			for _, uri := range store.URIs() {
+				if d, ok := store.Get(uri); ok {
+					texts[uri] = d.Text
+				}
+			}
+			texts[doc.URI] = doc.Text
+			return analyzers.LinkCycles(doc.URI, workspace.NewGraph(texts).Cycles())
+		}), analyzers.CostExpensive), analyzers.CodeLinkCycle),
+	}
+	documentAnalyzers = append(documentAnalyzers, analyzers.Defaults...)
+	publisher := analyzers.NewPublisher(func(params messages.PublishDiagnosticsParams) {
+		// Only send the tags, related information and version that the
+		// client supports.
+		initializeParams, _ := m.InitializeParams()
+		var c *messages.PublishDiagnosticsClientCapabilities
+		if textDocument := initializeParams.Capabilities.TextDocument; textDocument != nil {
+			c = textDocument.PublishDiagnostics
+		}
+		m.Notify(messages.PublishDiagnosticsMethod, analyzers.ForClient(params, c))
+	})
+	// checkSettingsFile publishes the problems in the settings file of the
+	// document, so that the user can see why their settings aren't used.
+	checkSettingsFile := func(uri string) {
+		dir, err := uriToDir(uri)
+		if err != nil {
+			return
+		}
+		path, ok := settings.Find(dir)
+		if !ok {
+			return
+		}
+		settingsURI, err := workspace.URIFromPath(path)
+		if err != nil {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn("failed to read settings", slog.String("path", path), slog.Any("error", err))
+			return
+		}
+		diagnostics := []messages.Diagnostic{}
+		for _, problem := range settings.Check(data) {
+			diagnostics = append(diagnostics, messages.Diagnostic{
+				Range: messages.Range{
+					Start: documents.PositionAt(string(data), problem.Start),
+					End:   documents.PositionAt(string(data), problem.End),
+				},
+				Severity:        ptr(messages.DiagnosticSeverityError),
+				Code:            ptr(codeInvalidSettings),
+				CodeDescription: invalidSettingsDescription,
+				Source:          ptr(sourceSettings),
+				Message:         problem.Message,
+			})
+		}
+		publisher.Publish(messages.PublishDiagnosticsParams{
+			URI:         settingsURI,
+			Diagnostics: encodeDiagnostics(string(data), diagnostics, m.PositionEncoding()),
+		})
+	}
+	analyze := func(doc messages.TextDocumentItem, phased bool) {
+		publish := func(diagnostics []messages.Diagnostic) {
+			publisher.Publish(messages.PublishDiagnosticsParams{
+				URI:         doc.URI,
+				Version:     &doc.Version,
+				Diagnostics: encodeDiagnostics(doc.Text, diagnostics, m.PositionEncoding()),
+			})
+		}
+		s := getSettings(doc.URI)
+		if publisher.RefreshIfIdle(doc.URI, s.DiagnosticsRefresh()) {
+			log.Info("refreshing idle diagnostics", slog.String("uri", doc.URI))
+		}
+		checkSettingsFile(doc.URI)
+		if phased {
+			analyzers.AnalyzeInPhases(doc, s, publish, documentAnalyzers...)
+			return
+		}
+		publish(analyzers.Analyze(doc, s, documentAnalyzers...))
+	}
+
+	// Create a queue to process document updates in the order they're received.
+	type documentUpdate struct {
+		doc messages.TextDocumentItem
+		// opened is set when the document has just been opened, so there are
+		// no diagnostics for it yet.
+		opened bool
+		// refresh is set when the client might have cleared the diagnostics
+		// of the document, e.g. after it's saved, so they're published even
+		// if they haven't changed. Only the URI of doc is set, and the
+		// document is analyzed as it is in the store.
+		refresh bool
+		// closed is set when the document has been closed, so it's removed
+		// from the store and its diagnostics are cleared. Only the URI of
+		// doc is set.
+		closed bool
+		// savedText is the content of the document when it was saved, if the
+		// client included it. It replaces the text in the store.
+		savedText *string
+	}
+	documentUpdates := make(chan documentUpdate, 10)
+	// Analyze all documents again when settings change. Changes that happen
+	// while documents are being analyzed result in a single extra run.
+	settingsChanged := make(chan struct{}, 1)
+	settingsStore.Subscribe(func() {
+		select {
+		case settingsChanged <- struct{}{}:
+		default:
+		}
+	})
+	go func() {
+		for {
+			select {
+			case update := <-documentUpdates:
+				if update.closed {
+					store.Delete(update.doc.URI)
+					publisher.Clear(update.doc.URI)
+					continue
+				}
+				if update.refresh {
+					doc, ok := store.Get(update.doc.URI)
+					if !ok {
+						continue
+					}
+					if update.savedText != nil {
+						doc.Text = *update.savedText
+						store.Set(doc)
+					}
+					publisher.Refresh(doc.URI)
+					analyze(doc, false)
+					continue
+				}
+				store.Set(update.doc)
+				analyze(update.doc, update.opened)
+			case <-settingsChanged:
+				for _, uri := range store.URIs() {
+					if doc, ok := store.Get(uri); ok {
+						analyze(doc, false)
+					}
+				}
+			}
+		}
+	}()
+
+	m.HandleNotification(messages.DidOpenTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didOpenTextDocument notification")
+
+		var params messages.DidOpenTextDocumentParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		documentUpdates <- documentUpdate{doc: params.TextDocument, opened: true}
+
+		return nil
+	})
+
+	m.HandleNotification(messages.DidChangeTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didChangeTextDocument notification")
+
+		var params messages.DidChangeTextDocumentParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+
+		// In our response to Initializes, we told the client that we need the
+		// full content of every document every time - we can't handle partial
+		// updates, so there's got to only be one event.
+		documentUpdates <- documentUpdate{
+			doc: messages.TextDocumentItem{
+				URI:     params.TextDocument.URI,
+				Version: params.TextDocument.Version,
+				Text:    params.ContentChanges[0].Text,
+			},
+		}
+
+		return nil
+	})
+
+	m.HandleNotification(messages.DidSaveTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didSaveTextDocument notification")
+
+		var params messages.DidSaveTextDocumentParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		// Some clients clear diagnostics when a document is saved.
+		documentUpdates <- documentUpdate{
+			doc:       messages.TextDocumentItem{URI: params.TextDocument.URI},
+			refresh:   true,
+			savedText: params.Text,
+		}
+
+		return nil
+	})
+	m.HandleNotification(messages.DidCloseTextDocumentNotification, func(rawParams json.RawMessage) (err error) {
+		log.Info("received didCloseTextDocument notification")
+
+		var params messages.DidCloseTextDocumentParams
+		if err = json.Unmarshal(rawParams, &params); err != nil {
+			return
+		}
+		documentUpdates <- documentUpdate{doc: messages.TextDocumentItem{URI: params.TextDocument.URI}, closed: true}
+
+		return nil
+	})
+	cmds.RefreshDiagnostics = func(uri string) {
+		documentUpdates <- documentUpdate{doc: messages.TextDocumentItem{URI: uri}, refresh: true}
+	}
+
+	return m
+}
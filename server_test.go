@@ -0,0 +1,539 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/examplelsp/analyzers"
+	"github.com/a-h/examplelsp/lsp"
+	"github.com/a-h/examplelsp/lsp/lsptest"
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/workspace"
+	"golang.org/x/exp/slog"
+)
+
+// testClientCapabilities are the capabilities of the client in server tests,
+// which accepts versioned diagnostics.
+var testClientCapabilities = messages.ClientCapabilities{
+	TextDocument: &messages.TextDocumentClientCapabilities{
+		PublishDiagnostics: &messages.PublishDiagnosticsClientCapabilities{VersionSupport: true},
+	},
+}
+
+// utf8ClientCapabilities are the capabilities of a client that prefers UTF-8
+// positions.
+var utf8ClientCapabilities = messages.ClientCapabilities{
+	General: &messages.GeneralClientCapabilities{
+		PositionEncodings: []messages.PositionEncodingKind{messages.PositionEncodingKindUTF8, messages.PositionEncodingKindUTF16},
+	},
+}
+
+// newTestServer starts a server, connected to a test client, and initializes
+// it with the params.
+func newTestServer(t *testing.T, params messages.InitializeParams) (client *lsptest.Client, result messages.InitializeResult) {
+	t.Helper()
+	r, w, client := lsptest.New()
+	t.Cleanup(func() { client.Close() })
+	m := newServer(slog.New(slog.NewJSONHandler(io.Discard, nil)), r, w, filepath.Join(t.TempDir(), "examplelsp.log"))
+	go m.Process()
+	if err := client.Call("initialize", params, &result); err != nil {
+		t.Fatalf("failed to initialize: %v", err)
+	}
+	if err := client.Notify("initialized", struct{}{}); err != nil {
+		t.Fatalf("failed to send initialized: %v", err)
+	}
+	return client, result
+}
+
+// testDocumentURI returns the URI of a recipe in a temporary directory, so
+// that no settings file is found for it.
+func testDocumentURI(t *testing.T) string {
+	t.Helper()
+	uri, err := workspace.URIFromPath(filepath.Join(t.TempDir(), "recipe.cook"))
+	if err != nil {
+		t.Fatalf("failed to create URI: %v", err)
+	}
+	return uri
+}
+
+// openDocument opens the text as the first version of the document. Messages
+// are handled concurrently, so it waits for the document's diagnostics to be
+// published, before requests about the document are sent. The publish is
+// consumed, so tests of the diagnostics open documents themselves.
+func openDocument(t *testing.T, client *lsptest.Client, uri, text string) {
+	t.Helper()
+	if err := client.Notify(messages.DidOpenTextDocumentNotification, messages.DidOpenTextDocumentParams{
+		TextDocument: messages.TextDocumentItem{URI: uri, Version: 1, Text: text},
+	}); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, func(messages.PublishDiagnosticsParams) bool { return true })
+}
+
+// changeDocument replaces the text of the document, as clients do with full
+// document sync.
+func changeDocument(t *testing.T, client *lsptest.Client, uri string, version int, text string) {
+	t.Helper()
+	if err := client.Notify(messages.DidChangeTextDocumentNotification, messages.DidChangeTextDocumentParams{
+		TextDocument:   messages.VersionedTextDocumentIdentifier{URI: uri, Version: version},
+		ContentChanges: []messages.TextDocumentContentChangeEvent{{Text: text}},
+	}); err != nil {
+		t.Fatalf("failed to change: %v", err)
+	}
+}
+
+// waitForDiagnostics waits for diagnostics of the document to be published
+// that match, skipping the others, e.g. those of an earlier analysis phase.
+func waitForDiagnostics(t *testing.T, client *lsptest.Client, uri string, match func(params messages.PublishDiagnosticsParams) bool) messages.PublishDiagnosticsParams {
+	t.Helper()
+	deadline := time.Now().Add(lsptest.DefaultTimeout)
+	var last *messages.PublishDiagnosticsParams
+	for time.Now().Before(deadline) {
+		n, err := client.WaitForNotification(messages.PublishDiagnosticsMethod)
+		if err != nil {
+			break
+		}
+		var params messages.PublishDiagnosticsParams
+		if err := json.Unmarshal(n.Params, &params); err != nil {
+			t.Fatalf("failed to unmarshal diagnostics: %v", err)
+		}
+		if params.URI != uri {
+			continue
+		}
+		if match(params) {
+			return params
+		}
+		last = &params
+	}
+	t.Fatalf("timed out waiting for diagnostics of %s, last received %#v", uri, last)
+	return messages.PublishDiagnosticsParams{}
+}
+
+// hasCode returns a match for waitForDiagnostics that's true for the version
+// of the document when it has, or doesn't have, a diagnostic with the code.
+func hasCode(version int, code string, expected bool) func(params messages.PublishDiagnosticsParams) bool {
+	return func(params messages.PublishDiagnosticsParams) bool {
+		if params.Version == nil || *params.Version != version {
+			return false
+		}
+		var found bool
+		for _, d := range params.Diagnostics {
+			if d.Code != nil && *d.Code == code {
+				found = true
+			}
+		}
+		return found == expected
+	}
+}
+
+func TestServerClearsDiagnosticsOfClosedDocuments(t *testing.T) {
+	client, _ := newTestServer(t, messages.InitializeParams{Capabilities: testClientCapabilities})
+	uri := testDocumentURI(t)
+
+	if err := client.Notify(messages.DidOpenTextDocumentNotification, messages.DidOpenTextDocumentParams{
+		TextDocument: messages.TextDocumentItem{URI: uri, Version: 1, Text: "Boil @water{1%l}."},
+	}); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasCode(1, codeSwearword, false))
+
+	if err := client.Notify(messages.DidChangeTextDocumentNotification, messages.DidChangeTextDocumentParams{
+		TextDocument:   messages.VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []messages.TextDocumentContentChangeEvent{{Text: "Boil the bloody @water{1%l}."}},
+	}); err != nil {
+		t.Fatalf("failed to change: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasCode(2, codeSwearword, true))
+
+	if err := client.Notify(messages.DidCloseTextDocumentNotification, messages.DidCloseTextDocumentParams{
+		TextDocument: messages.TextDocumentIdentifier{URI: uri},
+	}); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	cleared := waitForDiagnostics(t, client, uri, func(params messages.PublishDiagnosticsParams) bool {
+		return params.Version == nil
+	})
+	if cleared.Diagnostics == nil || len(cleared.Diagnostics) != 0 {
+		t.Errorf("expected an empty set of diagnostics, got %#v", cleared.Diagnostics)
+	}
+
+	// The client has no diagnostics for the reopened document, so they're
+	// sent even though they're the same as before it was closed.
+	if err := client.Notify(messages.DidOpenTextDocumentNotification, messages.DidOpenTextDocumentParams{
+		TextDocument: messages.TextDocumentItem{URI: uri, Version: 1, Text: "Boil the bloody @water{1%l}."},
+	}); err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasCode(1, codeSwearword, true))
+}
+
+// hasSwearWords returns a match for waitForDiagnostics that's true for the
+// version of the document when the swearword diagnostics are at the ranges.
+func hasSwearWords(version int, expected ...messages.Range) func(params messages.PublishDiagnosticsParams) bool {
+	return func(params messages.PublishDiagnosticsParams) bool {
+		if params.Version == nil || *params.Version != version {
+			return false
+		}
+		var actual []messages.Range
+		for _, d := range params.Diagnostics {
+			if d.Code != nil && *d.Code == codeSwearword {
+				actual = append(actual, d.Range)
+			}
+		}
+		if len(actual) != len(expected) {
+			return false
+		}
+		for i := range actual {
+			if actual[i] != expected[i] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func TestServerUsesConfiguredSwearWords(t *testing.T) {
+	client, _ := newTestServer(t, messages.InitializeParams{
+		Capabilities:          testClientCapabilities,
+		InitializationOptions: json.RawMessage(`{"swearWords": ["Blimey", "sod off"]}`),
+	})
+	uri := testDocumentURI(t)
+	text := "Stir the bloody sauce, blimey, or sod off."
+	rangeOf := func(s string) messages.Range {
+		start := strings.Index(text, s)
+		return messages.Range{
+			Start: messages.Position{Line: 0, Character: start},
+			End:   messages.Position{Line: 0, Character: start + len(s)},
+		}
+	}
+
+	// The words in the initialization options replace the built-in words.
+	if err := client.Notify(messages.DidOpenTextDocumentNotification, messages.DidOpenTextDocumentParams{
+		TextDocument: messages.TextDocumentItem{URI: uri, Version: 1, Text: text},
+	}); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasSwearWords(1, rangeOf("blimey"), rangeOf("sod off")))
+
+	// The open document is analyzed again when the words change.
+	if err := client.Notify(messages.DidChangeConfigurationNotification, messages.DidChangeConfigurationParams{
+		Settings: json.RawMessage(`{"examplelsp": {"swearWords": ["bloody"]}}`),
+	}); err != nil {
+		t.Fatalf("failed to change configuration: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasSwearWords(1, rangeOf("bloody")))
+
+	// Words from the initialization options are kept when other settings
+	// change, and turning the check off clears the diagnostics.
+	if err := client.Notify(messages.DidChangeConfigurationNotification, messages.DidChangeConfigurationParams{
+		Settings: json.RawMessage(`{"examplelsp": {"style": true}}`),
+	}); err != nil {
+		t.Fatalf("failed to change configuration: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasSwearWords(1, rangeOf("blimey"), rangeOf("sod off")))
+	if err := client.Notify(messages.DidChangeConfigurationNotification, messages.DidChangeConfigurationParams{
+		Settings: json.RawMessage(`{"examplelsp": {"swearWordsEnabled": false}}`),
+	}); err != nil {
+		t.Fatalf("failed to change configuration: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasSwearWords(1))
+}
+
+func TestServerTogglesNoServingsHint(t *testing.T) {
+	client, _ := newTestServer(t, messages.InitializeParams{Capabilities: testClientCapabilities})
+	uri := testDocumentURI(t)
+
+	if err := client.Notify(messages.DidOpenTextDocumentNotification, messages.DidOpenTextDocumentParams{
+		TextDocument: messages.TextDocumentItem{URI: uri, Version: 1, Text: "Boil @water{1%l}."},
+	}); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	waitForDiagnostics(t, client, uri, hasCode(1, analyzers.CodeNoServings, true))
+
+	changes := []struct {
+		text     string
+		expected bool
+	}{
+		{text: ">> servings: 2\nBoil @water{1%l}.", expected: false},
+		{text: "Boil @water{1%l}.", expected: true},
+		{text: ">> servings: 2\nBoil @water{1%l}.", expected: false},
+	}
+	for i, change := range changes {
+		version := i + 2
+		changeDocument(t, client, uri, version, change.text)
+		waitForDiagnostics(t, client, uri, hasCode(version, analyzers.CodeNoServings, change.expected))
+	}
+}
+
+func TestServerRefreshesAdvertisedFeaturesWhenSettingsChange(t *testing.T) {
+	refresh := &messages.RefreshClientCapabilities{RefreshSupport: true}
+	client, result := newTestServer(t, messages.InitializeParams{
+		Capabilities: messages.ClientCapabilities{
+			Workspace: &messages.WorkspaceClientCapabilities{
+				InlayHint:   refresh,
+				Diagnostics: refresh,
+			},
+		},
+	})
+	if result.Capabilities.DiagnosticProvider != nil || result.Capabilities.InlayHintProvider == nil {
+		t.Fatalf("expected inlay hints to be advertised, and pulled diagnostics not to be, got %#v", result.Capabilities)
+	}
+	if err := client.Notify(messages.DidChangeConfigurationNotification, messages.DidChangeConfigurationParams{
+		Settings: json.RawMessage(`{"examplelsp": {"style": true}}`),
+	}); err != nil {
+		t.Fatalf("failed to change configuration: %v", err)
+	}
+	var methods []string
+	quiet := time.After(lsp.DefaultRefreshDelay * 4)
+	for done := false; !done; {
+		select {
+		case req := <-client.Requests:
+			methods = append(methods, req.Method)
+			if err := client.Respond(req.ID, nil, nil); err != nil {
+				t.Fatalf("failed to respond: %v", err)
+			}
+		case <-quiet:
+			done = true
+		}
+	}
+	if len(methods) != 1 || methods[0] != messages.InlayHintRefreshRequestMethod {
+		t.Errorf("expected a single inlay hint refresh, got %v", methods)
+	}
+}
+
+// utf8Text has characters before the elements on its line that are longer in
+// UTF-8 than in UTF-16, so positions in the wrong encoding point past them.
+const utf8Text = "🧅🧅🧅 Émincer l'@oignon{1}, puis faire fondre le @beurre{20%g} avec l'@oignon{}."
+
+// utf8Position returns the UTF-8 position of the first occurrence of s in
+// utf8Text.
+func utf8Position(s string) messages.Position {
+	return messages.Position{Line: 0, Character: strings.Index(utf8Text, s)}
+}
+
+func TestServerHoverUsesNegotiatedPositionEncoding(t *testing.T) {
+	client, result := newTestServer(t, messages.InitializeParams{Capabilities: utf8ClientCapabilities})
+	if result.Capabilities.PositionEncoding != messages.PositionEncodingKindUTF8 {
+		t.Fatalf("expected utf-8 positions, got %q", result.Capabilities.PositionEncoding)
+	}
+	uri := testDocumentURI(t)
+	openDocument(t, client, uri, utf8Text)
+
+	var h *messages.Hover
+	if err := client.Call(messages.HoverRequestMethod, messages.HoverParams{
+		TextDocumentPositionParams: messages.TextDocumentPositionParams{
+			TextDocument: messages.TextDocumentIdentifier{URI: uri},
+			Position:     utf8Position("%g}"),
+		},
+	}, &h); err != nil {
+		t.Fatalf("failed to hover: %v", err)
+	}
+	if h == nil || h.Range == nil {
+		t.Fatalf("expected a hover with a range, got %#v", h)
+	}
+	expected := messages.Range{Start: utf8Position("@beurre"), End: utf8Position(" avec")}
+	if *h.Range != expected {
+		t.Errorf("expected range %v, got %v", expected, *h.Range)
+	}
+}
+
+func TestServerDefinitionUsesNegotiatedPositionEncoding(t *testing.T) {
+	capabilities := utf8ClientCapabilities
+	capabilities.TextDocument = &messages.TextDocumentClientCapabilities{
+		Definition: &messages.DefinitionClientCapabilities{LinkSupport: true},
+	}
+	client, _ := newTestServer(t, messages.InitializeParams{Capabilities: capabilities})
+	uri := testDocumentURI(t)
+	openDocument(t, client, uri, utf8Text)
+
+	// The second use of the onion is defined by the first.
+	second := strings.LastIndex(utf8Text, "@oignon")
+	var result messages.DefinitionResult
+	if err := client.Call(messages.DefinitionRequestMethod, messages.DefinitionParams{
+		TextDocumentPositionParams: messages.TextDocumentPositionParams{
+			TextDocument: messages.TextDocumentIdentifier{URI: uri},
+			Position:     messages.Position{Line: 0, Character: second + 1},
+		},
+	}, &result); err != nil {
+		t.Fatalf("failed to find definition: %v", err)
+	}
+	if len(result.LocationLinks) != 1 {
+		t.Fatalf("expected a single link, got %#v", result)
+	}
+	link := result.LocationLinks[0]
+	origin := messages.Range{
+		Start: messages.Position{Line: 0, Character: second},
+		End:   messages.Position{Line: 0, Character: len(utf8Text) - len(".")},
+	}
+	if link.OriginSelectionRange == nil || *link.OriginSelectionRange != origin {
+		t.Errorf("expected origin %v, got %v", origin, link.OriginSelectionRange)
+	}
+	target := messages.Range{Start: utf8Position("oignon"), End: utf8Position("{1}")}
+	if link.TargetSelectionRange != target {
+		t.Errorf("expected target selection %v, got %v", target, link.TargetSelectionRange)
+	}
+	step := messages.Range{End: messages.Position{Line: 0, Character: len(utf8Text)}}
+	if link.TargetRange != step {
+		t.Errorf("expected target %v, got %v", step, link.TargetRange)
+	}
+}
+
+func TestServerCompletionUsesNegotiatedPositionEncoding(t *testing.T) {
+	client, _ := newTestServer(t, messages.InitializeParams{Capabilities: utf8ClientCapabilities})
+	uri := testDocumentURI(t)
+	text := "🧅🧅🧅 Faire fondre le @beurre{20%k} dans une poêle."
+	openDocument(t, client, uri, text)
+
+	typed := strings.Index(text, "k}")
+	end := messages.Position{Line: 0, Character: typed + 1}
+	var list messages.CompletionList
+	if err := client.Call(messages.CompletionRequestMethod, messages.CompletionParams{
+		TextDocumentPositionParams: messages.TextDocumentPositionParams{
+			TextDocument: messages.TextDocumentIdentifier{URI: uri},
+			Position:     end,
+		},
+	}, &list); err != nil {
+		t.Fatalf("failed to complete: %v", err)
+	}
+	if len(list.Items) == 0 {
+		t.Fatal("expected units to be completed")
+	}
+	// The edit replaces the typed "k".
+	expected := messages.Range{Start: messages.Position{Line: 0, Character: typed}, End: end}
+	for _, item := range list.Items {
+		if item.TextEdit == nil || item.TextEdit.TextEdit == nil {
+			t.Fatalf("expected %q to have an edit", item.Label)
+		}
+		if r := item.TextEdit.TextEdit.Range; r != expected {
+			t.Errorf("expected %q to replace %v, got %v", item.Label, expected, r)
+		}
+	}
+}
+
+func TestServerWorkspaceSymbolsUseNegotiatedPositionEncoding(t *testing.T) {
+	capabilities := utf8ClientCapabilities
+	capabilities.Workspace = &messages.WorkspaceClientCapabilities{}
+	if err := json.Unmarshal([]byte(`{"resolveSupport": {"properties": ["location.range"]}}`), &capabilities.Workspace.Symbol); err != nil {
+		t.Fatalf("failed to unmarshal capabilities: %v", err)
+	}
+	client, result := newTestServer(t, messages.InitializeParams{Capabilities: capabilities})
+	if p := result.Capabilities.WorkspaceSymbolProvider; p == nil || p.Options == nil || !p.Options.ResolveProvider {
+		t.Fatalf("expected workspace symbols to be resolved, got %#v", p)
+	}
+	uri := testDocumentURI(t)
+	title := ">> title: Soupe à l'oignon 🧅"
+	openDocument(t, client, uri, title+"\n"+utf8Text)
+
+	var symbols []messages.WorkspaceSymbol
+	if err := client.Call(messages.WorkspaceSymbolRequestMethod, messages.WorkspaceSymbolParams{Query: "oignon"}, &symbols); err != nil {
+		t.Fatalf("failed to find symbols: %v", err)
+	}
+	if len(symbols) != 2 {
+		t.Fatalf("expected the title and the onion, got %#v", symbols)
+	}
+	onion := messages.Range{
+		Start: messages.Position{Line: 1, Character: utf8Position("@oignon").Character},
+		End:   messages.Position{Line: 1, Character: utf8Position(", puis").Character},
+	}
+	if r := symbols[1].Location.Range; r == nil || *r != onion {
+		t.Errorf("expected the onion to be at %v, got %v", onion, r)
+	}
+
+	// The range of the title is resolved when it's picked.
+	if symbols[0].Location.Range != nil {
+		t.Fatalf("expected the range of the title to be resolved, got %v", symbols[0].Location.Range)
+	}
+	var resolved messages.WorkspaceSymbol
+	if err := client.Call(messages.WorkspaceSymbolResolveRequestMethod, symbols[0], &resolved); err != nil {
+		t.Fatalf("failed to resolve symbol: %v", err)
+	}
+	line := messages.Range{End: messages.Position{Line: 0, Character: len(title)}}
+	if r := resolved.Location.Range; r == nil || *r != line {
+		t.Errorf("expected the title to be at %v, got %v", line, r)
+	}
+	if resolved.Name != symbols[0].Name || string(resolved.Data) != string(symbols[0].Data) {
+		t.Errorf("expected the same symbol back, got %#v", resolved)
+	}
+
+	// Symbols that already have a range are returned as they are.
+	if err := client.Call(messages.WorkspaceSymbolResolveRequestMethod, symbols[1], &resolved); err != nil {
+		t.Fatalf("failed to resolve symbol: %v", err)
+	}
+	if r := resolved.Location.Range; r == nil || *r != onion {
+		t.Errorf("expected the onion to be kept at %v, got %v", onion, r)
+	}
+}
+
+func TestServerWorkspaceSymbolsWithoutResolveSupport(t *testing.T) {
+	client, _ := newTestServer(t, messages.InitializeParams{Capabilities: utf8ClientCapabilities})
+	uri := testDocumentURI(t)
+	title := ">> title: Soupe à l'oignon 🧅"
+	openDocument(t, client, uri, title+"\n"+utf8Text)
+
+	// Clients that can't resolve ranges are sent the full location of titles.
+	var symbols []messages.SymbolInformation
+	if err := client.Call(messages.WorkspaceSymbolRequestMethod, messages.WorkspaceSymbolParams{Query: "oignon"}, &symbols); err != nil {
+		t.Fatalf("failed to find symbols: %v", err)
+	}
+	if len(symbols) != 2 {
+		t.Fatalf("expected the title and the onion, got %#v", symbols)
+	}
+	line := messages.Range{End: messages.Position{Line: 0, Character: len(title)}}
+	if symbols[0].Location.URI != uri || symbols[0].Location.Range != line {
+		t.Errorf("expected the title to be at %v, got %#v", line, symbols[0].Location)
+	}
+}
+
+func TestServerResolvesDocumentLinks(t *testing.T) {
+	client, result := newTestServer(t, messages.InitializeParams{Capabilities: utf8ClientCapabilities})
+	if p := result.Capabilities.DocumentLinkProvider; p == nil || !p.ResolveProvider {
+		t.Fatalf("expected document links to be resolved, got %#v", p)
+	}
+	uri := testDocumentURI(t)
+	base := "🍅 sauces/tomato"
+	openDocument(t, client, uri, ">> base: "+base+"\n\nAdd the @pasta.")
+
+	var links []messages.DocumentLink
+	if err := client.Call(messages.DocumentLinkRequestMethod, messages.DocumentLinkParams{
+		TextDocument: messages.TextDocumentIdentifier{URI: uri},
+	}, &links); err != nil {
+		t.Fatalf("failed to find links: %v", err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("expected a single link, got %#v", links)
+	}
+	link := links[0]
+	if link.Target != nil {
+		t.Errorf("expected the link to be returned without a target, got %q", *link.Target)
+	}
+	expected := messages.Range{
+		Start: messages.Position{Line: 0, Character: len(">> base: ")},
+		End:   messages.Position{Line: 0, Character: len(">> base: " + base)},
+	}
+	if link.Range != expected {
+		t.Errorf("expected range %v, got %v", expected, link.Range)
+	}
+
+	var resolved messages.DocumentLink
+	if err := client.Call(messages.DocumentLinkResolveRequestMethod, link, &resolved); err != nil {
+		t.Fatalf("failed to resolve link: %v", err)
+	}
+	if resolved.Range != link.Range || string(resolved.Data) != string(link.Data) {
+		t.Errorf("expected the range and data to be kept, got %#v", resolved)
+	}
+	path, err := workspace.PathFromURI(uri)
+	if err != nil {
+		t.Fatalf("failed to get path: %v", err)
+	}
+	target, err := workspace.URIFromPath(filepath.Join(filepath.Dir(path), "🍅 sauces", "tomato.cook"))
+	if err != nil {
+		t.Fatalf("failed to create URI: %v", err)
+	}
+	if resolved.Target == nil || *resolved.Target != target {
+		t.Errorf("expected target %q, got %v", target, resolved.Target)
+	}
+}
@@ -0,0 +1,100 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/a-h/examplelsp/snippet"
+)
+
+// Find searches dir and its parents for the settings file, and returns its
+// path.
+func Find(dir string) (path string, ok bool) {
+	for {
+		path = filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Problem found in a settings file.
+type Problem struct {
+	Message string
+	// Start and End are byte offsets of the problem in the file.
+	Start, End int
+}
+
+// Check returns the problems in the contents of a settings file, including
+// snippets that aren't valid.
+func Check(data []byte) (problems []Problem) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return []Problem{{Message: err.Error(), Start: int(syntaxErr.Offset) - 1, End: int(syntaxErr.Offset)}}
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			start, end := tokenBefore(data, int(typeErr.Offset))
+			return []Problem{{Message: err.Error(), Start: start, End: end}}
+		}
+		return []Problem{{Message: err.Error(), Start: 0, End: len(data)}}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// The JSON is valid, so the top level must be an object.
+	dec.Token()
+	for dec.More() {
+		key, _ := dec.Token()
+		if key != "snippets" {
+			var skipped json.RawMessage
+			dec.Decode(&skipped)
+			continue
+		}
+		if t, _ := dec.Token(); t != json.Delim('{') {
+			// The snippets are null.
+			continue
+		}
+		for dec.More() {
+			prefix, _ := dec.Token()
+			start := int(dec.InputOffset())
+			body, _ := dec.Token()
+			end := int(dec.InputOffset())
+			start += bytes.IndexByte(data[start:end], '"')
+			if err := snippet.Validate(body.(string)); err != nil {
+				problems = append(problems, Problem{
+					Message: fmt.Sprintf("Snippet %q is not valid: %v", prefix, err),
+					Start:   start,
+					End:     end,
+				})
+			}
+		}
+		dec.Token()
+	}
+	return problems
+}
+
+// tokenBefore returns the range of the JSON value that ends at offset.
+func tokenBefore(data []byte, offset int) (start, end int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	start = bytes.LastIndexAny(data[:offset], ":[,") + 1
+	for start < offset && (data[start] == ' ' || data[start] == '\t' || data[start] == '\r' || data[start] == '\n') {
+		start++
+	}
+	return start, offset
+}
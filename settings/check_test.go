@@ -0,0 +1,89 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "empty files are valid",
+			data:     "",
+			expected: nil,
+		},
+		{
+			name:     "valid snippets",
+			data:     `{"snippets": {"preheat": "Preheat the #oven{} to ${1:200}°C."}}`,
+			expected: nil,
+		},
+		{
+			name:     "invalid snippets are reported at their body",
+			data:     `{"style": true, "snippets": {"preheat": "Preheat to ${1:200°C.", "rest": "Rest for ~{$1%minutes}."}}`,
+			expected: []string{`"Preheat to ${1:200°C."`},
+		},
+		{
+			name:     "invalid JSON is reported",
+			data:     `{"style": true,, }`,
+			expected: []string{","},
+		},
+		{
+			name:     "values of the wrong type are reported",
+			data:     `{"style": "yes"}`,
+			expected: []string{`"yes"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+			for _, p := range Check([]byte(test.data)) {
+				actual = append(actual, test.data[p.Start:p.End])
+				if p.Message == "" {
+					t.Error("expected a message")
+				}
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected problems at %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestLoadSkipsInvalidSnippets(t *testing.T) {
+	root := t.TempDir()
+	data := `{"snippets": {"preheat": "Preheat to ${1:200°C.", "rest": "Rest for ~{$1%minutes}."}}`
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	s, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"rest": "Rest for ~{$1%minutes}."}
+	if !reflect.DeepEqual(s.Snippets, expected) {
+		t.Errorf("expected %v, got %v", expected, s.Snippets)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "recipes", "italian")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if path, ok := Find(dir); ok {
+		t.Skipf("found a settings file outside the test directory: %s", path)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), nil, 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	if path, ok := Find(dir); !ok || path != filepath.Join(root, FileName) {
+		t.Errorf("expected the settings file in the root to be found, got %q, %v", path, ok)
+	}
+}
@@ -0,0 +1,51 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/a-h/examplelsp/snippet"
+)
+
+// Section of the client's configuration that holds the settings.
+const Section = "examplelsp"
+
+// FromClient decodes the settings sent by the client in a
+// workspace/didChangeConfiguration notification. Clients differ in the shape
+// of the payload, so the settings can be nested in a section, e.g.
+// {"examplelsp": {"format": true}}, be flat, e.g. {"format": true}, or use
+// dotted keys, e.g. {"examplelsp.format": true}.
+//
+// Fields with values of the wrong type are skipped and the other fields are
+// still set, so the returned settings are usable even if err isn't nil.
+func FromClient(data []byte) (s Settings, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return s, nil
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return s, err
+	}
+	if section, ok := fields[Section]; ok && bytes.HasPrefix(bytes.TrimSpace(section), []byte("{")) {
+		if err = json.Unmarshal(section, &fields); err != nil {
+			return s, err
+		}
+	}
+	flat := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		flat[strings.TrimPrefix(key, Section+".")] = value
+	}
+	data, err = json.Marshal(flat)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	for prefix, body := range s.Snippets {
+		if snippet.Validate(body) != nil {
+			delete(s.Snippets, prefix)
+		}
+	}
+	return s, err
+}
@@ -0,0 +1,86 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		expected    Settings
+		expectedErr bool
+	}{
+		{
+			name:     "no settings",
+			payload:  `null`,
+			expected: Settings{},
+		},
+		{
+			name:     "VS Code nests the settings in a section",
+			payload:  `{"examplelsp": {"format": true, "diagnosticsRefreshSeconds": 30}, "editor": {"tabSize": 2}}`,
+			expected: Settings{Format: true, DiagnosticsRefreshSeconds: 30},
+		},
+		{
+			name:     "flat",
+			payload:  `{"format": true, "style": false}`,
+			expected: Settings{Format: true, Style: ptr(false)},
+		},
+		{
+			name:     "dotted keys",
+			payload:  `{"examplelsp.flatDiagnosticSource": true, "editor.tabSize": 2}`,
+			expected: Settings{FlatDiagnosticSource: true},
+		},
+		{
+			name:     "swear words",
+			payload:  `{"swearWords": ["Blimey", "crikey"], "swearWordsEnabled": true}`,
+			expected: Settings{SwearWords: []string{"Blimey", "crikey"}, SwearWordsEnabled: ptr(true)},
+		},
+		{
+			name:     "invalid snippets are left out",
+			payload:  `{"examplelsp": {"snippets": {"ok": "Boil @${1:water}.", "bad": "Boil @${1:water."}}}`,
+			expected: Settings{Snippets: map[string]string{"ok": "Boil @${1:water}."}},
+		},
+		{
+			name:        "fields of the wrong type are skipped",
+			payload:     `{"examplelsp": {"format": "yes", "flatDiagnosticSource": true}}`,
+			expected:    Settings{FlatDiagnosticSource: true},
+			expectedErr: true,
+		},
+		{
+			name:        "not an object",
+			payload:     `[true]`,
+			expected:    Settings{},
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromClient([]byte(test.payload))
+			if (err != nil) != test.expectedErr {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSnapshotSwearWords(t *testing.T) {
+	if _, ok := (Settings{}).Snapshot().SwearWords(); ok {
+		t.Error("expected the built-in swear words to be used by default")
+	}
+	s := Settings{SwearWords: []string{"Crikey", " blimey", "crikey", ""}}
+	words, ok := s.Snapshot().SwearWords()
+	if !ok || !reflect.DeepEqual(words, []string{"blimey", "crikey"}) {
+		t.Errorf("expected lowercased, deduplicated words, got %v, %v", words, ok)
+	}
+	if !s.Snapshot().SwearWordCheckEnabled() {
+		t.Error("expected the swear word check to be enabled by default")
+	}
+	if (Settings{SwearWordsEnabled: ptr(false)}).Snapshot().SwearWordCheckEnabled() {
+		t.Error("expected the swear word check to be disabled")
+	}
+}
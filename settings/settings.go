@@ -0,0 +1,136 @@
+// Package settings loads examplelsp configuration from the .examplelsp file
+// at the root of a recipe project.
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/a-h/examplelsp/snippet"
+)
+
+// FileName of the settings file. Editors use its location to find the root of
+// a recipe project, so an empty file is valid and results in the defaults.
+const FileName = ".examplelsp"
+
+type Settings struct {
+	// Style enables the trailing whitespace and tab indentation checks. If it
+	// isn't set, the checks are enabled when Format is true.
+	Style *bool `json:"style"`
+	// Format is set when recipes in the project are formatted by examplelsp.
+	Format bool `json:"format"`
+	// FlatDiagnosticSource sets the source of all diagnostics to "examplelsp"
+	// instead of "examplelsp.<analyzer>", for clients that group diagnostics
+	// by source poorly.
+	FlatDiagnosticSource bool `json:"flatDiagnosticSource"`
+	// Snippets offered as completions at the start of a line, keyed by the
+	// prefix that the user types. The bodies use LSP snippet syntax. Invalid
+	// snippets are left out, see Check.
+	Snippets map[string]string `json:"snippets"`
+	// DiagnosticsRefreshSeconds is the number of seconds after which
+	// diagnostics are sent again, even if they haven't changed, because some
+	// clients clear them, e.g. when a file is reloaded. Zero turns it off.
+	DiagnosticsRefreshSeconds int `json:"diagnosticsRefreshSeconds"`
+	// Spellcheck enables the spelling check of ingredient names. It's enabled
+	// if it isn't set, but recipes with lots of unusual ingredients can turn
+	// it off.
+	Spellcheck *bool `json:"spellcheck"`
+	// SwearWords replace the built-in list of swear words, if they're set.
+	// They're matched as literal text, ignoring case, so they can be
+	// phrases, or contain hyphens, but they only match whole words.
+	SwearWords []string `json:"swearWords"`
+	// SwearWordsEnabled turns the swear word check on or off. It's on if it
+	// isn't set.
+	SwearWordsEnabled *bool `json:"swearWordsEnabled"`
+}
+
+// StyleEnabled returns true if whitespace style checks should run.
+func (s Settings) StyleEnabled() bool {
+	if s.Style != nil {
+		return *s.Style
+	}
+	return s.Format
+}
+
+// SpellcheckEnabled returns true if ingredient names should be spellchecked.
+func (s Settings) SpellcheckEnabled() bool {
+	return s.Spellcheck == nil || *s.Spellcheck
+}
+
+// SwearWordCheckEnabled returns true if swear words should be reported.
+func (s Settings) SwearWordCheckEnabled() bool {
+	return s.SwearWordsEnabled == nil || *s.SwearWordsEnabled
+}
+
+// clone returns a copy of the settings that doesn't share any references.
+func (s Settings) clone() Settings {
+	if s.Style != nil {
+		s.Style = ptr(*s.Style)
+	}
+	if s.Spellcheck != nil {
+		s.Spellcheck = ptr(*s.Spellcheck)
+	}
+	if s.SwearWordsEnabled != nil {
+		s.SwearWordsEnabled = ptr(*s.SwearWordsEnabled)
+	}
+	if s.SwearWords != nil {
+		s.SwearWords = append([]string{}, s.SwearWords...)
+	}
+	if s.Snippets != nil {
+		snippets := make(map[string]string, len(s.Snippets))
+		for prefix, body := range s.Snippets {
+			snippets[prefix] = body
+		}
+		s.Snippets = snippets
+	}
+	return s
+}
+
+// Load searches dir and its parents for the settings file, and returns the
+// settings it contains. If there's no settings file, the defaults are returned.
+func Load(dir string) (s Settings, err error) {
+	return load(dir, Settings{})
+}
+
+// load the settings file, using defaults for fields that it doesn't set.
+func load(dir string, defaults Settings) (s Settings, err error) {
+	for {
+		s, err = loadFile(filepath.Join(dir, FileName), defaults.clone())
+		if !errors.Is(err, fs.ErrNotExist) {
+			return s, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return defaults, nil
+		}
+		dir = parent
+	}
+}
+
+func loadFile(name string, defaults Settings) (s Settings, err error) {
+	s = defaults
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return
+	}
+	if err = json.Unmarshal(data, &s); err != nil {
+		return
+	}
+	for prefix, body := range s.Snippets {
+		if snippet.Validate(body) != nil {
+			delete(s.Snippets, prefix)
+		}
+	}
+	return
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
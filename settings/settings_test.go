@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		contents      *string
+		expectedStyle bool
+	}{
+		{
+			name:          "style checks are disabled if there's no settings file",
+			contents:      nil,
+			expectedStyle: false,
+		},
+		{
+			name:          "an empty settings file results in the defaults",
+			contents:      ptr(""),
+			expectedStyle: false,
+		},
+		{
+			name:          "style checks can be enabled",
+			contents:      ptr(`{ "style": true }`),
+			expectedStyle: true,
+		},
+		{
+			name:          "style checks are enabled when the formatter is in use",
+			contents:      ptr(`{ "format": true }`),
+			expectedStyle: true,
+		},
+		{
+			name:          "style checks can be disabled even when the formatter is in use",
+			contents:      ptr(`{ "format": true, "style": false }`),
+			expectedStyle: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			if test.contents != nil {
+				if err := os.WriteFile(filepath.Join(root, FileName), []byte(*test.contents), 0644); err != nil {
+					t.Fatalf("failed to write settings: %v", err)
+				}
+			}
+			dir := filepath.Join(root, "recipes", "italian")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("failed to create directory: %v", err)
+			}
+			s, err := Load(dir)
+			if err != nil {
+				t.Fatalf("failed to load settings: %v", err)
+			}
+			if actual := s.StyleEnabled(); actual != test.expectedStyle {
+				t.Errorf("expected style enabled %v, got %v", test.expectedStyle, actual)
+			}
+		})
+	}
+}
+
+func TestLoadFlatDiagnosticSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{ "flatDiagnosticSource": true }`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if !s.FlatDiagnosticSource {
+		t.Error("expected flat diagnostic sources to be enabled")
+	}
+}
+
+func TestLoadSpellcheck(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if !s.SpellcheckEnabled() {
+		t.Error("expected spellcheck to be enabled by default")
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{ "spellcheck": false }`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	if s, err = Load(dir); err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if s.SpellcheckEnabled() || s.Snapshot().SpellcheckEnabled() {
+		t.Error("expected spellcheck to be disabled")
+	}
+}
+
+func TestLoadInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("expected an error, got nil")
+	}
+}
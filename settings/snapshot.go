@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot of the settings for a single analysis run. It's passed by value,
+// and contains no references, so changes to the settings can't be observed
+// during a run, and analyzers can't hold on to state that changes later.
+type Snapshot struct {
+	styleEnabled      bool
+	spellcheckEnabled bool
+	swearWordsEnabled bool
+	// swearWords are separated by newlines, so that the snapshot doesn't
+	// share a slice with the settings.
+	swearWords           string
+	customSwearWords     bool
+	flatDiagnosticSource bool
+	diagnosticsRefresh   time.Duration
+}
+
+// Snapshot returns the settings as a Snapshot.
+func (s Settings) Snapshot() Snapshot {
+	return Snapshot{
+		styleEnabled:         s.StyleEnabled(),
+		spellcheckEnabled:    s.SpellcheckEnabled(),
+		swearWordsEnabled:    s.SwearWordCheckEnabled(),
+		swearWords:           strings.Join(normalizeWords(s.SwearWords), "\n"),
+		customSwearWords:     s.SwearWords != nil,
+		flatDiagnosticSource: s.FlatDiagnosticSource,
+		diagnosticsRefresh:   time.Duration(s.DiagnosticsRefreshSeconds) * time.Second,
+	}
+}
+
+// StyleEnabled returns true if whitespace style checks should run.
+func (s Snapshot) StyleEnabled() bool {
+	return s.styleEnabled
+}
+
+// SpellcheckEnabled returns true if ingredient names should be spellchecked.
+func (s Snapshot) SpellcheckEnabled() bool {
+	return s.spellcheckEnabled
+}
+
+// SwearWordCheckEnabled returns true if swear words should be reported.
+func (s Snapshot) SwearWordCheckEnabled() bool {
+	return s.swearWordsEnabled
+}
+
+// SwearWords returns the swear words that replace the built-in list,
+// lowercased, deduplicated and sorted. ok is false if the built-in list
+// should be used.
+func (s Snapshot) SwearWords() (words []string, ok bool) {
+	if !s.customSwearWords {
+		return nil, false
+	}
+	if s.swearWords == "" {
+		return []string{}, true
+	}
+	return strings.Split(s.swearWords, "\n"), true
+}
+
+// normalizeWords lowercases, deduplicates and sorts the words, and removes
+// blank ones.
+func normalizeWords(words []string) (normalized []string) {
+	seen := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if _, ok := seen[word]; ok || word == "" {
+			continue
+		}
+		seen[word] = struct{}{}
+		normalized = append(normalized, word)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// FlatDiagnosticSource returns true if all diagnostics should have the same
+// source.
+func (s Snapshot) FlatDiagnosticSource() bool {
+	return s.flatDiagnosticSource
+}
+
+// DiagnosticsRefresh returns how long unchanged diagnostics are skipped for,
+// before they're sent again. Zero means that they're never sent again.
+func (s Snapshot) DiagnosticsRefresh() time.Duration {
+	if s.diagnosticsRefresh < 0 {
+		return 0
+	}
+	return s.diagnosticsRefresh
+}
+
+// MarshalJSON returns the effective settings, e.g. to include them in bug
+// reports.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	swearWords, _ := s.SwearWords()
+	return json.Marshal(struct {
+		Style                bool     `json:"style"`
+		Spellcheck           bool     `json:"spellcheck"`
+		SwearWordsEnabled    bool     `json:"swearWordsEnabled"`
+		SwearWords           []string `json:"swearWords,omitempty"`
+		FlatDiagnosticSource bool     `json:"flatDiagnosticSource"`
+		// The refresh is in seconds, as it is in the settings file.
+		DiagnosticsRefreshSeconds int `json:"diagnosticsRefreshSeconds"`
+	}{
+		Style:                     s.styleEnabled,
+		Spellcheck:                s.spellcheckEnabled,
+		SwearWordsEnabled:         s.swearWordsEnabled,
+		SwearWords:                swearWords,
+		FlatDiagnosticSource:      s.flatDiagnosticSource,
+		DiagnosticsRefreshSeconds: int(s.DiagnosticsRefresh() / time.Second),
+	})
+}
@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"sync"
+)
+
+// Store provides snapshots of the settings for each project, and notifies
+// subscribers when they change. It's safe for concurrent use.
+type Store struct {
+	lock sync.Mutex
+	// defaults are used for fields that aren't set by a project's settings
+	// file, e.g. settings sent by the client.
+	defaults Settings
+	// snapshots are the last snapshots returned for each directory, used to
+	// detect changes to settings files.
+	snapshots   map[string]Snapshot
+	subscribers map[int]func()
+	nextID      int
+}
+
+func NewStore() *Store {
+	return &Store{
+		snapshots:   map[string]Snapshot{},
+		subscribers: map[int]func(){},
+	}
+}
+
+// Snapshot of the settings for documents in dir. The settings file is read
+// each time, and if it has changed since the last snapshot for dir,
+// subscribers are notified.
+func (s *Store) Snapshot(dir string) (snapshot Snapshot, err error) {
+	s.lock.Lock()
+	defaults := s.defaults.clone()
+	s.lock.Unlock()
+
+	settings, err := load(dir, defaults)
+	if err != nil {
+		return defaults.Snapshot(), err
+	}
+	snapshot = settings.Snapshot()
+
+	s.lock.Lock()
+	previous, seen := s.snapshots[dir]
+	s.snapshots[dir] = snapshot
+	s.lock.Unlock()
+	if seen && previous != snapshot {
+		s.notify()
+	}
+	return snapshot, nil
+}
+
+// Settings for documents in dir, including fields that aren't part of a
+// Snapshot, such as snippets. Subscribers aren't notified of changes.
+func (s *Store) Settings(dir string) (settings Settings, err error) {
+	s.lock.Lock()
+	defaults := s.defaults.clone()
+	s.lock.Unlock()
+	settings, err = load(dir, defaults)
+	if err != nil {
+		return defaults, err
+	}
+	return settings, nil
+}
+
+// SetDefaults sets the settings used for fields that aren't set by a
+// project's settings file, and notifies subscribers.
+func (s *Store) SetDefaults(defaults Settings) {
+	s.lock.Lock()
+	s.defaults = defaults.clone()
+	s.lock.Unlock()
+	s.notify()
+}
+
+// Subscribe calls f each time the settings change, so that documents can be
+// analyzed again. f must not block. Call unsubscribe to stop receiving calls.
+func (s *Store) Subscribe(f func()) (unsubscribe func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = f
+	return func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+func (s *Store) notify() {
+	s.lock.Lock()
+	subscribers := make([]func(), 0, len(s.subscribers))
+	for _, f := range s.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	s.lock.Unlock()
+	for _, f := range subscribers {
+		f()
+	}
+}
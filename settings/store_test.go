@@ -0,0 +1,58 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore()
+	var notifications int
+	unsubscribe := s.Subscribe(func() { notifications++ })
+
+	snapshot, err := s.Snapshot(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.StyleEnabled() || snapshot.FlatDiagnosticSource() {
+		t.Errorf("expected the defaults, got %#v", snapshot)
+	}
+
+	// Defaults apply to fields that the settings file doesn't set.
+	s.SetDefaults(Settings{Style: ptr(true), FlatDiagnosticSource: true})
+	if notifications != 1 {
+		t.Errorf("expected subscribers to be notified when the defaults change, got %d notifications", notifications)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(`{ "style": false }`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	if snapshot, err = s.Snapshot(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.StyleEnabled() || !snapshot.FlatDiagnosticSource() {
+		t.Errorf("expected the settings file to override the defaults, got %#v", snapshot)
+	}
+	if notifications != 2 {
+		t.Errorf("expected subscribers to be notified when the settings file changes, got %d notifications", notifications)
+	}
+
+	// Loading the file must not change the defaults.
+	if s.defaults.Style == nil || !*s.defaults.Style {
+		t.Errorf("expected the defaults to be unchanged, got %v", s.defaults.Style)
+	}
+
+	if _, err = s.Snapshot(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notifications != 2 {
+		t.Errorf("expected no notification when the settings are unchanged, got %d notifications", notifications)
+	}
+
+	unsubscribe()
+	s.SetDefaults(Settings{})
+	if notifications != 2 {
+		t.Errorf("expected no notifications after unsubscribing, got %d notifications", notifications)
+	}
+}
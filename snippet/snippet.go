@@ -0,0 +1,171 @@
+// Package snippet reads LSP snippets, e.g. "Preheat the #oven{} to ${1:200}°C.".
+//
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#snippet_syntax
+package snippet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError is returned when a snippet isn't valid.
+type SyntaxError struct {
+	// Offset of the problem, in bytes from the start of the snippet.
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("snippet: %s at offset %d", e.Message, e.Offset)
+}
+
+// Validate returns a *SyntaxError if the snippet isn't valid.
+func Validate(body string) error {
+	_, err := PlainText(body)
+	return err
+}
+
+// PlainText returns the text that the snippet inserts, for clients that
+// don't support snippets. Tabstops and variables are removed, placeholders
+// are replaced by their default text, and choices by their first option.
+func PlainText(body string) (text string, err error) {
+	p := &parser{s: body}
+	return p.any(false)
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Offset: p.i, Message: fmt.Sprintf(format, args...)}
+}
+
+// any reads text, tabstops, placeholders, choices and variables, until the
+// end of the snippet, or until the closing brace of a placeholder.
+func (p *parser) any(inPlaceholder bool) (text string, err error) {
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s) && strings.IndexByte(`$}\`, p.s[p.i+1]) >= 0:
+			sb.WriteByte(p.s[p.i+1])
+			p.i += 2
+		case c == '}' && inPlaceholder:
+			return sb.String(), nil
+		case c == '$':
+			s, err := p.dollar()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(s)
+		default:
+			sb.WriteByte(c)
+			p.i++
+		}
+	}
+	return sb.String(), nil
+}
+
+// dollar reads an element that starts with a dollar sign. A dollar sign that
+// doesn't start an element is text.
+func (p *parser) dollar() (text string, err error) {
+	start := p.i
+	p.i++
+	if p.i < len(p.s) && isDigit(p.s[p.i]) {
+		p.int()
+		return "", nil
+	}
+	if name := p.name(); name != "" {
+		return "", nil
+	}
+	if p.i >= len(p.s) || p.s[p.i] != '{' {
+		return "$", nil
+	}
+	p.i++
+	if p.i < len(p.s) && isDigit(p.s[p.i]) {
+		p.int()
+	} else if p.name() == "" {
+		return "", p.errorf("expected a tabstop number or variable name")
+	}
+	if p.i >= len(p.s) {
+		p.i = start
+		return "", p.errorf("unclosed %q", "${")
+	}
+	switch p.s[p.i] {
+	case '}':
+		p.i++
+		return "", nil
+	case ':':
+		p.i++
+		text, err = p.any(true)
+		if err != nil {
+			return "", err
+		}
+		if p.i >= len(p.s) {
+			p.i = start
+			return "", p.errorf("unclosed %q", "${")
+		}
+		p.i++
+		return text, nil
+	case '|':
+		p.i++
+		return p.choice(start)
+	}
+	return "", p.errorf("unexpected %q", p.s[p.i])
+}
+
+// choice reads the options of a choice, and returns the first.
+func (p *parser) choice(start int) (first string, err error) {
+	var options []string
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s) && strings.IndexByte(`$}\,|`, p.s[p.i+1]) >= 0:
+			sb.WriteByte(p.s[p.i+1])
+			p.i += 2
+		case c == ',':
+			options = append(options, sb.String())
+			sb.Reset()
+			p.i++
+		case c == '|':
+			if p.i+1 >= len(p.s) || p.s[p.i+1] != '}' {
+				p.i++
+				return "", p.errorf("expected %q to end the choice", "}")
+			}
+			p.i += 2
+			return append(options, sb.String())[0], nil
+		default:
+			sb.WriteByte(c)
+			p.i++
+		}
+	}
+	p.i = start
+	return "", p.errorf("unclosed choice")
+}
+
+func (p *parser) int() {
+	for p.i < len(p.s) && isDigit(p.s[p.i]) {
+		p.i++
+	}
+}
+
+// name reads a variable name, e.g. TM_SELECTED_TEXT.
+func (p *parser) name() string {
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (p.i > start && isDigit(c)) {
+			p.i++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.i]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,63 @@
+package snippet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "text", body: "Preheat the #oven{}.", expected: "Preheat the #oven{}."},
+		{name: "tabstops are removed", body: "Add the @$1{$2%g}.$0", expected: "Add the @{%g}."},
+		{name: "braced tabstops are removed", body: "Add ${1}.", expected: "Add ."},
+		{name: "placeholders are replaced by their defaults", body: "Preheat the #oven{} to ${1:200}°C.", expected: "Preheat the #oven{} to 200°C."},
+		{name: "placeholders can be nested", body: "Bake for ~{${1:20%${2:minutes}}}.", expected: "Bake for ~{20%minutes}."},
+		{name: "choices are replaced by the first option", body: "Use a #${1|frying pan,wok|}{}.", expected: "Use a #frying pan{}."},
+		{name: "escaped characters in choices", body: "${1|a\\,b,c|}", expected: "a,b"},
+		{name: "variables are removed", body: "$TM_SELECTED_TEXT ${CLIPBOARD}", expected: " "},
+		{name: "variables with defaults", body: "${TM_SELECTED_TEXT:salt}", expected: "salt"},
+		{name: "escapes", body: "Costs \\$5 \\} \\\\", expected: "Costs $5 } \\"},
+		{name: "a lone dollar is text", body: "Costs $ 5", expected: "Costs $ 5"},
+		{name: "closing braces outside placeholders are text", body: "Use the #oven{}.", expected: "Use the #oven{}."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := PlainText(test.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedOffset int
+	}{
+		{name: "unclosed placeholder", body: "Preheat to ${1:200°C.", expectedOffset: 11},
+		{name: "unclosed tabstop", body: "Add ${1", expectedOffset: 4},
+		{name: "missing tabstop number", body: "Add ${:salt}.", expectedOffset: 6},
+		{name: "unclosed choice", body: "Use ${1|pan,wok}", expectedOffset: 4},
+		{name: "unexpected character", body: "Add ${1!}", expectedOffset: 7},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var syntaxErr *SyntaxError
+			if err := Validate(test.body); !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a syntax error, got %v", err)
+			}
+			if syntaxErr.Offset != test.expectedOffset {
+				t.Errorf("expected the error at offset %d, got %d: %v", test.expectedOffset, syntaxErr.Offset, syntaxErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import "runtime/debug"
+
+// version of the server, from the module version it was built from.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "(unknown)"
+	}
+	return info.Main.Version
+}
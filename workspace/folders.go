@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+// Folders are the workspace folders open in the client, which can change while
+// the server is running. It's safe for concurrent use.
+type Folders struct {
+	lock    sync.Mutex
+	folders []messages.WorkspaceFolder
+}
+
+// NewFolders returns the workspace folders that the client opened. The
+// workspaceFolders are used if the client sent any, otherwise the deprecated
+// rootUri, then rootPath.
+func NewFolders(params messages.InitializeParams) *Folders {
+	f := &Folders{}
+	switch {
+	case len(params.WorkspaceFolders) > 0:
+		f.Change(messages.WorkspaceFoldersChangeEvent{Added: params.WorkspaceFolders})
+	case params.RootURI != nil:
+		f.add(messages.WorkspaceFolder{URI: *params.RootURI, Name: folderName(*params.RootURI)})
+	case params.RootPath != nil:
+		uri, err := URIFromPath(*params.RootPath)
+		if err == nil {
+			f.add(messages.WorkspaceFolder{URI: uri, Name: filepath.Base(*params.RootPath)})
+		}
+	}
+	return f
+}
+
+func folderName(uri string) string {
+	path, err := PathFromURI(uri)
+	if err != nil {
+		return uri
+	}
+	return filepath.Base(path)
+}
+
+// Change the folders by removing, then adding, the folders in the event.
+func (f *Folders) Change(event messages.WorkspaceFoldersChangeEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, removed := range event.Removed {
+		for i, folder := range f.folders {
+			if folder.URI == removed.URI {
+				f.folders = append(f.folders[:i], f.folders[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, added := range event.Added {
+		f.addLocked(added)
+	}
+}
+
+func (f *Folders) add(folder messages.WorkspaceFolder) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.addLocked(folder)
+}
+
+func (f *Folders) addLocked(folder messages.WorkspaceFolder) {
+	for _, existing := range f.folders {
+		if existing.URI == folder.URI {
+			return
+		}
+	}
+	f.folders = append(f.folders, folder)
+}
+
+// Roots returns the paths of the folders, in the order they were added.
+// Folders that aren't on disk are skipped.
+func (f *Folders) Roots() (roots []string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, folder := range f.folders {
+		if path, err := PathFromURI(folder.URI); err == nil {
+			roots = append(roots, path)
+		}
+	}
+	return roots
+}
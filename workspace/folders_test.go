@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestNewFolders(t *testing.T) {
+	rootURI := "file:///recipes"
+	rootPath := filepath.FromSlash("/old")
+	tests := []struct {
+		name     string
+		params   messages.InitializeParams
+		expected []string
+	}{
+		{
+			name:     "no workspace",
+			params:   messages.InitializeParams{},
+			expected: nil,
+		},
+		{
+			name:     "only rootUri",
+			params:   messages.InitializeParams{RootURI: &rootURI},
+			expected: []string{filepath.FromSlash("/recipes")},
+		},
+		{
+			name:     "only rootPath",
+			params:   messages.InitializeParams{RootPath: &rootPath},
+			expected: []string{rootPath},
+		},
+		{
+			name: "only workspaceFolders",
+			params: messages.InitializeParams{
+				WorkspaceFolders: []messages.WorkspaceFolder{
+					{URI: "file:///recipes/italian", Name: "italian"},
+					{URI: "file:///recipes/french", Name: "french"},
+				},
+			},
+			expected: []string{filepath.FromSlash("/recipes/italian"), filepath.FromSlash("/recipes/french")},
+		},
+		{
+			name: "workspaceFolders are used instead of rootUri",
+			params: messages.InitializeParams{
+				RootPath: &rootPath,
+				RootURI:  &rootURI,
+				WorkspaceFolders: []messages.WorkspaceFolder{
+					{URI: "file:///recipes/italian", Name: "italian"},
+				},
+			},
+			expected: []string{filepath.FromSlash("/recipes/italian")},
+		},
+		{
+			name:     "rootUri is used when there are no workspaceFolders",
+			params:   messages.InitializeParams{RootPath: &rootPath, RootURI: &rootURI, WorkspaceFolders: []messages.WorkspaceFolder{}},
+			expected: []string{filepath.FromSlash("/recipes")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := NewFolders(test.params).Roots(); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFoldersChange(t *testing.T) {
+	rootURI := "file:///recipes"
+	f := NewFolders(messages.InitializeParams{RootURI: &rootURI})
+	f.Change(messages.WorkspaceFoldersChangeEvent{
+		Added: []messages.WorkspaceFolder{
+			{URI: "file:///cakes", Name: "cakes"},
+			{URI: "file:///recipes", Name: "recipes"},
+			{URI: "https://example.com/recipes", Name: "remote"},
+		},
+	})
+	if expected, actual := []string{filepath.FromSlash("/recipes"), filepath.FromSlash("/cakes")}, f.Roots(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	f.Change(messages.WorkspaceFoldersChangeEvent{
+		Added:   []messages.WorkspaceFolder{{URI: "file:///bread", Name: "bread"}},
+		Removed: []messages.WorkspaceFolder{{URI: "file:///recipes", Name: "recipes"}},
+	})
+	if expected, actual := []string{filepath.FromSlash("/cakes"), filepath.FromSlash("/bread")}, f.Roots(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,211 @@
+// Package workspace indexes the recipes on disk in the workspace, so that
+// commands can work on files that aren't open in the client.
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/examplelsp/documents"
+	"github.com/a-h/examplelsp/messages"
+)
+
+// File is a recipe on disk.
+type File struct {
+	URI  string
+	Path string
+	Text string
+	// modTime and size are used to detect changes to the file since it was
+	// read.
+	modTime time.Time
+	size    int64
+}
+
+// Index of the .cook files under a root directory. It's safe for concurrent
+// use.
+type Index struct {
+	root        string
+	lock        sync.Mutex
+	files       map[string]File
+	subscribers map[int]func()
+	nextID      int
+}
+
+// NewIndex creates an index of the root directory. The index is empty until
+// Refresh is called.
+func NewIndex(root string) *Index {
+	return &Index{
+		root:        root,
+		files:       map[string]File{},
+		subscribers: map[int]func(){},
+	}
+}
+
+// Refresh walks the root directory, reading files that are new or have changed
+// on disk since they were last read, and dropping files that have been
+// removed. Hidden directories are skipped. It returns the files, sorted by URI.
+func (ix *Index) Refresh() (files []File, err error) {
+	return ix.RefreshContext(context.Background(), nil)
+}
+
+// RefreshContext is Refresh, but stops reading files if the context is
+// cancelled, and calls report, if not nil, after each .cook file is checked.
+// Subscribers are notified if any files were read or dropped.
+func (ix *Index) RefreshContext(ctx context.Context, report func(done, total int)) (files []File, err error) {
+	var changed bool
+	defer func() {
+		if changed {
+			ix.notify()
+		}
+	}()
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+	var paths []string
+	err = filepath.WalkDir(ix.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != ix.root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".cook" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("workspace: failed to index %q: %w", ix.root, err)
+	}
+	seen := map[string]bool{}
+	for i, path := range paths {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		uri, read, err := ix.read(path)
+		if err != nil {
+			return nil, fmt.Errorf("workspace: failed to index %q: %w", path, err)
+		}
+		changed = changed || read
+		seen[uri] = true
+		if report != nil {
+			report(i+1, len(paths))
+		}
+	}
+	for uri, f := range ix.files {
+		if !seen[uri] {
+			delete(ix.files, uri)
+			changed = true
+			continue
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].URI < files[j].URI
+	})
+	return files, nil
+}
+
+// read the file into the index, unless it's unchanged since it was last read.
+func (ix *Index) read(path string) (uri string, read bool, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	uri, err = URIFromPath(path)
+	if err != nil {
+		return "", false, err
+	}
+	if f, ok := ix.files[uri]; ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+		return uri, false, nil
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	// Clients don't count the byte order mark in positions.
+	trimmed, _ := documents.TrimBOM(string(text))
+	ix.files[uri] = File{
+		URI:     uri,
+		Path:    path,
+		Text:    trimmed,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+	return uri, true, nil
+}
+
+// Subscribe calls f each time a refresh changes the files in the index. f
+// must not block. Call unsubscribe to stop receiving calls.
+func (ix *Index) Subscribe(f func()) (unsubscribe func()) {
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+	id := ix.nextID
+	ix.nextID++
+	ix.subscribers[id] = f
+	return func() {
+		ix.lock.Lock()
+		defer ix.lock.Unlock()
+		delete(ix.subscribers, id)
+	}
+}
+
+func (ix *Index) notify() {
+	ix.lock.Lock()
+	subscribers := make([]func(), 0, len(ix.subscribers))
+	for _, f := range ix.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	ix.lock.Unlock()
+	for _, f := range subscribers {
+		f()
+	}
+}
+
+// Root directory of the index.
+func (ix *Index) Root() string {
+	return ix.root
+}
+
+// Texts returns the text of each file in the index, keyed by URI, without
+// reading the files again.
+func (ix *Index) Texts() (texts map[string]string) {
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+	texts = make(map[string]string, len(ix.files))
+	for uri, f := range ix.files {
+		texts[uri] = f.Text
+	}
+	return texts
+}
+
+// Get a file from the index.
+func (ix *Index) Get(uri string) (f File, ok bool) {
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+	f, ok = ix.files[uri]
+	return
+}
+
+// URIFromPath returns the normalized file URI of the path.
+func URIFromPath(path string) (uri string, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return string(messages.DocumentURIFromPath(abs)), nil
+}
+
+// PathFromURI returns the path of a file URI.
+func PathFromURI(uri string) (path string, err error) {
+	return messages.DocumentURI(uri).Filename()
+}
@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+}
+
+func TestIndexRefresh(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "pasta.cook"), "Boil the @pasta.")
+	writeFile(t, filepath.Join(root, "sauces", "tomato.cook"), "Add the @tomatoes.")
+	writeFile(t, filepath.Join(root, "notes.txt"), "Not a recipe.")
+	writeFile(t, filepath.Join(root, ".git", "hidden.cook"), "Add the @salt.")
+
+	ix := NewIndex(root)
+	files, err := ix.Refresh()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d: %#v", len(files), files)
+	}
+	if files[0].Text != "Boil the @pasta." || files[1].Text != "Add the @tomatoes." {
+		t.Errorf("unexpected files: %#v", files)
+	}
+	if path, err := PathFromURI(files[0].URI); err != nil || path != files[0].Path {
+		t.Errorf("expected the URI to map back to %q, got %q: %v", files[0].Path, path, err)
+	}
+
+	t.Run("changed files are re-read", func(t *testing.T) {
+		writeFile(t, files[0].Path, "Boil the @spaghetti.")
+		// Make sure that the change is visible even if the file system has a
+		// coarse modification time.
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(files[0].Path, later, later); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+		if _, err := ix.Refresh(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f, ok := ix.Get(files[0].URI)
+		if !ok || f.Text != "Boil the @spaghetti." {
+			t.Errorf("expected the file to be re-read, got %#v", f)
+		}
+	})
+	t.Run("removed files are dropped", func(t *testing.T) {
+		if err := os.Remove(files[1].Path); err != nil {
+			t.Fatalf("failed to remove file: %v", err)
+		}
+		refreshed, err := ix.Refresh()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(refreshed) != 1 {
+			t.Errorf("expected 1 file, got %#v", refreshed)
+		}
+		if _, ok := ix.Get(files[1].URI); ok {
+			t.Errorf("expected the removed file to be dropped from the index")
+		}
+	})
+}
+
+func TestIndexRefreshContext(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.cook", "b.cook", "c.cook"} {
+		writeFile(t, filepath.Join(root, name), "Boil the @pasta.")
+	}
+	ix := NewIndex(root)
+
+	var reports [][2]int
+	files, err := ix.RefreshContext(context.Background(), func(done, total int) {
+		reports = append(reports, [2]int{done, total})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("expected 3 files, got %d", len(files))
+	}
+	if expected := [][2]int{{1, 3}, {2, 3}, {3, 3}}; !reflect.DeepEqual(reports, expected) {
+		t.Errorf("expected reports %v, got %v", expected, reports)
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := NewIndex(root).RefreshContext(ctx, func(done, total int) {
+			cancel()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the refresh to be cancelled, got %v", err)
+		}
+	})
+}
+
+func TestIndexSubscribe(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "pasta.cook"), "Boil the @pasta.")
+	ix := NewIndex(root)
+	var notifications int
+	unsubscribe := ix.Subscribe(func() { notifications++ })
+
+	refresh := func(expected int) {
+		t.Helper()
+		if _, err := ix.Refresh(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if notifications != expected {
+			t.Errorf("expected %d notifications, got %d", expected, notifications)
+		}
+	}
+	refresh(1)
+	// Nothing has changed on disk.
+	refresh(1)
+	writeFile(t, filepath.Join(root, "soup.cook"), "Add the @stock.")
+	refresh(2)
+	if err := os.Remove(filepath.Join(root, "soup.cook")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	refresh(3)
+	unsubscribe()
+	writeFile(t, filepath.Join(root, "soup.cook"), "Add the @stock.")
+	refresh(3)
+}
@@ -0,0 +1,213 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// BaseMetadataKey is the metadata key that links a recipe to the recipe it's
+// based on, e.g. ">> base: ../sauces/tomato.cook". The path is relative to
+// the recipe, and the .cook extension is optional.
+const BaseMetadataKey = "base"
+
+// MaxLinkDepth is the number of links that Walk follows from a recipe.
+const MaxLinkDepth = 32
+
+// Link from one recipe to another.
+type Link struct {
+	From string
+	To   string
+	// Range of the metadata line that makes the link.
+	Range messages.Range
+}
+
+// Links returns the links made by the metadata of a recipe.
+func Links(uri, text string) (links []Link) {
+	from, err := url.Parse(uri)
+	if err != nil {
+		return nil
+	}
+	for _, m := range recipe.ParseMetadata(text) {
+		if m.Key != BaseMetadataKey || m.Value == "" {
+			continue
+		}
+		links = append(links, Link{From: uri, To: linkTarget(from, m.Value), Range: m.Range})
+	}
+	return links
+}
+
+// linkTarget returns the URI of the recipe at the path, relative to the
+// recipe at from.
+func linkTarget(from *url.URL, p string) string {
+	if path.Ext(p) == "" {
+		p += ".cook"
+	}
+	to := *from
+	to.Path = path.Join(path.Dir(from.Path), p)
+	return to.String()
+}
+
+// DocumentLinkData is kept in the data of a document link, so that its
+// target can be computed when the link is resolved.
+type DocumentLinkData struct {
+	// URI of the recipe that makes the link.
+	URI string `json:"uri"`
+	// Path of the linked recipe, as it's written in the metadata.
+	Path string `json:"path"`
+}
+
+// DocumentLinks returns a link for the path of each recipe that the recipe is
+// based on. The links don't have targets, which are filled in by
+// ResolveDocumentLink.
+func DocumentLinks(uri, text string) (links []messages.DocumentLink) {
+	links = []messages.DocumentLink{}
+	lines := strings.Split(text, "\n")
+	for _, m := range recipe.ParseMetadata(text) {
+		if m.Key != BaseMetadataKey || m.Value == "" {
+			continue
+		}
+		// The link covers the path, not the whole metadata line.
+		line := strings.TrimSuffix(lines[m.Range.Start.Line], "\r")
+		colon := strings.Index(line, ":")
+		start := colon + 1 + strings.Index(line[colon+1:], m.Value)
+		data, err := json.Marshal(DocumentLinkData{URI: uri, Path: m.Value})
+		if err != nil {
+			continue
+		}
+		links = append(links, messages.DocumentLink{
+			Range: messages.Range{
+				Start: messages.NewPosition(m.Range.Start.Line, utf16Len(line[:start])),
+				End:   messages.NewPosition(m.Range.Start.Line, utf16Len(line[:start+len(m.Value)])),
+			},
+			Data: data,
+		})
+	}
+	return links
+}
+
+// ResolveDocumentLink returns the link with its target filled in from its
+// data.
+func ResolveDocumentLink(link messages.DocumentLink) (resolved messages.DocumentLink, err error) {
+	var data DocumentLinkData
+	if err = json.Unmarshal(link.Data, &data); err != nil {
+		return link, fmt.Errorf("workspace: invalid document link data: %w", err)
+	}
+	from, err := url.Parse(data.URI)
+	if err != nil {
+		return link, fmt.Errorf("workspace: invalid document link URI: %w", err)
+	}
+	target := linkTarget(from, data.Path)
+	link.Target = &target
+	return link, nil
+}
+
+// Graph of the links between recipes.
+type Graph struct {
+	links map[string][]Link
+}
+
+// NewGraph creates the graph of the links made by the recipes, keyed by URI.
+func NewGraph(texts map[string]string) *Graph {
+	g := &Graph{links: make(map[string][]Link, len(texts))}
+	for uri, text := range texts {
+		if links := Links(uri, text); len(links) > 0 {
+			g.links[uri] = links
+		}
+	}
+	return g
+}
+
+// Cycle of links, where each recipe is based on the next, and the last is
+// based on the first.
+type Cycle struct {
+	// Link that closes the cycle.
+	Link Link
+	// Chain of URIs, starting and ending with the recipe linked to by Link.
+	Chain []string
+}
+
+// Cycles returns the cycles in the graph. Each cycle is reported once, at the
+// link that closes it when the graph is searched from each URI in order.
+func (g *Graph) Cycles() (cycles []Cycle) {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(g.links))
+	var stack []string
+	var visit func(uri string)
+	visit = func(uri string) {
+		state[uri] = inProgress
+		stack = append(stack, uri)
+		for _, link := range g.links[uri] {
+			switch state[link.To] {
+			case unvisited:
+				visit(link.To)
+			case inProgress:
+				var chain []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == link.To {
+						chain = append(append(chain, stack[i:]...), link.To)
+						break
+					}
+				}
+				cycles = append(cycles, Cycle{Link: link, Chain: chain})
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[uri] = done
+	}
+	for _, uri := range g.uris() {
+		if state[uri] == unvisited {
+			visit(uri)
+		}
+	}
+	return cycles
+}
+
+func (g *Graph) uris() (uris []string) {
+	uris = make([]string, 0, len(g.links))
+	for uri := range g.links {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// Walk calls visit for the recipe, and each recipe it's linked to, directly
+// or indirectly. Each recipe is visited once, even if the links form a cycle,
+// and links more than MaxLinkDepth away aren't followed. depth is the number
+// of links from uri. It returns false if links were left out because of the
+// depth limit.
+func (g *Graph) Walk(uri string, visit func(uri string, depth int)) (complete bool) {
+	complete = true
+	visited := map[string]bool{uri: true}
+	queue := []string{uri}
+	for depth := 0; len(queue) > 0; depth++ {
+		var next []string
+		for _, u := range queue {
+			visit(u, depth)
+			for _, link := range g.links[u] {
+				if visited[link.To] {
+					continue
+				}
+				if depth == MaxLinkDepth {
+					complete = false
+					continue
+				}
+				visited[link.To] = true
+				next = append(next, link.To)
+			}
+		}
+		queue = next
+	}
+	return complete
+}
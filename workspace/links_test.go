@@ -0,0 +1,155 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestLinks(t *testing.T) {
+	text := ">> servings: 2\n>> base: ../sauces/tomato\n>> base: pesto.cook\n>> base:\n\nAdd the @pasta."
+	links := Links("file:///recipes/pasta/penne.cook", text)
+	expected := []Link{
+		{
+			From:  "file:///recipes/pasta/penne.cook",
+			To:    "file:///recipes/sauces/tomato.cook",
+			Range: messages.Range{Start: messages.NewPosition(1, 0), End: messages.NewPosition(1, 25)},
+		},
+		{
+			From:  "file:///recipes/pasta/penne.cook",
+			To:    "file:///recipes/pasta/pesto.cook",
+			Range: messages.Range{Start: messages.NewPosition(2, 0), End: messages.NewPosition(2, 19)},
+		},
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %#v, got %#v", expected, links)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		texts    map[string]string
+		expected []Cycle
+	}{
+		{
+			name: "a recipe based on itself",
+			texts: map[string]string{
+				"file:///a.cook": ">> base: a",
+			},
+			expected: []Cycle{
+				{
+					Link:  Link{From: "file:///a.cook", To: "file:///a.cook", Range: lineRange(0, 10)},
+					Chain: []string{"file:///a.cook", "file:///a.cook"},
+				},
+			},
+		},
+		{
+			name: "two recipes based on each other",
+			texts: map[string]string{
+				"file:///a.cook": ">> base: b",
+				"file:///b.cook": ">> servings: 2\n>> base: a",
+			},
+			expected: []Cycle{
+				{
+					Link:  Link{From: "file:///b.cook", To: "file:///a.cook", Range: lineRange(1, 10)},
+					Chain: []string{"file:///a.cook", "file:///b.cook", "file:///a.cook"},
+				},
+			},
+		},
+		{
+			name: "a diamond is not a cycle",
+			texts: map[string]string{
+				"file:///a.cook": ">> base: b\n>> base: c",
+				"file:///b.cook": ">> base: d",
+				"file:///c.cook": ">> base: d",
+				"file:///d.cook": "Boil the @water.",
+			},
+			expected: nil,
+		},
+		{
+			name: "links to recipes that don't exist",
+			texts: map[string]string{
+				"file:///a.cook": ">> base: missing",
+			},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cycles := NewGraph(test.texts).Cycles()
+			if !reflect.DeepEqual(cycles, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, cycles)
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Run("each recipe is visited once", func(t *testing.T) {
+		g := NewGraph(map[string]string{
+			"file:///a.cook": ">> base: b\n>> base: c",
+			"file:///b.cook": ">> base: d",
+			"file:///c.cook": ">> base: d",
+			"file:///d.cook": ">> base: a",
+		})
+		visited := map[string]int{}
+		if complete := g.Walk("file:///a.cook", func(uri string, depth int) { visited[uri]++ }); !complete {
+			t.Error("expected the walk to be complete")
+		}
+		expected := map[string]int{"file:///a.cook": 1, "file:///b.cook": 1, "file:///c.cook": 1, "file:///d.cook": 1}
+		if !reflect.DeepEqual(visited, expected) {
+			t.Errorf("expected %v, got %v", expected, visited)
+		}
+	})
+	t.Run("long chains are limited", func(t *testing.T) {
+		texts := map[string]string{}
+		for i := 0; i < MaxLinkDepth*2; i++ {
+			texts[fmt.Sprintf("file:///%d.cook", i)] = fmt.Sprintf(">> base: %d", i+1)
+		}
+		var maxDepth int
+		complete := NewGraph(texts).Walk("file:///0.cook", func(uri string, depth int) { maxDepth = depth })
+		if complete {
+			t.Error("expected the walk to be incomplete")
+		}
+		if maxDepth != MaxLinkDepth {
+			t.Errorf("expected a maximum depth of %d, got %d", MaxLinkDepth, maxDepth)
+		}
+	})
+}
+
+func lineRange(line, length int) messages.Range {
+	return messages.Range{Start: messages.NewPosition(line, 0), End: messages.NewPosition(line, length)}
+}
+
+func TestDocumentLinks(t *testing.T) {
+	text := ">> base: ../sauces/tomato\r\n>> servings: 2\r\n>>   base :  crème/fraîche.cook\r\n>> base:\r\n\r\nAdd the @pasta."
+	links := DocumentLinks("file:///recipes/pasta/penne.cook", text)
+	expected := []messages.DocumentLink{
+		{
+			Range: messages.Range{Start: messages.NewPosition(0, 9), End: messages.NewPosition(0, 25)},
+			Data:  json.RawMessage(`{"uri":"file:///recipes/pasta/penne.cook","path":"../sauces/tomato"}`),
+		},
+		{
+			Range: messages.Range{Start: messages.NewPosition(2, 13), End: messages.NewPosition(2, 31)},
+			Data:  json.RawMessage(`{"uri":"file:///recipes/pasta/penne.cook","path":"crème/fraîche.cook"}`),
+		},
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %#v, got %#v", expected, links)
+	}
+	if links := DocumentLinks("file:///a.cook", "Boil @water."); links == nil || len(links) != 0 {
+		t.Errorf("expected no links, got %#v", links)
+	}
+}
+
+func TestResolveDocumentLinkInvalidData(t *testing.T) {
+	var syntaxErr *json.SyntaxError
+	if _, err := ResolveDocumentLink(messages.DocumentLink{Data: json.RawMessage(`{`)}); !errors.As(err, &syntaxErr) {
+		t.Errorf("expected a syntax error, got %v", err)
+	}
+}
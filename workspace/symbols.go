@@ -0,0 +1,234 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/a-h/examplelsp/messages"
+	"github.com/a-h/examplelsp/recipe"
+)
+
+// Symbols returns the title of the recipe, and the first use of each
+// ingredient, item of cookware and named timer in the recipe whose name
+// contains the query, ignoring case.
+func Symbols(uri, text, query string) (symbols []messages.SymbolInformation) {
+	container := recipeName(uri)
+	query = strings.ToLower(query)
+	seen := map[string]bool{}
+	add := func(name string, kind messages.SymbolKind, r messages.Range) {
+		key := strings.ToLower(name)
+		if seen[key] || !strings.Contains(key, query) {
+			return
+		}
+		seen[key] = true
+		symbols = append(symbols, messages.SymbolInformation{
+			Name:          name,
+			Kind:          kind,
+			Location:      messages.Location{URI: uri, Range: r},
+			ContainerName: container,
+		})
+	}
+	r := recipe.Parse(text)
+	if title, ok := findMetadata(r.Metadata, titleKey); ok && title.Value != "" {
+		add(title.Value, messages.SymbolKindFile, title.Range)
+	}
+	// Names are prefixed as they are in the markup, which keeps a ~rest timer
+	// apart from a @rest ingredient.
+	for _, step := range r.Steps {
+		for _, ingredient := range step.Ingredients {
+			add("@"+ingredient.Name, messages.SymbolKindVariable, ingredient.Range)
+		}
+		for _, cookware := range step.Cookware {
+			add("#"+cookware.Name, messages.SymbolKindObject, cookware.Range)
+		}
+		for _, timer := range step.Timers {
+			if timer.Name != "" {
+				add("~"+timer.Name, messages.SymbolKindEvent, timer.Range)
+			}
+		}
+	}
+	return symbols
+}
+
+// titleKey is the metadata key of the title of a recipe.
+const titleKey = "title"
+
+func findMetadata(metadata []recipe.Metadata, key string) (m recipe.Metadata, ok bool) {
+	for _, m := range metadata {
+		if strings.EqualFold(m.Key, key) {
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// SymbolData is the data of a workspace symbol whose range is resolved by
+// ResolveWorkspaceSymbol.
+type SymbolData struct {
+	// Metadata is the key of the metadata line the symbol is for.
+	Metadata string `json:"metadata"`
+}
+
+// WorkspaceSymbols returns the Symbols for clients that resolve the ranges of
+// workspace symbols. The title is returned with only the URI of the recipe,
+// and its range is found by ResolveWorkspaceSymbol.
+func WorkspaceSymbols(uri, text, query string) (symbols []messages.WorkspaceSymbol) {
+	for _, s := range Symbols(uri, text, query) {
+		symbol := messages.NewWorkspaceSymbol(s)
+		if s.Kind == messages.SymbolKindFile {
+			symbol.Location.Range = nil
+			symbol.Data, _ = json.Marshal(SymbolData{Metadata: titleKey})
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// ResolveWorkspaceSymbol returns the symbol with the range of the metadata
+// line it's for. If the line has been removed from the text since the symbol
+// was returned, the range is the start of the recipe.
+func ResolveWorkspaceSymbol(symbol messages.WorkspaceSymbol, text string) (messages.WorkspaceSymbol, error) {
+	if symbol.Location.Range != nil {
+		return symbol, nil
+	}
+	var data SymbolData
+	if err := json.Unmarshal(symbol.Data, &data); err != nil {
+		return symbol, fmt.Errorf("workspace: invalid workspace symbol data: %w", err)
+	}
+	r := messages.Range{}
+	if m, ok := findMetadata(recipe.ParseMetadata(text), data.Metadata); ok {
+		r = m.Range
+	}
+	symbol.Location.Range = &r
+	return symbol, nil
+}
+
+// DocumentSymbols returns the outline of a recipe. Metadata is listed first,
+// as the children of a Metadata symbol, followed by each step, with the
+// ingredients, cookware and timers of the step as its children, in the order
+// they're written. Recipes are parsed leniently, so text that isn't valid
+// markup is left out, and the rest of the outline is returned.
+func DocumentSymbols(text string) (symbols []messages.DocumentSymbol) {
+	symbols = []messages.DocumentSymbol{}
+	r := recipe.Parse(text)
+	if len(r.Metadata) > 0 {
+		metadata := messages.DocumentSymbol{
+			Name: "Metadata",
+			Kind: messages.SymbolKindNamespace,
+			Range: messages.Range{
+				Start: r.Metadata[0].Range.Start,
+				End:   r.Metadata[len(r.Metadata)-1].Range.End,
+			},
+			SelectionRange: r.Metadata[0].Range,
+		}
+		for _, m := range r.Metadata {
+			metadata.Children = append(metadata.Children, messages.DocumentSymbol{
+				Name:           m.Key,
+				Detail:         m.Value,
+				Kind:           messages.SymbolKindProperty,
+				Range:          m.Range,
+				SelectionRange: m.Range,
+			})
+		}
+		symbols = append(symbols, metadata)
+	}
+	lines := strings.Split(text, "\n")
+	for i, step := range r.Steps {
+		// The first line of the step is selected when it's picked.
+		first := strings.TrimSuffix(lines[step.Range.Start.Line], "\r")
+		selection := messages.Range{
+			Start: step.Range.Start,
+			End:   messages.NewPosition(step.Range.Start.Line, utf16Len(first)),
+		}
+		if step.Range.End.Before(selection.End) {
+			selection.End = step.Range.End
+		}
+		symbols = append(symbols, messages.DocumentSymbol{
+			Name:           fmt.Sprintf("Step %d", i+1),
+			Detail:         truncate(firstSentence(step.Text), stepDetailLength),
+			Kind:           messages.SymbolKindFunction,
+			Range:          step.Range,
+			SelectionRange: selection,
+			Children:       stepSymbols(step),
+		})
+	}
+	return symbols
+}
+
+// stepDetailLength is the maximum number of characters of a step's first
+// sentence that are shown next to it in the outline.
+const stepDetailLength = 80
+
+// firstSentence returns the text up to the end of its first sentence.
+func firstSentence(text string) string {
+	for i, r := range text {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		if end := i + 1; end == len(text) || text[end] == ' ' {
+			return text[:end]
+		}
+	}
+	return text
+}
+
+// stepSymbols returns the elements of the step. Each element covers the whole
+// step, and its markup is selected when it's picked.
+func stepSymbols(step recipe.Step) (children []messages.DocumentSymbol) {
+	add := func(name, detail string, kind messages.SymbolKind, r messages.Range) {
+		children = append(children, messages.DocumentSymbol{
+			Name:           name,
+			Detail:         detail,
+			Kind:           kind,
+			Range:          step.Range,
+			SelectionRange: r,
+		})
+	}
+	for _, ingredient := range step.Ingredients {
+		add("@"+ingredient.Name, strings.TrimSpace(ingredient.Quantity+" "+ingredient.Unit), messages.SymbolKindField, ingredient.Range)
+	}
+	for _, cookware := range step.Cookware {
+		add("#"+cookware.Name, cookware.Quantity, messages.SymbolKindObject, cookware.Range)
+	}
+	for _, timer := range step.Timers {
+		name := "~" + timer.Name
+		if timer.Name == "" {
+			// Symbols must have a name, so unnamed timers are named by their
+			// duration.
+			name = "~" + strings.TrimSpace(timer.Quantity+" "+timer.Unit)
+		}
+		add(name, strings.TrimSpace(timer.Quantity+" "+timer.Unit), messages.SymbolKindEvent, timer.Range)
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].SelectionRange.Start.Before(children[j].SelectionRange.Start)
+	})
+	return children
+}
+
+// truncate s to n runes, adding an ellipsis if it's shortened.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimRightFunc(string([]rune(s)[:n]), unicode.IsSpace) + "…"
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// recipeName returns the name of the recipe file, without the extension.
+func recipeName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(path.Base(u.Path), ".cook")
+}
@@ -0,0 +1,186 @@
+package workspace
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/a-h/examplelsp/messages"
+)
+
+func TestSymbols(t *testing.T) {
+	uri := "file:///recipes/pasta.cook"
+	text := "Boil @water in a #pot{} for ~boil{10%minutes}.\nAdd the @Water and @salt, then ~{2%minutes}."
+	location := func(line, start, end int) messages.Location {
+		return messages.Location{
+			URI:   uri,
+			Range: messages.Range{Start: messages.NewPosition(line, start), End: messages.NewPosition(line, end)},
+		}
+	}
+	tests := []struct {
+		query    string
+		expected []messages.SymbolInformation
+	}{
+		{
+			query: "",
+			expected: []messages.SymbolInformation{
+				{Name: "@water", Kind: messages.SymbolKindVariable, Location: location(0, 5, 11), ContainerName: "pasta"},
+				{Name: "@salt", Kind: messages.SymbolKindVariable, Location: location(1, 19, 24), ContainerName: "pasta"},
+				{Name: "#pot", Kind: messages.SymbolKindObject, Location: location(0, 17, 23), ContainerName: "pasta"},
+				{Name: "~boil", Kind: messages.SymbolKindEvent, Location: location(0, 28, 45), ContainerName: "pasta"},
+			},
+		},
+		{
+			query: "SALT",
+			expected: []messages.SymbolInformation{
+				{Name: "@salt", Kind: messages.SymbolKindVariable, Location: location(1, 19, 24), ContainerName: "pasta"},
+			},
+		},
+		{
+			query: "flour",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			actual := Symbols(uri, text, test.query)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSymbolsIncludeTitle(t *testing.T) {
+	uri := "file:///recipes/pasta.cook"
+	text := ">> servings: 2\n>> Title: Penne all'arrabbiata\n\nBoil @water."
+	expected := []messages.SymbolInformation{
+		{
+			Name: "Penne all'arrabbiata",
+			Kind: messages.SymbolKindFile,
+			Location: messages.Location{
+				URI:   uri,
+				Range: messages.Range{Start: messages.NewPosition(1, 0), End: messages.NewPosition(1, 30)},
+			},
+			ContainerName: "pasta",
+		},
+	}
+	if actual := Symbols(uri, text, "penne"); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestWorkspaceSymbols(t *testing.T) {
+	uri := "file:///recipes/pasta.cook"
+	text := ">> title: Pasta\n\nBoil @water."
+	symbols := WorkspaceSymbols(uri, text, "")
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %#v", symbols)
+	}
+	title, water := symbols[0], symbols[1]
+	if title.Name != "Pasta" || title.Location.Range != nil || string(title.Data) != `{"metadata":"title"}` {
+		t.Errorf("expected the title without a range, got %#v", title)
+	}
+	if _, ok := water.Location.Location(); !ok || water.Data != nil {
+		t.Errorf("expected other symbols to have a full location, got %#v", water)
+	}
+
+	resolved, err := ResolveWorkspaceSymbol(title, text)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := messages.Range{Start: messages.NewPosition(0, 0), End: messages.NewPosition(0, 15)}
+	if resolved.Location.Range == nil || *resolved.Location.Range != expected {
+		t.Errorf("expected the range of the title line, got %#v", resolved.Location.Range)
+	}
+
+	// The title was removed before the symbol was resolved.
+	resolved, err = ResolveWorkspaceSymbol(title, "Boil @water.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.Location.Range == nil || *resolved.Location.Range != (messages.Range{}) {
+		t.Errorf("expected the start of the recipe, got %#v", resolved.Location.Range)
+	}
+
+	if _, err := ResolveWorkspaceSymbol(messages.WorkspaceSymbol{Location: messages.WorkspaceSymbolLocation{URI: uri}}, text); err == nil {
+		t.Error("expected an error for a symbol without data")
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	text := ">> servings: 2\n>> time: 30 minutes\n\n" +
+		"Boil @water{1%l} in a #pot{} for ~{10%minutes}. Add @salt.\n\n" +
+		"-- A comment.\nAdd the @penne{500%g} and stir!\nDrain.\n\n" +
+		"Serve with @crème fraîche{}"
+	r := func(line, start, end int) messages.Range {
+		return messages.Range{Start: messages.NewPosition(line, start), End: messages.NewPosition(line, end)}
+	}
+	step1, step2, step3 := r(3, 0, 58), messages.Range{Start: messages.NewPosition(6, 0), End: messages.NewPosition(7, 6)}, r(9, 0, 27)
+	expected := []messages.DocumentSymbol{
+		{
+			Name:           "Metadata",
+			Kind:           messages.SymbolKindNamespace,
+			Range:          messages.Range{Start: messages.NewPosition(0, 0), End: messages.NewPosition(1, 19)},
+			SelectionRange: r(0, 0, 14),
+			Children: []messages.DocumentSymbol{
+				{Name: "servings", Detail: "2", Kind: messages.SymbolKindProperty, Range: r(0, 0, 14), SelectionRange: r(0, 0, 14)},
+				{Name: "time", Detail: "30 minutes", Kind: messages.SymbolKindProperty, Range: r(1, 0, 19), SelectionRange: r(1, 0, 19)},
+			},
+		},
+		{
+			Name:           "Step 1",
+			Detail:         "Boil water in a pot for 10 minutes.",
+			Kind:           messages.SymbolKindFunction,
+			Range:          step1,
+			SelectionRange: step1,
+			Children: []messages.DocumentSymbol{
+				{Name: "@water", Detail: "1 l", Kind: messages.SymbolKindField, Range: step1, SelectionRange: r(3, 5, 16)},
+				{Name: "#pot", Kind: messages.SymbolKindObject, Range: step1, SelectionRange: r(3, 22, 28)},
+				{Name: "~10 minutes", Detail: "10 minutes", Kind: messages.SymbolKindEvent, Range: step1, SelectionRange: r(3, 33, 46)},
+				{Name: "@salt", Kind: messages.SymbolKindField, Range: step1, SelectionRange: r(3, 52, 57)},
+			},
+		},
+		{
+			Name:           "Step 2",
+			Detail:         "Add the penne and stir!",
+			Kind:           messages.SymbolKindFunction,
+			Range:          step2,
+			SelectionRange: r(6, 0, 31),
+			Children: []messages.DocumentSymbol{
+				{Name: "@penne", Detail: "500 g", Kind: messages.SymbolKindField, Range: step2, SelectionRange: r(6, 8, 21)},
+			},
+		},
+		{
+			Name:           "Step 3",
+			Detail:         "Serve with crème fraîche",
+			Kind:           messages.SymbolKindFunction,
+			Range:          step3,
+			SelectionRange: step3,
+			Children: []messages.DocumentSymbol{
+				{Name: "@crème fraîche", Kind: messages.SymbolKindField, Range: step3, SelectionRange: r(9, 11, 27)},
+			},
+		},
+	}
+	actual := DocumentSymbols(text)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v\ngot %#v", expected, actual)
+	}
+	if empty := DocumentSymbols(""); empty == nil || len(empty) != 0 {
+		t.Errorf("expected no symbols, got %#v", empty)
+	}
+}
+
+func TestDocumentSymbolsOfInvalidRecipes(t *testing.T) {
+	// The timer isn't closed, so it isn't a symbol, but the rest of the step
+	// is.
+	symbols := DocumentSymbols(">> servings\n\nBoil @water in a #pot for ~{10%minutes.")
+	if len(symbols) != 1 || symbols[0].Name != "Step 1" {
+		t.Fatalf("expected a step, got %#v", symbols)
+	}
+	var names []string
+	for _, child := range symbols[0].Children {
+		names = append(names, child.Name)
+	}
+	if expected := []string{"@water", "#pot"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}